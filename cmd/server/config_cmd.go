@@ -0,0 +1,89 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/FreePeak/db-mcp-server/internal/config"
+)
+
+// runConfigCommand implements the "config" subcommand and returns the exit code
+func runConfigCommand(args []string) int {
+	return configCommand(args, os.Stdout, os.Stderr)
+}
+
+// configCommand dispatches config subcommands
+func configCommand(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] != "show" {
+		fmt.Fprintln(stderr, "usage: db-mcp-server config show [--effective] [server flags]")
+		return 2
+	}
+
+	// --effective is accepted alongside the regular server flags
+	effective := false
+	rest := make([]string, 0, len(args)-1)
+	for _, arg := range args[1:] {
+		if arg == "--effective" || arg == "-effective" {
+			effective = true
+			continue
+		}
+		rest = append(rest, arg)
+	}
+
+	loader := &config.Loader{Args: rest, LoadDotEnv: true, FlagOutput: stderr}
+	if !effective {
+		// Show only what the config file and defaults contribute
+		loader.LoadDotEnv = false
+		loader.LookupEnv = configPathOnlyEnv
+		loader.Args = configPathArgs(rest)
+	}
+
+	cfg, err := loader.Load()
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+
+	if effective {
+		fmt.Fprintln(stdout, "# Effective configuration (precedence: flag > env > file > default)")
+	} else {
+		fmt.Fprintln(stdout, "# Configuration file and defaults (use --effective to include env and flags)")
+	}
+	if err := cfg.WriteEffective(stdout); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// configPathOnlyEnv exposes only the variables that locate the config file
+func configPathOnlyEnv(key string) (string, bool) {
+	switch key {
+	case "CONFIG_PATH", "DB_CONFIG_FILE":
+		return os.LookupEnv(key)
+	default:
+		return "", false
+	}
+}
+
+// configPathArgs keeps only the flags that locate the config file
+func configPathArgs(args []string) []string {
+	fs := config.NewFlagSet("config show", io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return nil
+	}
+
+	var kept []string
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "c" || f.Name == "config" {
+			kept = append(kept, "-"+f.Name, f.Value.String())
+		}
+	})
+	return kept
+}
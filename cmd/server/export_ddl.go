@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/FreePeak/db-mcp-server/internal/config"
+	"github.com/FreePeak/db-mcp-server/pkg/dbtools"
+)
+
+// runExportDDL implements the export-ddl subcommand and returns the exit code
+func runExportDDL(args []string) int {
+	return exportDDL(args, os.Stdout, os.Stderr)
+}
+
+// exportDDL writes the DDL of a configured connection's schema to stdout or
+// the -o file. The regular server flags select the configuration.
+func exportDDL(args []string, stdout, stderr io.Writer) int {
+	fs := config.NewFlagSet("export-ddl", stderr)
+	dbID := fs.String("db", "", "Database connection ID (optional when only one is configured)")
+	schema := fs.String("schema", "", "Schema (PostgreSQL) or database (MySQL) to export. Defaults to the connection's default_schema")
+	output := fs.String("o", "", "File to write the DDL to instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	cfg, err := loadSubcommandConfig(fs, []string{"db", "schema", "o"}, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+
+	id, err := connectionID(cfg, *dbID)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	closeDatabases, err := connectDatabases(cfg, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	defer closeDatabases()
+
+	result, err := dbtools.DescribeSchema(context.Background(), id, "ddl", *schema, "")
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to export DDL of %s: %v\n", id, err)
+		return 1
+	}
+	ddl, _ := result["ddl"].(string)
+
+	if *output == "" {
+		fmt.Fprint(stdout, ddl)
+		return 0
+	}
+	if err := os.WriteFile(*output, []byte(ddl), 0o644); err != nil {
+		fmt.Fprintf(stderr, "Failed to write %s: %v\n", *output, err)
+		return 1
+	}
+	fmt.Fprintf(stderr, "Wrote DDL of %s to %s\n", id, *output)
+	return 0
+}
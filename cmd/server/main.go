@@ -3,10 +3,10 @@ package main
 // TODO: Refactor main.go to separate server initialization logic from configuration loading
 // TODO: Create dedicated server setup package for better separation of concerns
 // TODO: Implement structured logging instead of using standard log package
-// TODO: Consider using a configuration management library like Viper for better config handling
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
@@ -27,112 +27,58 @@ import (
 	pkgLogger "github.com/FreePeak/db-mcp-server/pkg/logger"
 )
 
-// findConfigFile attempts to find config.json in the current directory or parent directories
-func findConfigFile() string {
-	// Default config file name
-	const defaultConfigFile = "config.json"
-
-	// Check if the file exists in current directory
-	if _, err := os.Stat(defaultConfigFile); err == nil {
-		return defaultConfigFile
+func main() {
+	// Subcommands are dispatched before the server flags are parsed
+	if len(os.Args) > 1 && os.Args[1] == "validate-config" {
+		os.Exit(runValidateConfig(os.Args[2:]))
 	}
 
-	// Get current working directory
-	cwd, err := os.Getwd()
-	if err != nil {
-		logger.Error("Error getting current directory: %v", err)
-		return defaultConfigFile
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:]))
 	}
 
-	// Try up to 3 parent directories
-	for i := 0; i < 3; i++ {
-		cwd = filepath.Dir(cwd)
-		configPath := filepath.Join(cwd, defaultConfigFile)
-		if _, err := os.Stat(configPath); err == nil {
-			return configPath
-		}
+	if len(os.Args) > 1 && os.Args[1] == "export-ddl" {
+		os.Exit(runExportDDL(os.Args[2:]))
 	}
 
-	// Fall back to default if not found
-	return defaultConfigFile
-}
-
-func main() {
-	// Parse command-line arguments
-	configFile := flag.String("c", "config.json", "Database configuration file")
-	configPath := flag.String("config", "config.json", "Database configuration file (alternative)")
-	transportMode := flag.String("t", "sse", "Transport mode (stdio or sse)")
-	serverPort := flag.Int("p", 9092, "Server port for SSE transport")
-	serverHost := flag.String("h", "localhost", "Server host for SSE transport")
-	dbConfigJSON := flag.String("db-config", "", "JSON string with database configuration")
-	logLevel := flag.String("log-level", "info", "Log level (debug, info, warn, error)")
-	flag.Parse()
-
-	// Initialize logger
-	logger.Initialize(*logLevel)
-	pkgLogger.Initialize(*logLevel)
-
-	// Prioritize flags with actual values
-	finalConfigPath := *configFile
-	if finalConfigPath == "config.json" && *configPath != "config.json" {
-		finalConfigPath = *configPath
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
 	}
 
-	// If no specific config path was provided, try to find a config file
-	if finalConfigPath == "config.json" {
-		possibleConfigPath := findConfigFile()
-		if possibleConfigPath != "config.json" {
-			logger.Info("Found config file at: %s", possibleConfigPath)
-			finalConfigPath = possibleConfigPath
+	// Resolve configuration: flags > env > file > defaults
+	cfg, err := config.Load(os.Args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(0)
 		}
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		os.Exit(1)
 	}
 
-	finalServerPort := *serverPort
-	// Set environment variables from command line arguments if provided
-	if finalConfigPath != "config.json" {
-		if err := os.Setenv("CONFIG_PATH", finalConfigPath); err != nil {
-			logger.Warn("Warning: failed to set CONFIG_PATH env: %v", err)
-		}
-	}
-	if *transportMode != "sse" {
-		if err := os.Setenv("TRANSPORT_MODE", *transportMode); err != nil {
-			logger.Warn("Warning: failed to set TRANSPORT_MODE env: %v", err)
-		}
-	}
-	if finalServerPort != 9092 {
-		if err := os.Setenv("SERVER_PORT", fmt.Sprintf("%d", finalServerPort)); err != nil {
-			logger.Warn("Warning: failed to set SERVER_PORT env: %v", err)
-		}
-	}
-	// Set DB_CONFIG environment variable if provided via flag
-	if *dbConfigJSON != "" {
-		if err := os.Setenv("DB_CONFIG", *dbConfigJSON); err != nil {
-			logger.Warn("Warning: failed to set DB_CONFIG env: %v", err)
-		}
+	// The cortex server reads these from the environment
+	if err := os.Setenv("TRANSPORT_MODE", cfg.Server.TransportMode); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to set TRANSPORT_MODE env: %v\n", err)
 	}
 
-	// Load configuration after environment variables are set
-	cfg, err := config.LoadConfig()
-	if err != nil {
-		logger.Warn("Warning: Failed to load configuration: %v", err)
-		// Create a default config if loading fails
-		cfg = &config.Config{
-			ServerPort:    finalServerPort,
-			TransportMode: *transportMode,
-			ConfigPath:    finalConfigPath,
-		}
-	}
+	// Initialize logger
+	logger.InitializeWithTransport(cfg.Logging.Level, cfg.Server.TransportMode)
+	pkgLogger.InitializeWithTransport(cfg.Logging.Level, cfg.Server.TransportMode)
 
-	// Initialize database connection from config
-	dbConfig := &dbtools.Config{
-		ConfigFile: cfg.ConfigPath,
-	}
+	logger.Info("Using configuration file: %s (%s)", cfg.ConfigPath, cfg.Sources["config_path"])
+	logger.Info("Database connections loaded from: %s", cfg.Sources["connections"])
 
-	// Ensure database configuration exists
-	logger.Info("Using database configuration from: %s", cfg.ConfigPath)
+	// Read-only servers never signal sessions for clients; the server still
+	// cancels its own statements as security.cancel_statements allows
+	sessionControl := cfg.Security.SessionControl
+	if cfg.Security.ReadOnly {
+		sessionControl = config.SessionControlOff
+	}
 
-	// Try to initialize database from config
-	if err := dbtools.InitDatabase(dbConfig); err != nil {
+	// Initialize database connections from the resolved configuration
+	if err := dbtools.InitDatabase(&dbtools.Config{
+		MultiDBConfig:    cfg.MultiDBConfig,
+		CancelStatements: cfg.Security.CancelStatements,
+	}); err != nil {
 		logger.Warn("Warning: Failed to initialize database: %v", err)
 	}
 
@@ -148,9 +94,11 @@ func main() {
 	)
 
 	// Set up Clean Architecture layers
-	dbRepo := repository.NewDatabaseRepository()
+	dbRepo := repository.NewDatabaseRepository(time.Duration(cfg.Cache.SchemaTTL) * time.Second)
 	dbUseCase := usecase.NewDatabaseUseCase(dbRepo)
+	dbUseCase.SetSessionControl(sessionControl)
 	toolRegistry := mcp.NewToolRegistry(mcpServer)
+	toolRegistry.SetReadOnly(cfg.Security.ReadOnly)
 
 	// Set the database use case in the tool registry
 	ctx := context.Background()
@@ -175,21 +123,6 @@ func main() {
 	}
 	logger.Info("Finished registering tools")
 
-	// If we have databases, display the available tools
-	if len(dbIDs) > 0 {
-		logger.Info("Available database tools:")
-		for _, dbID := range dbIDs {
-			logger.Info("  Database %s:", dbID)
-			logger.Info("    - query_%s: Execute SQL queries", dbID)
-			logger.Info("    - execute_%s: Execute SQL statements", dbID)
-			logger.Info("    - transaction_%s: Manage transactions", dbID)
-			logger.Info("    - performance_%s: Analyze query performance", dbID)
-			logger.Info("    - schema_%s: Get database schema", dbID)
-		}
-		logger.Info("  Common tools:")
-		logger.Info("    - list_databases: List all available databases")
-	}
-
 	// If no database connections, register mock tools to ensure at least some tools are available
 	if len(dbIDs) == 0 {
 		logger.Info("No database connections available. Adding mock tools...")
@@ -198,6 +131,12 @@ func main() {
 		}
 	}
 
+	// Display the tools as registered, so the list follows the registry
+	logger.Info("Available tools:")
+	for _, name := range toolRegistry.ToolNames() {
+		logger.Info("  - %s", name)
+	}
+
 	// Create a session store to track valid sessions
 	sessions := make(map[string]bool)
 
@@ -207,16 +146,16 @@ func main() {
 	logger.Info("Created default session: %s", defaultSessionID)
 
 	// Handle transport mode
-	switch cfg.TransportMode {
+	switch cfg.Server.TransportMode {
 	case "sse":
-		logger.Info("Starting SSE server on port %d", cfg.ServerPort)
+		logger.Info("Starting SSE server on port %d", cfg.Server.Port)
 
 		// Configure base URL with explicit protocol
-		baseURL := fmt.Sprintf("http://%s:%d", *serverHost, cfg.ServerPort)
+		baseURL := fmt.Sprintf("http://%s:%d", cfg.Server.Host, cfg.Server.Port)
 		logger.Info("Using base URL: %s", baseURL)
 
 		// Set logging mode based on configuration
-		if cfg.DisableLogging {
+		if cfg.Logging.Disable {
 			logger.Info("Logging in SSE transport is disabled")
 			// Redirect standard output to null device if logging is disabled
 			// This only works on Unix-like systems
@@ -225,7 +164,7 @@ func main() {
 			}
 		}
 		// Set the server address
-		mcpServer.SetAddress(fmt.Sprintf(":%d", cfg.ServerPort))
+		mcpServer.SetAddress(fmt.Sprintf(":%d", cfg.Server.Port))
 
 		// Start the server
 		errCh := make(chan error, 1)
@@ -282,6 +221,23 @@ func main() {
 			log.SetOutput(logFile)
 		}
 
+		// Read stdin ahead of the transport, which handles one message at a
+		// time, so that cancellations reach the tool calls they cancel
+		if reader, writer, err := os.Pipe(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to watch stdin for cancellations: %v\n", err)
+		} else {
+			stdin := os.Stdin
+			os.Stdin = reader
+			go func() {
+				if err := mcp.WatchStdio(stdin, writer, toolRegistry.Calls()); err != nil {
+					logger.Error("Error watching stdin: %v", err)
+				}
+				if err := writer.Close(); err != nil {
+					logger.Error("Error closing stdin pipe: %v", err)
+				}
+			}()
+		}
+
 		// Critical: Use ServeStdio WITHOUT any console output to stdout
 		if err := mcpServer.ServeStdio(); err != nil {
 			// Log error to stderr only - never stdout
@@ -290,7 +246,7 @@ func main() {
 		}
 
 	default:
-		logger.Error("Invalid transport mode: %s", cfg.TransportMode)
+		logger.Error("Invalid transport mode: %s", cfg.Server.TransportMode)
 	}
 
 	logger.Info("Server shutdown complete")
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/internal/config"
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+	"github.com/FreePeak/db-mcp-server/internal/repository"
+	"github.com/FreePeak/db-mcp-server/internal/usecase"
+)
+
+const migrateUsage = "usage: db-mcp-server migrate status|plan|up|down|force [-db id] [-steps n] [-direction up|down] [server flags] [version]"
+
+// runMigrate implements the migrate subcommand and returns the exit code
+func runMigrate(args []string) int {
+	return migrate(args, os.Stdout, os.Stderr)
+}
+
+// migrate runs the versioned SQL migrations of a configured connection. The
+// regular server flags select the configuration.
+func migrate(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, migrateUsage)
+		return 2
+	}
+	action := args[0]
+	switch action {
+	case "status", "plan", "up", "down", "force":
+	default:
+		fmt.Fprintln(stderr, migrateUsage)
+		return 2
+	}
+
+	fs := config.NewFlagSet("migrate "+action, stderr)
+	dbID := fs.String("db", "", "Database connection ID (optional when only one is configured)")
+	steps := fs.Int("steps", 0, "Number of migrations to run. up defaults to all pending, down to one")
+	direction := fs.String("direction", "up", "Direction planned by migrate plan: up or down")
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	var forceVersion int64
+	if action == "force" {
+		version, err := strconv.ParseInt(fs.Arg(0), 10, 64)
+		if fs.NArg() != 1 || err != nil || version < 0 {
+			fmt.Fprintln(stderr, "usage: db-mcp-server migrate force [-db id] [server flags] <version>")
+			return 2
+		}
+		forceVersion = version
+	}
+
+	cfg, err := loadSubcommandConfig(fs, []string{"db", "steps", "direction"}, stderr)
+	if err != nil {
+		fmt.Fprintf(stderr, "Failed to load configuration: %v\n", err)
+		return 1
+	}
+	if cfg.Security.ReadOnly && (action == "up" || action == "down" || action == "force") {
+		fmt.Fprintf(stderr, "migrate %s is not allowed in read-only mode\n", action)
+		return 1
+	}
+
+	id, err := connectionID(cfg, *dbID)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 2
+	}
+
+	closeDatabases, err := connectDatabases(cfg, stderr)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	defer closeDatabases()
+
+	repo := repository.NewDatabaseRepository(time.Duration(cfg.Cache.SchemaTTL) * time.Second)
+	uc := usecase.NewDatabaseUseCase(repo)
+	ctx := context.Background()
+
+	switch action {
+	case "status":
+		status, err := uc.MigrationStatus(ctx, id)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to read migration status of %s: %v\n", id, err)
+			return 1
+		}
+		printMigrationStatus(stdout, status)
+	case "plan":
+		plan, err := uc.PlanMigrations(ctx, id, *direction, *steps)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to plan migrations of %s: %v\n", id, err)
+			return 1
+		}
+		printMigrationPlan(stdout, plan)
+	case "up", "down":
+		plan, err := uc.Migrate(ctx, id, action, *steps)
+		if err != nil {
+			fmt.Fprintf(stderr, "Failed to migrate %s %s: %v\n", id, action, err)
+			return 1
+		}
+		for _, step := range plan.Steps {
+			fmt.Fprintf(stdout, "Ran %s\n", step.File)
+		}
+		fmt.Fprintf(stdout, "%s is at migration version %d\n", id, plan.ToVersion)
+	case "force":
+		if err := uc.ForceMigrationVersion(ctx, id, forceVersion); err != nil {
+			fmt.Fprintf(stderr, "Failed to force migration version of %s: %v\n", id, err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "%s is at migration version %d\n", id, forceVersion)
+	}
+	return 0
+}
+
+// printMigrationStatus writes the applied version and each migration's state
+func printMigrationStatus(w io.Writer, status *domain.MigrationStatus) {
+	fmt.Fprintf(w, "Database:  %s\nDirectory: %s\nVersion:   %d", status.Database, status.Directory, status.Version)
+	if status.Dirty {
+		fmt.Fprint(w, " (dirty)")
+	}
+	fmt.Fprintln(w)
+	for _, m := range status.Applied {
+		fmt.Fprintf(w, "  applied  %d_%s\n", m.Version, m.Name)
+	}
+	for _, m := range status.Pending {
+		fmt.Fprintf(w, "  pending  %d_%s\n", m.Version, m.Name)
+	}
+}
+
+// printMigrationPlan writes the SQL of a plan as a script with file headers
+func printMigrationPlan(w io.Writer, plan *domain.MigrationPlan) {
+	fmt.Fprintf(w, "-- %s %s: version %d to %d, %d migrations\n", plan.Database, plan.Direction, plan.FromVersion, plan.ToVersion, len(plan.Steps))
+	for _, step := range plan.Steps {
+		mode := "in a transaction"
+		if !step.Transactional {
+			mode = "without a transaction"
+		}
+		fmt.Fprintf(w, "\n-- %s (%s)\n%s", step.File, mode, step.SQL)
+		if len(step.SQL) > 0 && step.SQL[len(step.SQL)-1] != '\n' {
+			fmt.Fprintln(w)
+		}
+	}
+}
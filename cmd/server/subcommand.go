@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/FreePeak/db-mcp-server/internal/config"
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+	"github.com/FreePeak/db-mcp-server/pkg/dbtools"
+	pkgLogger "github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// loadSubcommandConfig loads the configuration from the server flags set on
+// a subcommand's flag set, leaving out the subcommand's own flags. Logs go to
+// stderr so stdout only carries the command's output.
+func loadSubcommandConfig(fs *flag.FlagSet, ownFlags []string, stderr io.Writer) (*config.Config, error) {
+	own := make(map[string]bool, len(ownFlags))
+	for _, name := range ownFlags {
+		own[name] = true
+	}
+
+	var loaderArgs []string
+	fs.Visit(func(f *flag.Flag) {
+		if !own[f.Name] {
+			loaderArgs = append(loaderArgs, "-"+f.Name+"="+f.Value.String())
+		}
+	})
+
+	loader := &config.Loader{Args: loaderArgs, LoadDotEnv: true, FlagOutput: stderr}
+	cfg, err := loader.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	logger.InitializeWithWriter(cfg.Logging.Level, os.Stderr)
+	pkgLogger.InitializeWithTransport(cfg.Logging.Level, "")
+	return cfg, nil
+}
+
+// connectionID returns dbID, or the only configured connection when it is empty
+func connectionID(cfg *config.Config, dbID string) (string, error) {
+	if dbID != "" {
+		return dbID, nil
+	}
+	if cfg.MultiDBConfig == nil || len(cfg.MultiDBConfig.Connections) != 1 {
+		return "", fmt.Errorf("-db is required when more than one connection is configured")
+	}
+	return cfg.MultiDBConfig.Connections[0].ID, nil
+}
+
+// connectDatabases opens the configured connections and returns a function
+// that closes them
+func connectDatabases(cfg *config.Config, stderr io.Writer) (func(), error) {
+	if err := dbtools.InitDatabase(&dbtools.Config{MultiDBConfig: cfg.MultiDBConfig}); err != nil {
+		return nil, fmt.Errorf("failed to initialize databases: %w", err)
+	}
+	return func() {
+		if err := dbtools.CloseDatabase(); err != nil {
+			fmt.Fprintf(stderr, "Failed to close databases: %v\n", err)
+		}
+	}, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/FreePeak/db-mcp-server/internal/config"
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+)
+
+// runValidateConfig implements the validate-config subcommand. It prints every
+// problem found in the configuration file and returns the process exit code.
+func runValidateConfig(args []string) int {
+	return validateConfig(args, os.Stdout, os.Stderr)
+}
+
+// validateConfig validates the config file named by the -c/-config flag
+func validateConfig(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("validate-config", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	configFile := fs.String("c", "", "Database configuration file (JSON, YAML or TOML)")
+	configPath := fs.String("config", "", "Database configuration file (alternative)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	path := *configFile
+	if path == "" {
+		path = *configPath
+	}
+	if path == "" && fs.NArg() > 0 {
+		path = fs.Arg(0)
+	}
+	if path == "" {
+		path = config.FindConfigFile()
+	}
+
+	format, err := db.ConfigFormatFromPath(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", path, err)
+		return 1
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(stderr, "failed to read %s: %v\n", path, err)
+		return 1
+	}
+
+	problems, err := db.ValidateMultiDBConfig(data, format)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", path, err)
+		return 1
+	}
+
+	if len(problems) == 0 {
+		fmt.Fprintf(stdout, "%s: configuration is valid\n", path)
+		return 0
+	}
+
+	fmt.Fprintf(stderr, "%s: %d problem(s) found\n", path, len(problems))
+	for _, p := range problems {
+		fmt.Fprintf(stderr, "  %s\n", p)
+	}
+	return 1
+}
@@ -2,26 +2,86 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 
-	"github.com/FreePeak/db-mcp-server/internal/logger"
 	"github.com/FreePeak/db-mcp-server/pkg/db"
 )
 
+// Default values, the lowest precedence layer
+const (
+	DefaultServerHost       = "localhost"
+	DefaultServerPort       = 9092
+	DefaultTransportMode    = "sse"
+	DefaultLogLevel         = "info"
+	DefaultSchemaTTL        = 300 // seconds
+	DefaultSessionControl   = SessionControlOff
+	DefaultCancelStatements = true
+)
+
+// SessionControlOff is the session control policy under which no session may
+// be signalled. The other policies, db.SessionCancel and db.SessionTerminate,
+// allow the signal they name and, for terminate, cancel too.
+const SessionControlOff = "off"
+
+// Source identifies which configuration layer supplied a value
+type Source string
+
+// Configuration layers, from lowest to highest precedence
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+)
+
 // Config holds all server configuration
 type Config struct {
-	ServerPort     int
-	TransportMode  string
-	LogLevel       string
-	DBConfig       DatabaseConfig    // Legacy single database config
-	MultiDBConfig  *db.MultiDBConfig // New multi-database config
-	ConfigPath     string            // Path to the configuration file
-	DisableLogging bool              // When true, disables logging in stdio/SSE transport
+	Server   ServerConfig
+	Logging  LoggingConfig
+	Security SecurityConfig
+	Cache    CacheConfig
+
+	DBConfig      DatabaseConfig    // Legacy single database config
+	MultiDBConfig *db.MultiDBConfig // Resolved database connections
+	ConfigPath    string            // Path to the configuration file
+
+	// Sources records which layer supplied each setting, keyed by its
+	// dotted name (e.g. "server.port")
+	Sources map[string]Source
+}
+
+// ServerConfig holds MCP server settings
+type ServerConfig struct {
+	Host          string
+	Port          int
+	TransportMode string
+}
+
+// LoggingConfig holds logging settings
+type LoggingConfig struct {
+	Level   string
+	Disable bool // When true, disables logging in stdio/SSE transport
+}
+
+// SecurityConfig holds settings that restrict what clients may do
+type SecurityConfig struct {
+	ReadOnly         bool   // When true, tools that modify data are not registered
+	SessionControl   string // Whether tools may cancel queries or terminate sessions: off, cancel or terminate
+	CancelStatements bool   // When true, the server cancels its own statements on the database when their call ends
+}
+
+// CacheConfig holds metadata caching settings
+type CacheConfig struct {
+	SchemaTTL int // Seconds schema metadata is cached; 0 disables the cache
 }
 
 // DatabaseConfig holds database configuration (legacy support)
@@ -34,109 +94,504 @@ type DatabaseConfig struct {
 	Name     string
 }
 
-// LoadConfig loads the configuration from environment variables and optional JSON config
+// fileSettings mirrors the non-connection sections of a config file.
+// Pointers distinguish "not set" from zero values.
+type fileSettings struct {
+	Server *struct {
+		Host      *string `json:"host"`
+		Port      *int    `json:"port"`
+		Transport *string `json:"transport"`
+	} `json:"server"`
+	Logging *struct {
+		Level   *string `json:"level"`
+		Disable *bool   `json:"disable"`
+	} `json:"logging"`
+	Security *struct {
+		ReadOnly         *bool   `json:"read_only"`
+		SessionControl   *string `json:"session_control"`
+		CancelStatements *bool   `json:"cancel_statements"`
+	} `json:"security"`
+	Cache *struct {
+		SchemaTTL *int `json:"schema_ttl"`
+	} `json:"cache"`
+}
+
+// Loader builds a Config from defaults, a config file, environment variables
+// and command-line flags, in increasing order of precedence
+type Loader struct {
+	// Args are the command-line arguments, without the program name
+	Args []string
+	// LookupEnv reads environment variables; defaults to os.LookupEnv
+	LookupEnv func(key string) (string, bool)
+	// LoadDotEnv loads a .env file into the process environment first
+	LoadDotEnv bool
+	// FlagOutput receives flag parse errors and usage; defaults to os.Stderr
+	FlagOutput io.Writer
+}
+
+// flagValues holds the parsed command-line flags
+type flagValues struct {
+	set              map[string]bool
+	configFile       string
+	configPath       string
+	transportMode    string
+	serverPort       int
+	serverHost       string
+	dbConfigJSON     string
+	logLevel         string
+	readOnly         bool
+	sessionControl   string
+	cancelStatements bool
+	disableLogging   bool
+}
+
+// Load builds the configuration with the given command-line arguments
+func Load(args []string) (*Config, error) {
+	loader := &Loader{Args: args, LoadDotEnv: true}
+	return loader.Load()
+}
+
+// LoadConfig loads the configuration from defaults, an optional JSON, YAML or
+// TOML config file and environment variables, without command-line flags
 func LoadConfig() (*Config, error) {
-	// Initialize logger with default level first to avoid nil pointer
-	logger.Initialize("info")
+	return Load(nil)
+}
+
+// NewFlagSet returns the flag set understood by the loader
+func NewFlagSet(name string, output io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	fs.String("c", "", "Configuration file (JSON, YAML or TOML)")
+	fs.String("config", "", "Configuration file (alternative)")
+	fs.String("t", DefaultTransportMode, "Transport mode (stdio or sse)")
+	fs.Int("p", DefaultServerPort, "Server port for SSE transport")
+	fs.String("h", DefaultServerHost, "Server host for SSE transport")
+	fs.String("db-config", "", "JSON string with database configuration")
+	fs.String("log-level", DefaultLogLevel, "Log level (debug, info, warn, error)")
+	fs.Bool("read-only", false, "Do not register tools that modify data")
+	fs.String("session-control", DefaultSessionControl, "Whether tools may cancel queries or terminate sessions (off, cancel or terminate)")
+	fs.Bool("cancel-statements", DefaultCancelStatements, "Cancel the server's own statements on the database when their call times out or is cancelled")
+	fs.Bool("disable-logging", false, "Disable logging in the MCP transport")
+	return fs
+}
+
+// Load resolves the configuration
+func (l *Loader) Load() (*Config, error) {
+	lookup := l.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	output := l.FlagOutput
+	if output == nil {
+		output = os.Stderr
+	}
 
-	// Load .env file if it exists
-	err := godotenv.Load()
+	flags, err := parseFlags(l.Args, output)
 	if err != nil {
-		logger.Info("Warning: .env file not found, using environment variables only")
-	} else {
-		logger.Info("Loaded configuration from .env file")
+		return nil, err
+	}
+
+	if l.LoadDotEnv {
+		// A missing .env file is not an error; existing variables win
+		_ = godotenv.Load()
+	}
+
+	cfg := defaultConfig()
+
+	// Resolve the config file path: flag > env > discovery
+	switch {
+	case flags.set["c"]:
+		cfg.ConfigPath, cfg.Sources["config_path"] = flags.configFile, SourceFlag
+	case flags.set["config"]:
+		cfg.ConfigPath, cfg.Sources["config_path"] = flags.configPath, SourceFlag
+	case envValue(lookup, "CONFIG_PATH") != "":
+		cfg.ConfigPath, cfg.Sources["config_path"] = envValue(lookup, "CONFIG_PATH"), SourceEnv
+	case envValue(lookup, "DB_CONFIG_FILE") != "":
+		cfg.ConfigPath, cfg.Sources["config_path"] = envValue(lookup, "DB_CONFIG_FILE"), SourceEnv
+	default:
+		cfg.ConfigPath = FindConfigFile()
+	}
+	if !filepath.IsAbs(cfg.ConfigPath) {
+		if absPath, err := filepath.Abs(cfg.ConfigPath); err == nil {
+			cfg.ConfigPath = absPath
+		}
 	}
 
-	port, err := strconv.Atoi(getEnv("SERVER_PORT", "9090"))
+	if err := cfg.applyFile(); err != nil {
+		return nil, err
+	}
+	if err := cfg.applyEnv(lookup); err != nil {
+		return nil, err
+	}
+	if err := cfg.applyFlags(flags); err != nil {
+		return nil, err
+	}
+	cfg.applyLegacyConnection(lookup)
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// defaultConfig returns the defaults layer
+func defaultConfig() *Config {
+	cfg := &Config{
+		Server: ServerConfig{
+			Host:          DefaultServerHost,
+			Port:          DefaultServerPort,
+			TransportMode: DefaultTransportMode,
+		},
+		Logging: LoggingConfig{
+			Level: DefaultLogLevel,
+		},
+		Security: SecurityConfig{
+			SessionControl:   DefaultSessionControl,
+			CancelStatements: DefaultCancelStatements,
+		},
+		Cache: CacheConfig{
+			SchemaTTL: DefaultSchemaTTL,
+		},
+		DBConfig: DatabaseConfig{
+			Type: "mysql",
+			Host: "localhost",
+			Port: 3306,
+		},
+		Sources: make(map[string]Source),
+	}
+	for _, key := range settingKeys {
+		cfg.Sources[key] = SourceDefault
+	}
+	cfg.Sources["config_path"] = SourceDefault
+	cfg.Sources["connections"] = SourceDefault
+	return cfg
+}
+
+// settingKeys lists the scalar settings tracked in Sources
+var settingKeys = []string{
+	"server.host", "server.port", "server.transport",
+	"logging.level", "logging.disable",
+	"security.read_only", "security.session_control", "security.cancel_statements",
+	"cache.schema_ttl",
+}
+
+// parseFlags parses the command-line arguments and records which were set
+func parseFlags(args []string, output io.Writer) (*flagValues, error) {
+	fs := NewFlagSet("db-mcp-server", output)
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	values := &flagValues{set: make(map[string]bool)}
+	fs.Visit(func(f *flag.Flag) {
+		values.set[f.Name] = true
+	})
+
+	get := func(name string) string { return fs.Lookup(name).Value.String() }
+	values.configFile = get("c")
+	values.configPath = get("config")
+	values.transportMode = get("t")
+	values.serverHost = get("h")
+	values.dbConfigJSON = get("db-config")
+	values.logLevel = get("log-level")
+	values.readOnly = get("read-only") == "true"
+	values.sessionControl = get("session-control")
+	values.cancelStatements = get("cancel-statements") == "true"
+	values.disableLogging = get("disable-logging") == "true"
+	port, err := strconv.Atoi(get("p"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid -p value: %w", err)
+	}
+	values.serverPort = port
+
+	return values, nil
+}
+
+// applyFile applies settings and connections from the config file, if present
+func (c *Config) applyFile() error {
+	if _, err := os.Stat(c.ConfigPath); err != nil {
+		return nil
+	}
+
+	format, err := db.ConfigFormatFromPath(c.ConfigPath)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(c.ConfigPath)
 	if err != nil {
-		logger.Warn("Warning: Invalid SERVER_PORT value, using default 9090")
-		port = 9090
+		return fmt.Errorf("failed to read config file %s: %w", c.ConfigPath, err)
 	}
 
-	dbPort, err := strconv.Atoi(getEnv("DB_PORT", "3306"))
+	multiDBConfig, err := db.ParseMultiDBConfig(data, format)
 	if err != nil {
-		logger.Warn("Warning: Invalid DB_PORT value, using default 3306")
-		dbPort = 3306
+		return fmt.Errorf("failed to load config file %s: %w", c.ConfigPath, err)
+	}
+	if len(multiDBConfig.Connections) > 0 {
+		c.MultiDBConfig = multiDBConfig
+		c.Sources["connections"] = SourceFile
 	}
 
-	// Get config path from environment or use default
-	configPath := getEnv("CONFIG_PATH", "")
-	if configPath == "" {
-		configPath = getEnv("DB_CONFIG_FILE", "config.json")
+	normalized, err := db.NormalizeConfig(data, format)
+	if err != nil {
+		return err
+	}
+	var settings fileSettings
+	if err := json.Unmarshal(normalized, &settings); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", c.ConfigPath, err)
 	}
 
-	// Resolve absolute path if relative path is provided
-	if !filepath.IsAbs(configPath) {
-		absPath, err := filepath.Abs(configPath)
+	if s := settings.Server; s != nil {
+		c.setString(&c.Server.Host, "server.host", s.Host, SourceFile)
+		c.setInt(&c.Server.Port, "server.port", s.Port, SourceFile)
+		c.setString(&c.Server.TransportMode, "server.transport", s.Transport, SourceFile)
+	}
+	if s := settings.Logging; s != nil {
+		c.setString(&c.Logging.Level, "logging.level", s.Level, SourceFile)
+		c.setBool(&c.Logging.Disable, "logging.disable", s.Disable, SourceFile)
+	}
+	if s := settings.Security; s != nil {
+		c.setBool(&c.Security.ReadOnly, "security.read_only", s.ReadOnly, SourceFile)
+		c.setString(&c.Security.SessionControl, "security.session_control", s.SessionControl, SourceFile)
+		c.setBool(&c.Security.CancelStatements, "security.cancel_statements", s.CancelStatements, SourceFile)
+	}
+	if s := settings.Cache; s != nil {
+		c.setInt(&c.Cache.SchemaTTL, "cache.schema_ttl", s.SchemaTTL, SourceFile)
+	}
+
+	return nil
+}
+
+// applyEnv applies settings from environment variables
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	if v := envValue(lookup, "SERVER_HOST"); v != "" {
+		c.setString(&c.Server.Host, "server.host", &v, SourceEnv)
+	}
+	if v := envValue(lookup, "SERVER_PORT"); v != "" {
+		port, err := strconv.Atoi(v)
 		if err != nil {
-			logger.Warn("Warning: Could not resolve absolute path for config file: %v", err)
-		} else {
-			configPath = absPath
+			return fmt.Errorf("invalid SERVER_PORT value %q: %w", v, err)
 		}
+		c.setInt(&c.Server.Port, "server.port", &port, SourceEnv)
 	}
-
-	// Parse DISABLE_LOGGING env var
-	disableLogging := false
-	if v := getEnv("DISABLE_LOGGING", "false"); v == "true" || v == "1" {
-		disableLogging = true
+	if v := envValue(lookup, "TRANSPORT_MODE"); v != "" {
+		c.setString(&c.Server.TransportMode, "server.transport", &v, SourceEnv)
+	}
+	if v := envValue(lookup, "LOG_LEVEL"); v != "" {
+		c.setString(&c.Logging.Level, "logging.level", &v, SourceEnv)
+	}
+	if v := envValue(lookup, "DISABLE_LOGGING"); v != "" {
+		b := parseBool(v)
+		c.setBool(&c.Logging.Disable, "logging.disable", &b, SourceEnv)
+	}
+	if v := envValue(lookup, "READ_ONLY"); v != "" {
+		b := parseBool(v)
+		c.setBool(&c.Security.ReadOnly, "security.read_only", &b, SourceEnv)
+	}
+	if v := envValue(lookup, "SESSION_CONTROL"); v != "" {
+		c.setString(&c.Security.SessionControl, "security.session_control", &v, SourceEnv)
+	}
+	if v := envValue(lookup, "CANCEL_STATEMENTS"); v != "" {
+		b := parseBool(v)
+		c.setBool(&c.Security.CancelStatements, "security.cancel_statements", &b, SourceEnv)
+	}
+	if v := envValue(lookup, "SCHEMA_CACHE_TTL"); v != "" {
+		ttl, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid SCHEMA_CACHE_TTL value %q: %w", v, err)
+		}
+		c.setInt(&c.Cache.SchemaTTL, "cache.schema_ttl", &ttl, SourceEnv)
 	}
 
-	config := &Config{
-		ServerPort:     port,
-		TransportMode:  getEnv("TRANSPORT_MODE", "sse"),
-		LogLevel:       getEnv("LOG_LEVEL", "info"),
-		ConfigPath:     configPath,
-		DisableLogging: disableLogging,
-		DBConfig: DatabaseConfig{
-			Type:     getEnv("DB_TYPE", "mysql"),
-			Host:     getEnv("DB_HOST", "localhost"),
-			Port:     dbPort,
-			User:     getEnv("DB_USER", ""),
-			Password: getEnv("DB_PASSWORD", ""),
-			Name:     getEnv("DB_NAME", ""),
-		},
+	if v := envValue(lookup, "DB_CONFIG"); v != "" {
+		multiDBConfig, err := db.ParseMultiDBConfig([]byte(v), db.ConfigFormatJSON)
+		if err != nil {
+			return fmt.Errorf("invalid DB_CONFIG environment variable: %w", err)
+		}
+		c.MultiDBConfig = multiDBConfig
+		c.Sources["connections"] = SourceEnv
 	}
 
-	// Try to load multi-database configuration from JSON file
-	if _, err := os.Stat(config.ConfigPath); err == nil {
-		logger.Info("Loading configuration from: %s", config.ConfigPath)
-		configData, err := os.ReadFile(config.ConfigPath)
+	// Legacy single database settings
+	if v := envValue(lookup, "DB_TYPE"); v != "" {
+		c.DBConfig.Type = v
+	}
+	if v := envValue(lookup, "DB_HOST"); v != "" {
+		c.DBConfig.Host = v
+	}
+	if v := envValue(lookup, "DB_PORT"); v != "" {
+		port, err := strconv.Atoi(v)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read config file %s: %w", config.ConfigPath, err)
+			return fmt.Errorf("invalid DB_PORT value %q: %w", v, err)
 		}
+		c.DBConfig.Port = port
+	}
+	c.DBConfig.User = envValue(lookup, "DB_USER")
+	c.DBConfig.Password = envValue(lookup, "DB_PASSWORD")
+	c.DBConfig.Name = envValue(lookup, "DB_NAME")
+
+	return nil
+}
+
+// applyFlags applies explicitly set command-line flags
+func (c *Config) applyFlags(f *flagValues) error {
+	if f.set["h"] {
+		c.setString(&c.Server.Host, "server.host", &f.serverHost, SourceFlag)
+	}
+	if f.set["p"] {
+		c.setInt(&c.Server.Port, "server.port", &f.serverPort, SourceFlag)
+	}
+	if f.set["t"] {
+		c.setString(&c.Server.TransportMode, "server.transport", &f.transportMode, SourceFlag)
+	}
+	if f.set["log-level"] {
+		c.setString(&c.Logging.Level, "logging.level", &f.logLevel, SourceFlag)
+	}
+	if f.set["disable-logging"] {
+		c.setBool(&c.Logging.Disable, "logging.disable", &f.disableLogging, SourceFlag)
+	}
+	if f.set["read-only"] {
+		c.setBool(&c.Security.ReadOnly, "security.read_only", &f.readOnly, SourceFlag)
+	}
+	if f.set["session-control"] {
+		c.setString(&c.Security.SessionControl, "security.session_control", &f.sessionControl, SourceFlag)
+	}
+	if f.set["cancel-statements"] {
+		c.setBool(&c.Security.CancelStatements, "security.cancel_statements", &f.cancelStatements, SourceFlag)
+	}
 
-		var multiDBConfig db.MultiDBConfig
-		if err := json.Unmarshal(configData, &multiDBConfig); err != nil {
-			return nil, fmt.Errorf("failed to parse config file %s: %w", config.ConfigPath, err)
+	if f.set["db-config"] && f.dbConfigJSON != "" {
+		multiDBConfig, err := db.ParseMultiDBConfig([]byte(f.dbConfigJSON), db.ConfigFormatJSON)
+		if err != nil {
+			return fmt.Errorf("invalid -db-config value: %w", err)
 		}
+		c.MultiDBConfig = multiDBConfig
+		c.Sources["connections"] = SourceFlag
+	}
 
-		config.MultiDBConfig = &multiDBConfig
-	} else {
-		logger.Info("Warning: Config file not found at %s, using environment variables", config.ConfigPath)
-		// If no JSON config found, create a single connection config from environment variables
-		config.MultiDBConfig = &db.MultiDBConfig{
-			Connections: []db.DatabaseConnectionConfig{
-				{
-					ID:       "default",
-					Type:     config.DBConfig.Type,
-					Host:     config.DBConfig.Host,
-					Port:     config.DBConfig.Port,
-					User:     config.DBConfig.User,
-					Password: config.DBConfig.Password,
-					Name:     config.DBConfig.Name,
-				},
+	return nil
+}
+
+// applyLegacyConnection builds a "default" connection from the DB_* variables
+// when no connection list was configured by any other layer
+func (c *Config) applyLegacyConnection(lookup func(string) (string, bool)) {
+	if c.MultiDBConfig != nil && len(c.MultiDBConfig.Connections) > 0 {
+		return
+	}
+	if envValue(lookup, "DB_USER") == "" {
+		return
+	}
+
+	c.MultiDBConfig = &db.MultiDBConfig{
+		Connections: []db.DatabaseConnectionConfig{
+			{
+				ID:       "default",
+				Type:     c.DBConfig.Type,
+				Host:     c.DBConfig.Host,
+				Port:     c.DBConfig.Port,
+				User:     c.DBConfig.User,
+				Password: c.DBConfig.Password,
+				Name:     c.DBConfig.Name,
 			},
-		}
+		},
+	}
+	c.Sources["connections"] = SourceEnv
+}
+
+// Validate checks the resolved settings
+func (c *Config) Validate() error {
+	var problems []string
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		problems = append(problems, fmt.Sprintf("server.port: %d is out of range", c.Server.Port))
+	}
+	if c.Server.TransportMode != "sse" && c.Server.TransportMode != "stdio" {
+		problems = append(problems, fmt.Sprintf("server.transport: unsupported mode %q (use sse or stdio)", c.Server.TransportMode))
+	}
+	switch strings.ToLower(c.Logging.Level) {
+	case "debug", "info", "warn", "error":
+	default:
+		problems = append(problems, fmt.Sprintf("logging.level: unsupported level %q", c.Logging.Level))
+	}
+	switch c.Security.SessionControl {
+	case SessionControlOff, db.SessionCancel, db.SessionTerminate:
+	default:
+		problems = append(problems, fmt.Sprintf("security.session_control: unsupported policy %q (use off, cancel or terminate)", c.Security.SessionControl))
+	}
+	if c.Cache.SchemaTTL < 0 {
+		problems = append(problems, fmt.Sprintf("cache.schema_ttl: %d must not be negative", c.Cache.SchemaTTL))
+	}
+
+	if len(problems) > 0 {
+		return errors.New("invalid configuration: " + strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// setString assigns a string setting and records its source
+func (c *Config) setString(dst *string, key string, value *string, source Source) {
+	if value == nil {
+		return
 	}
+	*dst = *value
+	c.Sources[key] = source
+}
 
-	return config, nil
+// setInt assigns an int setting and records its source
+func (c *Config) setInt(dst *int, key string, value *int, source Source) {
+	if value == nil {
+		return
+	}
+	*dst = *value
+	c.Sources[key] = source
 }
 
-// getEnv gets an environment variable or returns a default value
-func getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
+// setBool assigns a bool setting and records its source
+func (c *Config) setBool(dst *bool, key string, value *bool, source Source) {
+	if value == nil {
+		return
+	}
+	*dst = *value
+	c.Sources[key] = source
+}
+
+// DefaultConfigFileNames lists the config file names probed, in order, when
+// no explicit path is given
+var DefaultConfigFileNames = []string{"config.json", "config.yaml", "config.yml", "config.toml"}
+
+// FindConfigFile looks for a default config file in the current directory and
+// up to three parent directories, falling back to config.json
+func FindConfigFile() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return DefaultConfigFileNames[0]
+	}
+
+	for i := 0; i < 4; i++ {
+		for _, name := range DefaultConfigFileNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				return path
+			}
+		}
+		dir = filepath.Dir(dir)
 	}
+
+	return DefaultConfigFileNames[0]
+}
+
+// envValue returns an environment variable or an empty string
+func envValue(lookup func(string) (string, bool), key string) string {
+	value, _ := lookup(key)
 	return value
 }
+
+// parseBool accepts the usual truthy spellings used in env files
+func parseBool(value string) bool {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "1", "true", "yes", "on":
+		return true
+	default:
+		return false
+	}
+}
@@ -1,34 +1,15 @@
 package config
 
 import (
+	"bytes"
 	"os"
 	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
-)
 
-func TestGetEnv(t *testing.T) {
-	// Setup
-	err := os.Setenv("TEST_ENV_VAR", "test_value")
-	if err != nil {
-		t.Fatalf("Failed to set environment variable: %v", err)
-	}
-	defer func() {
-		err := os.Unsetenv("TEST_ENV_VAR")
-		if err != nil {
-			t.Fatalf("Failed to unset environment variable: %v", err)
-		}
-	}()
-
-	// Test with existing env var
-	value := getEnv("TEST_ENV_VAR", "default_value")
-	assert.Equal(t, "test_value", value)
-
-	// Test with non-existing env var
-	value = getEnv("NON_EXISTING_VAR", "default_value")
-	assert.Equal(t, "default_value", value)
-}
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+)
 
 func TestLoadConfig(t *testing.T) {
 	// Clear any environment variables that might affect the test
@@ -73,9 +54,10 @@ func TestLoadConfig(t *testing.T) {
 	// Test with default values (no .env file and no environment variables)
 	config, err := LoadConfig()
 	assert.NoError(t, err)
-	assert.Equal(t, 9090, config.ServerPort)
-	assert.Equal(t, "sse", config.TransportMode)
-	assert.Equal(t, "info", config.LogLevel)
+	assert.Equal(t, 9092, config.Server.Port)
+	assert.Equal(t, "sse", config.Server.TransportMode)
+	assert.Equal(t, "info", config.Logging.Level)
+	assert.True(t, config.Security.CancelStatements)
 	assert.Equal(t, "mysql", config.DBConfig.Type)
 	assert.Equal(t, "localhost", config.DBConfig.Host)
 	assert.Equal(t, 3306, config.DBConfig.Port)
@@ -131,9 +113,9 @@ func TestLoadConfig(t *testing.T) {
 
 	config, err = LoadConfig()
 	assert.NoError(t, err)
-	assert.Equal(t, 8080, config.ServerPort)
-	assert.Equal(t, "stdio", config.TransportMode)
-	assert.Equal(t, "debug", config.LogLevel)
+	assert.Equal(t, 8080, config.Server.Port)
+	assert.Equal(t, "stdio", config.Server.TransportMode)
+	assert.Equal(t, "debug", config.Logging.Level)
 	assert.Equal(t, "postgres", config.DBConfig.Type)
 	assert.Equal(t, "db.example.com", config.DBConfig.Host)
 	assert.Equal(t, 5432, config.DBConfig.Port)
@@ -141,3 +123,149 @@ func TestLoadConfig(t *testing.T) {
 	assert.Equal(t, "testpass", config.DBConfig.Password)
 	assert.Equal(t, "testdb", config.DBConfig.Name)
 }
+
+// mapEnv returns a LookupEnv function backed by a map
+func mapEnv(env map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := env[key]
+		return value, ok
+	}
+}
+
+func TestLoaderPrecedence(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	content := `
+server:
+  host: file-host
+  port: 9100
+  transport: stdio
+logging:
+  level: debug
+security:
+  read_only: true
+  session_control: cancel
+  cancel_statements: false
+cache:
+  schema_ttl: 60
+connections:
+  - id: filedb
+    type: postgres
+    host: localhost
+`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+
+	loader := &Loader{
+		Args: []string{"-c", path, "-p", "9300", "-session-control", "terminate"},
+		LookupEnv: mapEnv(map[string]string{
+			"SERVER_PORT":     "9200",
+			"LOG_LEVEL":       "warn",
+			"SESSION_CONTROL": "off",
+		}),
+	}
+
+	cfg, err := loader.Load()
+	assert.NoError(t, err)
+
+	// flag > env > file > default
+	assert.Equal(t, 9300, cfg.Server.Port)
+	assert.Equal(t, SourceFlag, cfg.Sources["server.port"])
+	assert.Equal(t, "warn", cfg.Logging.Level)
+	assert.Equal(t, SourceEnv, cfg.Sources["logging.level"])
+	assert.Equal(t, "file-host", cfg.Server.Host)
+	assert.Equal(t, SourceFile, cfg.Sources["server.host"])
+	assert.Equal(t, "stdio", cfg.Server.TransportMode)
+	assert.True(t, cfg.Security.ReadOnly)
+	assert.Equal(t, db.SessionTerminate, cfg.Security.SessionControl)
+	assert.Equal(t, SourceFlag, cfg.Sources["security.session_control"])
+	assert.False(t, cfg.Security.CancelStatements)
+	assert.Equal(t, SourceFile, cfg.Sources["security.cancel_statements"])
+	assert.Equal(t, 60, cfg.Cache.SchemaTTL)
+	assert.Equal(t, SourceFile, cfg.Sources["cache.schema_ttl"])
+	assert.False(t, cfg.Logging.Disable)
+	assert.Equal(t, SourceDefault, cfg.Sources["logging.disable"])
+
+	assert.Equal(t, path, cfg.ConfigPath)
+	assert.Equal(t, SourceFile, cfg.Sources["connections"])
+	assert.Equal(t, "filedb", cfg.MultiDBConfig.Connections[0].ID)
+}
+
+func TestLoaderConnectionSources(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	envJSON := `{"connections":[{"id":"envdb","type":"mysql","host":"h"}]}`
+	flagJSON := `{"connections":[{"id":"flagdb","type":"mysql","host":"h"}]}`
+
+	// DB_CONFIG env is used without a file
+	cfg, err := (&Loader{
+		Args:      []string{"-c", missing},
+		LookupEnv: mapEnv(map[string]string{"DB_CONFIG": envJSON}),
+	}).Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "envdb", cfg.MultiDBConfig.Connections[0].ID)
+	assert.Equal(t, SourceEnv, cfg.Sources["connections"])
+
+	// -db-config flag overrides DB_CONFIG
+	cfg, err = (&Loader{
+		Args:      []string{"-c", missing, "-db-config", flagJSON},
+		LookupEnv: mapEnv(map[string]string{"DB_CONFIG": envJSON}),
+	}).Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "flagdb", cfg.MultiDBConfig.Connections[0].ID)
+	assert.Equal(t, SourceFlag, cfg.Sources["connections"])
+
+	// Legacy DB_* variables only apply when nothing else configured connections
+	cfg, err = (&Loader{
+		Args:      []string{"-c", missing},
+		LookupEnv: mapEnv(map[string]string{"DB_USER": "u", "DB_TYPE": "postgres"}),
+	}).Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "default", cfg.MultiDBConfig.Connections[0].ID)
+	assert.Equal(t, "postgres", cfg.MultiDBConfig.Connections[0].Type)
+
+	// Invalid connection JSON is reported
+	_, err = (&Loader{
+		Args:      []string{"-c", missing},
+		LookupEnv: mapEnv(map[string]string{"DB_CONFIG": `{"connections":[{"id":"x","type":"oracle","host":"h"}]}`}),
+	}).Load()
+	assert.Error(t, err)
+}
+
+func TestLoaderValidation(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.json")
+
+	_, err := (&Loader{Args: []string{"-c", missing, "-t", "grpc"}, LookupEnv: mapEnv(nil)}).Load()
+	assert.Error(t, err)
+
+	_, err = (&Loader{Args: []string{"-c", missing}, LookupEnv: mapEnv(map[string]string{"SERVER_PORT": "abc"})}).Load()
+	assert.Error(t, err)
+
+	_, err = (&Loader{Args: []string{"-c", missing}, LookupEnv: mapEnv(map[string]string{"SCHEMA_CACHE_TTL": "-1"})}).Load()
+	assert.Error(t, err)
+
+	_, err = (&Loader{Args: []string{"-c", missing}, LookupEnv: mapEnv(map[string]string{"SESSION_CONTROL": "kill"})}).Load()
+	assert.ErrorContains(t, err, `security.session_control: unsupported policy "kill"`)
+}
+
+func TestWriteEffectiveRedactsSecrets(t *testing.T) {
+	cfg, err := (&Loader{
+		Args: []string{"-c", filepath.Join(t.TempDir(), "missing.json")},
+		LookupEnv: mapEnv(map[string]string{
+			"DB_CONFIG": `{"connections":[{"id":"a","type":"postgres","host":"h","password":"hunter2","options":{"auth_token":"tok","timezone":"UTC"}}]}`,
+		}),
+	}).Load()
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	assert.NoError(t, cfg.WriteEffective(&buf))
+
+	out := buf.String()
+	assert.NotContains(t, out, "hunter2")
+	assert.NotContains(t, out, "tok\n")
+	assert.Contains(t, out, "timezone: UTC")
+	assert.Contains(t, out, "server.port: default")
+
+	// The original configuration is not modified
+	assert.Equal(t, "hunter2", cfg.MultiDBConfig.Connections[0].Password)
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+)
+
+// redacted replaces secret values in configuration dumps
+const redacted = "***"
+
+// secretOptionKeys are substrings of option keys whose values are redacted
+var secretOptionKeys = []string{"password", "passwd", "secret", "token"}
+
+// effectiveView is the serialized form of the effective configuration
+type effectiveView struct {
+	ConfigPath string            `yaml:"config_path"`
+	Server     serverView        `yaml:"server"`
+	Logging    loggingView       `yaml:"logging"`
+	Security   securityView      `yaml:"security"`
+	Cache      cacheView         `yaml:"cache"`
+	Sources    map[string]Source `yaml:"sources"`
+}
+
+type serverView struct {
+	Host      string `yaml:"host"`
+	Port      int    `yaml:"port"`
+	Transport string `yaml:"transport"`
+}
+
+type loggingView struct {
+	Level   string `yaml:"level"`
+	Disable bool   `yaml:"disable"`
+}
+
+type securityView struct {
+	ReadOnly         bool   `yaml:"read_only"`
+	SessionControl   string `yaml:"session_control"`
+	CancelStatements bool   `yaml:"cancel_statements"`
+}
+
+type cacheView struct {
+	SchemaTTL int `yaml:"schema_ttl"`
+}
+
+// Redacted returns a copy of the connection list with secrets masked
+func (c *Config) Redacted() []db.DatabaseConnectionConfig {
+	if c.MultiDBConfig == nil {
+		return nil
+	}
+
+	conns := make([]db.DatabaseConnectionConfig, 0, len(c.MultiDBConfig.Connections))
+	for _, conn := range c.MultiDBConfig.Connections {
+		if conn.Password != "" {
+			conn.Password = redacted
+		}
+		if len(conn.Options) > 0 {
+			options := make(map[string]string, len(conn.Options))
+			for key, value := range conn.Options {
+				if isSecretKey(key) {
+					value = redacted
+				}
+				options[key] = value
+			}
+			conn.Options = options
+		}
+		conns = append(conns, conn)
+	}
+	return conns
+}
+
+// WriteEffective writes the effective configuration as YAML, with secrets
+// redacted and the source of each setting listed
+func (c *Config) WriteEffective(w io.Writer) error {
+	view := effectiveView{
+		ConfigPath: c.ConfigPath,
+		Server: serverView{
+			Host:      c.Server.Host,
+			Port:      c.Server.Port,
+			Transport: c.Server.TransportMode,
+		},
+		Logging: loggingView{
+			Level:   c.Logging.Level,
+			Disable: c.Logging.Disable,
+		},
+		Security: securityView{
+			ReadOnly:         c.Security.ReadOnly,
+			SessionControl:   c.Security.SessionControl,
+			CancelStatements: c.Security.CancelStatements,
+		},
+		Cache: cacheView{
+			SchemaTTL: c.Cache.SchemaTTL,
+		},
+		Sources: c.Sources,
+	}
+
+	out, err := yaml.Marshal(view)
+	if err != nil {
+		return fmt.Errorf("failed to render configuration: %w", err)
+	}
+
+	// Connections are rendered through their JSON field names so the dump
+	// matches the keys used in config files
+	conns, err := connectionsYAML(c.Redacted())
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "%s%s", out, conns); err != nil {
+		return fmt.Errorf("failed to write configuration: %w", err)
+	}
+	return nil
+}
+
+// connectionsYAML renders connections using the config file key names
+func connectionsYAML(conns []db.DatabaseConnectionConfig) (string, error) {
+	if len(conns) == 0 {
+		return "connections: []\n", nil
+	}
+
+	data, err := json.Marshal(map[string]interface{}{"connections": conns})
+	if err != nil {
+		return "", fmt.Errorf("failed to render connections: %w", err)
+	}
+
+	// JSON is valid YAML, so decode it generically and re-encode as YAML
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("failed to render connections: %w", err)
+	}
+	out, err := yaml.Marshal(doc)
+	if err != nil {
+		return "", fmt.Errorf("failed to render connections: %w", err)
+	}
+	return string(out), nil
+}
+
+// isSecretKey reports whether an option key names a secret
+func isSecretKey(key string) bool {
+	lower := strings.ToLower(key)
+	for _, s := range secretOptionKeys {
+		if strings.Contains(lower, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/tools"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+// ActivityTool lists the live sessions of a database and, when the session
+// control policy allows it, cancels their queries
+type ActivityTool struct {
+	BaseToolType
+}
+
+// NewActivityTool creates a new activity tool type
+func NewActivityTool() *ActivityTool {
+	return &ActivityTool{
+		BaseToolType: BaseToolType{
+			name:        "activity",
+			description: "Show live sessions and long-running queries",
+		},
+	}
+}
+
+// CreateTool creates an activity tool
+func (t *ActivityTool) CreateTool(name string, dbID string) interface{} {
+	return tools.NewTool(
+		name,
+		tools.WithDescription(t.GetDescription(dbID)+". Lists sessions with their state, current query, duration, wait event and client address, longest first. Cancelling a query requires security.session_control"),
+		tools.WithString("action",
+			tools.Description("list (default) or cancel_query to cancel the running query of the session given by pid"),
+		),
+		tools.WithString("state",
+			tools.Description("Only sessions in this state: active, idle, idle in transaction, ..."),
+		),
+		tools.WithString("user",
+			tools.Description("Only sessions of this database user"),
+		),
+		tools.WithNumber("min_duration",
+			tools.Description("Only sessions in their current state for at least this many seconds"),
+		),
+		tools.WithNumber("limit",
+			tools.Description("Number of sessions to list (default: 50)"),
+		),
+		tools.WithNumber("pid",
+			tools.Description("Session whose query to cancel: a PostgreSQL backend pid or a MySQL processlist id"),
+		),
+	)
+}
+
+// HandleRequest handles activity tool requests
+func (t *ActivityTool) HandleRequest(ctx context.Context, request server.ToolCallRequest, dbID string, useCase UseCaseProvider) (interface{}, error) {
+	if dbID == "" {
+		dbID = extractDatabaseIDFromName(request.Name)
+	}
+
+	action := getStringParam(request.Parameters, "action")
+	switch action {
+	case "", "list":
+		filter := domain.ActivityFilter{
+			State: getStringParam(request.Parameters, "state"),
+			User:  getStringParam(request.Parameters, "user"),
+		}
+		if value, ok := request.Parameters["min_duration"].(float64); ok && value > 0 {
+			filter.MinDuration = value * 1000
+		}
+		if value, ok := request.Parameters["limit"].(float64); ok && value > 0 {
+			filter.Limit = int(value)
+		}
+		sessions, err := useCase.GetActivity(ctx, dbID, filter)
+		if err != nil {
+			return nil, err
+		}
+		resp := createTextResponse(formatActivity(dbID, sessions))
+		addMetadata(resp, "sessions", len(sessions))
+		return resp, nil
+
+	case "cancel_query":
+		value, ok := request.Parameters["pid"].(float64)
+		if !ok || value <= 0 {
+			return nil, fmt.Errorf("pid parameter is required to cancel a query")
+		}
+		pid := int64(value)
+		if err := useCase.CancelQuery(ctx, dbID, pid); err != nil {
+			return nil, err
+		}
+		resp := createTextResponse(fmt.Sprintf("Cancelled the query of session %d on %s", pid, dbID))
+		addMetadata(resp, "action", action)
+		addMetadata(resp, "pid", pid)
+		return resp, nil
+
+	default:
+		return nil, fmt.Errorf("invalid action: %s", action)
+	}
+}
+
+// formatActivity renders live sessions as text
+func formatActivity(dbID string, sessions []domain.SessionActivity) string {
+	if len(sessions) == 0 {
+		return fmt.Sprintf("No matching sessions on %s", dbID)
+	}
+
+	var output strings.Builder
+	fmt.Fprintf(&output, "Sessions on %s: %d\n", dbID, len(sessions))
+	for _, s := range sessions {
+		fmt.Fprintf(&output, "\npid %d%s from %s", s.PID, userSuffix(s.User), s.ClientAddr)
+		if s.Application != "" {
+			fmt.Fprintf(&output, " (%s)", s.Application)
+		}
+		fmt.Fprintf(&output, ": %s for %s", s.State, formatMilliseconds(s.Duration))
+		if s.TransactionDuration > 0 {
+			fmt.Fprintf(&output, ", transaction open %s", formatMilliseconds(s.TransactionDuration))
+		}
+		output.WriteString("\n")
+		if wait := strings.Trim(s.WaitEventType+": "+s.WaitEvent, ": "); wait != "" {
+			fmt.Fprintf(&output, "  wait: %s\n", wait)
+		}
+		if s.Query != "" {
+			fmt.Fprintf(&output, "  %s\n", oneLine(s.Query))
+		}
+	}
+	return output.String()
+}
@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+func TestActivityToolHandleRequest(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	filter := domain.ActivityFilter{State: "active", User: "app", MinDuration: 2500, Limit: 10}
+	mockUseCase.On("GetActivity", mock.Anything, "test_db", filter).Return([]domain.SessionActivity{
+		{PID: 100, User: "app", ClientAddr: "10.0.0.7", Application: "billing", State: "active",
+			Query: "SELECT *\n  FROM orders", Duration: 95000, TransactionDuration: 96000, WaitEventType: "Lock", WaitEvent: "relation"},
+		{PID: 101, User: "app", ClientAddr: "local", State: "active", Query: "SELECT 1", Duration: 2600},
+	}, nil)
+
+	result, err := NewActivityTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"state": "active", "user": "app", "min_duration": 2.5, "limit": float64(10)},
+	}, "test_db", mockUseCase)
+	require.NoError(t, err)
+
+	resp := result.(map[string]interface{})
+	text := resp["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Equal(t, "Sessions on test_db: 2\n"+
+		"\npid 100 (app) from 10.0.0.7 (billing): active for 95.0 s, transaction open 96.0 s\n"+
+		"  wait: Lock: relation\n"+
+		"  SELECT * FROM orders\n"+
+		"\npid 101 (app) from local: active for 2.6 s\n"+
+		"  SELECT 1\n", text)
+	assert.Equal(t, 2, resp["metadata"].(map[string]interface{})["sessions"])
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestActivityToolCancelQuery(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("CancelQuery", mock.Anything, "test_db", int64(100)).Return(nil)
+
+	result, err := NewActivityTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"action": "cancel_query", "pid": float64(100)},
+	}, "test_db", mockUseCase)
+	require.NoError(t, err)
+	text := result.(map[string]interface{})["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Equal(t, "Cancelled the query of session 100 on test_db", text)
+
+	_, err = NewActivityTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"action": "cancel_query"},
+	}, "test_db", mockUseCase)
+	assert.ErrorContains(t, err, "pid parameter is required")
+	mockUseCase.AssertExpectations(t)
+}
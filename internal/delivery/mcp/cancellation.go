@@ -0,0 +1,182 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+)
+
+// ErrCallCancelled is the cause of the context of a tool call the client
+// cancelled
+var ErrCallCancelled = errors.New("cancelled by the client")
+
+// CallTracker connects MCP notifications/cancelled to the tool calls they
+// cancel. Tool handlers are not told the JSON-RPC id of their request, so
+// WatchStdio records tool calls as they arrive and each handler claims the
+// oldest pending call of its tool when it starts.
+type CallTracker struct {
+	mu      sync.Mutex
+	pending []*trackedCall
+}
+
+// trackedCall is a tools/call request that has not finished yet
+type trackedCall struct {
+	id      string
+	tool    string
+	started bool
+	cancel  context.CancelCauseFunc // set once a handler runs the call
+	cause   error                   // set when the call is cancelled before it starts
+}
+
+// NewCallTracker creates a new call tracker
+func NewCallTracker() *CallTracker {
+	return &CallTracker{}
+}
+
+// Received records a tools/call request read from the client
+func (t *CallTracker) Received(id, tool string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending = append(t.pending, &trackedCall{id: id, tool: tool})
+}
+
+// Start claims the oldest pending call of a tool. It returns a context that
+// ends when the client cancels the call and a func to call once it is done.
+// Calls that were never received, as over SSE, cannot be cancelled.
+func (t *CallTracker) Start(ctx context.Context, tool string) (context.Context, func()) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i, call := range t.pending {
+		if call.started || call.tool != tool {
+			continue
+		}
+		// Calls received before this one that no handler claimed were
+		// answered by the transport itself
+		t.pending = append(dropUnstarted(t.pending[:i]), t.pending[i:]...)
+
+		callCtx, cancel := context.WithCancelCause(ctx)
+		call.started = true
+		call.cancel = cancel
+		if call.cause != nil {
+			cancel(call.cause)
+		}
+		return callCtx, func() {
+			cancel(nil)
+			t.finish(call)
+		}
+	}
+	return ctx, func() {}
+}
+
+// Cancel cancels a call by its request id. It reports whether the call was
+// still pending or running.
+func (t *CallTracker) Cancel(id, reason string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, call := range t.pending {
+		if call.id != id {
+			continue
+		}
+		cause := ErrCallCancelled
+		if reason != "" {
+			cause = fmt.Errorf("%w: %s", ErrCallCancelled, reason)
+		}
+		if call.cancel != nil {
+			call.cancel(cause)
+		} else {
+			call.cause = cause
+		}
+		return true
+	}
+	return false
+}
+
+// finish forgets a call once its handler returned
+func (t *CallTracker) finish(done *trackedCall) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for i, call := range t.pending {
+		if call == done {
+			t.pending = append(t.pending[:i], t.pending[i+1:]...)
+			return
+		}
+	}
+}
+
+// dropUnstarted returns the calls a handler is running
+func dropUnstarted(calls []*trackedCall) []*trackedCall {
+	running := make([]*trackedCall, 0, len(calls))
+	for _, call := range calls {
+		if call.started {
+			running = append(running, call)
+		}
+	}
+	return running
+}
+
+// WatchStdio forwards the JSON-RPC messages a client writes to in to out, one
+// line at a time, recording tool calls and cancellations with calls on the
+// way. The stdio transport handles one message at a time and drops
+// notifications, so reading ahead of it is the only way a cancellation can
+// reach the call it cancels while that call is running.
+func WatchStdio(in io.Reader, out io.Writer, calls *CallTracker) error {
+	reader := bufio.NewReader(in)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			observeMessage(line, calls)
+			if _, writeErr := out.Write(line); writeErr != nil {
+				return fmt.Errorf("failed to forward message: %w", writeErr)
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read message: %w", err)
+		}
+	}
+}
+
+// observeMessage records a tools/call request or a notifications/cancelled
+// notification; other messages are ignored
+func observeMessage(line []byte, calls *CallTracker) {
+	var message struct {
+		ID     json.RawMessage `json:"id"`
+		Method string          `json:"method"`
+		Params struct {
+			Name      string          `json:"name"`
+			RequestID json.RawMessage `json:"requestId"`
+			Reason    string          `json:"reason"`
+		} `json:"params"`
+	}
+	if err := json.Unmarshal(line, &message); err != nil {
+		return
+	}
+
+	switch message.Method {
+	case "tools/call":
+		if len(message.ID) > 0 {
+			calls.Received(requestKey(message.ID), message.Params.Name)
+		}
+	case "notifications/cancelled":
+		id := requestKey(message.Params.RequestID)
+		if calls.Cancel(id, message.Params.Reason) {
+			logger.Info("Client cancelled tool call %s", id)
+		}
+	}
+}
+
+// requestKey renders a JSON-RPC id, a string or a number, as a map key
+func requestKey(id json.RawMessage) string {
+	return string(bytes.TrimSpace(id))
+}
@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+)
+
+func TestCallTrackerCancelsRunningCall(t *testing.T) {
+	calls := NewCallTracker()
+	calls.Received("1", "query_main")
+
+	ctx, done := calls.Start(context.Background(), "query_main")
+	defer done()
+	require.NoError(t, ctx.Err())
+
+	assert.True(t, calls.Cancel("1", "user aborted"))
+	<-ctx.Done()
+	assert.True(t, errors.Is(context.Cause(ctx), ErrCallCancelled))
+	assert.Contains(t, context.Cause(ctx).Error(), "user aborted")
+}
+
+func TestCallTrackerCancelsPendingCall(t *testing.T) {
+	calls := NewCallTracker()
+	calls.Received("1", "query_main")
+	calls.Received(`"b"`, "schema_main")
+	assert.True(t, calls.Cancel(`"b"`, ""))
+
+	// The first call was answered without reaching a handler
+	ctx, done := calls.Start(context.Background(), "schema_main")
+	defer done()
+	assert.ErrorIs(t, context.Cause(ctx), ErrCallCancelled)
+	assert.Len(t, calls.pending, 1)
+}
+
+func TestCallTrackerForgetsFinishedCalls(t *testing.T) {
+	calls := NewCallTracker()
+	calls.Received("1", "query_main")
+
+	ctx, done := calls.Start(context.Background(), "query_main")
+	done()
+	assert.Empty(t, calls.pending)
+	assert.False(t, calls.Cancel("1", ""))
+	assert.NotErrorIs(t, context.Cause(ctx), ErrCallCancelled)
+
+	// Untracked calls run with the transport's context
+	parent := context.Background()
+	ctx, done = calls.Start(parent, "query_main")
+	done()
+	assert.Equal(t, parent, ctx)
+}
+
+func TestWatchStdio(t *testing.T) {
+	logger.Initialize("error")
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"query_main","arguments":{}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7,"reason":"timeout"}}`,
+		`not json`,
+		`{"jsonrpc":"2.0","id":8,"method":"tools/list"}`,
+	}, "\n")
+
+	calls := NewCallTracker()
+	var out strings.Builder
+	require.NoError(t, WatchStdio(strings.NewReader(input), &out, calls))
+	assert.Equal(t, input, out.String())
+
+	ctx, done := calls.Start(context.Background(), "query_main")
+	defer done()
+	assert.ErrorIs(t, context.Cause(ctx), ErrCallCancelled)
+	assert.Contains(t, context.Cause(ctx).Error(), "timeout")
+}
+
+func TestCallError(t *testing.T) {
+	cause := errors.New("pq: canceling statement due to user request")
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(ErrCallCancelled)
+	err := callError(ctx, "query_main", 0, cause)
+	assert.EqualError(t, err, "query_main was cancelled by the client: pq: canceling statement due to user request")
+	assert.ErrorIs(t, err, cause)
+
+	ctx, stop := context.WithTimeout(context.Background(), 0)
+	defer stop()
+	<-ctx.Done()
+	assert.Contains(t, callError(ctx, "query_main", 0, cause).Error(), "query_main timed out after")
+
+	assert.Equal(t, cause, callError(context.Background(), "query_main", 0, cause))
+}
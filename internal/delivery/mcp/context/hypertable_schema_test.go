@@ -90,14 +90,11 @@ func TestHypertableSchemaProvider(t *testing.T) {
 
 		// Set up expectations for the mock
 		mockUseCase.On("GetDatabaseType", "timescale_db").Return("postgres", nil).Once()
-		mockUseCase.On("ExecuteStatement", mock.Anything, "timescale_db", mock.MatchedBy(func(sql string) bool {
-			return sql == "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-		}), mock.Anything).Return(`[{"extversion": "2.8.0"}]`, nil).Once()
+		mockUseCase.On("GetSchemaComponent", mock.Anything, "timescale_db", "hypertables", "", "").Return(map[string]interface{}{"timescaledb_version": "2.8.0"}, nil).Once()
 
 		// Metadata query
 		mockUseCase.On("ExecuteStatement", mock.Anything, "timescale_db", mock.MatchedBy(func(sql string) bool {
-			return sql != "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'" &&
-				strings.Contains(sql, "hypertable")
+			return strings.Contains(sql, "hypertable")
 		}), mock.Anything).Return(sampleMetadataResult, nil).Once()
 
 		// Columns query
@@ -163,9 +160,7 @@ func TestHypertableSchemaProvider(t *testing.T) {
 	t.Run("get_hypertable_schema_with_non_timescaledb", func(t *testing.T) {
 		// Set up expectations for the mock
 		mockUseCase.On("GetDatabaseType", "postgres_db").Return("postgres", nil).Once()
-		mockUseCase.On("ExecuteStatement", mock.Anything, "postgres_db", mock.MatchedBy(func(sql string) bool {
-			return sql == "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-		}), mock.Anything).Return(`[]`, nil).Once()
+		mockUseCase.On("GetSchemaComponent", mock.Anything, "postgres_db", "hypertables", "", "").Return(map[string]interface{}{"timescaledb_version": nil}, nil).Once()
 
 		// Create the schema provider
 		provider := mcp.NewHypertableSchemaProvider()
@@ -185,9 +180,7 @@ func TestHypertableSchemaProvider(t *testing.T) {
 	t.Run("get_hypertable_schema_with_not_a_hypertable", func(t *testing.T) {
 		// Set up expectations for the mock
 		mockUseCase.On("GetDatabaseType", "timescale_db").Return("postgres", nil).Once()
-		mockUseCase.On("ExecuteStatement", mock.Anything, "timescale_db", mock.MatchedBy(func(sql string) bool {
-			return sql == "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-		}), mock.Anything).Return(`[{"extversion": "2.8.0"}]`, nil).Once()
+		mockUseCase.On("GetSchemaComponent", mock.Anything, "timescale_db", "hypertables", "", "").Return(map[string]interface{}{"timescaledb_version": "2.8.0"}, nil).Once()
 
 		// Empty result for metadata query indicates it's not a hypertable
 		mockUseCase.On("ExecuteStatement", mock.Anything, "timescale_db", mock.MatchedBy(func(sql string) bool {
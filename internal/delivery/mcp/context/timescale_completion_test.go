@@ -20,9 +20,7 @@ func TestTimescaleDBCompletionProvider(t *testing.T) {
 	t.Run("get_time_bucket_completions", func(t *testing.T) {
 		// Set up expectations for the mock
 		mockUseCase.On("GetDatabaseType", "timescale_db").Return("postgres", nil).Once()
-		mockUseCase.On("ExecuteStatement", mock.Anything, "timescale_db", mock.MatchedBy(func(sql string) bool {
-			return sql == "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-		}), mock.Anything).Return(`[{"extversion": "2.8.0"}]`, nil).Once()
+		mockUseCase.On("GetSchemaComponent", mock.Anything, "timescale_db", "hypertables", "", "").Return(map[string]interface{}{"timescaledb_version": "2.8.0"}, nil).Once()
 
 		// Create the completion provider
 		provider := mcp.NewTimescaleDBCompletionProvider()
@@ -64,9 +62,7 @@ func TestTimescaleDBCompletionProvider(t *testing.T) {
 	t.Run("get_hypertable_function_completions", func(t *testing.T) {
 		// Set up expectations for the mock
 		mockUseCase.On("GetDatabaseType", "timescale_db").Return("postgres", nil).Once()
-		mockUseCase.On("ExecuteStatement", mock.Anything, "timescale_db", mock.MatchedBy(func(sql string) bool {
-			return sql == "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-		}), mock.Anything).Return(`[{"extversion": "2.8.0"}]`, nil).Once()
+		mockUseCase.On("GetSchemaComponent", mock.Anything, "timescale_db", "hypertables", "", "").Return(map[string]interface{}{"timescaledb_version": "2.8.0"}, nil).Once()
 
 		// Create the completion provider
 		provider := mcp.NewTimescaleDBCompletionProvider()
@@ -112,10 +108,8 @@ func TestTimescaleDBCompletionProvider(t *testing.T) {
 		// The new implementation makes fewer calls to GetDatabaseType
 		localMock.On("GetDatabaseType", "timescale_db").Return("postgres", nil).Once()
 
-		// It also calls ExecuteStatement once through DetectTimescaleDB
-		localMock.On("ExecuteStatement", mock.Anything, "timescale_db", mock.MatchedBy(func(sql string) bool {
-			return sql == "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-		}), mock.Anything).Return(`[{"extversion": "2.8.0"}]`, nil).Once()
+		// It also reads the hypertables component once through DetectTimescaleDB
+		localMock.On("GetSchemaComponent", mock.Anything, "timescale_db", "hypertables", "", "").Return(map[string]interface{}{"timescaledb_version": "2.8.0"}, nil).Once()
 
 		// Create the completion provider
 		provider := mcp.NewTimescaleDBCompletionProvider()
@@ -171,10 +165,8 @@ func TestTimescaleDBCompletionProvider(t *testing.T) {
 		// With the new implementation, we only need one GetDatabaseType call
 		localMock.On("GetDatabaseType", "postgres_db").Return("postgres", nil).Once()
 
-		// It also calls ExecuteStatement through DetectTimescaleDB
-		localMock.On("ExecuteStatement", mock.Anything, "postgres_db", mock.MatchedBy(func(sql string) bool {
-			return sql == "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-		}), mock.Anything).Return(`[]`, nil).Once()
+		// It also reads the hypertables component through DetectTimescaleDB
+		localMock.On("GetSchemaComponent", mock.Anything, "postgres_db", "hypertables", "", "").Return(map[string]interface{}{"timescaledb_version": nil}, nil).Once()
 
 		// Create the completion provider
 		provider := mcp.NewTimescaleDBCompletionProvider()
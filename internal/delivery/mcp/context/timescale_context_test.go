@@ -8,48 +8,14 @@ import (
 	"github.com/stretchr/testify/mock"
 
 	"github.com/FreePeak/db-mcp-server/internal/delivery/mcp"
+	"github.com/FreePeak/db-mcp-server/internal/delivery/mcp/mcptest"
 )
 
-// MockDatabaseUseCase is a mock implementation of the UseCaseProvider interface
-type MockDatabaseUseCase struct {
-	mock.Mock
-}
-
-// ExecuteStatement mocks the ExecuteStatement method
-func (m *MockDatabaseUseCase) ExecuteStatement(ctx context.Context, dbID, statement string, params []interface{}) (string, error) {
-	args := m.Called(ctx, dbID, statement, params)
-	return args.String(0), args.Error(1)
-}
+// MockDatabaseUseCase is the mock of the database use case shared by the
+// delivery tests
+type MockDatabaseUseCase = mcptest.MockDatabaseUseCase
 
-// GetDatabaseType mocks the GetDatabaseType method
-func (m *MockDatabaseUseCase) GetDatabaseType(dbID string) (string, error) {
-	args := m.Called(dbID)
-	return args.String(0), args.Error(1)
-}
-
-// ExecuteQuery mocks the ExecuteQuery method
-func (m *MockDatabaseUseCase) ExecuteQuery(ctx context.Context, dbID, query string, params []interface{}) (string, error) {
-	args := m.Called(ctx, dbID, query, params)
-	return args.String(0), args.Error(1)
-}
-
-// ExecuteTransaction mocks the ExecuteTransaction method
-func (m *MockDatabaseUseCase) ExecuteTransaction(ctx context.Context, dbID, action string, txID string, statement string, params []interface{}, readOnly bool) (string, map[string]interface{}, error) {
-	args := m.Called(ctx, dbID, action, txID, statement, params, readOnly)
-	return args.String(0), args.Get(1).(map[string]interface{}), args.Error(2)
-}
-
-// GetDatabaseInfo mocks the GetDatabaseInfo method
-func (m *MockDatabaseUseCase) GetDatabaseInfo(dbID string) (map[string]interface{}, error) {
-	args := m.Called(dbID)
-	return args.Get(0).(map[string]interface{}), args.Error(1)
-}
-
-// ListDatabases mocks the ListDatabases method
-func (m *MockDatabaseUseCase) ListDatabases() []string {
-	args := m.Called()
-	return args.Get(0).([]string)
-}
+var _ mcp.UseCaseProvider = (*MockDatabaseUseCase)(nil)
 
 func TestTimescaleDBContextProvider(t *testing.T) {
 	// Create a mock use case provider
@@ -60,13 +26,11 @@ func TestTimescaleDBContextProvider(t *testing.T) {
 
 	t.Run("detect_timescaledb_with_extension", func(t *testing.T) {
 		// Sample result indicating TimescaleDB is available
-		sampleVersionResult := `[{"extversion": "2.9.1"}]`
+		sampleVersionResult := map[string]interface{}{"timescaledb_version": "2.9.1"}
 
 		// Set up expectations for the mock
 		mockUseCase.On("GetDatabaseType", "test_db").Return("postgres", nil).Once()
-		mockUseCase.On("ExecuteStatement", mock.Anything, "test_db", mock.MatchedBy(func(sql string) bool {
-			return sql == "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-		}), mock.Anything).Return(sampleVersionResult, nil).Once()
+		mockUseCase.On("GetSchemaComponent", mock.Anything, "test_db", "hypertables", "", "").Return(sampleVersionResult, nil).Once()
 
 		// Create the context provider
 		provider := mcp.NewTimescaleDBContextProvider()
@@ -86,13 +50,11 @@ func TestTimescaleDBContextProvider(t *testing.T) {
 
 	t.Run("detect_timescaledb_with_no_extension", func(t *testing.T) {
 		// Sample result indicating TimescaleDB is not available
-		sampleEmptyResult := `[]`
+		sampleEmptyResult := map[string]interface{}{"timescaledb_version": nil}
 
 		// Set up expectations for the mock
 		mockUseCase.On("GetDatabaseType", "postgres_db").Return("postgres", nil).Once()
-		mockUseCase.On("ExecuteStatement", mock.Anything, "postgres_db", mock.MatchedBy(func(sql string) bool {
-			return sql == "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-		}), mock.Anything).Return(sampleEmptyResult, nil).Once()
+		mockUseCase.On("GetSchemaComponent", mock.Anything, "postgres_db", "hypertables", "", "").Return(sampleEmptyResult, nil).Once()
 
 		// Create the context provider
 		provider := mcp.NewTimescaleDBContextProvider()
@@ -132,20 +94,17 @@ func TestTimescaleDBContextProvider(t *testing.T) {
 
 	t.Run("get_hypertables_info", func(t *testing.T) {
 		// Sample result with list of hypertables
-		sampleHypertablesResult := `[
-			{"table_name": "metrics", "time_column": "timestamp", "chunk_interval": "1 day"},
-			{"table_name": "logs", "time_column": "log_time", "chunk_interval": "4 hours"}
-		]`
+		sampleHypertablesResult := map[string]interface{}{
+			"timescaledb_version": "2.8.0",
+			"hypertables": []map[string]interface{}{
+				{"table_name": "metrics", "time_column": "timestamp", "chunk_interval": "1 day"},
+				{"table_name": "logs", "time_column": "log_time", "chunk_interval": "4 hours"},
+			},
+		}
 
 		// Set up expectations for the mock
 		mockUseCase.On("GetDatabaseType", "timescale_db").Return("postgres", nil).Once()
-		mockUseCase.On("ExecuteStatement", mock.Anything, "timescale_db", mock.MatchedBy(func(sql string) bool {
-			return sql == "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-		}), mock.Anything).Return(`[{"extversion": "2.8.0"}]`, nil).Once()
-
-		mockUseCase.On("ExecuteStatement", mock.Anything, "timescale_db", mock.MatchedBy(func(sql string) bool {
-			return sql != "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-		}), mock.Anything).Return(sampleHypertablesResult, nil).Once()
+		mockUseCase.On("GetSchemaComponent", mock.Anything, "timescale_db", "hypertables", "", "").Return(sampleHypertablesResult, nil).Once()
 
 		// Create the context provider
 		provider := mcp.NewTimescaleDBContextProvider()
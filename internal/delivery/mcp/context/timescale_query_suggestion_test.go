@@ -20,14 +20,12 @@ func TestTimescaleDBQuerySuggestions(t *testing.T) {
 	t.Run("get_query_suggestions_with_hypertables", func(t *testing.T) {
 		// Set up expectations for the mock
 		mockUseCase.On("GetDatabaseType", "timescale_db").Return("postgres", nil).Once()
-		mockUseCase.On("ExecuteStatement", mock.Anything, "timescale_db", mock.MatchedBy(func(sql string) bool {
-			return sql == "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-		}), mock.Anything).Return(`[{"extversion": "2.8.0"}]`, nil).Once()
-
-		// Mock the hypertable query
-		mockUseCase.On("ExecuteStatement", mock.Anything, "timescale_db", mock.MatchedBy(func(sql string) bool {
-			return sql != "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-		}), mock.Anything).Return(`[{"table_name": "metrics", "time_column": "timestamp", "chunk_interval": "604800000000"}]`, nil).Once()
+		mockUseCase.On("GetSchemaComponent", mock.Anything, "timescale_db", "hypertables", "", "").Return(map[string]interface{}{
+			"timescaledb_version": "2.8.0",
+			"hypertables": []map[string]interface{}{
+				{"table_name": "metrics", "time_column": "timestamp", "chunk_interval": "604800000000"},
+			},
+		}, nil).Once()
 
 		// Create the completion provider
 		provider := mcp.NewTimescaleDBCompletionProvider()
@@ -97,14 +95,11 @@ func TestTimescaleDBQuerySuggestions(t *testing.T) {
 
 		// Set up expectations for the mock
 		localMock.On("GetDatabaseType", "timescale_db").Return("postgres", nil).Once()
-		localMock.On("ExecuteStatement", mock.Anything, "timescale_db", mock.MatchedBy(func(sql string) bool {
-			return sql == "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-		}), mock.Anything).Return(`[{"extversion": "2.8.0"}]`, nil).Once()
-
-		// Mock the hypertable query with empty results
-		localMock.On("ExecuteStatement", mock.Anything, "timescale_db", mock.MatchedBy(func(sql string) bool {
-			return sql != "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-		}), mock.Anything).Return(`[]`, nil).Once()
+		// No hypertables in the database
+		localMock.On("GetSchemaComponent", mock.Anything, "timescale_db", "hypertables", "", "").Return(map[string]interface{}{
+			"timescaledb_version": "2.8.0",
+			"hypertables":         []map[string]interface{}{},
+		}, nil).Once()
 
 		// Create the completion provider
 		provider := mcp.NewTimescaleDBCompletionProvider()
@@ -135,9 +130,7 @@ func TestTimescaleDBQuerySuggestions(t *testing.T) {
 
 		// Set up expectations for the mock
 		localMock.On("GetDatabaseType", "postgres_db").Return("postgres", nil).Once()
-		localMock.On("ExecuteStatement", mock.Anything, "postgres_db", mock.MatchedBy(func(sql string) bool {
-			return sql == "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-		}), mock.Anything).Return(`[]`, nil).Once()
+		localMock.On("GetSchemaComponent", mock.Anything, "postgres_db", "hypertables", "", "").Return(map[string]interface{}{"timescaledb_version": nil}, nil).Once()
 
 		// Create the completion provider
 		provider := mcp.NewTimescaleDBCompletionProvider()
@@ -0,0 +1,128 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/tools"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+// IndexAdviceTool proposes indexes for a database's workload and flags
+// unused and duplicate indexes
+type IndexAdviceTool struct {
+	BaseToolType
+}
+
+// NewIndexAdviceTool creates a new index advice tool type
+func NewIndexAdviceTool() *IndexAdviceTool {
+	return &IndexAdviceTool{
+		BaseToolType: BaseToolType{
+			name:        "index_advice",
+			description: "Propose indexes for the heaviest queries and flag unused and duplicate indexes",
+		},
+	}
+}
+
+// CreateTool creates an index advice tool
+func (t *IndexAdviceTool) CreateTool(name string, dbID string) interface{} {
+	return tools.NewTool(
+		name,
+		tools.WithDescription(t.GetDescription(dbID)+". Plans the workload's heaviest queries, proposes CREATE INDEX statements for selective full scans with a rationale and estimated saving, and lists indexes that could be dropped. Nothing is created or dropped"),
+		tools.WithString("schema",
+			tools.Description("Schema (PostgreSQL) or database (MySQL) to advise on. Defaults to the connection's default_schema"),
+		),
+		tools.WithString("source",
+			tools.Description("Workload to plan: auto (default) uses pg_stat_statements or performance_schema when available, history the queries run through this server, statements only the database's statistics"),
+		),
+		tools.WithNumber("limit",
+			tools.Description("Number of the heaviest queries to plan (default: 20)"),
+		),
+		tools.WithNumber("min_rows",
+			tools.Description("Smallest table, in estimated rows, worth an index (default: 1000)"),
+		),
+		tools.WithBoolean("validate",
+			tools.Description("PostgreSQL with the hypopg extension: keep only proposals the planner would use, checked with hypothetical indexes"),
+		),
+	)
+}
+
+// HandleRequest handles index advice tool requests
+func (t *IndexAdviceTool) HandleRequest(ctx context.Context, request server.ToolCallRequest, dbID string, useCase UseCaseProvider) (interface{}, error) {
+	if dbID == "" {
+		dbID = extractDatabaseIDFromName(request.Name)
+	}
+
+	opts := domain.IndexAdviceOptions{
+		Source:   getStringParam(request.Parameters, "source"),
+		Validate: getBoolParam(request.Parameters, "validate"),
+	}
+	if value, ok := request.Parameters["limit"].(float64); ok && value > 0 {
+		opts.Limit = int(value)
+	}
+	if value, ok := request.Parameters["min_rows"].(float64); ok && value > 0 {
+		opts.MinRows = value
+	}
+
+	advice, err := useCase.AdviseIndexes(ctx, dbID, getStringParam(request.Parameters, "schema"), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := createTextResponse(formatIndexAdvice(advice))
+	addMetadata(resp, "proposals", len(advice.Proposals))
+	addMetadata(resp, "unused", len(advice.Unused))
+	addMetadata(resp, "duplicates", len(advice.Duplicates))
+	return resp, nil
+}
+
+// formatIndexAdvice renders index advice as text
+func formatIndexAdvice(advice *domain.IndexAdvice) string {
+	var output strings.Builder
+	fmt.Fprintf(&output, "Index advice for schema %s from %s: %d queries planned", advice.Schema, advice.Source, advice.Planned)
+	if advice.Skipped > 0 {
+		fmt.Fprintf(&output, ", %d skipped", advice.Skipped)
+	}
+	output.WriteString("\n")
+
+	output.WriteString("\nProposed indexes:\n")
+	if len(advice.Proposals) == 0 {
+		output.WriteString("  (none)\n")
+	}
+	for i, p := range advice.Proposals {
+		fmt.Fprintf(&output, "%d. %s\n", i+1, p.Statement)
+		fmt.Fprintf(&output, "   %s\n", p.Rationale)
+		fmt.Fprintf(&output, "   Estimated saving: %.2f ms over %d calls of %d queries", p.EstimatedSaving, p.Calls, len(p.Fingerprints))
+		if advice.Validated {
+			fmt.Fprintf(&output, "; plan cost %.2f -> %.2f", p.CostBefore, p.CostAfter)
+		}
+		output.WriteString("\n")
+	}
+
+	writeFindings := func(title string, findings []domain.IndexFinding) {
+		fmt.Fprintf(&output, "\n%s:\n", title)
+		if len(findings) == 0 {
+			output.WriteString("  (none)\n")
+		}
+		for _, f := range findings {
+			fmt.Fprintf(&output, "- %s on %s: %s", f.Index, f.Table, f.Reason)
+			if f.SizeBytes > 0 {
+				fmt.Fprintf(&output, " (%d bytes)", f.SizeBytes)
+			}
+			fmt.Fprintf(&output, "\n  %s\n", f.Statement)
+		}
+	}
+	writeFindings("Unused indexes", advice.Unused)
+	writeFindings("Duplicate indexes", advice.Duplicates)
+
+	if len(advice.Notes) > 0 {
+		output.WriteString("\nNotes:\n")
+		for _, note := range advice.Notes {
+			fmt.Fprintf(&output, "- %s\n", note)
+		}
+	}
+	return output.String()
+}
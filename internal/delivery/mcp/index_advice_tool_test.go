@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+func TestIndexAdviceToolHandleRequest(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	opts := domain.IndexAdviceOptions{Source: "history", Limit: 5, Validate: true}
+	mockUseCase.On("AdviseIndexes", mock.Anything, "test_db", "shop", opts).Return(&domain.IndexAdvice{
+		Source:    "history",
+		Schema:    "shop",
+		Planned:   3,
+		Skipped:   1,
+		Validated: true,
+		Proposals: []domain.IndexProposal{{
+			Table:           "orders",
+			Columns:         []string{"status"},
+			Statement:       "CREATE INDEX CONCURRENTLY idx_orders_status ON shop.orders (status);",
+			Rationale:       "Full scans of orders read about 100000 rows to keep 50 on (status = 'open'::text)",
+			Fingerprints:    []string{"a1", "b2"},
+			Calls:           40,
+			EstimatedSaving: 812.5,
+			CostBefore:      2041,
+			CostAfter:       12.3,
+		}},
+		Duplicates: []domain.IndexFinding{{
+			Table:     "orders",
+			Index:     "idx_orders_id",
+			Reason:    "same columns as orders_pkey",
+			Statement: "DROP INDEX CONCURRENTLY shop.idx_orders_id;",
+		}},
+		Notes: []string{"the hypopg extension is not installed"},
+	}, nil)
+
+	result, err := NewIndexAdviceTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"schema": "shop", "source": "history", "limit": float64(5), "validate": true},
+	}, "test_db", mockUseCase)
+	require.NoError(t, err)
+
+	resp := result.(map[string]interface{})
+	text := resp["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Contains(t, text, "Index advice for schema shop from history: 3 queries planned, 1 skipped\n")
+	assert.Contains(t, text, "1. CREATE INDEX CONCURRENTLY idx_orders_status ON shop.orders (status);\n")
+	assert.Contains(t, text, "Estimated saving: 812.50 ms over 40 calls of 2 queries; plan cost 2041.00 -> 12.30\n")
+	assert.Contains(t, text, "Unused indexes:\n  (none)\n")
+	assert.Contains(t, text, "- idx_orders_id on orders: same columns as orders_pkey\n  DROP INDEX CONCURRENTLY shop.idx_orders_id;\n")
+	assert.Contains(t, text, "Notes:\n- the hypopg extension is not installed\n")
+	metadata := resp["metadata"].(map[string]interface{})
+	assert.Equal(t, 1, metadata["proposals"])
+	assert.Equal(t, 1, metadata["duplicates"])
+	mockUseCase.AssertExpectations(t)
+}
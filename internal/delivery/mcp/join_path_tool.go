@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/tools"
+)
+
+// JoinPathTool finds how to join tables through their foreign keys
+type JoinPathTool struct {
+	BaseToolType
+}
+
+// NewJoinPathTool creates a new join path tool type
+func NewJoinPathTool() *JoinPathTool {
+	return &JoinPathTool{
+		BaseToolType: BaseToolType{
+			name:        "join_path",
+			description: "Find the shortest foreign key join path between tables",
+		},
+	}
+}
+
+// CreateTool creates a join path tool
+func (t *JoinPathTool) CreateTool(name string, dbID string) interface{} {
+	return tools.NewTool(
+		name,
+		tools.WithDescription(t.GetDescription(dbID)+". Returns the join chain with ON clauses, including intermediate tables"),
+		tools.WithArray("tables",
+			tools.Description("Two or more table names, optionally qualified as schema.table; the path starts at the first"),
+			tools.Items(map[string]interface{}{"type": "string"}),
+			tools.Required(),
+		),
+		tools.WithString("schema",
+			tools.Description("Schema (PostgreSQL) or database (MySQL) whose foreign keys are followed. Defaults to the connection's default_schema"),
+		),
+		tools.WithBoolean("query",
+			tools.Description("Also return a SELECT skeleton joining the tables"),
+		),
+	)
+}
+
+// HandleRequest handles join path tool requests
+func (t *JoinPathTool) HandleRequest(ctx context.Context, request server.ToolCallRequest, dbID string, useCase UseCaseProvider) (interface{}, error) {
+	if dbID == "" {
+		dbID = extractDatabaseIDFromName(request.Name)
+	}
+
+	var tables []string
+	switch value := request.Parameters["tables"].(type) {
+	case []interface{}:
+		for _, item := range value {
+			name, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("tables parameter must be an array of strings")
+			}
+			tables = append(tables, name)
+		}
+	case string:
+		// Tolerate clients that send a comma-separated list
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				tables = append(tables, name)
+			}
+		}
+	}
+	if len(tables) < 2 {
+		return nil, fmt.Errorf("tables parameter must name at least two tables")
+	}
+
+	path, err := useCase.GetJoinPath(ctx, dbID, getStringParam(request.Parameters, "schema"), tables, getBoolParam(request.Parameters, "query"))
+	if err != nil {
+		return nil, err
+	}
+
+	var output strings.Builder
+	fmt.Fprintf(&output, "Join path for %s", strings.Join(tables, ", "))
+	if len(path.Via) > 0 {
+		fmt.Fprintf(&output, " (via %s)", strings.Join(path.Via, ", "))
+	}
+	fmt.Fprintf(&output, ":\n\nFROM %s\n", path.From)
+	for _, step := range path.Joins {
+		fmt.Fprintf(&output, "JOIN %s ON %s  -- %s\n", step.Table, step.On, step.Constraint)
+	}
+	if path.Query != "" {
+		fmt.Fprintf(&output, "\nQuery:\n%s\n", path.Query)
+	}
+
+	resp := createTextResponse(output.String())
+	addMetadata(resp, "joins", len(path.Joins))
+	return resp, nil
+}
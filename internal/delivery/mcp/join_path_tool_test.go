@@ -0,0 +1,45 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+func TestJoinPathToolHandleRequest(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetJoinPath", mock.Anything, "test_db", "", []string{"customers", "products"}, true).Return(&domain.JoinPath{
+		Schema: "public",
+		From:   "customers",
+		Via:    []string{"orders"},
+		Joins: []domain.JoinStep{
+			{Table: "orders", To: "customers", Constraint: "orders_customer_id_fkey", On: "orders.customer_id = customers.id"},
+			{Table: "products", To: "orders", Constraint: "orders_product_id_fkey", On: "orders.product_id = products.id"},
+		},
+		Query: "SELECT * FROM public.customers INNER JOIN public.orders ON orders.customer_id = customers.id LIMIT 100",
+	}, nil)
+
+	result, err := NewJoinPathTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"tables": []interface{}{"customers", "products"}, "query": true},
+	}, "test_db", mockUseCase)
+	require.NoError(t, err)
+
+	resp := result.(map[string]interface{})
+	text := resp["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Contains(t, text, "Join path for customers, products (via orders):")
+	assert.Contains(t, text, "FROM customers\nJOIN orders ON orders.customer_id = customers.id  -- orders_customer_id_fkey\n")
+	assert.Contains(t, text, "Query:\nSELECT * FROM public.customers")
+	assert.Equal(t, 2, resp["metadata"].(map[string]interface{})["joins"])
+	mockUseCase.AssertExpectations(t)
+
+	_, err = NewJoinPathTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"tables": []interface{}{"customers"}},
+	}, "test_db", mockUseCase)
+	assert.ErrorContains(t, err, "at least two tables")
+}
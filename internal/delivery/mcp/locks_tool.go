@@ -0,0 +1,201 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/tools"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+)
+
+// LocksTool lists the blocking chains of a database and, when the session
+// control policy allows it, cancels or terminates blocking sessions
+type LocksTool struct {
+	BaseToolType
+}
+
+// NewLocksTool creates a new locks tool type
+func NewLocksTool() *LocksTool {
+	return &LocksTool{
+		BaseToolType: BaseToolType{
+			name:        "locks",
+			description: "List sessions waiting for locks and the sessions blocking them",
+		},
+	}
+}
+
+// CreateTool creates a locks tool
+func (t *LocksTool) CreateTool(name string, dbID string) interface{} {
+	return tools.NewTool(
+		name,
+		tools.WithDescription(t.GetDescription(dbID)+". Shows each blocking chain with the waiting and blocking queries and how long they have waited. Cancelling or terminating a blocking session requires security.session_control"),
+		tools.WithString("action",
+			tools.Description("list (default), cancel to cancel the blocking session's query, or terminate to close the blocking session and roll back its transaction"),
+		),
+		tools.WithNumber("pid",
+			tools.Description("Blocking session to cancel or terminate: a PostgreSQL backend pid or a MySQL processlist id"),
+		),
+	)
+}
+
+// HandleRequest handles locks tool requests
+func (t *LocksTool) HandleRequest(ctx context.Context, request server.ToolCallRequest, dbID string, useCase UseCaseProvider) (interface{}, error) {
+	if dbID == "" {
+		dbID = extractDatabaseIDFromName(request.Name)
+	}
+
+	action := getStringParam(request.Parameters, "action")
+	switch action {
+	case "", "list":
+		waits, err := useCase.GetLockWaits(ctx, dbID)
+		if err != nil {
+			return nil, err
+		}
+		resp := createTextResponse(formatLockWaits(dbID, waits))
+		addMetadata(resp, "waiting", countWaiting(waits))
+		addMetadata(resp, "waits", len(waits))
+		return resp, nil
+
+	case db.SessionCancel, db.SessionTerminate:
+		value, ok := request.Parameters["pid"].(float64)
+		if !ok || value <= 0 {
+			return nil, fmt.Errorf("pid parameter is required to %s a session", action)
+		}
+		pid := int64(value)
+		if err := useCase.SignalBlockingSession(ctx, dbID, pid, action); err != nil {
+			return nil, err
+		}
+		verb := "Cancelled the query of"
+		if action == db.SessionTerminate {
+			verb = "Terminated"
+		}
+		resp := createTextResponse(fmt.Sprintf("%s blocking session %d on %s", verb, pid, dbID))
+		addMetadata(resp, "action", action)
+		addMetadata(resp, "pid", pid)
+		return resp, nil
+
+	default:
+		return nil, fmt.Errorf("invalid action: %s", action)
+	}
+}
+
+// countWaiting counts the distinct waiting sessions of lock waits
+func countWaiting(waits []domain.LockWait) int {
+	waiting := make(map[int64]bool)
+	for _, wait := range waits {
+		waiting[wait.WaitingPID] = true
+	}
+	return len(waiting)
+}
+
+// formatLockWaits renders lock waits as trees rooted at the sessions that
+// block others without waiting themselves
+func formatLockWaits(dbID string, waits []domain.LockWait) string {
+	if len(waits) == 0 {
+		return fmt.Sprintf("No sessions are waiting for locks on %s", dbID)
+	}
+
+	blocked := make(map[int64][]domain.LockWait)
+	blockers := make(map[int64]domain.LockWait)
+	waiting := make(map[int64]bool)
+	for _, wait := range waits {
+		blocked[wait.BlockingPID] = append(blocked[wait.BlockingPID], wait)
+		waiting[wait.WaitingPID] = true
+		if _, ok := blockers[wait.BlockingPID]; !ok {
+			blockers[wait.BlockingPID] = wait
+		}
+	}
+
+	// Roots block others without waiting; sessions waiting on each other
+	// in a cycle have no root and are listed from their lowest pid
+	var roots []int64
+	for pid := range blockers {
+		if !waiting[pid] {
+			roots = append(roots, pid)
+		}
+	}
+	sort.Slice(roots, func(i, j int) bool { return roots[i] < roots[j] })
+
+	var output strings.Builder
+	fmt.Fprintf(&output, "Blocking chains on %s: %d sessions waiting\n", dbID, countWaiting(waits))
+	shown := make(map[int64]bool)
+	var writeBlocked func(pid int64, depth int)
+	writeBlocked = func(pid int64, depth int) {
+		for _, wait := range blocked[pid] {
+			indent := strings.Repeat("  ", depth)
+			fmt.Fprintf(&output, "%s└ pid %d%s waiting %s", indent, wait.WaitingPID, userSuffix(wait.WaitingUser), formatMilliseconds(wait.WaitDuration))
+			if wait.LockMode != "" {
+				fmt.Fprintf(&output, " for %s", wait.LockMode)
+			}
+			if wait.Object != "" {
+				fmt.Fprintf(&output, " on %s", wait.Object)
+			}
+			fmt.Fprintf(&output, ": %s\n", oneLine(wait.WaitingQuery))
+			if shown[wait.WaitingPID] {
+				fmt.Fprintf(&output, "%s  (cycle: pid %d is listed above)\n", indent, wait.WaitingPID)
+				continue
+			}
+			shown[wait.WaitingPID] = true
+			writeBlocked(wait.WaitingPID, depth+1)
+		}
+	}
+	writeRoot := func(pid int64) {
+		blocker := blockers[pid]
+		shown[pid] = true
+		fmt.Fprintf(&output, "\nBlocking pid %d%s", pid, userSuffix(blocker.BlockingUser))
+		if blocker.BlockingState != "" {
+			fmt.Fprintf(&output, ", %s", blocker.BlockingState)
+		}
+		if blocker.BlockingDuration > 0 {
+			fmt.Fprintf(&output, ", transaction open %s", formatMilliseconds(blocker.BlockingDuration))
+		}
+		fmt.Fprintf(&output, ": %s\n", oneLine(blocker.BlockingQuery))
+		writeBlocked(pid, 1)
+	}
+
+	for _, pid := range roots {
+		writeRoot(pid)
+	}
+	var rest []int64
+	for pid := range blockers {
+		if !shown[pid] {
+			rest = append(rest, pid)
+		}
+	}
+	sort.Slice(rest, func(i, j int) bool { return rest[i] < rest[j] })
+	for _, pid := range rest {
+		if !shown[pid] {
+			writeRoot(pid)
+		}
+	}
+	return output.String()
+}
+
+// userSuffix renders the user of a session, if known
+func userSuffix(user string) string {
+	if user == "" {
+		return ""
+	}
+	return " (" + user + ")"
+}
+
+// formatMilliseconds renders a duration in milliseconds
+func formatMilliseconds(ms float64) string {
+	if ms >= 1000 {
+		return fmt.Sprintf("%.1f s", ms/1000)
+	}
+	return fmt.Sprintf("%.0f ms", ms)
+}
+
+// oneLine collapses the whitespace of a query
+func oneLine(query string) string {
+	if query == "" {
+		return "(unknown query)"
+	}
+	return strings.Join(strings.Fields(query), " ")
+}
@@ -0,0 +1,73 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+func TestLocksToolHandleRequest(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetLockWaits", mock.Anything, "test_db").Return([]domain.LockWait{
+		{WaitingPID: 101, WaitingUser: "app", WaitingQuery: "UPDATE orders\n  SET status = 'paid' WHERE id = 7", WaitDuration: 5100,
+			LockMode: "ShareLock", Object: "orders", BlockingPID: 100, BlockingUser: "admin",
+			BlockingQuery: "UPDATE orders SET total = 0", BlockingState: "idle in transaction", BlockingDuration: 62000},
+		{WaitingPID: 102, WaitingQuery: "SELECT * FROM orders FOR UPDATE", WaitDuration: 800, BlockingPID: 101},
+	}, nil)
+
+	result, err := NewLocksTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{},
+	}, "test_db", mockUseCase)
+	require.NoError(t, err)
+
+	resp := result.(map[string]interface{})
+	text := resp["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Equal(t, "Blocking chains on test_db: 2 sessions waiting\n"+
+		"\nBlocking pid 100 (admin), idle in transaction, transaction open 62.0 s: UPDATE orders SET total = 0\n"+
+		"  └ pid 101 (app) waiting 5.1 s for ShareLock on orders: UPDATE orders SET status = 'paid' WHERE id = 7\n"+
+		"    └ pid 102 waiting 800 ms: SELECT * FROM orders FOR UPDATE\n", text)
+	assert.Equal(t, 2, resp["metadata"].(map[string]interface{})["waiting"])
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestLocksToolSignal(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("SignalBlockingSession", mock.Anything, "test_db", int64(100), "terminate").Return(nil)
+	mockUseCase.On("SignalBlockingSession", mock.Anything, "test_db", int64(200), "cancel").
+		Return(fmt.Errorf("sending cancel to sessions is not allowed"))
+
+	result, err := NewLocksTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"action": "terminate", "pid": float64(100)},
+	}, "test_db", mockUseCase)
+	require.NoError(t, err)
+	text := result.(map[string]interface{})["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Equal(t, "Terminated blocking session 100 on test_db", text)
+
+	_, err = NewLocksTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"action": "cancel", "pid": float64(200)},
+	}, "test_db", mockUseCase)
+	assert.ErrorContains(t, err, "not allowed")
+
+	_, err = NewLocksTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"action": "cancel"},
+	}, "test_db", mockUseCase)
+	assert.ErrorContains(t, err, "pid parameter is required")
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestFormatLockWaitsCycle(t *testing.T) {
+	text := formatLockWaits("test_db", []domain.LockWait{
+		{WaitingPID: 1, WaitingQuery: "UPDATE a", BlockingPID: 2, BlockingQuery: "UPDATE b"},
+		{WaitingPID: 2, WaitingQuery: "UPDATE b", BlockingPID: 1, BlockingQuery: "UPDATE a"},
+	})
+	assert.Contains(t, text, "\nBlocking pid 1: UPDATE a\n  └ pid 2 waiting 0 ms: UPDATE b\n    └ pid 1 waiting 0 ms: UPDATE a\n")
+	assert.Contains(t, text, "(cycle: pid 1 is listed above)")
+	assert.Equal(t, "No sessions are waiting for locks on test_db", formatLockWaits("test_db", nil))
+}
@@ -0,0 +1,227 @@
+// Package mcptest provides the mocks shared by the tests of the MCP delivery
+// packages.
+package mcptest
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+// MockDatabaseUseCase is a mock implementation of the database use case the
+// MCP tools run on
+type MockDatabaseUseCase struct {
+	mock.Mock
+}
+
+// ExecuteStatement mocks the ExecuteStatement method
+func (m *MockDatabaseUseCase) ExecuteStatement(ctx context.Context, dbID, statement string, params []interface{}) (string, error) {
+	args := m.Called(ctx, dbID, statement, params)
+	return args.String(0), args.Error(1)
+}
+
+// GetDatabaseType mocks the GetDatabaseType method
+func (m *MockDatabaseUseCase) GetDatabaseType(dbID string) (string, error) {
+	args := m.Called(dbID)
+	return args.String(0), args.Error(1)
+}
+
+// ExecuteQuery mocks the ExecuteQuery method
+func (m *MockDatabaseUseCase) ExecuteQuery(ctx context.Context, dbID, query string, params []interface{}) (string, error) {
+	args := m.Called(ctx, dbID, query, params)
+	return args.String(0), args.Error(1)
+}
+
+// ExecuteTransaction mocks the ExecuteTransaction method
+func (m *MockDatabaseUseCase) ExecuteTransaction(ctx context.Context, dbID, action string, txID string, statement string, params []interface{}, readOnly bool) (string, map[string]interface{}, error) {
+	args := m.Called(ctx, dbID, action, txID, statement, params, readOnly)
+	return args.String(0), args.Get(1).(map[string]interface{}), args.Error(2)
+}
+
+// GetSchemaComponent mocks the GetSchemaComponent method
+func (m *MockDatabaseUseCase) GetSchemaComponent(ctx context.Context, dbID, component, schema, table string) (map[string]interface{}, error) {
+	args := m.Called(ctx, dbID, component, schema, table)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
+
+// GetSchemaSummary mocks the GetSchemaSummary method
+func (m *MockDatabaseUseCase) GetSchemaSummary(ctx context.Context, dbID, schema, question string, maxChars int) (string, error) {
+	args := m.Called(ctx, dbID, schema, question, maxChars)
+	return args.String(0), args.Error(1)
+}
+
+// GetSchemaERD mocks the GetSchemaERD method
+func (m *MockDatabaseUseCase) GetSchemaERD(ctx context.Context, dbID, schema, table, format string, depth int) (string, error) {
+	args := m.Called(ctx, dbID, schema, table, format, depth)
+	return args.String(0), args.Error(1)
+}
+
+// GetSchemaSnapshot mocks the GetSchemaSnapshot method
+func (m *MockDatabaseUseCase) GetSchemaSnapshot(ctx context.Context, dbID, schema string) (*domain.SchemaSnapshot, error) {
+	args := m.Called(ctx, dbID, schema)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SchemaSnapshot), args.Error(1)
+}
+
+// GetJoinPath mocks the GetJoinPath method
+func (m *MockDatabaseUseCase) GetJoinPath(ctx context.Context, dbID, schema string, tables []string, withQuery bool) (*domain.JoinPath, error) {
+	args := m.Called(ctx, dbID, schema, tables, withQuery)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.JoinPath), args.Error(1)
+}
+
+// GetPerformanceAnalyzer mocks the GetPerformanceAnalyzer method
+func (m *MockDatabaseUseCase) GetPerformanceAnalyzer(dbID string) (domain.PerformanceAnalyzer, error) {
+	args := m.Called(dbID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(domain.PerformanceAnalyzer), args.Error(1)
+}
+
+// GetWorkloadStatistics mocks the GetWorkloadStatistics method
+func (m *MockDatabaseUseCase) GetWorkloadStatistics(ctx context.Context, dbID, orderBy string, limit int) (*domain.WorkloadStatistics, error) {
+	args := m.Called(ctx, dbID, orderBy, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.WorkloadStatistics), args.Error(1)
+}
+
+// GetLockWaits mocks the GetLockWaits method
+func (m *MockDatabaseUseCase) GetLockWaits(ctx context.Context, dbID string) ([]domain.LockWait, error) {
+	args := m.Called(ctx, dbID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.LockWait), args.Error(1)
+}
+
+// SignalBlockingSession mocks the SignalBlockingSession method
+func (m *MockDatabaseUseCase) SignalBlockingSession(ctx context.Context, dbID string, pid int64, signal string) error {
+	args := m.Called(ctx, dbID, pid, signal)
+	return args.Error(0)
+}
+
+// GetActivity mocks the GetActivity method
+func (m *MockDatabaseUseCase) GetActivity(ctx context.Context, dbID string, filter domain.ActivityFilter) ([]domain.SessionActivity, error) {
+	args := m.Called(ctx, dbID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]domain.SessionActivity), args.Error(1)
+}
+
+// CancelQuery mocks the CancelQuery method
+func (m *MockDatabaseUseCase) CancelQuery(ctx context.Context, dbID string, pid int64) error {
+	args := m.Called(ctx, dbID, pid)
+	return args.Error(0)
+}
+
+// AdviseIndexes mocks the AdviseIndexes method
+func (m *MockDatabaseUseCase) AdviseIndexes(ctx context.Context, dbID, schema string, opts domain.IndexAdviceOptions) (*domain.IndexAdvice, error) {
+	args := m.Called(ctx, dbID, schema, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.IndexAdvice), args.Error(1)
+}
+
+// GetStorageStats mocks the GetStorageStats method
+func (m *MockDatabaseUseCase) GetStorageStats(ctx context.Context, dbID, schema string, opts domain.StorageOptions) (*domain.StorageReport, error) {
+	args := m.Called(ctx, dbID, schema, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.StorageReport), args.Error(1)
+}
+
+// AnalyzeQuery mocks the AnalyzeQuery method
+func (m *MockDatabaseUseCase) AnalyzeQuery(ctx context.Context, dbID, query string) (*domain.QueryAnalysis, error) {
+	args := m.Called(ctx, dbID, query)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.QueryAnalysis), args.Error(1)
+}
+
+// MigrationStatus mocks the MigrationStatus method
+func (m *MockDatabaseUseCase) MigrationStatus(ctx context.Context, dbID string) (*domain.MigrationStatus, error) {
+	args := m.Called(ctx, dbID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MigrationStatus), args.Error(1)
+}
+
+// PlanMigrations mocks the PlanMigrations method
+func (m *MockDatabaseUseCase) PlanMigrations(ctx context.Context, dbID, direction string, steps int) (*domain.MigrationPlan, error) {
+	args := m.Called(ctx, dbID, direction, steps)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MigrationPlan), args.Error(1)
+}
+
+// Migrate mocks the Migrate method
+func (m *MockDatabaseUseCase) Migrate(ctx context.Context, dbID, direction string, steps int) (*domain.MigrationPlan, error) {
+	args := m.Called(ctx, dbID, direction, steps)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MigrationPlan), args.Error(1)
+}
+
+// InvalidateSchemaCache mocks the InvalidateSchemaCache method
+func (m *MockDatabaseUseCase) InvalidateSchemaCache(dbID string) {
+	m.Called(dbID)
+}
+
+// ResolveSchema mocks the ResolveSchema method. Without an expectation the
+// requested schema is returned unchanged.
+func (m *MockDatabaseUseCase) ResolveSchema(dbID, schema string) (string, error) {
+	for _, call := range m.ExpectedCalls {
+		if call.Method == "ResolveSchema" {
+			args := m.Called(dbID, schema)
+			return args.String(0), args.Error(1)
+		}
+	}
+	return schema, nil
+}
+
+// GetDatabaseInfo mocks the GetDatabaseInfo method
+func (m *MockDatabaseUseCase) GetDatabaseInfo(ctx context.Context, dbID string) (map[string]interface{}, error) {
+	args := m.Called(ctx, dbID)
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
+
+// GetQueryTimeout mocks the GetQueryTimeout method
+func (m *MockDatabaseUseCase) GetQueryTimeout(dbID string) (time.Duration, error) {
+	args := m.Called(dbID)
+	return args.Get(0).(time.Duration), args.Error(1)
+}
+
+// WithStatementTimeout mocks the WithStatementTimeout method
+func (m *MockDatabaseUseCase) WithStatementTimeout(ctx context.Context, dbID string, timeout time.Duration) (context.Context, error) {
+	args := m.Called(ctx, dbID, timeout)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(context.Context), args.Error(1)
+}
+
+// ListDatabases mocks the ListDatabases method
+func (m *MockDatabaseUseCase) ListDatabases() []string {
+	args := m.Called()
+	return args.Get(0).([]string)
+}
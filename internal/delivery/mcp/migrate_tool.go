@@ -0,0 +1,141 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/tools"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+// migrateToolDescriptions describe the migrate tool of each action
+var migrateToolDescriptions = map[string]string{
+	"status": "Show the applied migration version of a database and its pending migrations",
+	"plan":   "Show the migrations migrate_up or migrate_down would run, with their SQL, without running them",
+	"up":     "Apply pending migrations from the database's migrations_dir",
+	"down":   "Revert applied migrations using their down files",
+}
+
+// MigrateTool runs one action of the versioned SQL migrations of a database
+type MigrateTool struct {
+	BaseToolType
+	action string
+}
+
+// NewMigrateTool creates a migrate tool type for status, plan, up or down
+func NewMigrateTool(action string) *MigrateTool {
+	return &MigrateTool{
+		BaseToolType: BaseToolType{
+			name:        "migrate_" + action,
+			description: migrateToolDescriptions[action],
+		},
+		action: action,
+	}
+}
+
+// CreateTool creates a migrate tool
+func (t *MigrateTool) CreateTool(name string, dbID string) interface{} {
+	options := []tools.ToolOption{
+		tools.WithDescription(t.description + ". Migrations are numbered <version>_<name>.up.sql and .down.sql files; the applied version is kept in the schema_migrations table"),
+		tools.WithString("database",
+			tools.Description("Database ID whose migrations are used"),
+			tools.Required(),
+		),
+	}
+	if t.action == "plan" {
+		options = append(options, tools.WithString("direction",
+			tools.Description("Direction to plan: up (default) or down"),
+		))
+	}
+	if t.action != "status" {
+		options = append(options, tools.WithNumber("steps",
+			tools.Description("Number of migrations to run. Up defaults to all pending migrations, down to one"),
+		))
+	}
+	return tools.NewTool(name, options...)
+}
+
+// HandleRequest handles migrate tool requests
+func (t *MigrateTool) HandleRequest(ctx context.Context, request server.ToolCallRequest, dbID string, useCase UseCaseProvider) (interface{}, error) {
+	database := getStringParam(request.Parameters, "database")
+	if database == "" {
+		return nil, fmt.Errorf("database parameter is required")
+	}
+
+	if t.action == "status" {
+		var status *domain.MigrationStatus
+		err := withQueryTimeout(ctx, useCase, database, request.Name, func(ctx context.Context) error {
+			var err error
+			status, err = useCase.MigrationStatus(ctx, database)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		summary := fmt.Sprintf("%s is at migration version %d with %d pending", database, status.Version, len(status.Pending))
+		if status.Dirty {
+			summary += " and is DIRTY: the last migration failed half-way"
+		}
+		resp, err := migrateResponse(summary, status)
+		if err != nil {
+			return nil, err
+		}
+		addMetadata(resp, "version", status.Version)
+		addMetadata(resp, "dirty", status.Dirty)
+		return resp, nil
+	}
+
+	steps := 0
+	if value, ok := request.Parameters["steps"].(float64); ok {
+		steps = int(value)
+	}
+	direction := t.action
+	if t.action == "plan" {
+		direction = getStringParam(request.Parameters, "direction")
+		if direction == "" {
+			direction = "up"
+		}
+	}
+
+	if t.action == "plan" {
+		var plan *domain.MigrationPlan
+		err := withQueryTimeout(ctx, useCase, database, request.Name, func(ctx context.Context) error {
+			var err error
+			plan, err = useCase.PlanMigrations(ctx, database, direction, steps)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		summary := fmt.Sprintf("Migrating %s %s would run %d migrations, from version %d to %d",
+			database, direction, len(plan.Steps), plan.FromVersion, plan.ToVersion)
+		return migrateResponse(summary, plan)
+	}
+
+	// A run is not cut off at the query timeout: stopping between statements
+	// would leave the schema half-migrated and the version dirty
+	plan, err := useCase.Migrate(ctx, database, direction, steps)
+	if err != nil {
+		return nil, err
+	}
+	summary := fmt.Sprintf("Migrated %s %s: ran %d migrations, now at version %d",
+		database, direction, len(plan.Steps), plan.ToVersion)
+	resp, err := migrateResponse(summary, plan)
+	if err != nil {
+		return nil, err
+	}
+	addMetadata(resp, "version", plan.ToVersion)
+	return resp, nil
+}
+
+// migrateResponse formats a summary line followed by the result as JSON
+func migrateResponse(summary string, result interface{}) (map[string]interface{}, error) {
+	data, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format migration result: %w", err)
+	}
+	return createTextResponse(summary + "\n\n" + string(data)), nil
+}
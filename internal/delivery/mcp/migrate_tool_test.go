@@ -0,0 +1,104 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+func TestMigrateToolStatus(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetQueryTimeout", "main").Return(30*time.Second, nil)
+	mockUseCase.On("MigrationStatus", mock.Anything, "main").Return(&domain.MigrationStatus{
+		Database: "main",
+		Version:  2,
+		Dirty:    true,
+		Pending:  []domain.Migration{{Version: 3, Name: "backfill"}},
+	}, nil)
+
+	result, err := NewMigrateTool("status").HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"database": "main"},
+	}, "", mockUseCase)
+	require.NoError(t, err)
+
+	resp := result.(map[string]interface{})
+	text := resp["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Contains(t, text, "main is at migration version 2 with 1 pending and is DIRTY")
+	assert.Contains(t, text, `"name": "backfill"`)
+	assert.Equal(t, true, resp["metadata"].(map[string]interface{})["dirty"])
+}
+
+func TestMigrateToolPlanAndRun(t *testing.T) {
+	plan := &domain.MigrationPlan{
+		Database: "main", Direction: "down", FromVersion: 2, ToVersion: 1,
+		Steps: []domain.MigrationStep{{Version: 2, Name: "add_email", File: "000002_add_email.down.sql", SQL: "ALTER TABLE users DROP COLUMN email;"}},
+	}
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetQueryTimeout", "main").Return(30*time.Second, nil)
+	mockUseCase.On("PlanMigrations", mock.Anything, "main", "down", 1).Return(plan, nil)
+	mockUseCase.On("Migrate", mock.Anything, "main", "up", 0).Return(&domain.MigrationPlan{
+		Database: "main", Direction: "up", FromVersion: 1, ToVersion: 1, Steps: []domain.MigrationStep{},
+	}, nil)
+
+	result, err := NewMigrateTool("plan").HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"database": "main", "direction": "down", "steps": float64(1)},
+	}, "", mockUseCase)
+	require.NoError(t, err)
+	text := result.(map[string]interface{})["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Contains(t, text, "Migrating main down would run 1 migrations, from version 2 to 1")
+	assert.Contains(t, text, "DROP COLUMN email")
+
+	result, err = NewMigrateTool("up").HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"database": "main"},
+	}, "", mockUseCase)
+	require.NoError(t, err)
+	text = result.(map[string]interface{})["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Contains(t, text, "Migrated main up: ran 0 migrations, now at version 1")
+	mockUseCase.AssertExpectations(t)
+
+	_, err = NewMigrateTool("down").HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{},
+	}, "", mockUseCase)
+	assert.ErrorContains(t, err, "database parameter is required")
+}
+
+func TestMigrateToolQueryTimeout(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetQueryTimeout", "main").Return(10*time.Millisecond, nil)
+	mockUseCase.On("PlanMigrations", mock.Anything, "main", "up", 0).
+		Run(func(args mock.Arguments) {
+			<-args.Get(0).(context.Context).Done()
+		}).
+		Return(nil, errors.New("canceling statement due to user request"))
+
+	_, err := NewMigrateTool("plan").HandleRequest(context.Background(), server.ToolCallRequest{
+		Name:       "migrate_plan",
+		Parameters: map[string]interface{}{"database": "main"},
+	}, "", mockUseCase)
+	assert.ErrorContains(t, err, "migrate_plan timed out after")
+	assert.ErrorContains(t, err, "canceling statement")
+
+	// Running migrations outlasts the query timeout
+	mockUseCase.On("Migrate", mock.Anything, "main", "up", 0).
+		Run(func(args mock.Arguments) {
+			ctx := args.Get(0).(context.Context)
+			_, hasDeadline := ctx.Deadline()
+			assert.False(t, hasDeadline)
+			time.Sleep(20 * time.Millisecond)
+		}).
+		Return(&domain.MigrationPlan{Database: "main", Direction: "up", FromVersion: 1, ToVersion: 2}, nil)
+
+	_, err = NewMigrateTool("up").HandleRequest(context.Background(), server.ToolCallRequest{
+		Name:       "migrate_up",
+		Parameters: map[string]interface{}{"database": "main"},
+	}, "", mockUseCase)
+	assert.NoError(t, err)
+}
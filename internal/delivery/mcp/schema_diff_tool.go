@@ -0,0 +1,160 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/tools"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+// SchemaDiffTool compares the schemas of two databases, or of a database and
+// a saved snapshot
+type SchemaDiffTool struct {
+	BaseToolType
+}
+
+// NewSchemaDiffTool creates a new schema diff tool type
+func NewSchemaDiffTool() *SchemaDiffTool {
+	return &SchemaDiffTool{
+		BaseToolType: BaseToolType{
+			name:        "schema_diff",
+			description: "Compare the schemas of two databases, or a database against a saved snapshot",
+		},
+	}
+}
+
+// CreateTool creates a schema diff tool
+func (t *SchemaDiffTool) CreateTool(name string, dbID string) interface{} {
+	return tools.NewTool(
+		name,
+		tools.WithDescription(t.description+". Reports added, removed and changed tables, columns, types, indexes and constraints"),
+		tools.WithString("target",
+			tools.Description("Database ID whose schema is compared (the new side)"),
+			tools.Required(),
+		),
+		tools.WithString("source",
+			tools.Description("Database ID to compare against (the old side), e.g. prod when target is staging"),
+		),
+		tools.WithString("snapshot",
+			tools.Description("Path of a snapshot file to compare against instead of a source database"),
+		),
+		tools.WithString("schema",
+			tools.Description("Schema to compare on both sides. Defaults to each connection's default_schema"),
+		),
+		tools.WithString("save_snapshot",
+			tools.Description("Path to save the target's schema snapshot to, for later comparisons"),
+		),
+	)
+}
+
+// HandleRequest handles schema diff tool requests
+func (t *SchemaDiffTool) HandleRequest(ctx context.Context, request server.ToolCallRequest, dbID string, useCase UseCaseProvider) (interface{}, error) {
+	target := getStringParam(request.Parameters, "target")
+	source := getStringParam(request.Parameters, "source")
+	snapshotPath := getStringParam(request.Parameters, "snapshot")
+	schema := getStringParam(request.Parameters, "schema")
+	savePath := getStringParam(request.Parameters, "save_snapshot")
+
+	if target == "" {
+		return nil, fmt.Errorf("target parameter is required")
+	}
+	if source != "" && snapshotPath != "" {
+		return nil, fmt.Errorf("source and snapshot parameters are mutually exclusive")
+	}
+	if source == "" && snapshotPath == "" && savePath == "" {
+		return nil, fmt.Errorf("one of source, snapshot or save_snapshot is required")
+	}
+
+	var targetSnapshot *domain.SchemaSnapshot
+	err := withQueryTimeout(ctx, useCase, target, request.Name, func(ctx context.Context) error {
+		var err error
+		targetSnapshot, err = useCase.GetSchemaSnapshot(ctx, target, schema)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var output strings.Builder
+	if savePath != "" {
+		if err := saveSchemaSnapshot(savePath, targetSnapshot); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&output, "Saved schema snapshot of %s (%d tables) to %s\n", target, len(targetSnapshot.Tables), savePath)
+	}
+
+	var sourceSnapshot *domain.SchemaSnapshot
+	switch {
+	case snapshotPath != "":
+		sourceSnapshot, err = loadSchemaSnapshot(snapshotPath)
+	case source != "":
+		err = withQueryTimeout(ctx, useCase, source, request.Name, func(ctx context.Context) error {
+			var err error
+			sourceSnapshot, err = useCase.GetSchemaSnapshot(ctx, source, schema)
+			return err
+		})
+	default:
+		return createTextResponse(output.String()), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	diff := domain.DiffSchemaSnapshots(sourceSnapshot, targetSnapshot)
+	if snapshotPath != "" {
+		diff.Source = "snapshot:" + snapshotPath
+	}
+
+	data, err := json.MarshalIndent(diff, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format schema diff: %w", err)
+	}
+
+	fmt.Fprintf(&output, "Schema diff %s -> %s: %s\n\n%s", diff.Source, diff.Target, describeSchemaDiff(diff), data)
+	resp := createTextResponse(output.String())
+	addMetadata(resp, "identical", diff.Empty())
+	return resp, nil
+}
+
+// describeSchemaDiff summarises a diff in one line
+func describeSchemaDiff(diff *domain.SchemaDiff) string {
+	if diff.Empty() {
+		return "no differences"
+	}
+	return fmt.Sprintf("%d tables added, %d removed, %d changed",
+		len(diff.AddedTables), len(diff.RemovedTables), len(diff.ChangedTables))
+}
+
+// loadSchemaSnapshot reads a snapshot saved by saveSchemaSnapshot
+func loadSchemaSnapshot(path string) (*domain.SchemaSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema snapshot: %w", err)
+	}
+	var snapshot domain.SchemaSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("failed to parse schema snapshot %s: %w", path, err)
+	}
+	if snapshot.Tables == nil {
+		return nil, fmt.Errorf("schema snapshot %s has no tables section", path)
+	}
+	return &snapshot, nil
+}
+
+// saveSchemaSnapshot writes a snapshot as indented JSON
+func saveSchemaSnapshot(path string, snapshot *domain.SchemaSnapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schema snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write schema snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package mcp
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+func diffSnapshot(database string, columns ...domain.ColumnInfo) *domain.SchemaSnapshot {
+	return &domain.SchemaSnapshot{
+		Database: database,
+		DBType:   "postgres",
+		Schema:   "public",
+		Tables:   map[string]domain.TableSnapshot{"users": {Columns: columns}},
+	}
+}
+
+func TestSchemaDiffToolComparesDatabases(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetQueryTimeout", "staging").Return(30*time.Second, nil)
+	mockUseCase.On("GetQueryTimeout", "prod").Return(30*time.Second, nil)
+	mockUseCase.On("GetSchemaSnapshot", mock.Anything, "staging", "").
+		Return(diffSnapshot("staging", domain.ColumnInfo{Name: "id", Type: "bigint"}), nil)
+	mockUseCase.On("GetSchemaSnapshot", mock.Anything, "prod", "").
+		Return(diffSnapshot("prod", domain.ColumnInfo{Name: "id", Type: "integer"}), nil)
+
+	result, err := NewSchemaDiffTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"target": "staging", "source": "prod"},
+	}, "", mockUseCase)
+	require.NoError(t, err)
+
+	resp := result.(map[string]interface{})
+	text := resp["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Contains(t, text, "Schema diff prod -> staging: 0 tables added, 0 removed, 1 changed")
+	assert.Contains(t, text, `"from": "integer"`)
+	assert.Equal(t, false, resp["metadata"].(map[string]interface{})["identical"])
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestSchemaDiffToolSnapshotFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prod.json")
+	snapshot := diffSnapshot("prod", domain.ColumnInfo{Name: "id", Type: "integer"})
+
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetQueryTimeout", "prod").Return(30*time.Second, nil)
+	mockUseCase.On("GetSchemaSnapshot", mock.Anything, "prod", "public").Return(snapshot, nil)
+
+	// Save a snapshot, then compare the database against it
+	_, err := NewSchemaDiffTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"target": "prod", "schema": "public", "save_snapshot": path},
+	}, "", mockUseCase)
+	require.NoError(t, err)
+	_, err = os.Stat(path)
+	require.NoError(t, err)
+
+	result, err := NewSchemaDiffTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"target": "prod", "schema": "public", "snapshot": path},
+	}, "", mockUseCase)
+	require.NoError(t, err)
+
+	resp := result.(map[string]interface{})
+	text := resp["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Contains(t, text, "Schema diff snapshot:"+path+" -> prod: no differences")
+	assert.Equal(t, true, resp["metadata"].(map[string]interface{})["identical"])
+}
+
+func TestSchemaDiffToolValidation(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	tool := NewSchemaDiffTool()
+
+	for _, params := range []map[string]interface{}{
+		{"source": "prod"},
+		{"target": "staging"},
+		{"target": "staging", "source": "prod", "snapshot": "prod.json"},
+	} {
+		_, err := tool.HandleRequest(context.Background(), server.ToolCallRequest{Parameters: params}, "", mockUseCase)
+		assert.Error(t, err, "params %v", params)
+	}
+
+	badPath := filepath.Join(t.TempDir(), "bad.json")
+	require.NoError(t, os.WriteFile(badPath, []byte(`{"database": "prod"}`), 0o644))
+	mockUseCase.On("GetQueryTimeout", "staging").Return(30*time.Second, nil)
+	mockUseCase.On("GetSchemaSnapshot", mock.Anything, "staging", "").Return(diffSnapshot("staging"), nil)
+	_, err := tool.HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"target": "staging", "snapshot": badPath},
+	}, "", mockUseCase)
+	assert.ErrorContains(t, err, "no tables section")
+}
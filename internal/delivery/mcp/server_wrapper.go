@@ -12,6 +12,7 @@ import (
 // ServerWrapper provides a wrapper around server.MCPServer to handle type assertions
 type ServerWrapper struct {
 	mcpServer *server.MCPServer
+	toolNames []string // names of the tools added, in order
 }
 
 // NewServerWrapper creates a new ServerWrapper
@@ -34,5 +35,14 @@ func (sw *ServerWrapper) AddTool(ctx context.Context, tool interface{}, handler
 	}
 
 	// Pass the tool to the MCPServer's AddTool method
-	return sw.mcpServer.AddTool(ctx, typedTool, handler)
+	if err := sw.mcpServer.AddTool(ctx, typedTool, handler); err != nil {
+		return err
+	}
+	sw.toolNames = append(sw.toolNames, typedTool.Name)
+	return nil
+}
+
+// ToolNames returns the names of the tools added to the server
+func (sw *ServerWrapper) ToolNames() []string {
+	return append([]string(nil), sw.toolNames...)
 }
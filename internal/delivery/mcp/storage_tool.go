@@ -0,0 +1,168 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/FreePeak/cortex/pkg/tools"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+// StorageTool reports the disk usage, dead tuples and bloat of a database's
+// tables and indexes
+type StorageTool struct {
+	BaseToolType
+}
+
+// NewStorageTool creates a new storage tool type
+func NewStorageTool() *StorageTool {
+	return &StorageTool{
+		BaseToolType: BaseToolType{
+			name:        "storage",
+			description: "Report table and index sizes, dead tuples and bloat",
+		},
+	}
+}
+
+// CreateTool creates a storage tool
+func (t *StorageTool) CreateTool(name string, dbID string) interface{} {
+	return tools.NewTool(
+		name,
+		tools.WithDescription(t.GetDescription(dbID)+". Ranks the largest or most bloated tables and indexes of a schema with row estimates, dead tuples and last vacuum and analyze times on PostgreSQL, or data, index and free space on MySQL. TimescaleDB hypertables are totalled over their chunks"),
+		tools.WithString("schema",
+			tools.Description("Schema (PostgreSQL) or database (MySQL) to report on. Defaults to the connection's default_schema"),
+		),
+		tools.WithString("order_by",
+			tools.Description("Ranking: size (default), bloat (estimated bloat, or free space on MySQL) or dead (dead tuples, PostgreSQL only)"),
+		),
+		tools.WithNumber("limit",
+			tools.Description("Number of tables, indexes and chunks per hypertable to report (default: 20)"),
+		),
+	)
+}
+
+// HandleRequest handles storage tool requests
+func (t *StorageTool) HandleRequest(ctx context.Context, request server.ToolCallRequest, dbID string, useCase UseCaseProvider) (interface{}, error) {
+	if dbID == "" {
+		dbID = extractDatabaseIDFromName(request.Name)
+	}
+
+	opts := domain.StorageOptions{OrderBy: getStringParam(request.Parameters, "order_by")}
+	if value, ok := request.Parameters["limit"].(float64); ok && value > 0 {
+		opts.Limit = int(value)
+	}
+
+	report, err := useCase.GetStorageStats(ctx, dbID, getStringParam(request.Parameters, "schema"), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := createTextResponse(formatStorageReport(report))
+	addMetadata(resp, "tables", report.Tables)
+	addMetadata(resp, "totalBytes", report.TotalBytes)
+	addMetadata(resp, "hypertables", len(report.Hypertables))
+	return resp, nil
+}
+
+// formatStorageReport renders a storage report as text
+func formatStorageReport(report *domain.StorageReport) string {
+	var output strings.Builder
+	fmt.Fprintf(&output, "Storage of schema %s: %d tables, %s", report.Schema, report.Tables, formatBytes(report.TotalBytes))
+	if report.DatabaseSize != "" {
+		fmt.Fprintf(&output, " (database %s, hypertables %s)", report.DatabaseSize, report.HypertablesSize)
+	}
+	output.WriteString("\n")
+
+	fmt.Fprintf(&output, "\nTop tables by %s:\n", report.OrderBy)
+	if len(report.TopTables) == 0 {
+		output.WriteString("  (none)\n")
+	}
+	for i, table := range report.TopTables {
+		fmt.Fprintf(&output, "%d. %s: %s (table %s, indexes %s), ~%.0f rows", i+1, table.Table,
+			formatBytes(table.TotalBytes), formatBytes(table.TableBytes), formatBytes(table.IndexBytes), table.RowEstimate)
+		if table.DeadTuples > 0 {
+			fmt.Fprintf(&output, ", %d dead tuples", table.DeadTuples)
+		}
+		if table.BloatBytes > 0 {
+			fmt.Fprintf(&output, ", ~%s bloat", formatBytes(table.BloatBytes))
+		}
+		if table.FreeBytes > 0 {
+			fmt.Fprintf(&output, ", %s free", formatBytes(table.FreeBytes))
+		}
+		output.WriteString("\n")
+		if table.LastVacuum != "" || table.LastAnalyze != "" {
+			fmt.Fprintf(&output, "   last vacuum %s, last analyze %s\n", valueOr(table.LastVacuum, "never"), valueOr(table.LastAnalyze, "never"))
+		}
+	}
+
+	if len(report.TopIndexes) > 0 {
+		fmt.Fprintf(&output, "\nTop indexes by %s:\n", indexRanking(report.OrderBy))
+		for i, index := range report.TopIndexes {
+			fmt.Fprintf(&output, "%d. %s on %s (%s): %s", i+1, index.Index, index.Table, index.Method, formatBytes(index.Bytes))
+			if index.BloatBytes > 0 {
+				fmt.Fprintf(&output, ", ~%s bloat", formatBytes(index.BloatBytes))
+			}
+			fmt.Fprintf(&output, ", %d scans\n", index.Scans)
+		}
+	}
+
+	if len(report.Hypertables) > 0 {
+		output.WriteString("\nHypertables:\n")
+		for _, h := range report.Hypertables {
+			fmt.Fprintf(&output, "- %s: %s in %d chunks (%d compressed): table %s, indexes %s, toast %s\n", h.Table,
+				formatBytes(h.TotalBytes), h.ChunkCount, h.CompressedChunks, formatBytes(h.TableBytes), formatBytes(h.IndexBytes), formatBytes(h.ToastBytes))
+			for _, chunk := range h.Chunks {
+				fmt.Fprintf(&output, "  %s [%s, %s): %s", chunk.Name, chunk.RangeStart, chunk.RangeEnd, formatBytes(chunk.TotalBytes))
+				if chunk.Compressed {
+					output.WriteString(" compressed")
+				}
+				output.WriteString("\n")
+			}
+			if h.ChunkCount > len(h.Chunks) {
+				fmt.Fprintf(&output, "  ... %d older chunks\n", h.ChunkCount-len(h.Chunks))
+			}
+		}
+	}
+
+	if len(report.Notes) > 0 {
+		output.WriteString("\nNotes:\n")
+		for _, note := range report.Notes {
+			fmt.Fprintf(&output, "- %s\n", note)
+		}
+	}
+	return output.String()
+}
+
+// indexRanking names the ranking of indexes, which have no dead tuples
+func indexRanking(orderBy string) string {
+	if orderBy == "bloat" {
+		return orderBy
+	}
+	return "size"
+}
+
+// formatBytes renders a byte count in binary units, like pg_size_pretty
+func formatBytes(bytes int64) string {
+	units := []string{"bytes", "kB", "MB", "GB", "TB"}
+	value := float64(bytes)
+	unit := 0
+	for value >= 1024 && unit < len(units)-1 {
+		value /= 1024
+		unit++
+	}
+	if unit == 0 {
+		return fmt.Sprintf("%d bytes", bytes)
+	}
+	return fmt.Sprintf("%.1f %s", value, units[unit])
+}
+
+// valueOr returns a value, or a fallback when it is empty
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
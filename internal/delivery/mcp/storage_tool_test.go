@@ -0,0 +1,57 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+func TestStorageToolHandleRequest(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetStorageStats", mock.Anything, "test_db", "public", domain.StorageOptions{OrderBy: "bloat", Limit: 1}).Return(&domain.StorageReport{
+		Schema:          "public",
+		OrderBy:         "bloat",
+		Tables:          12,
+		TotalBytes:      3 << 30,
+		DatabaseSize:    "9 GB",
+		HypertablesSize: "6 GB",
+		TopTables: []domain.TableStorage{{
+			Table: "orders", TotalBytes: 512 << 20, TableBytes: 400 << 20, IndexBytes: 112 << 20,
+			BloatBytes: 40 << 20, RowEstimate: 1000000, DeadTuples: 12000, LastAnalyze: "2024-05-01 10:00:00+00",
+		}},
+		TopIndexes: []domain.IndexStorage{{Table: "orders", Index: "idx_orders_status", Method: "btree", Bytes: 50 << 20, BloatBytes: 10 << 20, Scans: 120}},
+		Hypertables: []domain.HypertableStorage{{
+			Table: "metrics", ChunkCount: 3, CompressedChunks: 2, TableBytes: 5 << 30, IndexBytes: 1 << 30, TotalBytes: 6 << 30,
+			Chunks: []domain.ChunkStorage{{Name: "_timescaledb_internal._hyper_1_3_chunk", RangeStart: "2024-05-01 00:00:00+00",
+				RangeEnd: "2024-05-08 00:00:00+00", TotalBytes: 700 << 20}},
+		}},
+	}, nil)
+
+	result, err := NewStorageTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"schema": "public", "order_by": "bloat", "limit": float64(1)},
+	}, "test_db", mockUseCase)
+	require.NoError(t, err)
+
+	resp := result.(map[string]interface{})
+	text := resp["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Contains(t, text, "Storage of schema public: 12 tables, 3.0 GB (database 9 GB, hypertables 6 GB)\n")
+	assert.Contains(t, text, "Top tables by bloat:\n1. orders: 512.0 MB (table 400.0 MB, indexes 112.0 MB), ~1000000 rows, 12000 dead tuples, ~40.0 MB bloat\n")
+	assert.Contains(t, text, "   last vacuum never, last analyze 2024-05-01 10:00:00+00\n")
+	assert.Contains(t, text, "1. idx_orders_status on orders (btree): 50.0 MB, ~10.0 MB bloat, 120 scans\n")
+	assert.Contains(t, text, "- metrics: 6.0 GB in 3 chunks (2 compressed): table 5.0 GB, indexes 1.0 GB, toast 0 bytes\n")
+	assert.Contains(t, text, "  _timescaledb_internal._hyper_1_3_chunk [2024-05-01 00:00:00+00, 2024-05-08 00:00:00+00): 700.0 MB\n  ... 2 older chunks\n")
+	assert.Equal(t, 12, resp["metadata"].(map[string]interface{})["tables"])
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 bytes", formatBytes(512))
+	assert.Equal(t, "1.5 kB", formatBytes(1536))
+	assert.Equal(t, "2.0 TB", formatBytes(2<<40))
+}
@@ -2,7 +2,6 @@ package mcp
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 )
@@ -13,7 +12,8 @@ import (
 //   ExecuteQuery(ctx context.Context, dbID, query string, params []interface{}) (string, error)
 //   ExecuteStatement(ctx context.Context, dbID, statement string, params []interface{}) (string, error)
 //   ExecuteTransaction(ctx context.Context, dbID, action string, txID string, statement string, params []interface{}, readOnly bool) (string, map[string]interface{}, error)
-//   GetDatabaseInfo(dbID string) (map[string]interface{}, error)
+//   GetDatabaseInfo(ctx context.Context, dbID string) (map[string]interface{}, error)
+//   GetSchemaComponent(ctx context.Context, dbID, component, schema, table string) (map[string]interface{}, error)
 //   ListDatabases() []string
 //   GetDatabaseType(dbID string) (string, error)
 // }
@@ -42,94 +42,17 @@ func NewTimescaleDBContextProvider() *TimescaleDBContextProvider {
 
 // DetectTimescaleDB detects if TimescaleDB is installed in the given database
 func (p *TimescaleDBContextProvider) DetectTimescaleDB(ctx context.Context, dbID string, useCase UseCaseProvider) (*TimescaleDBContextInfo, error) {
-	// Check database type first
-	dbType, err := useCase.GetDatabaseType(dbID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get database type: %w", err)
-	}
-
-	// TimescaleDB is a PostgreSQL extension, so we only check PostgreSQL databases
-	if !strings.Contains(strings.ToLower(dbType), "postgres") {
-		// Return a context info object with isTimescaleDB = false
-		return &TimescaleDBContextInfo{
-			IsTimescaleDB: false,
-		}, nil
-	}
-
-	// Check for TimescaleDB extension
-	query := "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
-	result, err := useCase.ExecuteStatement(ctx, dbID, query, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check for TimescaleDB extension: %w", err)
-	}
-
-	// Parse the result to determine if TimescaleDB is available
-	var versions []map[string]interface{}
-	if err := json.Unmarshal([]byte(result), &versions); err != nil {
-		return nil, fmt.Errorf("failed to parse extension result: %w", err)
-	}
-
-	// If no results, TimescaleDB is not installed
-	if len(versions) == 0 {
-		return &TimescaleDBContextInfo{
-			IsTimescaleDB: false,
-		}, nil
-	}
-
-	// Extract version information
-	version := ""
-	if extVersion, ok := versions[0]["extversion"]; ok && extVersion != nil {
-		version = fmt.Sprintf("%v", extVersion)
-	}
-
-	// Create and return context info
-	return &TimescaleDBContextInfo{
-		IsTimescaleDB: true,
-		Version:       version,
-	}, nil
+	contextInfo, _, err := p.describeHypertables(ctx, dbID, useCase)
+	return contextInfo, err
 }
 
 // GetTimescaleDBContext gets comprehensive TimescaleDB context information
 func (p *TimescaleDBContextProvider) GetTimescaleDBContext(ctx context.Context, dbID string, useCase UseCaseProvider) (*TimescaleDBContextInfo, error) {
-	// First, detect if TimescaleDB is available
-	contextInfo, err := p.DetectTimescaleDB(ctx, dbID, useCase)
+	contextInfo, hypertables, err := p.describeHypertables(ctx, dbID, useCase)
 	if err != nil {
 		return nil, err
 	}
 
-	// If not TimescaleDB, return basic info
-	if !contextInfo.IsTimescaleDB {
-		return contextInfo, nil
-	}
-
-	// Get information about hypertables
-	query := `
-		SELECT 
-			h.table_name,
-			d.column_name as time_column,
-			d.time_interval as chunk_interval
-		FROM 
-			_timescaledb_catalog.hypertable h
-		JOIN 
-			_timescaledb_catalog.dimension d ON h.id = d.hypertable_id
-		WHERE 
-			d.column_type = 'TIMESTAMP' OR d.column_type = 'TIMESTAMPTZ'
-		ORDER BY 
-			h.table_name
-	`
-
-	result, err := useCase.ExecuteStatement(ctx, dbID, query, nil)
-	if err != nil {
-		// Don't fail the whole context if just hypertable info fails
-		return contextInfo, nil
-	}
-
-	// Parse the result
-	var hypertables []map[string]interface{}
-	if err := json.Unmarshal([]byte(result), &hypertables); err != nil {
-		return contextInfo, nil
-	}
-
 	// Process hypertable information
 	for _, h := range hypertables {
 		hypertableInfo := TimescaleDBHypertableInfo{}
@@ -154,3 +77,48 @@ func (p *TimescaleDBContextProvider) GetTimescaleDBContext(ctx context.Context,
 
 	return contextInfo, nil
 }
+
+// describeHypertables reads the hypertables schema component, which the
+// repository caches, and returns the detection result with the raw rows
+func (p *TimescaleDBContextProvider) describeHypertables(ctx context.Context, dbID string, useCase UseCaseProvider) (*TimescaleDBContextInfo, []map[string]interface{}, error) {
+	// Check database type first
+	dbType, err := useCase.GetDatabaseType(dbID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get database type: %w", err)
+	}
+
+	// TimescaleDB is a PostgreSQL extension, so we only check PostgreSQL databases
+	if !strings.Contains(strings.ToLower(dbType), "postgres") {
+		return &TimescaleDBContextInfo{IsTimescaleDB: false}, nil, nil
+	}
+
+	result, err := useCase.GetSchemaComponent(ctx, dbID, "hypertables", "", "")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to check for TimescaleDB extension: %w", err)
+	}
+
+	// A nil version means the extension is not installed
+	version, ok := result["timescaledb_version"]
+	if !ok || version == nil {
+		return &TimescaleDBContextInfo{IsTimescaleDB: false}, nil, nil
+	}
+
+	contextInfo := &TimescaleDBContextInfo{
+		IsTimescaleDB: true,
+		Version:       fmt.Sprintf("%v", version),
+	}
+
+	var hypertables []map[string]interface{}
+	switch rows := result["hypertables"].(type) {
+	case []map[string]interface{}:
+		hypertables = rows
+	case []interface{}:
+		for _, row := range rows {
+			if m, ok := row.(map[string]interface{}); ok {
+				hypertables = append(hypertables, m)
+			}
+		}
+	}
+
+	return contextInfo, hypertables, nil
+}
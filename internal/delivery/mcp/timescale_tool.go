@@ -9,6 +9,8 @@ import (
 
 	"github.com/FreePeak/cortex/pkg/server"
 	cortextools "github.com/FreePeak/cortex/pkg/tools"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
 )
 
 // TimescaleDBTool implements a tool for TimescaleDB operations
@@ -38,6 +40,14 @@ func (t *TimescaleDBTool) GetDescription(dbID string) string {
 	return fmt.Sprintf("%s on %s", t.description, dbID)
 }
 
+// timescaleSchemaOption declares the schema parameter shared by the
+// TimescaleDB tools
+func timescaleSchemaOption() cortextools.ToolOption {
+	return cortextools.WithString("schema",
+		cortextools.Description("Schema of the target table or view; names may also be qualified as schema.name"),
+	)
+}
+
 // CreateTool creates the TimescaleDB tool
 func (t *TimescaleDBTool) CreateTool(name string, dbID string) interface{} {
 	// Create main tool that describes the available operations
@@ -48,6 +58,7 @@ func (t *TimescaleDBTool) CreateTool(name string, dbID string) interface{} {
 			cortextools.Description("TimescaleDB operation to perform"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 		cortextools.WithString("target_table",
 			cortextools.Description("The table to perform the operation on"),
 		),
@@ -65,6 +76,7 @@ func (t *TimescaleDBTool) CreateHypertableTool(name string, dbID string) interfa
 			cortextools.Description("The operation must be 'create_hypertable'"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 		cortextools.WithString("target_table",
 			cortextools.Description("The table to convert to a hypertable"),
 			cortextools.Required(),
@@ -94,6 +106,7 @@ func (t *TimescaleDBTool) CreateListHypertablesTool(name string, dbID string) in
 			cortextools.Description("The operation must be 'list_hypertables'"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 	)
 }
 
@@ -106,6 +119,7 @@ func (t *TimescaleDBTool) CreateCompressionEnableTool(name string, dbID string)
 			cortextools.Description("The operation must be 'enable_compression'"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 		cortextools.WithString("target_table",
 			cortextools.Description("The hypertable to enable compression on"),
 			cortextools.Required(),
@@ -125,6 +139,7 @@ func (t *TimescaleDBTool) CreateCompressionDisableTool(name string, dbID string)
 			cortextools.Description("The operation must be 'disable_compression'"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 		cortextools.WithString("target_table",
 			cortextools.Description("The hypertable to disable compression on"),
 			cortextools.Required(),
@@ -141,6 +156,7 @@ func (t *TimescaleDBTool) CreateCompressionPolicyAddTool(name string, dbID strin
 			cortextools.Description("The operation must be 'add_compression_policy'"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 		cortextools.WithString("target_table",
 			cortextools.Description("The hypertable to add compression policy to"),
 			cortextools.Required(),
@@ -167,6 +183,7 @@ func (t *TimescaleDBTool) CreateCompressionPolicyRemoveTool(name string, dbID st
 			cortextools.Description("The operation must be 'remove_compression_policy'"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 		cortextools.WithString("target_table",
 			cortextools.Description("The hypertable to remove compression policy from"),
 			cortextools.Required(),
@@ -183,6 +200,7 @@ func (t *TimescaleDBTool) CreateCompressionSettingsTool(name string, dbID string
 			cortextools.Description("The operation must be 'get_compression_settings'"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 		cortextools.WithString("target_table",
 			cortextools.Description("The hypertable to get compression settings for"),
 			cortextools.Required(),
@@ -199,6 +217,7 @@ func (t *TimescaleDBTool) CreateRetentionPolicyTool(name string, dbID string) in
 			cortextools.Description("The operation must be one of: add_retention_policy, remove_retention_policy, get_retention_policy"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 		cortextools.WithString("target_table",
 			cortextools.Description("The hypertable to manage retention policy for"),
 			cortextools.Required(),
@@ -218,6 +237,7 @@ func (t *TimescaleDBTool) CreateTimeSeriesQueryTool(name string, dbID string) in
 			cortextools.Description("The operation must be 'time_series_query'"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 		cortextools.WithString("target_table",
 			cortextools.Description("The table to query"),
 			cortextools.Required(),
@@ -269,6 +289,7 @@ func (t *TimescaleDBTool) CreateTimeSeriesAnalyzeTool(name string, dbID string)
 			cortextools.Description("The operation must be 'analyze_time_series'"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 		cortextools.WithString("target_table",
 			cortextools.Description("The table to analyze"),
 			cortextools.Required(),
@@ -295,6 +316,7 @@ func (t *TimescaleDBTool) CreateContinuousAggregateTool(name string, dbID string
 			cortextools.Description("The operation must be 'create_continuous_aggregate'"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 		cortextools.WithString("view_name",
 			cortextools.Description("Name for the continuous aggregate view"),
 			cortextools.Required(),
@@ -338,6 +360,7 @@ func (t *TimescaleDBTool) CreateContinuousAggregateRefreshTool(name string, dbID
 			cortextools.Description("The operation must be 'refresh_continuous_aggregate'"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 		cortextools.WithString("view_name",
 			cortextools.Description("Name of the continuous aggregate view"),
 			cortextools.Required(),
@@ -360,6 +383,7 @@ func (t *TimescaleDBTool) CreateContinuousAggregateDropTool(name string, dbID st
 			cortextools.Description("The operation must be 'drop_continuous_aggregate'"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 		cortextools.WithString("view_name",
 			cortextools.Description("Name of the continuous aggregate view to drop"),
 			cortextools.Required(),
@@ -379,6 +403,7 @@ func (t *TimescaleDBTool) CreateContinuousAggregateListTool(name string, dbID st
 			cortextools.Description("The operation must be 'list_continuous_aggregates'"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 	)
 }
 
@@ -391,6 +416,7 @@ func (t *TimescaleDBTool) CreateContinuousAggregateInfoTool(name string, dbID st
 			cortextools.Description("The operation must be 'get_continuous_aggregate_info'"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 		cortextools.WithString("view_name",
 			cortextools.Description("Name of the continuous aggregate view"),
 			cortextools.Required(),
@@ -407,6 +433,7 @@ func (t *TimescaleDBTool) CreateContinuousAggregatePolicyAddTool(name string, db
 			cortextools.Description("The operation must be 'add_continuous_aggregate_policy'"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 		cortextools.WithString("view_name",
 			cortextools.Description("Name of the continuous aggregate view"),
 			cortextools.Required(),
@@ -435,6 +462,7 @@ func (t *TimescaleDBTool) CreateContinuousAggregatePolicyRemoveTool(name string,
 			cortextools.Description("The operation must be 'remove_continuous_aggregate_policy'"),
 			cortextools.Required(),
 		),
+		timescaleSchemaOption(),
 		cortextools.WithString("view_name",
 			cortextools.Description("Name of the continuous aggregate view"),
 			cortextools.Required(),
@@ -512,13 +540,18 @@ func (t *TimescaleDBTool) handleCreateHypertable(ctx context.Context, request se
 		return nil, fmt.Errorf("time_column parameter is required")
 	}
 
+	target, err := resolveTimescaleObject(request, "target_table", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Extract optional parameters
 	chunkTimeInterval := getStringParam(request.Parameters, "chunk_time_interval")
 	partitioningColumn := getStringParam(request.Parameters, "partitioning_column")
 	ifNotExists := getBoolParam(request.Parameters, "if_not_exists")
 
 	// Build the SQL statement to create a hypertable
-	sql := buildCreateHypertableSQL(targetTable, timeColumn, chunkTimeInterval, partitioningColumn, ifNotExists)
+	sql := buildCreateHypertableSQL(target, timeColumn, chunkTimeInterval, partitioningColumn, ifNotExists)
 
 	// Check if the database is PostgreSQL (TimescaleDB requires PostgreSQL)
 	dbType, err := useCase.GetDatabaseType(dbID)
@@ -554,8 +587,17 @@ func (t *TimescaleDBTool) handleListHypertables(ctx context.Context, request ser
 		return nil, fmt.Errorf("TimescaleDB operations are only supported on PostgreSQL databases")
 	}
 
+	schema, err := useCase.ResolveSchema(dbID, getStringParam(request.Parameters, "schema"))
+	if err != nil {
+		return nil, err
+	}
+
 	// Build the SQL query to list hypertables
-	sql := `
+	schemaFilter := ""
+	if schema != "" {
+		schemaFilter = "WHERE h.schema_name = " + db.QuoteLiteral(schema)
+	}
+	sql := fmt.Sprintf(`
 		SELECT h.table_name, h.schema_name, d.column_name as time_column,
 			count(d.id) as num_dimensions,
 			(
@@ -566,8 +608,9 @@ func (t *TimescaleDBTool) handleListHypertables(ctx context.Context, request ser
 			) as space_column
 		FROM _timescaledb_catalog.hypertable h
 		JOIN _timescaledb_catalog.dimension d ON h.id = d.hypertable_id
+		%s
 		GROUP BY h.id, h.table_name, h.schema_name
-	`
+	`, schemaFilter)
 
 	// Execute the statement
 	result, err := useCase.ExecuteStatement(ctx, dbID, sql, nil)
@@ -589,6 +632,11 @@ func (t *TimescaleDBTool) handleEnableCompression(ctx context.Context, request s
 		return nil, fmt.Errorf("target_table parameter is required")
 	}
 
+	target, err := resolveTimescaleObject(request, "target_table", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Extract optional interval parameter
 	afterInterval := getStringParam(request.Parameters, "after")
 
@@ -603,7 +651,7 @@ func (t *TimescaleDBTool) handleEnableCompression(ctx context.Context, request s
 	}
 
 	// Build the SQL statement to enable compression
-	sql := fmt.Sprintf("ALTER TABLE %s SET (timescaledb.compress = true)", targetTable)
+	sql := fmt.Sprintf("ALTER TABLE %s SET (timescaledb.compress = true)", target.ident())
 
 	// Execute the statement
 	_, err = useCase.ExecuteStatement(ctx, dbID, sql, nil)
@@ -615,7 +663,7 @@ func (t *TimescaleDBTool) handleEnableCompression(ctx context.Context, request s
 	// If interval is specified, add compression policy
 	if afterInterval != "" {
 		// Build the SQL statement for compression policy
-		policySQL := fmt.Sprintf("SELECT add_compression_policy('%s', INTERVAL '%s')", targetTable, afterInterval)
+		policySQL := fmt.Sprintf("SELECT add_compression_policy(%s, INTERVAL '%s')", target.regclass(), afterInterval)
 
 		// Execute the statement
 		_, err = useCase.ExecuteStatement(ctx, dbID, policySQL, nil)
@@ -641,6 +689,11 @@ func (t *TimescaleDBTool) handleDisableCompression(ctx context.Context, request
 		return nil, fmt.Errorf("target_table parameter is required")
 	}
 
+	target, err := resolveTimescaleObject(request, "target_table", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if the database is PostgreSQL (TimescaleDB requires PostgreSQL)
 	dbType, err := useCase.GetDatabaseType(dbID)
 	if err != nil {
@@ -653,8 +706,8 @@ func (t *TimescaleDBTool) handleDisableCompression(ctx context.Context, request
 
 	// First, find and remove any existing compression policy
 	policyQuery := fmt.Sprintf(
-		"SELECT job_id FROM timescaledb_information.jobs WHERE hypertable_name = '%s' AND proc_name = 'policy_compression'",
-		targetTable,
+		"SELECT job_id FROM timescaledb_information.jobs WHERE %s AND proc_name = 'policy_compression'",
+		target.filter("hypertable_schema", "hypertable_name"),
 	)
 
 	policyResult, err := useCase.ExecuteStatement(ctx, dbID, policyQuery, nil)
@@ -682,7 +735,7 @@ func (t *TimescaleDBTool) handleDisableCompression(ctx context.Context, request
 	}
 
 	// Build the SQL statement to disable compression
-	sql := fmt.Sprintf("ALTER TABLE %s SET (timescaledb.compress = false)", targetTable)
+	sql := fmt.Sprintf("ALTER TABLE %s SET (timescaledb.compress = false)", target.ident())
 
 	// Execute the statement
 	_, err = useCase.ExecuteStatement(ctx, dbID, sql, nil)
@@ -708,6 +761,11 @@ func (t *TimescaleDBTool) handleAddCompressionPolicy(ctx context.Context, reques
 		return nil, fmt.Errorf("interval parameter is required")
 	}
 
+	target, err := resolveTimescaleObject(request, "target_table", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Extract optional parameters
 	segmentBy := getStringParam(request.Parameters, "segment_by")
 	orderBy := getStringParam(request.Parameters, "order_by")
@@ -724,8 +782,8 @@ func (t *TimescaleDBTool) handleAddCompressionPolicy(ctx context.Context, reques
 
 	// First, check if compression is enabled
 	compressionQuery := fmt.Sprintf(
-		"SELECT compress FROM timescaledb_information.hypertables WHERE hypertable_name = '%s'",
-		targetTable,
+		"SELECT compress FROM timescaledb_information.hypertables WHERE %s",
+		target.filter("hypertable_schema", "hypertable_name"),
 	)
 
 	compressionResult, err := useCase.ExecuteStatement(ctx, dbID, compressionQuery, nil)
@@ -750,7 +808,7 @@ func (t *TimescaleDBTool) handleAddCompressionPolicy(ctx context.Context, reques
 
 	// If compression isn't enabled, enable it first
 	if !isCompressed {
-		enableSQL := fmt.Sprintf("ALTER TABLE %s SET (timescaledb.compress = true)", targetTable)
+		enableSQL := fmt.Sprintf("ALTER TABLE %s SET (timescaledb.compress = true)", target.ident())
 		_, err = useCase.ExecuteStatement(ctx, dbID, enableSQL, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to enable compression: %w", err)
@@ -759,7 +817,7 @@ func (t *TimescaleDBTool) handleAddCompressionPolicy(ctx context.Context, reques
 
 	// Build the compression policy SQL
 	var policyQueryBuilder strings.Builder
-	policyQueryBuilder.WriteString(fmt.Sprintf("SELECT add_compression_policy('%s', INTERVAL '%s'", targetTable, interval))
+	policyQueryBuilder.WriteString(fmt.Sprintf("SELECT add_compression_policy(%s, INTERVAL '%s'", target.regclass(), interval))
 
 	if segmentBy != "" {
 		policyQueryBuilder.WriteString(fmt.Sprintf(", segmentby => '%s'", segmentBy))
@@ -790,6 +848,11 @@ func (t *TimescaleDBTool) handleRemoveCompressionPolicy(ctx context.Context, req
 		return nil, fmt.Errorf("target_table parameter is required")
 	}
 
+	target, err := resolveTimescaleObject(request, "target_table", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if the database is PostgreSQL (TimescaleDB requires PostgreSQL)
 	dbType, err := useCase.GetDatabaseType(dbID)
 	if err != nil {
@@ -802,8 +865,8 @@ func (t *TimescaleDBTool) handleRemoveCompressionPolicy(ctx context.Context, req
 
 	// Find the policy ID
 	policyQuery := fmt.Sprintf(
-		"SELECT job_id FROM timescaledb_information.jobs WHERE hypertable_name = '%s' AND proc_name = 'policy_compression'",
-		targetTable,
+		"SELECT job_id FROM timescaledb_information.jobs WHERE %s AND proc_name = 'policy_compression'",
+		target.filter("hypertable_schema", "hypertable_name"),
 	)
 
 	policyResult, err := useCase.ExecuteStatement(ctx, dbID, policyQuery, nil)
@@ -848,6 +911,11 @@ func (t *TimescaleDBTool) handleGetCompressionSettings(ctx context.Context, requ
 		return nil, fmt.Errorf("target_table parameter is required")
 	}
 
+	target, err := resolveTimescaleObject(request, "target_table", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if the database is PostgreSQL (TimescaleDB requires PostgreSQL)
 	dbType, err := useCase.GetDatabaseType(dbID)
 	if err != nil {
@@ -860,8 +928,8 @@ func (t *TimescaleDBTool) handleGetCompressionSettings(ctx context.Context, requ
 
 	// Check if the table is a hypertable and has compression enabled
 	hypertableQuery := fmt.Sprintf(
-		"SELECT compress FROM timescaledb_information.hypertables WHERE hypertable_name = '%s'",
-		targetTable,
+		"SELECT compress FROM timescaledb_information.hypertables WHERE %s",
+		target.filter("hypertable_schema", "hypertable_name"),
 	)
 
 	hypertableResult, err := useCase.ExecuteStatement(ctx, dbID, hypertableQuery, nil)
@@ -899,8 +967,8 @@ func (t *TimescaleDBTool) handleGetCompressionSettings(ctx context.Context, requ
 	if isCompressed {
 		// Get compression settings
 		compressionQuery := fmt.Sprintf(
-			"SELECT segmentby, orderby FROM timescaledb_information.compression_settings WHERE hypertable_name = '%s'",
-			targetTable,
+			"SELECT segmentby, orderby FROM timescaledb_information.compression_settings WHERE %s",
+			target.filter("hypertable_schema", "hypertable_name"),
 		)
 
 		compressionResult, err := useCase.ExecuteStatement(ctx, dbID, compressionQuery, nil)
@@ -927,9 +995,9 @@ func (t *TimescaleDBTool) handleGetCompressionSettings(ctx context.Context, requ
 		policyQuery := fmt.Sprintf(
 			"SELECT s.schedule_interval, h.chunk_time_interval FROM timescaledb_information.jobs j "+
 				"JOIN timescaledb_information.job_stats s ON j.job_id = s.job_id "+
-				"JOIN timescaledb_information.hypertables h ON j.hypertable_name = h.hypertable_name "+
-				"WHERE j.hypertable_name = '%s' AND j.proc_name = 'policy_compression'",
-			targetTable,
+				"JOIN timescaledb_information.hypertables h ON j.hypertable_schema = h.hypertable_schema AND j.hypertable_name = h.hypertable_name "+
+				"WHERE %s AND j.proc_name = 'policy_compression'",
+			target.filter("j.hypertable_schema", "j.hypertable_name"),
 		)
 
 		policyResult, err := useCase.ExecuteStatement(ctx, dbID, policyQuery, nil)
@@ -970,6 +1038,11 @@ func (t *TimescaleDBTool) handleAddRetentionPolicy(ctx context.Context, request
 		return nil, fmt.Errorf("retention_interval parameter is required")
 	}
 
+	target, err := resolveTimescaleObject(request, "target_table", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if the database is PostgreSQL (TimescaleDB requires PostgreSQL)
 	dbType, err := useCase.GetDatabaseType(dbID)
 	if err != nil {
@@ -981,7 +1054,7 @@ func (t *TimescaleDBTool) handleAddRetentionPolicy(ctx context.Context, request
 	}
 
 	// Build the SQL statement to add a retention policy
-	sql := fmt.Sprintf("SELECT add_retention_policy('%s', INTERVAL '%s')", targetTable, retentionInterval)
+	sql := fmt.Sprintf("SELECT add_retention_policy(%s, INTERVAL '%s')", target.regclass(), retentionInterval)
 
 	// Execute the statement
 	result, err := useCase.ExecuteStatement(ctx, dbID, sql, nil)
@@ -1003,6 +1076,11 @@ func (t *TimescaleDBTool) handleRemoveRetentionPolicy(ctx context.Context, reque
 		return nil, fmt.Errorf("target_table parameter is required")
 	}
 
+	target, err := resolveTimescaleObject(request, "target_table", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if the database is PostgreSQL (TimescaleDB requires PostgreSQL)
 	dbType, err := useCase.GetDatabaseType(dbID)
 	if err != nil {
@@ -1015,8 +1093,8 @@ func (t *TimescaleDBTool) handleRemoveRetentionPolicy(ctx context.Context, reque
 
 	// First, find the policy job ID
 	findPolicySQL := fmt.Sprintf(
-		"SELECT job_id FROM timescaledb_information.jobs WHERE hypertable_name = '%s' AND proc_name = 'policy_retention'",
-		targetTable,
+		"SELECT job_id FROM timescaledb_information.jobs WHERE %s AND proc_name = 'policy_retention'",
+		target.filter("hypertable_schema", "hypertable_name"),
 	)
 
 	// Execute the statement to find the policy
@@ -1034,8 +1112,8 @@ func (t *TimescaleDBTool) handleRemoveRetentionPolicy(ctx context.Context, reque
 
 	// Now remove the policy - assuming we received a JSON array with the job_id
 	removeSQL := fmt.Sprintf(
-		"SELECT remove_retention_policy((SELECT job_id FROM timescaledb_information.jobs WHERE hypertable_name = '%s' AND proc_name = 'policy_retention' LIMIT 1))",
-		targetTable,
+		"SELECT remove_retention_policy(%s)",
+		target.regclass(),
 	)
 
 	// Execute the statement to remove the policy
@@ -1058,6 +1136,11 @@ func (t *TimescaleDBTool) handleGetRetentionPolicy(ctx context.Context, request
 		return nil, fmt.Errorf("target_table parameter is required")
 	}
 
+	target, err := resolveTimescaleObject(request, "target_table", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if the database is PostgreSQL (TimescaleDB requires PostgreSQL)
 	dbType, err := useCase.GetDatabaseType(dbID)
 	if err != nil {
@@ -1071,7 +1154,7 @@ func (t *TimescaleDBTool) handleGetRetentionPolicy(ctx context.Context, request
 	// Build the SQL query to get retention policy details
 	sql := fmt.Sprintf(`
 		SELECT 
-			'%s' as hypertable_name,
+			%s as hypertable_name,
 			js.schedule_interval as retention_interval,
 			CASE WHEN j.job_id IS NOT NULL THEN true ELSE false END as retention_enabled
 		FROM 
@@ -1079,8 +1162,8 @@ func (t *TimescaleDBTool) handleGetRetentionPolicy(ctx context.Context, request
 		JOIN 
 			timescaledb_information.job_stats js ON j.job_id = js.job_id
 		WHERE 
-			j.hypertable_name = '%s' AND j.proc_name = 'policy_retention'
-	`, targetTable, targetTable)
+			%s AND j.proc_name = 'policy_retention'
+	`, db.QuoteLiteral(target.name), target.filter("j.hypertable_schema", "j.hypertable_name"))
 
 	// Execute the statement
 	result, err := useCase.ExecuteStatement(ctx, dbID, sql, nil)
@@ -1093,7 +1176,7 @@ func (t *TimescaleDBTool) handleGetRetentionPolicy(ctx context.Context, request
 		// No retention policy found, return a default structure
 		return map[string]interface{}{
 			"message": fmt.Sprintf("No retention policy found for table '%s'", targetTable),
-			"details": fmt.Sprintf(`[{"hypertable_name":"%s","retention_enabled":false}]`, targetTable),
+			"details": fmt.Sprintf(`[{"hypertable_name":%q,"retention_enabled":false}]`, target.name),
 		}, nil
 	}
 
@@ -1121,6 +1204,12 @@ func (t *TimescaleDBTool) handleTimeSeriesQuery(ctx context.Context, request ser
 		return nil, fmt.Errorf("bucket_interval parameter is required")
 	}
 
+	target, err := resolveTimescaleObject(request, "target_table", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+	timeIdent := db.QuoteIdentifier("postgres", timeColumn)
+
 	// Extract optional parameters
 	startTimeStr := getStringParam(request.Parameters, "start_time")
 	endTimeStr := getStringParam(request.Parameters, "end_time")
@@ -1140,7 +1229,7 @@ func (t *TimescaleDBTool) handleTimeSeriesQuery(ctx context.Context, request ser
 	// Build WHERE clause
 	whereClause := ""
 	if startTimeStr != "" && endTimeStr != "" {
-		whereClause = fmt.Sprintf("%s BETWEEN '%s' AND '%s'", timeColumn, startTimeStr, endTimeStr)
+		whereClause = fmt.Sprintf("%s BETWEEN '%s' AND '%s'", timeIdent, startTimeStr, endTimeStr)
 		if whereCondition != "" {
 			whereClause = fmt.Sprintf("%s AND %s", whereClause, whereCondition)
 		}
@@ -1187,7 +1276,7 @@ func (t *TimescaleDBTool) handleTimeSeriesQuery(ctx context.Context, request ser
 			ORDER BY 
 				%s
 			LIMIT %d
-		`, bucketInterval, timeColumn, aggregations, targetTable, whereClause, groupBy, orderBy, limit)
+		`, bucketInterval, timeIdent, aggregations, target.ident(), whereClause, groupBy, orderBy, limit)
 	} else {
 		// Query with window functions - need to use a subquery
 		sql = fmt.Sprintf(`
@@ -1211,7 +1300,7 @@ func (t *TimescaleDBTool) handleTimeSeriesQuery(ctx context.Context, request ser
 			ORDER BY 
 				%s
 			LIMIT %d
-		`, aggregations, windowFunctions, bucketInterval, timeColumn, aggregations, targetTable, whereClause, groupBy, orderBy, orderBy, limit)
+		`, aggregations, windowFunctions, bucketInterval, timeIdent, aggregations, target.ident(), whereClause, groupBy, orderBy, orderBy, limit)
 	}
 
 	// Execute the query
@@ -1264,6 +1353,12 @@ func (t *TimescaleDBTool) handleTimeSeriesAnalyze(ctx context.Context, request s
 		return nil, fmt.Errorf("time_column parameter is required")
 	}
 
+	target, err := resolveTimescaleObject(request, "target_table", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+	timeIdent := db.QuoteIdentifier("postgres", timeColumn)
+
 	// Extract optional parameters
 	startTimeStr := getStringParam(request.Parameters, "start_time")
 	endTimeStr := getStringParam(request.Parameters, "end_time")
@@ -1271,7 +1366,7 @@ func (t *TimescaleDBTool) handleTimeSeriesAnalyze(ctx context.Context, request s
 	// Build WHERE clause
 	whereClause := ""
 	if startTimeStr != "" && endTimeStr != "" {
-		whereClause = fmt.Sprintf("WHERE %s BETWEEN '%s' AND '%s'", timeColumn, startTimeStr, endTimeStr)
+		whereClause = fmt.Sprintf("WHERE %s BETWEEN '%s' AND '%s'", timeIdent, startTimeStr, endTimeStr)
 	}
 
 	// Build the SQL query for basic time series analysis
@@ -1285,7 +1380,7 @@ func (t *TimescaleDBTool) handleTimeSeriesAnalyze(ctx context.Context, request s
 		FROM 
 			%s
 		%s
-	`, timeColumn, timeColumn, timeColumn, timeColumn, timeColumn, targetTable, whereClause)
+	`, timeIdent, timeIdent, timeIdent, timeIdent, timeIdent, target.ident(), whereClause)
 
 	// Execute the query
 	result, err := useCase.ExecuteStatement(ctx, dbID, sql, nil)
@@ -1322,6 +1417,15 @@ func (t *TimescaleDBTool) handleCreateContinuousAggregate(ctx context.Context, r
 		return nil, fmt.Errorf("bucket_interval parameter is required")
 	}
 
+	view, err := resolveTimescaleObject(request, "view_name", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+	source, err := resolveTimescaleObject(request, "source_table", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Extract optional parameters
 	aggregationsStr := getStringParam(request.Parameters, "aggregations")
 	whereCondition := getStringParam(request.Parameters, "where_condition")
@@ -1341,11 +1445,11 @@ func (t *TimescaleDBTool) handleCreateContinuousAggregate(ctx context.Context, r
 	// Build the SQL statement to create a continuous aggregate
 	var builder strings.Builder
 	builder.WriteString("CREATE MATERIALIZED VIEW ")
-	builder.WriteString(viewName)
+	builder.WriteString(view.ident())
 	builder.WriteString("\nAS SELECT\n    time_bucket('")
 	builder.WriteString(bucketInterval)
 	builder.WriteString("', ")
-	builder.WriteString(timeColumn)
+	builder.WriteString(db.QuoteIdentifier("postgres", timeColumn))
 	builder.WriteString(") AS time_bucket")
 
 	// Add aggregations
@@ -1356,7 +1460,7 @@ func (t *TimescaleDBTool) handleCreateContinuousAggregate(ctx context.Context, r
 
 	// Add FROM clause
 	builder.WriteString("\nFROM ")
-	builder.WriteString(sourceTable)
+	builder.WriteString(source.ident())
 
 	// Add WHERE clause if specified
 	if whereCondition != "" {
@@ -1375,14 +1479,14 @@ func (t *TimescaleDBTool) handleCreateContinuousAggregate(ctx context.Context, r
 	}
 
 	// Execute the statement
-	_, err := useCase.ExecuteStatement(ctx, dbID, builder.String(), nil)
+	_, err = useCase.ExecuteStatement(ctx, dbID, builder.String(), nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create continuous aggregate: %w", err)
 	}
 
 	// Add refresh policy if requested
 	if refreshPolicy && refreshInterval != "" {
-		policySQL := fmt.Sprintf("SELECT add_continuous_aggregate_policy('%s', start_offset => INTERVAL '1 week', end_offset => INTERVAL '1 hour', schedule_interval => INTERVAL '%s')", viewName, refreshInterval)
+		policySQL := fmt.Sprintf("SELECT add_continuous_aggregate_policy(%s, start_offset => INTERVAL '1 week', end_offset => INTERVAL '1 hour', schedule_interval => INTERVAL '%s')", view.regclass(), refreshInterval)
 		_, err := useCase.ExecuteStatement(ctx, dbID, policySQL, nil)
 		if err != nil {
 			return map[string]interface{}{
@@ -1405,6 +1509,11 @@ func (t *TimescaleDBTool) handleRefreshContinuousAggregate(ctx context.Context,
 		return nil, fmt.Errorf("view_name parameter is required")
 	}
 
+	view, err := resolveTimescaleObject(request, "view_name", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Extract optional parameters
 	startTimeStr := getStringParam(request.Parameters, "start_time")
 	endTimeStr := getStringParam(request.Parameters, "end_time")
@@ -1412,14 +1521,14 @@ func (t *TimescaleDBTool) handleRefreshContinuousAggregate(ctx context.Context,
 	// Build the SQL statement to refresh a continuous aggregate
 	var sql string
 	if startTimeStr != "" && endTimeStr != "" {
-		sql = fmt.Sprintf("CALL refresh_continuous_aggregate('%s', '%s', '%s')",
-			viewName, startTimeStr, endTimeStr)
+		sql = fmt.Sprintf("CALL refresh_continuous_aggregate(%s, '%s', '%s')",
+			view.regclass(), startTimeStr, endTimeStr)
 	} else {
-		sql = fmt.Sprintf("CALL refresh_continuous_aggregate('%s', NULL, NULL)", viewName)
+		sql = fmt.Sprintf("CALL refresh_continuous_aggregate(%s, NULL, NULL)", view.regclass())
 	}
 
 	// Execute the statement
-	_, err := useCase.ExecuteStatement(ctx, dbID, sql, nil)
+	_, err = useCase.ExecuteStatement(ctx, dbID, sql, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to refresh continuous aggregate: %w", err)
 	}
@@ -1437,18 +1546,23 @@ func (t *TimescaleDBTool) handleDropContinuousAggregate(ctx context.Context, req
 		return nil, fmt.Errorf("view_name parameter is required")
 	}
 
+	view, err := resolveTimescaleObject(request, "view_name", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Extract optional parameters
 	cascade := getBoolParam(request.Parameters, "cascade")
 
 	// Build the SQL statement to drop a continuous aggregate
-	sql := fmt.Sprintf("DROP MATERIALIZED VIEW %s", viewName)
+	sql := fmt.Sprintf("DROP MATERIALIZED VIEW %s", view.ident())
 
 	if cascade {
 		sql += " CASCADE"
 	}
 
 	// Execute the statement
-	_, err := useCase.ExecuteStatement(ctx, dbID, sql, nil)
+	_, err = useCase.ExecuteStatement(ctx, dbID, sql, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to drop continuous aggregate: %w", err)
 	}
@@ -1470,11 +1584,19 @@ func (t *TimescaleDBTool) handleListContinuousAggregates(ctx context.Context, re
 		return nil, fmt.Errorf("TimescaleDB operations are only supported on PostgreSQL databases")
 	}
 
+	schema, err := useCase.ResolveSchema(dbID, getStringParam(request.Parameters, "schema"))
+	if err != nil {
+		return nil, err
+	}
+
 	// Build the SQL query to list continuous aggregates
 	sql := `
 		SELECT view_name, source_table, time_column, bucket_interval, aggregations, where_condition, with_data, refresh_policy, refresh_interval
 		FROM timescaledb_information.continuous_aggregates
 	`
+	if schema != "" {
+		sql += "WHERE view_schema = " + db.QuoteLiteral(schema)
+	}
 
 	// Execute the statement
 	result, err := useCase.ExecuteStatement(ctx, dbID, sql, nil)
@@ -1496,6 +1618,11 @@ func (t *TimescaleDBTool) handleGetContinuousAggregateInfo(ctx context.Context,
 		return nil, fmt.Errorf("view_name parameter is required")
 	}
 
+	view, err := resolveTimescaleObject(request, "view_name", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if the database is PostgreSQL (TimescaleDB requires PostgreSQL)
 	dbType, err := useCase.GetDatabaseType(dbID)
 	if err != nil {
@@ -1521,8 +1648,8 @@ func (t *TimescaleDBTool) handleGetContinuousAggregateInfo(ctx context.Context,
 		FROM 
 			timescaledb_information.continuous_aggregates
 		WHERE 
-			view_name = '%s'
-	`, viewName)
+			%s
+	`, view.filter("view_schema", "view_name"))
 
 	// Execute the statement
 	result, err := useCase.ExecuteStatement(ctx, dbID, sql, nil)
@@ -1559,6 +1686,11 @@ func (t *TimescaleDBTool) handleAddContinuousAggregatePolicy(ctx context.Context
 		return nil, fmt.Errorf("schedule_interval parameter is required")
 	}
 
+	view, err := resolveTimescaleObject(request, "view_name", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if the database is PostgreSQL (TimescaleDB requires PostgreSQL)
 	dbType, err := useCase.GetDatabaseType(dbID)
 	if err != nil {
@@ -1570,8 +1702,8 @@ func (t *TimescaleDBTool) handleAddContinuousAggregatePolicy(ctx context.Context
 	}
 
 	// Build the SQL statement to add a continuous aggregate policy
-	sql := fmt.Sprintf("SELECT add_continuous_aggregate_policy('%s', start_offset => INTERVAL '%s', end_offset => INTERVAL '%s', schedule_interval => INTERVAL '%s')",
-		viewName, startOffset, endOffset, scheduleInterval)
+	sql := fmt.Sprintf("SELECT add_continuous_aggregate_policy(%s, start_offset => INTERVAL '%s', end_offset => INTERVAL '%s', schedule_interval => INTERVAL '%s')",
+		view.regclass(), startOffset, endOffset, scheduleInterval)
 
 	// Execute the statement
 	_, err = useCase.ExecuteStatement(ctx, dbID, sql, nil)
@@ -1592,6 +1724,11 @@ func (t *TimescaleDBTool) handleRemoveContinuousAggregatePolicy(ctx context.Cont
 		return nil, fmt.Errorf("view_name parameter is required")
 	}
 
+	view, err := resolveTimescaleObject(request, "view_name", dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	// Check if the database is PostgreSQL (TimescaleDB requires PostgreSQL)
 	dbType, err := useCase.GetDatabaseType(dbID)
 	if err != nil {
@@ -1603,7 +1740,7 @@ func (t *TimescaleDBTool) handleRemoveContinuousAggregatePolicy(ctx context.Cont
 	}
 
 	// Build the SQL statement to remove a continuous aggregate policy
-	sql := fmt.Sprintf("SELECT remove_continuous_aggregate_policy('%s')", viewName)
+	sql := fmt.Sprintf("SELECT remove_continuous_aggregate_policy(%s)", view.regclass())
 
 	// Execute the statement
 	_, err = useCase.ExecuteStatement(ctx, dbID, sql, nil)
@@ -1633,12 +1770,12 @@ func getBoolParam(params map[string]interface{}, key string) bool {
 }
 
 // buildCreateHypertableSQL constructs the SQL statement to create a hypertable
-func buildCreateHypertableSQL(table, timeColumn, chunkTimeInterval, partitioningColumn string, ifNotExists bool) string {
+func buildCreateHypertableSQL(table timescaleObject, timeColumn, chunkTimeInterval, partitioningColumn string, ifNotExists bool) string {
 	var args []string
 
 	// Add required arguments: table name and time column
-	args = append(args, fmt.Sprintf("'%s'", table))
-	args = append(args, fmt.Sprintf("'%s'", timeColumn))
+	args = append(args, table.regclass())
+	args = append(args, db.QuoteLiteral(timeColumn))
 
 	// Build optional parameters
 	var options []string
@@ -1648,7 +1785,7 @@ func buildCreateHypertableSQL(table, timeColumn, chunkTimeInterval, partitioning
 	}
 
 	if partitioningColumn != "" {
-		options = append(options, fmt.Sprintf("partitioning_column => '%s'", partitioningColumn))
+		options = append(options, fmt.Sprintf("partitioning_column => %s", db.QuoteLiteral(partitioningColumn)))
 	}
 
 	options = append(options, fmt.Sprintf("if_not_exists => %t", ifNotExists))
@@ -1665,6 +1802,56 @@ func buildCreateHypertableSQL(table, timeColumn, chunkTimeInterval, partitioning
 	return sql
 }
 
+// timescaleObject is a table or view targeted by a TimescaleDB operation. An
+// empty schema leaves resolution to the connection's search_path.
+type timescaleObject struct {
+	schema string
+	name   string
+}
+
+// resolveTimescaleObject reads a possibly qualified table or view name from a
+// request parameter and resolves its schema against the schema parameter and
+// the connection's default_schema and schema_allowlist
+func resolveTimescaleObject(request server.ToolCallRequest, param, dbID string, useCase UseCaseProvider) (timescaleObject, error) {
+	schema, name, err := db.ParseQualifiedName(getStringParam(request.Parameters, param))
+	if err != nil {
+		return timescaleObject{}, fmt.Errorf("invalid %s: %w", param, err)
+	}
+
+	requested := getStringParam(request.Parameters, "schema")
+	if schema != "" && requested != "" && schema != requested {
+		return timescaleObject{}, fmt.Errorf("%s is qualified with schema %q but schema parameter is %q", param, schema, requested)
+	}
+	if schema == "" {
+		schema = requested
+	}
+
+	schema, err = useCase.ResolveSchema(dbID, schema)
+	if err != nil {
+		return timescaleObject{}, err
+	}
+	return timescaleObject{schema: schema, name: name}, nil
+}
+
+// ident returns the object as a quoted, qualified SQL identifier
+func (o timescaleObject) ident() string {
+	return db.QuoteQualifiedName("postgres", o.schema, o.name)
+}
+
+// regclass returns the object as a string literal for regclass arguments
+func (o timescaleObject) regclass() string {
+	return db.QuoteLiteral(o.ident())
+}
+
+// filter returns a condition matching the object in a catalog view
+func (o timescaleObject) filter(schemaColumn, nameColumn string) string {
+	cond := fmt.Sprintf("%s = %s", nameColumn, db.QuoteLiteral(o.name))
+	if o.schema != "" {
+		cond = fmt.Sprintf("%s = %s AND %s", schemaColumn, db.QuoteLiteral(o.schema), cond)
+	}
+	return cond
+}
+
 // RegisterTimescaleDBTools registers TimescaleDB tools
 func RegisterTimescaleDBTools(registry interface{}) error {
 	// Cast the registry to the expected type
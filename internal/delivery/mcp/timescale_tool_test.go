@@ -2,6 +2,8 @@ package mcp
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/FreePeak/cortex/pkg/server"
@@ -262,3 +264,78 @@ func TestHandleNonPostgresDB(t *testing.T) {
 	// Verify mock expectations
 	mockUseCase.AssertExpectations(t)
 }
+
+func TestHandleCreateHypertableQualifiedName(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetDatabaseType", "test_db").Return("postgres", nil)
+	mockUseCase.On("ResolveSchema", "test_db", "Sales").Return("Sales", nil)
+	mockUseCase.On("ExecuteStatement", mock.Anything, "test_db",
+		`SELECT create_hypertable('"Sales"."metrics"', 'ts', partitioning_column => 'device''s', if_not_exists => false)`,
+		mock.Anything).Return(`{"result": "success"}`, nil)
+
+	request := server.ToolCallRequest{
+		Parameters: map[string]interface{}{
+			"operation":           "create_hypertable",
+			"target_table":        `"Sales".metrics`,
+			"time_column":         "ts",
+			"partitioning_column": "device's",
+		},
+	}
+
+	_, err := NewTimescaleDBTool().HandleRequest(context.Background(), request, "test_db", mockUseCase)
+	assert.NoError(t, err)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestHandleTimeSeriesQueryUsesSchemaParameter(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("ExecuteStatement", mock.Anything, "test_db", mock.MatchedBy(func(sql string) bool {
+		return strings.Contains(sql, `"analytics"."metrics"`) && strings.Contains(sql, `time_bucket('1 hour', "Time")`)
+	}), mock.Anything).Return(`[]`, nil)
+
+	request := server.ToolCallRequest{
+		Parameters: map[string]interface{}{
+			"operation":       "time_series_query",
+			"schema":          "analytics",
+			"target_table":    "metrics",
+			"time_column":     "Time",
+			"bucket_interval": "1 hour",
+		},
+	}
+
+	_, err := NewTimescaleDBTool().HandleRequest(context.Background(), request, "test_db", mockUseCase)
+	assert.NoError(t, err)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestHandleTimescaleSchemaErrors(t *testing.T) {
+	tool := NewTimescaleDBTool()
+
+	// Qualified name conflicting with the schema parameter
+	_, err := tool.HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{
+			"operation":    "enable_compression",
+			"schema":       "public",
+			"target_table": "sales.metrics",
+		},
+	}, "test_db", new(MockDatabaseUseCase))
+	assert.Error(t, err)
+
+	// Schema rejected by the connection's allowlist
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("ResolveSchema", "test_db", "hr").Return("", fmt.Errorf(`schema "hr" is not allowed for connection test_db`))
+	_, err = tool.HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{
+			"operation": "drop_continuous_aggregate",
+			"view_name": "hr.daily",
+		},
+	}, "test_db", mockUseCase)
+	assert.EqualError(t, err, `schema "hr" is not allowed for connection test_db`)
+}
+
+func TestTimescaleObjectFilter(t *testing.T) {
+	assert.Equal(t, "hypertable_name = 'metrics'", timescaleObject{name: "metrics"}.filter("hypertable_schema", "hypertable_name"))
+	assert.Equal(t, "hypertable_schema = 'sales' AND hypertable_name = 'o''brien'",
+		timescaleObject{schema: "sales", name: "o'brien"}.filter("hypertable_schema", "hypertable_name"))
+	assert.Equal(t, `'"sales"."Metrics"'`, timescaleObject{schema: "sales", name: "Metrics"}.regclass())
+}
@@ -8,7 +8,9 @@ package mcp
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/FreePeak/cortex/pkg/server"
 
@@ -21,6 +23,27 @@ type ToolRegistry struct {
 	mcpServer       *server.MCPServer
 	databaseUseCase UseCaseProvider
 	factory         *ToolTypeFactory
+	readOnly        bool
+	calls           *CallTracker
+}
+
+// globalToolTypes are registered once under their own name rather than per
+// database
+var globalToolTypes = map[string]bool{
+	"list_databases": true,
+	"schema_diff":    true,
+	"migrate_status": true,
+	"migrate_plan":   true,
+	"migrate_up":     true,
+	"migrate_down":   true,
+}
+
+// writeToolTypes are the tool types skipped when the registry is read-only
+var writeToolTypes = map[string]bool{
+	"execute":      true,
+	"transaction":  true,
+	"migrate_up":   true,
+	"migrate_down": true,
 }
 
 // NewToolRegistry creates a new tool registry
@@ -30,9 +53,25 @@ func NewToolRegistry(mcpServer *server.MCPServer) *ToolRegistry {
 		server:    NewServerWrapper(mcpServer),
 		mcpServer: mcpServer,
 		factory:   factory,
+		calls:     NewCallTracker(),
 	}
 }
 
+// Calls returns the tracker that lets clients cancel the registry's tool calls
+func (tr *ToolRegistry) Calls() *CallTracker {
+	return tr.calls
+}
+
+// ToolNames returns the names of the tools registered so far
+func (tr *ToolRegistry) ToolNames() []string {
+	return tr.server.ToolNames()
+}
+
+// SetReadOnly controls whether tools that modify data are registered
+func (tr *ToolRegistry) SetReadOnly(readOnly bool) {
+	tr.readOnly = readOnly
+}
+
 // RegisterAllTools registers all tools with the server
 func (tr *ToolRegistry) RegisterAllTools(ctx context.Context, useCase UseCaseProvider) error {
 	tr.databaseUseCase = useCase
@@ -69,9 +108,7 @@ func (tr *ToolRegistry) RegisterAllTools(ctx context.Context, useCase UseCasePro
 // registerDatabaseTools registers all tools for a specific database
 func (tr *ToolRegistry) registerDatabaseTools(ctx context.Context, dbID string) error {
 	// Get all tool types from the factory
-	toolTypeNames := []string{
-		"query", "execute", "transaction", "performance", "schema",
-	}
+	toolTypeNames := tr.databaseToolTypeNames()
 
 	logger.Info("Registering tools for database %s", dbID)
 
@@ -116,10 +153,7 @@ func (tr *ToolRegistry) registerDatabaseTools(ctx context.Context, dbID string)
 			// Register time series query tool
 			tsQueryToolName := fmt.Sprintf("timescaledb_timeseries_query_%s", dbID)
 			tsQueryTool := timescaleTool.CreateTimeSeriesQueryTool(tsQueryToolName, dbID)
-			if err := tr.server.AddTool(ctx, tsQueryTool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
-				response, err := timescaleTool.HandleRequest(ctx, request, dbID, tr.databaseUseCase)
-				return FormatResponse(response, err)
-			}); err != nil {
+			if err := tr.server.AddTool(ctx, tsQueryTool, tr.toolHandler(dbID, timescaleTool.HandleRequest)); err != nil {
 				logger.Error("Error registering TimescaleDB time series query tool: %v", err)
 				registrationErrors++
 			} else {
@@ -129,10 +163,7 @@ func (tr *ToolRegistry) registerDatabaseTools(ctx context.Context, dbID string)
 			// Register time series analyze tool
 			tsAnalyzeToolName := fmt.Sprintf("timescaledb_analyze_timeseries_%s", dbID)
 			tsAnalyzeTool := timescaleTool.CreateTimeSeriesAnalyzeTool(tsAnalyzeToolName, dbID)
-			if err := tr.server.AddTool(ctx, tsAnalyzeTool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
-				response, err := timescaleTool.HandleRequest(ctx, request, dbID, tr.databaseUseCase)
-				return FormatResponse(response, err)
-			}); err != nil {
+			if err := tr.server.AddTool(ctx, tsAnalyzeTool, tr.toolHandler(dbID, timescaleTool.HandleRequest)); err != nil {
 				logger.Error("Error registering TimescaleDB time series analyze tool: %v", err)
 				registrationErrors++
 			} else {
@@ -150,7 +181,7 @@ func (tr *ToolRegistry) registerDatabaseTools(ctx context.Context, dbID string)
 
 	// For other database types, continue with the normal approach
 	// Check if this database actually exists
-	dbInfo, err := tr.databaseUseCase.GetDatabaseInfo(dbID)
+	dbInfo, err := tr.databaseUseCase.GetDatabaseInfo(ctx, dbID)
 	if err != nil {
 		return fmt.Errorf("failed to get database info for %s: %w", dbID, err)
 	}
@@ -178,6 +209,19 @@ func (tr *ToolRegistry) registerDatabaseTools(ctx context.Context, dbID string)
 	return nil
 }
 
+// databaseToolTypeNames returns the per-database tool types to register
+func (tr *ToolRegistry) databaseToolTypeNames() []string {
+	names := make([]string, 0, 10)
+	for _, name := range []string{"query", "execute", "transaction", "performance", "schema", "join_path", "index_advice", "locks", "activity", "storage"} {
+		if tr.readOnly && writeToolTypes[name] {
+			logger.Info("Read-only mode: skipping %s tools", name)
+			continue
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
 // registerTool registers a tool with the server
 func (tr *ToolRegistry) registerTool(ctx context.Context, toolTypeName string, name string, dbID string) error {
 	logger.Info("Registering tool '%s' of type '%s' (database: %s)", name, toolTypeName, dbID)
@@ -189,23 +233,86 @@ func (tr *ToolRegistry) registerTool(ctx context.Context, toolTypeName string, n
 
 	tool := toolTypeImpl.CreateTool(name, dbID)
 
-	return tr.server.AddTool(ctx, tool, func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
-		response, err := toolTypeImpl.HandleRequest(ctx, request, dbID, tr.databaseUseCase)
+	return tr.server.AddTool(ctx, tool, tr.toolHandler(dbID, toolTypeImpl.HandleRequest))
+}
+
+// toolHandler wraps the handler of a tool. The call can be cancelled by the
+// client and, for the tools of a database, ends after the connection's query
+// timeout, which stops the statement it is running. Global tools bound the
+// work on each connection they name with withQueryTimeout.
+func (tr *ToolRegistry) toolHandler(dbID string, handle func(context.Context, server.ToolCallRequest, string, UseCaseProvider) (interface{}, error)) func(context.Context, server.ToolCallRequest) (interface{}, error) {
+	return func(ctx context.Context, request server.ToolCallRequest) (interface{}, error) {
+		ctx, done := tr.calls.Start(ctx, request.Name)
+		defer done()
+
+		if dbID != "" {
+			timeout, err := tr.databaseUseCase.GetQueryTimeout(dbID)
+			if err != nil {
+				return FormatResponse(nil, err)
+			}
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		started := time.Now()
+		response, err := handle(ctx, request, dbID, tr.databaseUseCase)
+		if err != nil {
+			err = callError(ctx, request.Name, time.Since(started), err)
+		}
 		return FormatResponse(response, err)
-	})
+	}
+}
+
+// withQueryTimeout runs part of a global tool call against a connection,
+// ending it after the connection's query timeout as toolHandler does for the
+// tools of a database
+func withQueryTimeout(ctx context.Context, useCase UseCaseProvider, dbID, name string, run func(context.Context) error) error {
+	timeout, err := useCase.GetQueryTimeout(dbID)
+	if err != nil {
+		return err
+	}
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	started := time.Now()
+	err = run(callCtx)
+	if err != nil && ctx.Err() == nil {
+		// toolHandler explains the call's own cancellation
+		err = callError(callCtx, name, time.Since(started), err)
+	}
+	return err
+}
+
+// callError explains why a tool call whose context ended failed
+func callError(ctx context.Context, name string, elapsed time.Duration, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s timed out after %s: %w", name, elapsed.Round(time.Millisecond), err)
+	}
+	if ctx.Err() != nil {
+		if cause := context.Cause(ctx); errors.Is(cause, ErrCallCancelled) {
+			return fmt.Errorf("%s was %v: %w", name, cause, err)
+		}
+		return fmt.Errorf("%s was cancelled: %w", name, err)
+	}
+	return err
 }
 
 // registerCommonTools registers tools that are not specific to a database
 func (tr *ToolRegistry) registerCommonTools(ctx context.Context) {
-	// Register the list_databases tool with simple name
-	_, ok := tr.factory.GetToolType("list_databases")
-	if ok {
-		// Use simple name for list_databases tool
-		listDbName := "list_databases"
-		if err := tr.registerTool(ctx, "list_databases", listDbName, ""); err != nil {
-			logger.Error("Error registering %s tool: %v", listDbName, err)
+	for _, name := range []string{"list_databases", "schema_diff", "migrate_status", "migrate_plan", "migrate_up", "migrate_down"} {
+		if _, ok := tr.factory.GetToolType(name); !ok {
+			continue
+		}
+		if tr.readOnly && writeToolTypes[name] {
+			logger.Info("Read-only mode: skipping %s tool", name)
+			continue
+		}
+		// Global tools use their type name as the tool name
+		if err := tr.registerTool(ctx, name, name, ""); err != nil {
+			logger.Error("Error registering %s tool: %v", name, err)
 		} else {
-			logger.Info("Successfully registered tool %s", listDbName)
+			logger.Info("Successfully registered tool %s", name)
 		}
 	}
 }
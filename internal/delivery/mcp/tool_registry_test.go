@@ -0,0 +1,60 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+)
+
+func TestDatabaseToolTypeNamesReadOnly(t *testing.T) {
+	logger.Initialize("error")
+
+	tr := &ToolRegistry{factory: NewToolTypeFactory()}
+	assert.Equal(t, []string{"query", "execute", "transaction", "performance", "schema", "join_path", "index_advice", "locks", "activity", "storage"}, tr.databaseToolTypeNames())
+
+	tr.SetReadOnly(true)
+	assert.Equal(t, []string{"query", "performance", "schema", "join_path", "index_advice", "locks", "activity", "storage"}, tr.databaseToolTypeNames())
+}
+
+func TestToolTypeFactoryGlobalTools(t *testing.T) {
+	factory := NewToolTypeFactory()
+
+	toolType, ok := factory.GetToolType("schema_diff")
+	assert.True(t, ok)
+	assert.Equal(t, "schema_diff", toolType.GetName())
+
+	toolType, ok = factory.GetToolType("schema_test_db")
+	assert.True(t, ok)
+	assert.Equal(t, "schema", toolType.GetName())
+
+	toolType, dbID, ok := factory.GetToolTypeForSourceName("schema_diff")
+	assert.True(t, ok)
+	assert.Equal(t, "schema_diff", toolType.GetName())
+	assert.Empty(t, dbID)
+
+	toolType, dbID, ok = factory.GetToolTypeForSourceName("join_path_test_db")
+	assert.True(t, ok)
+	assert.Equal(t, "join_path", toolType.GetName())
+	assert.Equal(t, "test_db", dbID)
+
+	toolType, dbID, ok = factory.GetToolTypeForSourceName("migrate_up")
+	assert.True(t, ok)
+	assert.Equal(t, "migrate_up", toolType.GetName())
+	assert.Empty(t, dbID)
+}
+
+func TestToolNamesFollowRegistration(t *testing.T) {
+	logger.Initialize("error")
+
+	tr := NewToolRegistry(server.NewMCPServer("test", "1.0.0", nil))
+	tr.SetReadOnly(true)
+	tr.registerCommonTools(context.Background())
+	assert.Equal(t, []string{"list_databases", "schema_diff", "migrate_status", "migrate_plan"}, tr.ToolNames())
+
+	assert.NoError(t, tr.registerTool(context.Background(), "locks", "locks_test_db", "test_db"))
+	assert.Equal(t, "locks_test_db", tr.ToolNames()[4])
+}
@@ -2,11 +2,15 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/FreePeak/cortex/pkg/server"
 	"github.com/FreePeak/cortex/pkg/tools"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
 )
 
 // createTextResponse creates a simple response with a text content
@@ -65,7 +69,28 @@ type UseCaseProvider interface {
 	ExecuteQuery(ctx context.Context, dbID, query string, params []interface{}) (string, error)
 	ExecuteStatement(ctx context.Context, dbID, statement string, params []interface{}) (string, error)
 	ExecuteTransaction(ctx context.Context, dbID, action string, txID string, statement string, params []interface{}, readOnly bool) (string, map[string]interface{}, error)
-	GetDatabaseInfo(dbID string) (map[string]interface{}, error)
+	GetDatabaseInfo(ctx context.Context, dbID string) (map[string]interface{}, error)
+	GetQueryTimeout(dbID string) (time.Duration, error)
+	WithStatementTimeout(ctx context.Context, dbID string, timeout time.Duration) (context.Context, error)
+	GetSchemaComponent(ctx context.Context, dbID, component, schema, table string) (map[string]interface{}, error)
+	GetSchemaSummary(ctx context.Context, dbID, schema, question string, maxChars int) (string, error)
+	GetSchemaERD(ctx context.Context, dbID, schema, table, format string, depth int) (string, error)
+	GetSchemaSnapshot(ctx context.Context, dbID, schema string) (*domain.SchemaSnapshot, error)
+	GetJoinPath(ctx context.Context, dbID, schema string, tables []string, withQuery bool) (*domain.JoinPath, error)
+	MigrationStatus(ctx context.Context, dbID string) (*domain.MigrationStatus, error)
+	PlanMigrations(ctx context.Context, dbID, direction string, steps int) (*domain.MigrationPlan, error)
+	Migrate(ctx context.Context, dbID, direction string, steps int) (*domain.MigrationPlan, error)
+	GetPerformanceAnalyzer(dbID string) (domain.PerformanceAnalyzer, error)
+	AnalyzeQuery(ctx context.Context, dbID, query string) (*domain.QueryAnalysis, error)
+	GetWorkloadStatistics(ctx context.Context, dbID, orderBy string, limit int) (*domain.WorkloadStatistics, error)
+	AdviseIndexes(ctx context.Context, dbID, schema string, opts domain.IndexAdviceOptions) (*domain.IndexAdvice, error)
+	GetStorageStats(ctx context.Context, dbID, schema string, opts domain.StorageOptions) (*domain.StorageReport, error)
+	GetLockWaits(ctx context.Context, dbID string) ([]domain.LockWait, error)
+	SignalBlockingSession(ctx context.Context, dbID string, pid int64, signal string) error
+	GetActivity(ctx context.Context, dbID string, filter domain.ActivityFilter) ([]domain.SessionActivity, error)
+	CancelQuery(ctx context.Context, dbID string, pid int64) error
+	ResolveSchema(dbID, schema string) (string, error)
+	InvalidateSchemaCache(dbID string)
 	ListDatabases() []string
 	GetDatabaseType(dbID string) (string, error)
 }
@@ -118,6 +143,7 @@ func (t *QueryTool) CreateTool(name string, dbID string) interface{} {
 			tools.Description("Query parameters"),
 			tools.Items(map[string]interface{}{"type": "string"}),
 		),
+		statementTimeoutOption(),
 	)
 }
 
@@ -140,6 +166,11 @@ func (t *QueryTool) HandleRequest(ctx context.Context, request server.ToolCallRe
 		}
 	}
 
+	ctx, err := withStatementTimeout(ctx, request, dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	result, err := useCase.ExecuteQuery(ctx, dbID, query, queryParams)
 	if err != nil {
 		return nil, err
@@ -148,6 +179,23 @@ func (t *QueryTool) HandleRequest(ctx context.Context, request server.ToolCallRe
 	return createTextResponse(result), nil
 }
 
+// statementTimeoutOption declares the statement_timeout_ms parameter
+func statementTimeoutOption() tools.ToolOption {
+	return tools.WithNumber("statement_timeout_ms",
+		tools.Description("Milliseconds after which the database server stops the statement, overriding the connection's statement_timeout_ms up to its max_statement_timeout_ms. On MySQL only SELECT statements are limited"),
+	)
+}
+
+// withStatementTimeout applies the statement_timeout_ms parameter of a
+// request to the context of its statements
+func withStatementTimeout(ctx context.Context, request server.ToolCallRequest, dbID string, useCase UseCaseProvider) (context.Context, error) {
+	value, ok := request.Parameters["statement_timeout_ms"].(float64)
+	if !ok {
+		return ctx, nil
+	}
+	return useCase.WithStatementTimeout(ctx, dbID, time.Duration(value*float64(time.Millisecond)))
+}
+
 // extractDatabaseIDFromName extracts the database ID from a tool name
 func extractDatabaseIDFromName(name string) string {
 	// Format is: <tooltype>_<dbID>
@@ -192,6 +240,7 @@ func (t *ExecuteTool) CreateTool(name string, dbID string) interface{} {
 			tools.Description("Statement parameters"),
 			tools.Items(map[string]interface{}{"type": "string"}),
 		),
+		statementTimeoutOption(),
 	)
 }
 
@@ -214,6 +263,11 @@ func (t *ExecuteTool) HandleRequest(ctx context.Context, request server.ToolCall
 		}
 	}
 
+	ctx, err := withStatementTimeout(ctx, request, dbID, useCase)
+	if err != nil {
+		return nil, err
+	}
+
 	result, err := useCase.ExecuteStatement(ctx, dbID, statement, statementParams)
 	if err != nil {
 		return nil, err
@@ -342,7 +396,7 @@ func NewPerformanceTool() *PerformanceTool {
 	return &PerformanceTool{
 		BaseToolType: BaseToolType{
 			name:        "performance",
-			description: "Analyze query performance",
+			description: "Analyze the performance of the queries run on the database",
 		},
 	}
 }
@@ -353,9 +407,12 @@ func (t *PerformanceTool) CreateTool(name string, dbID string) interface{} {
 		name,
 		tools.WithDescription(t.GetDescription(dbID)),
 		tools.WithString("action",
-			tools.Description("Action (getSlowQueries, getMetrics, analyzeQuery, reset, setThreshold)"),
+			tools.Description("Action (getSlowQueries, getTopQueries, getStatementStats, getMetrics, analyzeQuery, getPlanRegressions, acceptPlan, reset, setThreshold)"),
 			tools.Required(),
 		),
+		tools.WithString("orderBy",
+			tools.Description("Ranking for getTopQueries: total_time (default), mean_time, max_time, p95_time, calls, errors or rows. For getStatementStats: total_time (default), mean_time, calls, rows or io"),
+		),
 		tools.WithString("query",
 			tools.Description("SQL query to analyze (required for analyzeQuery)"),
 		),
@@ -365,64 +422,260 @@ func (t *PerformanceTool) CreateTool(name string, dbID string) interface{} {
 		tools.WithNumber("threshold",
 			tools.Description("Slow query threshold in milliseconds (required for setThreshold)"),
 		),
+		tools.WithString("fingerprint",
+			tools.Description("Query fingerprint whose latest plan becomes its baseline (required for acceptPlan)"),
+		),
 	)
 }
 
-// HandleRequest handles performance tool requests
+// HandleRequest handles performance tool requests against the analyzer of
+// the queries run on the database
 func (t *PerformanceTool) HandleRequest(ctx context.Context, request server.ToolCallRequest, dbID string, useCase UseCaseProvider) (interface{}, error) {
 	// If dbID is not provided, extract it from the tool name
 	if dbID == "" {
 		dbID = extractDatabaseIDFromName(request.Name)
 	}
 
-	// This is a simplified implementation
-	// In a real implementation, this would analyze query performance
-
 	action, ok := request.Parameters["action"].(string)
 	if !ok {
 		return nil, fmt.Errorf("action parameter must be a string")
 	}
 
-	var limit int
-	if request.Parameters["limit"] != nil {
-		if limitParam, ok := request.Parameters["limit"].(float64); ok {
-			limit = int(limitParam)
+	if action == "analyzeQuery" {
+		query, ok := request.Parameters["query"].(string)
+		if !ok || strings.TrimSpace(query) == "" {
+			return nil, fmt.Errorf("query parameter is required for analyzeQuery")
+		}
+		analysis, err := useCase.AnalyzeQuery(ctx, dbID, query)
+		if err != nil {
+			return nil, err
+		}
+		return formatQueryAnalysis(analysis), nil
+	}
+
+	limit := 10
+	if value, ok := request.Parameters["limit"].(float64); ok && value > 0 {
+		limit = int(value)
+	}
+
+	if action == "getStatementStats" {
+		stats, err := useCase.GetWorkloadStatistics(ctx, dbID, getStringParam(request.Parameters, "orderBy"), limit)
+		if err != nil {
+			return nil, err
 		}
+		if !stats.Available {
+			resp := createTextResponse(fmt.Sprintf("Statement statistics are not available on %s: %s", dbID, stats.Reason))
+			addMetadata(resp, "available", false)
+			return resp, nil
+		}
+		resp := formatTopQueries(fmt.Sprintf("Top statements on %s from %s by %s", dbID, stats.Source, stats.OrderBy), stats.Queries)
+		addMetadata(resp, "source", stats.Source)
+		return resp, nil
 	}
 
-	query := ""
-	if request.Parameters["query"] != nil {
-		var ok bool
-		query, ok = request.Parameters["query"].(string)
+	analyzer, err := useCase.GetPerformanceAnalyzer(dbID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch action {
+	case "getSlowQueries":
+		slow, err := analyzer.GetSlowQueries(limit)
+		if err != nil {
+			return nil, err
+		}
+		metrics, err := analyzer.GetMetrics()
+		if err != nil {
+			return nil, err
+		}
+		return formatSlowQueries(dbID, slow, metrics.Threshold), nil
+
+	case "getTopQueries":
+		orderBy := getStringParam(request.Parameters, "orderBy")
+		if orderBy == "" {
+			orderBy = "total_time"
+		}
+		top, err := analyzer.GetTopQueries(orderBy, limit)
+		if err != nil {
+			return nil, err
+		}
+		return formatTopQueries(fmt.Sprintf("Top queries on %s by %s", dbID, orderBy), top), nil
+
+	case "getMetrics":
+		metrics, err := analyzer.GetMetrics()
+		if err != nil {
+			return nil, err
+		}
+		output := fmt.Sprintf("Query performance on %s since %s:\n\n", dbID, metrics.LastResetTime)
+		output += fmt.Sprintf("Queries: %d\n", metrics.TotalQueries)
+		output += fmt.Sprintf("Average duration: %.2f ms\n", metrics.AvgDuration)
+		output += fmt.Sprintf("Max duration: %.2f ms\n", metrics.MaxDuration)
+		output += fmt.Sprintf("Slow queries: %d (threshold %d ms)\n", metrics.SlowQueries, metrics.Threshold)
+		output += fmt.Sprintf("Errors: %d\n", metrics.Errors)
+		resp := createTextResponse(output)
+		addMetadata(resp, "metrics", metrics)
+		return resp, nil
+
+	case "getPlanRegressions":
+		regressions, err := analyzer.GetPlanRegressions()
+		if err != nil {
+			return nil, err
+		}
+		return formatPlanRegressions(dbID, regressions), nil
+
+	case "acceptPlan":
+		fingerprint := getStringParam(request.Parameters, "fingerprint")
+		if fingerprint == "" {
+			return nil, fmt.Errorf("fingerprint parameter is required for acceptPlan")
+		}
+		if err := analyzer.AcceptPlan(fingerprint); err != nil {
+			return nil, err
+		}
+		return createTextResponse(fmt.Sprintf("The latest plan of %s is now its baseline on %s", fingerprint, dbID)), nil
+
+	case "reset":
+		if err := analyzer.Reset(); err != nil {
+			return nil, err
+		}
+		return createTextResponse(fmt.Sprintf("Performance history of %s cleared", dbID)), nil
+
+	case "setThreshold":
+		threshold, ok := request.Parameters["threshold"].(float64)
 		if !ok {
-			return nil, fmt.Errorf("query parameter must be a string")
+			return nil, fmt.Errorf("threshold parameter is required for setThreshold")
 		}
+		if err := analyzer.SetThreshold(int(threshold)); err != nil {
+			return nil, err
+		}
+		return createTextResponse(fmt.Sprintf("Slow query threshold of %s set to %d ms", dbID, int(threshold))), nil
+
+	default:
+		return nil, fmt.Errorf("invalid performance action: %s", action)
+	}
+}
+
+// formatSlowQueries lists slow queries, slowest first
+func formatSlowQueries(dbID string, slow []domain.SlowQuery, threshold int) map[string]interface{} {
+	if len(slow) == 0 {
+		resp := createTextResponse(fmt.Sprintf("No queries on %s reached the slow query threshold of %d ms", dbID, threshold))
+		addMetadata(resp, "count", 0)
+		return resp
 	}
 
-	var threshold int
-	if request.Parameters["threshold"] != nil {
-		if thresholdParam, ok := request.Parameters["threshold"].(float64); ok {
-			threshold = int(thresholdParam)
+	var output strings.Builder
+	fmt.Fprintf(&output, "Slowest queries on %s (threshold %d ms):\n\n", dbID, threshold)
+	for i, query := range slow {
+		fmt.Fprintf(&output, "%d. %.2f ms at %s\n   %s\n", i+1, query.Duration, query.Timestamp, query.Query)
+		if query.Error != "" {
+			fmt.Fprintf(&output, "   error: %s\n", query.Error)
 		}
 	}
+	resp := createTextResponse(output.String())
+	addMetadata(resp, "count", len(slow))
+	return resp
+}
 
-	// This is where we would call the useCase to analyze performance
-	// For now, just return a placeholder
-	output := fmt.Sprintf("Performance analysis for action '%s' on database '%s'\n", action, dbID)
+// formatPlanRegressions lists the queries whose plan regressed with the
+// baseline and latest plans
+func formatPlanRegressions(dbID string, regressions []domain.PlanRegression) map[string]interface{} {
+	if len(regressions) == 0 {
+		resp := createTextResponse(fmt.Sprintf("No plan regressions on %s", dbID))
+		addMetadata(resp, "count", 0)
+		return resp
+	}
 
-	if query != "" {
-		output += fmt.Sprintf("Query: %s\n", query)
+	var output strings.Builder
+	fmt.Fprintf(&output, "Plan regressions on %s:\n", dbID)
+	for i, r := range regressions {
+		change := "plan changed shape"
+		if r.Kind == "cost" {
+			change = "plan cost increased"
+		}
+		fmt.Fprintf(&output, "\n%d. [%s] %s, cost %.2f -> %.2f (checked %s)\n   %s\n", i+1, r.Fingerprint, change,
+			r.BaselineCost, r.LatestCost, r.CheckedAt, r.Query)
+		fmt.Fprintf(&output, "   Baseline plan (since %s):\n%s\n", r.BaselineSince, indent(r.BaselinePlan, "     "))
+		fmt.Fprintf(&output, "   Latest plan:\n%s\n", indent(r.LatestPlan, "     "))
 	}
+	resp := createTextResponse(output.String())
+	addMetadata(resp, "count", len(regressions))
+	return resp
+}
+
+// indent prefixes every line of a text
+func indent(text, prefix string) string {
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
 
-	if limit > 0 {
-		output += fmt.Sprintf("Limit: %d\n", limit)
+// formatTopQueries lists query fingerprints with their calls and latencies,
+// and the percentiles and I/O the source reports
+func formatTopQueries(title string, fingerprints []domain.QueryFingerprint) map[string]interface{} {
+	if len(fingerprints) == 0 {
+		resp := createTextResponse(title + ": no queries recorded")
+		addMetadata(resp, "count", 0)
+		return resp
 	}
 
-	if threshold > 0 {
-		output += fmt.Sprintf("Threshold: %d ms\n", threshold)
+	var output strings.Builder
+	output.WriteString(title + ":\n\n")
+	for i, fp := range fingerprints {
+		fmt.Fprintf(&output, "%d. [%s] %d calls, %.2f ms total, %.2f ms mean", i+1, fp.Fingerprint, fp.Calls, fp.TotalTime, fp.MeanTime)
+		switch {
+		case fp.P50Time > 0:
+			fmt.Fprintf(&output, ", p50/p95/p99 %.2f/%.2f/%.2f ms", fp.P50Time, fp.P95Time, fp.P99Time)
+		case fp.P95Time > 0:
+			fmt.Fprintf(&output, ", p95/p99 %.2f/%.2f ms", fp.P95Time, fp.P99Time)
+		}
+		fmt.Fprintf(&output, ", %d rows", fp.Rows)
+		if fp.RowsExamined > 0 {
+			fmt.Fprintf(&output, ", %d rows examined", fp.RowsExamined)
+		}
+		if fp.BlocksHit > 0 || fp.BlocksRead > 0 {
+			fmt.Fprintf(&output, ", %d blocks hit, %d read", fp.BlocksHit, fp.BlocksRead)
+		}
+		if fp.IOTime > 0 {
+			fmt.Fprintf(&output, ", %.2f ms I/O", fp.IOTime)
+		}
+		if fp.Errors > 0 {
+			fmt.Fprintf(&output, ", %d errors", fp.Errors)
+		}
+		fmt.Fprintf(&output, "\n   %s\n", fp.Query)
 	}
+	resp := createTextResponse(output.String())
+	addMetadata(resp, "count", len(fingerprints))
+	addMetadata(resp, "queries", fingerprints)
+	return resp
+}
 
-	return createTextResponse(output), nil
+// formatQueryAnalysis describes a query's recorded executions, suggestions
+// and plan
+func formatQueryAnalysis(analysis *domain.QueryAnalysis) map[string]interface{} {
+	var output strings.Builder
+	fmt.Fprintf(&output, "Analysis of: %s\n\n", analysis.Query)
+	if analysis.Executions > 0 {
+		fmt.Fprintf(&output, "Recorded executions of fingerprint %s: %d (average %.2f ms, p95 %.2f ms, max %.2f ms)\n",
+			analysis.Fingerprint, analysis.Executions, analysis.AvgDuration, analysis.P95Duration, analysis.MaxDuration)
+	} else {
+		output.WriteString("Recorded executions: none\n")
+	}
+	if len(analysis.PlanIssues) > 0 {
+		output.WriteString("\nPlan issues:\n")
+		for _, issue := range analysis.PlanIssues {
+			fmt.Fprintf(&output, "- [%s] %s\n", issue.Kind, issue.Message)
+		}
+	}
+	if len(analysis.Suggestions) > 0 {
+		output.WriteString("\nSuggestions:\n")
+		for _, suggestion := range analysis.Suggestions {
+			fmt.Fprintf(&output, "- %s\n", suggestion)
+		}
+	}
+	if analysis.ExplainPlan != "" {
+		fmt.Fprintf(&output, "\nPlan:\n%s\n", analysis.ExplainPlan)
+	}
+	resp := createTextResponse(output.String())
+	addMetadata(resp, "executions", analysis.Executions)
+	addMetadata(resp, "planIssues", len(analysis.PlanIssues))
+	return resp
 }
 
 //------------------------------------------------------------------------------
@@ -449,7 +702,34 @@ func (t *SchemaTool) CreateTool(name string, dbID string) interface{} {
 	return tools.NewTool(
 		name,
 		tools.WithDescription(t.GetDescription(dbID)),
-		// Use any string parameter for compatibility
+		tools.WithString("component",
+			tools.Description("Schema component (list_schemas, tables, columns, relationships, indexes, constraints, views, sequences, triggers, functions, hypertables, summary, erd, full, ddl). Defaults to tables"),
+		),
+		tools.WithString("schema",
+			tools.Description("Schema (PostgreSQL) or database (MySQL) to inspect. Defaults to the connection's default_schema"),
+		),
+		tools.WithString("table",
+			tools.Description("Table name, optionally qualified as schema.table (required for columns, optional filter for relationships, indexes, constraints and triggers, and the center of an erd diagram)"),
+		),
+		tools.WithBoolean("refresh",
+			tools.Description("Bypass the schema metadata cache and read the catalogs again"),
+		),
+		tools.WithString("question",
+			tools.Description("Question or keywords used to rank tables in the summary component"),
+		),
+		tools.WithNumber("max_tokens",
+			tools.Description("Approximate token budget for the summary component (default 2000)"),
+		),
+		tools.WithNumber("max_chars",
+			tools.Description("Character budget for the summary component; overrides max_tokens"),
+		),
+		tools.WithString("format",
+			tools.Description("Diagram language of the erd component: mermaid (default), plantuml or dot"),
+		),
+		tools.WithNumber("depth",
+			tools.Description("Foreign key hops around table drawn by the erd component (default 1)"),
+		),
+		// Kept for compatibility with clients that send a dummy parameter
 		tools.WithString("random_string",
 			tools.Description("Dummy parameter (optional)"),
 		),
@@ -463,14 +743,132 @@ func (t *SchemaTool) HandleRequest(ctx context.Context, request server.ToolCallR
 		dbID = extractDatabaseIDFromName(request.Name)
 	}
 
-	info, err := useCase.GetDatabaseInfo(dbID)
+	component := "tables"
+	if request.Parameters["component"] != nil {
+		var ok bool
+		component, ok = request.Parameters["component"].(string)
+		if !ok {
+			return nil, fmt.Errorf("component parameter must be a string")
+		}
+	}
+
+	schema := ""
+	if request.Parameters["schema"] != nil {
+		var ok bool
+		schema, ok = request.Parameters["schema"].(string)
+		if !ok {
+			return nil, fmt.Errorf("schema parameter must be a string")
+		}
+	}
+
+	table := ""
+	if request.Parameters["table"] != nil {
+		var ok bool
+		table, ok = request.Parameters["table"].(string)
+		if !ok {
+			return nil, fmt.Errorf("table parameter must be a string")
+		}
+	}
+
+	refresh := false
+	if request.Parameters["refresh"] != nil {
+		var ok bool
+		refresh, ok = request.Parameters["refresh"].(bool)
+		if !ok {
+			return nil, fmt.Errorf("refresh parameter must be a boolean")
+		}
+	}
+	if refresh {
+		useCase.InvalidateSchemaCache(dbID)
+	}
+
+	if component == "summary" {
+		return t.handleSummary(ctx, request, dbID, schema, useCase)
+	}
+	if component == "erd" {
+		return t.handleERD(ctx, request, dbID, schema, table, useCase)
+	}
+
+	info, err := useCase.GetSchemaComponent(ctx, dbID, component, schema, table)
+	if err != nil {
+		return nil, err
+	}
+
+	// DDL is returned as is so it can be saved and executed
+	if ddl, ok := info["ddl"].(string); ok && component == "ddl" {
+		resp := createTextResponse(ddl)
+		addMetadata(resp, "component", component)
+		return resp, nil
+	}
+
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format schema: %w", err)
+	}
+
+	resp := createTextResponse(fmt.Sprintf("Database Schema (%s) for %s:\n\n%s", component, dbID, data))
+	addMetadata(resp, "component", component)
+	return resp, nil
+}
+
+// charsPerToken converts a summary token budget into characters
+const charsPerToken = 4
+
+// handleSummary renders the compact schema summary within the requested budget
+func (t *SchemaTool) handleSummary(ctx context.Context, request server.ToolCallRequest, dbID, schema string, useCase UseCaseProvider) (interface{}, error) {
+	question := ""
+	if request.Parameters["question"] != nil {
+		var ok bool
+		question, ok = request.Parameters["question"].(string)
+		if !ok {
+			return nil, fmt.Errorf("question parameter must be a string")
+		}
+	}
+
+	maxChars := 0
+	if tokens, ok := request.Parameters["max_tokens"].(float64); ok && tokens > 0 {
+		maxChars = int(tokens) * charsPerToken
+	}
+	if chars, ok := request.Parameters["max_chars"].(float64); ok && chars > 0 {
+		maxChars = int(chars)
+	}
+
+	summary, err := useCase.GetSchemaSummary(ctx, dbID, schema, question, maxChars)
 	if err != nil {
 		return nil, err
 	}
 
-	// Format response text
-	infoStr := fmt.Sprintf("Database Schema for %s:\n\n%+v", dbID, info)
-	return createTextResponse(infoStr), nil
+	resp := createTextResponse(fmt.Sprintf("Database Schema (summary) for %s:\n\n%s", dbID, summary))
+	addMetadata(resp, "component", "summary")
+	return resp, nil
+}
+
+// handleERD renders the relationship diagram as text in the requested format
+func (t *SchemaTool) handleERD(ctx context.Context, request server.ToolCallRequest, dbID, schema, table string, useCase UseCaseProvider) (interface{}, error) {
+	format := "mermaid"
+	if request.Parameters["format"] != nil {
+		var ok bool
+		format, ok = request.Parameters["format"].(string)
+		if !ok {
+			return nil, fmt.Errorf("format parameter must be a string")
+		}
+	}
+
+	depth := 0
+	if value, ok := request.Parameters["depth"].(float64); ok {
+		depth = int(value)
+	}
+
+	diagram, err := useCase.GetSchemaERD(ctx, dbID, schema, table, format, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	// The diagram is returned as is so it can be pasted into a renderer
+	resp := createTextResponse(diagram)
+	addMetadata(resp, "component", "erd")
+	addMetadata(resp, "format", format)
+	return resp, nil
 }
 
 //------------------------------------------------------------------------------
@@ -542,7 +940,16 @@ func NewToolTypeFactory() *ToolTypeFactory {
 	factory.Register(NewTransactionTool())
 	factory.Register(NewPerformanceTool())
 	factory.Register(NewSchemaTool())
+	factory.Register(NewJoinPathTool())
+	factory.Register(NewIndexAdviceTool())
+	factory.Register(NewLocksTool())
+	factory.Register(NewActivityTool())
+	factory.Register(NewStorageTool())
 	factory.Register(NewListDatabasesTool())
+	factory.Register(NewSchemaDiffTool())
+	for _, action := range []string{"status", "plan", "up", "down"} {
+		factory.Register(NewMigrateTool(action))
+	}
 
 	return factory
 }
@@ -554,44 +961,37 @@ func (f *ToolTypeFactory) Register(toolType ToolType) {
 
 // GetToolType returns a tool type by name
 func (f *ToolTypeFactory) GetToolType(name string) (ToolType, bool) {
-	// Handle new simpler format: <tooltype>_<dbID> or just the tool type name
-	parts := strings.Split(name, "_")
-	if len(parts) > 0 {
-		// First part is the tool type name
-		toolType, ok := f.toolTypes[parts[0]]
-		if ok {
-			return toolType, true
-		}
+	// Direct tool type lookup first, so schema_diff is not taken for schema
+	if toolType, ok := f.toolTypes[name]; ok {
+		return toolType, true
 	}
 
-	// Direct tool type lookup
-	toolType, ok := f.toolTypes[name]
+	// Handle new simpler format: <tooltype>_<dbID>
+	toolType, _, ok := f.toolTypeForPrefix(name)
 	return toolType, ok
 }
 
-// GetToolTypeForSourceName finds the appropriate tool type for a source name
-func (f *ToolTypeFactory) GetToolTypeForSourceName(sourceName string) (ToolType, string, bool) {
-	// Handle simpler format: <tooltype>_<dbID>
-	parts := strings.Split(sourceName, "_")
-
-	if len(parts) >= 2 {
-		// First part is tool type, last part is dbID
-		toolTypeName := parts[0]
-		dbID := parts[len(parts)-1]
-
-		toolType, ok := f.toolTypes[toolTypeName]
-		if ok {
-			return toolType, dbID, true
+// toolTypeForPrefix splits a <tooltype>_<dbID> name at the longest
+// registered tool type, so join_path_<dbID> is not taken for a join type
+func (f *ToolTypeFactory) toolTypeForPrefix(name string) (ToolType, string, bool) {
+	parts := strings.Split(name, "_")
+	for i := len(parts) - 1; i >= 1; i-- {
+		if toolType, ok := f.toolTypes[strings.Join(parts[:i], "_")]; ok {
+			return toolType, strings.Join(parts[i:], "_"), true
 		}
 	}
+	return nil, "", false
+}
 
+// GetToolTypeForSourceName finds the appropriate tool type for a source name
+func (f *ToolTypeFactory) GetToolTypeForSourceName(sourceName string) (ToolType, string, bool) {
 	// Handle case for global tools
-	if sourceName == "list_databases" {
-		toolType, ok := f.toolTypes["list_databases"]
-		return toolType, "", ok
+	if toolType, ok := f.toolTypes[sourceName]; ok && globalToolTypes[sourceName] {
+		return toolType, "", true
 	}
 
-	return nil, "", false
+	// Handle simpler format: <tooltype>_<dbID>
+	return f.toolTypeForPrefix(sourceName)
 }
 
 // GetAllToolTypes returns all registered tool types
@@ -0,0 +1,367 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/FreePeak/cortex/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+func TestSchemaToolHandleRequestComponent(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetSchemaComponent", mock.Anything, "test_db", "indexes", "", "users").Return(map[string]interface{}{
+		"indexes": []map[string]interface{}{
+			{"index_name": "users_email_key", "is_unique": true, "columns": "email", "method": "btree"},
+		},
+	}, nil)
+
+	tool := NewSchemaTool()
+	request := server.ToolCallRequest{
+		Name:       "schema_test_db",
+		Parameters: map[string]interface{}{"component": "indexes", "table": "users"},
+	}
+
+	result, err := tool.HandleRequest(context.Background(), request, "test_db", mockUseCase)
+	require.NoError(t, err)
+
+	resp := result.(map[string]interface{})
+	text := resp["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Contains(t, text, "users_email_key")
+	assert.Contains(t, text, `"method": "btree"`)
+	assert.Equal(t, "indexes", resp["metadata"].(map[string]interface{})["component"])
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestSchemaToolHandleRequestDefaultsToTables(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetSchemaComponent", mock.Anything, "testdb", "tables", "", "").Return(map[string]interface{}{"tables": []string{"users"}}, nil)
+
+	_, err := NewSchemaTool().HandleRequest(context.Background(), server.ToolCallRequest{Name: "schema_testdb"}, "", mockUseCase)
+	require.NoError(t, err)
+	mockUseCase.AssertExpectations(t)
+
+	failing := new(MockDatabaseUseCase)
+	failing.On("GetSchemaComponent", mock.Anything, "test_db", "bogus", "", "").Return(nil, fmt.Errorf("invalid component: bogus"))
+	_, err = NewSchemaTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"component": "bogus"},
+	}, "test_db", failing)
+	assert.Error(t, err)
+}
+
+func TestSchemaToolHandleRequestSchema(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetSchemaComponent", mock.Anything, "test_db", "tables", "sales", "").Return(map[string]interface{}{"schema": "sales", "tables": []string{"orders"}}, nil)
+
+	_, err := NewSchemaTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"schema": "sales"},
+	}, "test_db", mockUseCase)
+	require.NoError(t, err)
+	mockUseCase.AssertExpectations(t)
+
+	_, err = NewSchemaTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"schema": 1},
+	}, "test_db", mockUseCase)
+	assert.EqualError(t, err, "schema parameter must be a string")
+}
+
+func TestSchemaToolHandleRequestSummary(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetSchemaSummary", mock.Anything, "test_db", "sales", "revenue by customer", 2000).
+		Return("orders(id integer PK) ~48K rows\n", nil).Once()
+	mockUseCase.On("GetSchemaSummary", mock.Anything, "test_db", "", "", 500).Return("", nil).Once()
+	mockUseCase.On("GetSchemaSummary", mock.Anything, "test_db", "", "", 0).Return("", nil).Once()
+
+	result, err := NewSchemaTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"component": "summary", "schema": "sales", "question": "revenue by customer", "max_tokens": float64(500)},
+	}, "test_db", mockUseCase)
+	require.NoError(t, err)
+	text := result.(map[string]interface{})["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Contains(t, text, "orders(id integer PK)")
+
+	// max_chars wins over max_tokens
+	_, err = NewSchemaTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"component": "summary", "max_tokens": float64(10), "max_chars": float64(500)},
+	}, "test_db", mockUseCase)
+	require.NoError(t, err)
+
+	_, err = NewSchemaTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"component": "summary"},
+	}, "test_db", mockUseCase)
+	require.NoError(t, err)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestSchemaToolHandleRequestERD(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetSchemaERD", mock.Anything, "test_db", "", "orders", "dot", 2).Return("digraph erd {\n}\n", nil).Once()
+	mockUseCase.On("GetSchemaERD", mock.Anything, "test_db", "", "", "mermaid", 0).Return("erDiagram\n", nil).Once()
+
+	result, err := NewSchemaTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"component": "erd", "table": "orders", "format": "dot", "depth": float64(2)},
+	}, "test_db", mockUseCase)
+	require.NoError(t, err)
+	resp := result.(map[string]interface{})
+	assert.Equal(t, "digraph erd {\n}\n", resp["content"].([]map[string]interface{})[0]["text"])
+	assert.Equal(t, "dot", resp["metadata"].(map[string]interface{})["format"])
+
+	_, err = NewSchemaTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"component": "erd"},
+	}, "test_db", mockUseCase)
+	require.NoError(t, err)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestSchemaToolHandleRequestRefresh(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("InvalidateSchemaCache", "test_db").Return().Once()
+	mockUseCase.On("GetSchemaComponent", mock.Anything, "test_db", "tables", "", "").Return(map[string]interface{}{"tables": []string{"users"}}, nil)
+
+	_, err := NewSchemaTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"refresh": true},
+	}, "test_db", mockUseCase)
+	require.NoError(t, err)
+
+	// Without refresh the cache is left alone
+	_, err = NewSchemaTool().HandleRequest(context.Background(), server.ToolCallRequest{}, "test_db", mockUseCase)
+	require.NoError(t, err)
+	mockUseCase.AssertExpectations(t)
+	mockUseCase.AssertNumberOfCalls(t, "InvalidateSchemaCache", 1)
+
+	_, err = NewSchemaTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"refresh": "yes"},
+	}, "test_db", mockUseCase)
+	assert.EqualError(t, err, "refresh parameter must be a boolean")
+}
+
+func TestSchemaToolHandleRequestDDL(t *testing.T) {
+	ddl := "-- PostgreSQL schema public\n\nCREATE TABLE public.users (\n    id integer NOT NULL\n);\n"
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetSchemaComponent", mock.Anything, "test_db", "ddl", "", "").
+		Return(map[string]interface{}{"ddl": ddl, "dbType": "postgres", "schema": "public"}, nil)
+
+	result, err := NewSchemaTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"component": "ddl"},
+	}, "test_db", mockUseCase)
+	require.NoError(t, err)
+
+	// The DDL is returned verbatim rather than as JSON
+	resp := result.(map[string]interface{})
+	assert.Equal(t, ddl, resp["content"].([]map[string]interface{})[0]["text"])
+	assert.Equal(t, "ddl", resp["metadata"].(map[string]interface{})["component"])
+}
+
+// stubPerformanceAnalyzer serves fixed slow queries and metrics
+type stubPerformanceAnalyzer struct {
+	slow        []domain.SlowQuery
+	metrics     domain.PerformanceMetrics
+	top         []domain.QueryFingerprint
+	threshold   int
+	resets      int
+	regressions []domain.PlanRegression
+	accepted    []string
+}
+
+func (a *stubPerformanceAnalyzer) RecordQuery(query string, params []interface{}, duration time.Duration, rows int64, err error) {
+}
+func (a *stubPerformanceAnalyzer) GetSlowQueries(limit int) ([]domain.SlowQuery, error) {
+	if len(a.slow) > limit {
+		return a.slow[:limit], nil
+	}
+	return a.slow, nil
+}
+func (a *stubPerformanceAnalyzer) GetMetrics() (domain.PerformanceMetrics, error) {
+	return a.metrics, nil
+}
+func (a *stubPerformanceAnalyzer) GetTopQueries(orderBy string, limit int) ([]domain.QueryFingerprint, error) {
+	return a.top, nil
+}
+func (a *stubPerformanceAnalyzer) AnalyzeQuery(query string) (domain.QueryAnalysis, error) {
+	return domain.QueryAnalysis{Query: query}, nil
+}
+func (a *stubPerformanceAnalyzer) Reset() error { a.resets++; return nil }
+func (a *stubPerformanceAnalyzer) SetThreshold(threshold int) error {
+	a.threshold = threshold
+	return nil
+}
+func (a *stubPerformanceAnalyzer) GetPlanRegressions() ([]domain.PlanRegression, error) {
+	return a.regressions, nil
+}
+func (a *stubPerformanceAnalyzer) AcceptPlan(fingerprint string) error {
+	a.accepted = append(a.accepted, fingerprint)
+	return nil
+}
+
+func TestPerformanceToolHandleRequest(t *testing.T) {
+	analyzer := &stubPerformanceAnalyzer{
+		slow: []domain.SlowQuery{
+			{Query: "SELECT * FROM orders", Duration: 812.5, Timestamp: "2024-01-01T00:00:00Z"},
+			{Query: "UPDATE users SET name = 'x'", Duration: 640, Timestamp: "2024-01-01T00:00:01Z", Error: "deadlock detected"},
+		},
+		top: []domain.QueryFingerprint{{
+			Fingerprint: "0a1b2c3d4e5f6789", Query: "SELECT * FROM users WHERE id = ?", Calls: 10, Errors: 1, Rows: 10,
+			TotalTime: 120, MeanTime: 12, P50Time: 10, P95Time: 30, P99Time: 45,
+		}},
+		metrics: domain.PerformanceMetrics{TotalQueries: 12, AvgDuration: 101.25, MaxDuration: 812.5, SlowQueries: 2, Errors: 1, Threshold: 500},
+	}
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetPerformanceAnalyzer", "test_db").Return(analyzer, nil)
+	tool := NewPerformanceTool()
+	call := func(params map[string]interface{}) (string, map[string]interface{}) {
+		result, err := tool.HandleRequest(context.Background(), server.ToolCallRequest{Parameters: params}, "test_db", mockUseCase)
+		require.NoError(t, err)
+		resp := result.(map[string]interface{})
+		return resp["content"].([]map[string]interface{})[0]["text"].(string), resp
+	}
+
+	text, resp := call(map[string]interface{}{"action": "getSlowQueries", "limit": float64(5)})
+	assert.Contains(t, text, "Slowest queries on test_db (threshold 500 ms):")
+	assert.Contains(t, text, "1. 812.50 ms at 2024-01-01T00:00:00Z\n   SELECT * FROM orders\n")
+	assert.Contains(t, text, "   error: deadlock detected\n")
+	assert.Equal(t, 2, resp["metadata"].(map[string]interface{})["count"])
+
+	text, resp = call(map[string]interface{}{"action": "getTopQueries", "orderBy": "calls"})
+	assert.Contains(t, text, "Top queries on test_db by calls:\n\n")
+	assert.Contains(t, text, "1. [0a1b2c3d4e5f6789] 10 calls, 120.00 ms total, 12.00 ms mean, p50/p95/p99 10.00/30.00/45.00 ms, 10 rows, 1 errors\n   SELECT * FROM users WHERE id = ?\n")
+	assert.Equal(t, 1, resp["metadata"].(map[string]interface{})["count"])
+
+	text, _ = call(map[string]interface{}{"action": "getMetrics"})
+	assert.Contains(t, text, "Queries: 12\n")
+	assert.Contains(t, text, "Average duration: 101.25 ms\n")
+	assert.Contains(t, text, "Slow queries: 2 (threshold 500 ms)\n")
+
+	text, _ = call(map[string]interface{}{"action": "setThreshold", "threshold": float64(250)})
+	assert.Equal(t, "Slow query threshold of test_db set to 250 ms", text)
+	assert.Equal(t, 250, analyzer.threshold)
+
+	call(map[string]interface{}{"action": "reset"})
+	assert.Equal(t, 1, analyzer.resets)
+
+	text, _ = call(map[string]interface{}{"action": "getPlanRegressions"})
+	assert.Equal(t, "No plan regressions on test_db", text)
+	analyzer.regressions = []domain.PlanRegression{{
+		Fingerprint: "0a1b2c3d4e5f6789", Query: "SELECT * FROM orders WHERE status = ?", Kind: "shape",
+		BaselineCost: 8.29, LatestCost: 2041, BaselinePlan: "-> Index Scan on orders using idx_orders_status (cost=8.29 rows=1)",
+		LatestPlan:    "-> Seq Scan on orders (cost=2041.00 rows=1)\n   Filter: (status = 'open'::text)",
+		BaselineSince: "2024-01-01T00:00:00Z", CheckedAt: "2024-01-02T00:00:00Z",
+	}}
+	text, resp = call(map[string]interface{}{"action": "getPlanRegressions"})
+	assert.Contains(t, text, "1. [0a1b2c3d4e5f6789] plan changed shape, cost 8.29 -> 2041.00 (checked 2024-01-02T00:00:00Z)\n   SELECT * FROM orders WHERE status = ?\n")
+	assert.Contains(t, text, "   Baseline plan (since 2024-01-01T00:00:00Z):\n     -> Index Scan on orders using idx_orders_status (cost=8.29 rows=1)\n")
+	assert.Contains(t, text, "   Latest plan:\n     -> Seq Scan on orders (cost=2041.00 rows=1)\n        Filter: (status = 'open'::text)\n")
+	assert.Equal(t, 1, resp["metadata"].(map[string]interface{})["count"])
+
+	text, _ = call(map[string]interface{}{"action": "acceptPlan", "fingerprint": "0a1b2c3d4e5f6789"})
+	assert.Equal(t, "The latest plan of 0a1b2c3d4e5f6789 is now its baseline on test_db", text)
+	assert.Equal(t, []string{"0a1b2c3d4e5f6789"}, analyzer.accepted)
+
+	_, err := tool.HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"action": "getSlowQueries!"},
+	}, "test_db", mockUseCase)
+	assert.ErrorContains(t, err, "invalid performance action")
+}
+
+func TestPerformanceToolHandleRequestAnalyzeQuery(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("AnalyzeQuery", mock.Anything, "test_db", "SELECT * FROM orders").Return(&domain.QueryAnalysis{
+		Query:       "SELECT * FROM orders",
+		Executions:  3,
+		Fingerprint: "9f2c1d4e5a6b7c8d",
+		AvgDuration: 12.5,
+		P95Duration: 19,
+		MaxDuration: 20,
+		Suggestions: []string{
+			"Avoid using SELECT * - specify only the columns you need",
+			"Filter on indexed columns or add a LIMIT if the whole table is not needed",
+		},
+		ExplainPlan: "-> Seq Scan on orders (cost=1935.00 rows=120000)",
+		PlanIssues: []domain.PlanIssue{{
+			Kind:       "seq-scan",
+			Relation:   "orders",
+			Message:    "Sequential scan of orders reads 120000 rows",
+			Suggestion: "Filter on indexed columns or add a LIMIT if the whole table is not needed",
+		}},
+	}, nil)
+
+	result, err := NewPerformanceTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"action": "analyzeQuery", "query": "SELECT * FROM orders"},
+	}, "test_db", mockUseCase)
+	require.NoError(t, err)
+
+	text := result.(map[string]interface{})["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Contains(t, text, "Recorded executions of fingerprint 9f2c1d4e5a6b7c8d: 3 (average 12.50 ms, p95 19.00 ms, max 20.00 ms)\n")
+	assert.Contains(t, text, "- Avoid using SELECT *")
+	assert.Contains(t, text, "Plan issues:\n- [seq-scan] Sequential scan of orders reads 120000 rows\n")
+	assert.Contains(t, text, "Plan:\n-> Seq Scan on orders")
+
+	_, err = NewPerformanceTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"action": "analyzeQuery"},
+	}, "test_db", mockUseCase)
+	assert.ErrorContains(t, err, "query parameter is required")
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestPerformanceToolHandleRequestStatementStats(t *testing.T) {
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("GetWorkloadStatistics", mock.Anything, "pg", "io", 5).Return(&domain.WorkloadStatistics{
+		Source:    "pg_stat_statements",
+		Available: true,
+		OrderBy:   "io",
+		Queries: []domain.QueryFingerprint{{
+			Fingerprint: "-4617514393217834925", Query: "SELECT * FROM orders WHERE id = $1", Calls: 120, Rows: 120,
+			TotalTime: 360.5, MeanTime: 3, BlocksHit: 900, BlocksRead: 12, IOTime: 2.5,
+		}},
+	}, nil)
+	mockUseCase.On("GetWorkloadStatistics", mock.Anything, "mysql", "", 10).Return(&domain.WorkloadStatistics{
+		Source: "performance_schema",
+		Reason: "performance_schema is disabled; start the server with performance_schema=ON",
+	}, nil)
+	tool := NewPerformanceTool()
+
+	result, err := tool.HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"action": "getStatementStats", "orderBy": "io", "limit": float64(5)},
+	}, "pg", mockUseCase)
+	require.NoError(t, err)
+	resp := result.(map[string]interface{})
+	text := resp["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Contains(t, text, "Top statements on pg from pg_stat_statements by io:\n\n")
+	assert.Contains(t, text, "1. [-4617514393217834925] 120 calls, 360.50 ms total, 3.00 ms mean, 120 rows, 900 blocks hit, 12 read, 2.50 ms I/O\n")
+	assert.Equal(t, "pg_stat_statements", resp["metadata"].(map[string]interface{})["source"])
+
+	result, err = tool.HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"action": "getStatementStats"},
+	}, "mysql", mockUseCase)
+	require.NoError(t, err)
+	text = result.(map[string]interface{})["content"].([]map[string]interface{})[0]["text"].(string)
+	assert.Equal(t, "Statement statistics are not available on mysql: performance_schema is disabled; start the server with performance_schema=ON", text)
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestQueryToolHandleRequestStatementTimeout(t *testing.T) {
+	limited := domain.WithStatementTimeout(context.Background(), 1500*time.Millisecond)
+	mockUseCase := new(MockDatabaseUseCase)
+	mockUseCase.On("WithStatementTimeout", mock.Anything, "test_db", 1500*time.Millisecond).Return(limited, nil)
+	mockUseCase.On("ExecuteQuery", limited, "test_db", "SELECT 1", []interface{}(nil)).Return("Results:", nil)
+
+	request := server.ToolCallRequest{
+		Name:       "query_test_db",
+		Parameters: map[string]interface{}{"query": "SELECT 1", "statement_timeout_ms": float64(1500)},
+	}
+	_, err := NewQueryTool().HandleRequest(context.Background(), request, "test_db", mockUseCase)
+	require.NoError(t, err)
+	mockUseCase.AssertExpectations(t)
+
+	failing := new(MockDatabaseUseCase)
+	failing.On("WithStatementTimeout", mock.Anything, "test_db", time.Minute).Return(nil, fmt.Errorf("statement timeout 1m0s exceeds the maximum of 30s for database test_db"))
+	_, err = NewExecuteTool().HandleRequest(context.Background(), server.ToolCallRequest{
+		Parameters: map[string]interface{}{"statement": "DELETE FROM t", "statement_timeout_ms": float64(60000)},
+	}, "test_db", failing)
+	assert.ErrorContains(t, err, "exceeds the maximum")
+	failing.AssertNotCalled(t, "ExecuteStatement", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 )
 
 // Database represents a database connection and operations
@@ -39,36 +40,293 @@ type TxOptions struct {
 	ReadOnly bool
 }
 
-// PerformanceAnalyzer for analyzing database query performance
+// statementTimeoutKey is the context key of a statement timeout
+type statementTimeoutKey struct{}
+
+// WithStatementTimeout returns a context whose statements the database server
+// stops after timeout, overriding the connection's statement timeout
+func WithStatementTimeout(ctx context.Context, timeout time.Duration) context.Context {
+	return context.WithValue(ctx, statementTimeoutKey{}, timeout)
+}
+
+// StatementTimeout returns the statement timeout set on a context
+func StatementTimeout(ctx context.Context) (time.Duration, bool) {
+	timeout, ok := ctx.Value(statementTimeoutKey{}).(time.Duration)
+	return timeout, ok
+}
+
+// PerformanceAnalyzer records the queries run on a database and reports on
+// their performance
 type PerformanceAnalyzer interface {
+	RecordQuery(query string, params []interface{}, duration time.Duration, rows int64, err error)
 	GetSlowQueries(limit int) ([]SlowQuery, error)
 	GetMetrics() (PerformanceMetrics, error)
+	GetTopQueries(orderBy string, limit int) ([]QueryFingerprint, error)
 	AnalyzeQuery(query string) (QueryAnalysis, error)
 	Reset() error
 	SetThreshold(threshold int) error
+	GetPlanRegressions() ([]PlanRegression, error)
+	AcceptPlan(fingerprint string) error
 }
 
 // SlowQuery represents a slow query that has been recorded
 type SlowQuery struct {
-	Query     string
-	Duration  float64
-	Timestamp string
+	Query     string  `json:"query"`
+	Duration  float64 `json:"duration_ms"`
+	Timestamp string  `json:"timestamp"`
+	Error     string  `json:"error,omitempty"`
 }
 
-// PerformanceMetrics represents database performance metrics
+// PerformanceMetrics represents database performance metrics. Durations are
+// in milliseconds.
 type PerformanceMetrics struct {
-	TotalQueries  int
-	AvgDuration   float64
-	MaxDuration   float64
-	SlowQueries   int
-	Threshold     int
-	LastResetTime string
+	TotalQueries  int     `json:"total_queries"`
+	AvgDuration   float64 `json:"avg_duration_ms"`
+	MaxDuration   float64 `json:"max_duration_ms"`
+	SlowQueries   int     `json:"slow_queries"`
+	Errors        int     `json:"errors"`
+	Threshold     int     `json:"threshold_ms"`
+	LastResetTime string  `json:"last_reset_time"`
+}
+
+// QueryFingerprint aggregates the executions of a query with its literals
+// replaced by placeholders. Times are in milliseconds; percentiles cover
+// the recent executions.
+type QueryFingerprint struct {
+	Fingerprint string  `json:"fingerprint"`
+	Query       string  `json:"query"`
+	Calls       int     `json:"calls"`
+	Errors      int     `json:"errors"`
+	Rows        int64   `json:"rows"`
+	TotalTime   float64 `json:"total_time_ms"`
+	MeanTime    float64 `json:"mean_time_ms"`
+	MinTime     float64 `json:"min_time_ms"`
+	MaxTime     float64 `json:"max_time_ms"`
+	P50Time     float64 `json:"p50_time_ms"`
+	P95Time     float64 `json:"p95_time_ms"`
+	P99Time     float64 `json:"p99_time_ms"`
+	// I/O, where the database reports it
+	RowsExamined int64   `json:"rows_examined,omitempty"`
+	BlocksHit    int64   `json:"blocks_hit,omitempty"`
+	BlocksRead   int64   `json:"blocks_read,omitempty"`
+	IOTime       float64 `json:"io_time_ms,omitempty"`
+	LastSeen     string  `json:"last_seen,omitempty"`
+}
+
+// WorkloadStatistics are the statement statistics a database keeps itself,
+// e.g. pg_stat_statements, covering queries from every client
+type WorkloadStatistics struct {
+	Source    string             `json:"source"`
+	Available bool               `json:"available"`
+	Reason    string             `json:"reason,omitempty"` // why they are not available
+	OrderBy   string             `json:"order_by"`
+	Queries   []QueryFingerprint `json:"queries"`
 }
 
 // QueryAnalysis represents the analysis of a SQL query
 type QueryAnalysis struct {
-	Query       string
-	ExplainPlan string
+	Query       string      `json:"query"`
+	Fingerprint string      `json:"fingerprint"`
+	Executions  int         `json:"executions"`
+	AvgDuration float64     `json:"avg_duration_ms,omitempty"`
+	P95Duration float64     `json:"p95_duration_ms,omitempty"`
+	MaxDuration float64     `json:"max_duration_ms,omitempty"`
+	Suggestions []string    `json:"suggestions"`
+	ExplainPlan string      `json:"explain_plan,omitempty"`
+	PlanIssues  []PlanIssue `json:"plan_issues,omitempty"`
+}
+
+// QueryPlan is the parsed EXPLAIN plan of a query, rendered as a tree
+type QueryPlan struct {
+	Analyzed bool        `json:"analyzed"`
+	Plan     string      `json:"plan"`
+	Issues   []PlanIssue `json:"issues"`
+}
+
+// PlanIssue is a problem found in a query plan, such as a sequential scan of
+// a large table, with a suggestion to fix it
+type PlanIssue struct {
+	Kind       string `json:"kind"`
+	Relation   string `json:"relation,omitempty"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion"`
+}
+
+// IndexAdviceOptions select the workload the index advisor plans
+type IndexAdviceOptions struct {
+	Source   string // auto, history or statements
+	Limit    int
+	MinRows  float64
+	Validate bool
+}
+
+// IndexAdvice proposes indexes for the full scans of a database's workload
+// and flags indexes worth dropping
+type IndexAdvice struct {
+	Source     string          `json:"source"`
+	Schema     string          `json:"schema"`
+	Planned    int             `json:"planned"`
+	Skipped    int             `json:"skipped"`
+	Validated  bool            `json:"validated"`
+	Proposals  []IndexProposal `json:"proposals"`
+	Unused     []IndexFinding  `json:"unused"`
+	Duplicates []IndexFinding  `json:"duplicates"`
+	Notes      []string        `json:"notes,omitempty"`
+}
+
+// IndexProposal is a CREATE INDEX statement with why it would help. The
+// estimated saving is in milliseconds of workload time; costs are only set
+// when the proposal was validated with a hypothetical index.
+type IndexProposal struct {
+	Table           string   `json:"table"`
+	Columns         []string `json:"columns"`
+	Statement       string   `json:"statement"`
+	Rationale       string   `json:"rationale"`
+	Fingerprints    []string `json:"fingerprints"`
+	Calls           int      `json:"calls"`
+	RowsScanned     float64  `json:"rows_scanned"`
+	RowsReturned    float64  `json:"rows_returned"`
+	EstimatedSaving float64  `json:"estimated_saving_ms"`
+	CostBefore      float64  `json:"cost_before,omitempty"`
+	CostAfter       float64  `json:"cost_after,omitempty"`
+}
+
+// IndexFinding is an unused or duplicate index with the statement dropping it
+type IndexFinding struct {
+	Table      string `json:"table"`
+	Index      string `json:"index"`
+	Definition string `json:"definition,omitempty"`
+	Reason     string `json:"reason"`
+	SizeBytes  int64  `json:"size_bytes,omitempty"`
+	Statement  string `json:"statement"`
+}
+
+// PlanRegression is a frequent query whose plan changed shape or costs much
+// more than the baseline plan recorded for it
+type PlanRegression struct {
+	Fingerprint   string  `json:"fingerprint"`
+	Query         string  `json:"query"`
+	Kind          string  `json:"kind"` // shape or cost
+	BaselineCost  float64 `json:"baseline_cost"`
+	LatestCost    float64 `json:"latest_cost"`
+	BaselinePlan  string  `json:"baseline_plan"`
+	LatestPlan    string  `json:"latest_plan"`
+	BaselineSince string  `json:"baseline_since"`
+	CheckedAt     string  `json:"checked_at"`
+}
+
+// LockWait is a session waiting for a lock held by another session.
+// Durations are in milliseconds; the blocking duration is the age of the
+// blocking transaction.
+type LockWait struct {
+	WaitingPID       int64   `json:"waiting_pid"`
+	WaitingUser      string  `json:"waiting_user,omitempty"`
+	WaitingQuery     string  `json:"waiting_query"`
+	WaitDuration     float64 `json:"wait_duration_ms"`
+	LockType         string  `json:"lock_type,omitempty"`
+	LockMode         string  `json:"lock_mode,omitempty"`
+	Object           string  `json:"object,omitempty"`
+	BlockingPID      int64   `json:"blocking_pid"`
+	BlockingUser     string  `json:"blocking_user,omitempty"`
+	BlockingQuery    string  `json:"blocking_query,omitempty"`
+	BlockingState    string  `json:"blocking_state,omitempty"`
+	BlockingDuration float64 `json:"blocking_duration_ms"`
+}
+
+// StorageOptions tune a storage report: the ranking (size, bloat or dead)
+// and how many tables, indexes and chunks to report
+type StorageOptions struct {
+	OrderBy string
+	Limit   int
+}
+
+// StorageReport is the disk usage of the tables and indexes of a schema,
+// worst offenders first
+type StorageReport struct {
+	Schema          string              `json:"schema"`
+	OrderBy         string              `json:"order_by"`
+	Tables          int                 `json:"tables"`
+	TotalBytes      int64               `json:"total_bytes"`
+	DatabaseSize    string              `json:"database_size,omitempty"`
+	HypertablesSize string              `json:"hypertables_size,omitempty"`
+	TopTables       []TableStorage      `json:"top_tables"`
+	TopIndexes      []IndexStorage      `json:"top_indexes,omitempty"`
+	Hypertables     []HypertableStorage `json:"hypertables,omitempty"`
+	Notes           []string            `json:"notes,omitempty"`
+}
+
+// TableStorage is the disk usage of a table. Free bytes are only reported
+// by MySQL, estimated bloat and dead tuples only by PostgreSQL.
+type TableStorage struct {
+	Table       string  `json:"table"`
+	TotalBytes  int64   `json:"total_bytes"`
+	TableBytes  int64   `json:"table_bytes"`
+	IndexBytes  int64   `json:"index_bytes"`
+	FreeBytes   int64   `json:"free_bytes,omitempty"`
+	BloatBytes  int64   `json:"bloat_bytes,omitempty"`
+	RowEstimate float64 `json:"row_estimate"`
+	DeadTuples  int64   `json:"dead_tuples,omitempty"`
+	LastVacuum  string  `json:"last_vacuum,omitempty"`
+	LastAnalyze string  `json:"last_analyze,omitempty"`
+}
+
+// IndexStorage is the disk usage of an index
+type IndexStorage struct {
+	Table      string `json:"table"`
+	Index      string `json:"index"`
+	Method     string `json:"method"`
+	Bytes      int64  `json:"bytes"`
+	BloatBytes int64  `json:"bloat_bytes,omitempty"`
+	Scans      int64  `json:"scans"`
+}
+
+// HypertableStorage is the disk usage of a TimescaleDB hypertable, totalled
+// over its chunks, with its most recent chunks
+type HypertableStorage struct {
+	Table            string         `json:"table"`
+	ChunkCount       int            `json:"chunk_count"`
+	CompressedChunks int            `json:"compressed_chunks"`
+	TableBytes       int64          `json:"table_bytes"`
+	IndexBytes       int64          `json:"index_bytes"`
+	ToastBytes       int64          `json:"toast_bytes"`
+	TotalBytes       int64          `json:"total_bytes"`
+	Chunks           []ChunkStorage `json:"chunks"`
+}
+
+// ChunkStorage is the disk usage of a hypertable chunk
+type ChunkStorage struct {
+	Name       string `json:"name"`
+	RangeStart string `json:"range_start,omitempty"`
+	RangeEnd   string `json:"range_end,omitempty"`
+	Compressed bool   `json:"compressed"`
+	TotalBytes int64  `json:"total_bytes"`
+	IndexBytes int64  `json:"index_bytes"`
+}
+
+// SessionActivity is a live database session. Durations are in
+// milliseconds; the duration is the time spent in the current state.
+type SessionActivity struct {
+	PID                 int64   `json:"pid"`
+	User                string  `json:"user,omitempty"`
+	Database            string  `json:"database,omitempty"`
+	Application         string  `json:"application,omitempty"`
+	ClientAddr          string  `json:"client_addr,omitempty"`
+	State               string  `json:"state"`
+	Query               string  `json:"query,omitempty"`
+	Duration            float64 `json:"duration_ms"`
+	TransactionDuration float64 `json:"transaction_duration_ms,omitempty"`
+	WaitEventType       string  `json:"wait_event_type,omitempty"`
+	WaitEvent           string  `json:"wait_event,omitempty"`
+}
+
+// ActivityFilter selects the sessions to list. MinDuration is in
+// milliseconds; a zero Limit uses the default.
+type ActivityFilter struct {
+	PID         int64
+	State       string
+	User        string
+	MinDuration float64
+	Limit       int
 }
 
 // SchemaInfo represents database schema information
@@ -81,29 +339,51 @@ type SchemaInfo interface {
 
 // ColumnInfo represents information about a database column
 type ColumnInfo struct {
-	Name     string
-	Type     string
-	Nullable bool
-	Default  string
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Nullable bool   `json:"nullable"`
+	Default  string `json:"default,omitempty"`
 }
 
 // IndexInfo represents information about a database index
 type IndexInfo struct {
-	Name    string
-	Table   string
-	Columns []string
-	Unique  bool
-	Primary bool
+	Name      string   `json:"name"`
+	Table     string   `json:"table"`
+	Columns   []string `json:"columns,omitempty"`
+	Unique    bool     `json:"unique"`
+	Primary   bool     `json:"primary"`
+	Method    string   `json:"method,omitempty"`
+	Predicate string   `json:"predicate,omitempty"`
+	// Definition is only kept when the columns could not be introspected
+	Definition string `json:"definition,omitempty"`
 }
 
 // ConstraintInfo represents information about a database constraint
 type ConstraintInfo struct {
-	Name              string
-	Type              string
-	Table             string
-	Columns           []string
-	ReferencedTable   string
-	ReferencedColumns []string
+	Name              string   `json:"name"`
+	Type              string   `json:"type"`
+	Table             string   `json:"table"`
+	Columns           []string `json:"columns,omitempty"`
+	ReferencedTable   string   `json:"referenced_table,omitempty"`
+	ReferencedColumns []string `json:"referenced_columns,omitempty"`
+	Definition        string   `json:"definition,omitempty"`
+}
+
+// JoinPath is the chain of joins connecting tables through foreign keys
+type JoinPath struct {
+	Schema string     `json:"schema"`
+	From   string     `json:"from"`
+	Joins  []JoinStep `json:"joins"`
+	Via    []string   `json:"via"` // intermediate tables that were not requested
+	Query  string     `json:"query,omitempty"`
+}
+
+// JoinStep joins one table to a table already in the chain
+type JoinStep struct {
+	Table      string `json:"table"`
+	To         string `json:"to"`
+	Constraint string `json:"constraint"`
+	On         string `json:"on"`
 }
 
 // DatabaseRepository defines methods for managing database connections
@@ -111,4 +391,19 @@ type DatabaseRepository interface {
 	GetDatabase(id string) (Database, error)
 	ListDatabases() []string
 	GetDatabaseType(id string) (string, error)
+	GetSchemaComponent(ctx context.Context, id, component, schema, table string) (map[string]interface{}, error)
+	ResolveSchema(id, schema string) (string, error)
+	InvalidateSchemaCache(id string)
+	GetMigrationsDir(id string) (string, error)
+	GetQueryTimeout(id string) (time.Duration, error)
+	GetMaxStatementTimeout(id string) (time.Duration, error)
+	GetJoinPath(ctx context.Context, id, schema string, tables []string, withQuery bool) (*JoinPath, error)
+	GetPerformanceAnalyzer(id string) (PerformanceAnalyzer, error)
+	GetWorkloadStatistics(ctx context.Context, id, orderBy string, limit int) (*WorkloadStatistics, error)
+	ExplainQuery(ctx context.Context, id, query string) (*QueryPlan, error)
+	AdviseIndexes(ctx context.Context, id, schema string, opts IndexAdviceOptions) (*IndexAdvice, error)
+	GetLockWaits(ctx context.Context, id string) ([]LockWait, error)
+	GetActivity(ctx context.Context, id string, filter ActivityFilter) ([]SessionActivity, error)
+	GetStorageStats(ctx context.Context, id, schema string, opts StorageOptions) (*StorageReport, error)
+	SignalSession(ctx context.Context, id string, pid int64, signal string) error
 }
@@ -0,0 +1,39 @@
+package domain
+
+// Migration is a numbered schema change read from a connection's migrations
+// directory, e.g. 000042_add_orders.up.sql and 000042_add_orders.down.sql
+type Migration struct {
+	Version  int64  `json:"version"`
+	Name     string `json:"name"`
+	UpFile   string `json:"up_file"`
+	DownFile string `json:"down_file,omitempty"` // empty when irreversible
+}
+
+// MigrationStatus is the applied version of a database compared with the
+// migrations on disk
+type MigrationStatus struct {
+	Database  string      `json:"database"`
+	Directory string      `json:"directory"`
+	Version   int64       `json:"version"` // 0 when nothing was applied
+	Dirty     bool        `json:"dirty"`   // a migration failed half-way
+	Applied   []Migration `json:"applied"`
+	Pending   []Migration `json:"pending"`
+}
+
+// MigrationPlan lists the migrations an up or down run applies, in order
+type MigrationPlan struct {
+	Database    string          `json:"database"`
+	Direction   string          `json:"direction"`
+	FromVersion int64           `json:"from_version"`
+	ToVersion   int64           `json:"to_version"`
+	Steps       []MigrationStep `json:"steps"`
+}
+
+// MigrationStep is one migration file run in a plan
+type MigrationStep struct {
+	Version       int64  `json:"version"`
+	Name          string `json:"name"`
+	File          string `json:"file"`
+	Transactional bool   `json:"transactional"`
+	SQL           string `json:"sql,omitempty"`
+}
@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"reflect"
+	"sort"
+	"time"
+)
+
+// SchemaSnapshot is the introspected structure of one schema at a point in
+// time. Snapshots are saved as JSON so a database can later be compared with
+// an earlier state of itself or of another database.
+type SchemaSnapshot struct {
+	Database string                   `json:"database"`
+	DBType   string                   `json:"db_type"`
+	Schema   string                   `json:"schema"`
+	TakenAt  time.Time                `json:"taken_at"`
+	Tables   map[string]TableSnapshot `json:"tables"`
+}
+
+// TableSnapshot holds the columns, indexes and constraints of a table
+type TableSnapshot struct {
+	Columns     []ColumnInfo     `json:"columns"`
+	Indexes     []IndexInfo      `json:"indexes,omitempty"`
+	Constraints []ConstraintInfo `json:"constraints,omitempty"`
+}
+
+// SchemaDiff lists what changed from a source schema to a target schema
+type SchemaDiff struct {
+	Source        string      `json:"source"`
+	Target        string      `json:"target"`
+	AddedTables   []string    `json:"added_tables"`
+	RemovedTables []string    `json:"removed_tables"`
+	ChangedTables []TableDiff `json:"changed_tables"`
+}
+
+// TableDiff lists the changes to a table present on both sides
+type TableDiff struct {
+	Table              string           `json:"table"`
+	AddedColumns       []ColumnInfo     `json:"added_columns,omitempty"`
+	RemovedColumns     []ColumnInfo     `json:"removed_columns,omitempty"`
+	ChangedColumns     []ObjectChange   `json:"changed_columns,omitempty"`
+	AddedIndexes       []IndexInfo      `json:"added_indexes,omitempty"`
+	RemovedIndexes     []IndexInfo      `json:"removed_indexes,omitempty"`
+	ChangedIndexes     []ObjectChange   `json:"changed_indexes,omitempty"`
+	AddedConstraints   []ConstraintInfo `json:"added_constraints,omitempty"`
+	RemovedConstraints []ConstraintInfo `json:"removed_constraints,omitempty"`
+	ChangedConstraints []ObjectChange   `json:"changed_constraints,omitempty"`
+}
+
+// ObjectChange lists the attributes of a column, index or constraint that
+// differ between source and target
+type ObjectChange struct {
+	Name    string                 `json:"name"`
+	Changes map[string]ValueChange `json:"changes"`
+}
+
+// ValueChange is an attribute's value in the source and in the target
+type ValueChange struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// Empty reports whether the schemas are identical
+func (d *SchemaDiff) Empty() bool {
+	return len(d.AddedTables) == 0 && len(d.RemovedTables) == 0 && len(d.ChangedTables) == 0
+}
+
+// DiffSchemaSnapshots compares two snapshots. Added objects exist only in
+// the target, removed objects only in the source.
+func DiffSchemaSnapshots(source, target *SchemaSnapshot) *SchemaDiff {
+	diff := &SchemaDiff{
+		Source:        source.Database,
+		Target:        target.Database,
+		AddedTables:   []string{},
+		RemovedTables: []string{},
+		ChangedTables: []TableDiff{},
+	}
+
+	for _, name := range sortedKeys(target.Tables) {
+		if _, ok := source.Tables[name]; !ok {
+			diff.AddedTables = append(diff.AddedTables, name)
+		}
+	}
+	for _, name := range sortedKeys(source.Tables) {
+		targetTable, ok := target.Tables[name]
+		if !ok {
+			diff.RemovedTables = append(diff.RemovedTables, name)
+			continue
+		}
+		if tableDiff, changed := diffTables(name, source.Tables[name], targetTable); changed {
+			diff.ChangedTables = append(diff.ChangedTables, tableDiff)
+		}
+	}
+	return diff
+}
+
+// diffTables compares a table present in both snapshots
+func diffTables(name string, source, target TableSnapshot) (TableDiff, bool) {
+	diff := TableDiff{Table: name}
+
+	diff.AddedColumns, diff.RemovedColumns, diff.ChangedColumns = diffObjects(source.Columns, target.Columns,
+		func(c ColumnInfo) string { return c.Name },
+		func(c ColumnInfo) map[string]interface{} {
+			return map[string]interface{}{"type": c.Type, "nullable": c.Nullable, "default": c.Default}
+		})
+
+	diff.AddedIndexes, diff.RemovedIndexes, diff.ChangedIndexes = diffObjects(source.Indexes, target.Indexes,
+		func(i IndexInfo) string { return i.Name },
+		func(i IndexInfo) map[string]interface{} {
+			return map[string]interface{}{
+				"columns": i.Columns, "unique": i.Unique, "primary": i.Primary,
+				"method": i.Method, "predicate": i.Predicate, "definition": i.Definition,
+			}
+		})
+
+	diff.AddedConstraints, diff.RemovedConstraints, diff.ChangedConstraints = diffObjects(source.Constraints, target.Constraints,
+		func(c ConstraintInfo) string { return c.Name },
+		func(c ConstraintInfo) map[string]interface{} {
+			return map[string]interface{}{
+				"type": c.Type, "columns": c.Columns, "definition": c.Definition,
+				"referenced_table": c.ReferencedTable, "referenced_columns": c.ReferencedColumns,
+			}
+		})
+
+	changed := len(diff.AddedColumns)+len(diff.RemovedColumns)+len(diff.ChangedColumns)+
+		len(diff.AddedIndexes)+len(diff.RemovedIndexes)+len(diff.ChangedIndexes)+
+		len(diff.AddedConstraints)+len(diff.RemovedConstraints)+len(diff.ChangedConstraints) > 0
+	return diff, changed
+}
+
+// diffObjects matches objects by name and compares their attributes
+func diffObjects[T any](source, target []T, name func(T) string, attrs func(T) map[string]interface{}) (added, removed []T, changed []ObjectChange) {
+	sourceByName := make(map[string]T, len(source))
+	for _, obj := range source {
+		sourceByName[name(obj)] = obj
+	}
+	targetByName := make(map[string]T, len(target))
+	for _, obj := range target {
+		targetByName[name(obj)] = obj
+	}
+
+	for _, obj := range target {
+		if _, ok := sourceByName[name(obj)]; !ok {
+			added = append(added, obj)
+		}
+	}
+	for _, obj := range source {
+		targetObj, ok := targetByName[name(obj)]
+		if !ok {
+			removed = append(removed, obj)
+			continue
+		}
+
+		from, to := attrs(obj), attrs(targetObj)
+		changes := make(map[string]ValueChange)
+		for key, value := range from {
+			if !reflect.DeepEqual(emptyToNil(value), emptyToNil(to[key])) {
+				changes[key] = ValueChange{From: value, To: to[key]}
+			}
+		}
+		if len(changes) > 0 {
+			changed = append(changed, ObjectChange{Name: name(obj), Changes: changes})
+		}
+	}
+	return added, removed, changed
+}
+
+// emptyToNil treats empty slices like nil so a snapshot round trip through
+// JSON does not show up as a change
+func emptyToNil(value interface{}) interface{} {
+	if s, ok := value.([]string); ok && len(s) == 0 {
+		return nil
+	}
+	return value
+}
+
+// sortedKeys returns the table names of a snapshot in order
+func sortedKeys(tables map[string]TableSnapshot) []string {
+	keys := make([]string, 0, len(tables))
+	for key := range tables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleSnapshot(database string) *SchemaSnapshot {
+	return &SchemaSnapshot{
+		Database: database,
+		Tables: map[string]TableSnapshot{
+			"users": {
+				Columns: []ColumnInfo{
+					{Name: "id", Type: "integer"},
+					{Name: "email", Type: "character varying(255)", Nullable: true},
+				},
+				Indexes: []IndexInfo{
+					{Name: "users_pkey", Table: "users", Columns: []string{"id"}, Unique: true, Primary: true, Method: "btree"},
+				},
+				Constraints: []ConstraintInfo{
+					{Name: "users_pkey", Type: "PRIMARY KEY", Table: "users", Columns: []string{"id"}},
+				},
+			},
+			"audit_log": {Columns: []ColumnInfo{{Name: "id", Type: "bigint"}}},
+		},
+	}
+}
+
+func TestDiffSchemaSnapshotsIdentical(t *testing.T) {
+	diff := DiffSchemaSnapshots(sampleSnapshot("prod"), sampleSnapshot("staging"))
+	assert.True(t, diff.Empty())
+	assert.Equal(t, "prod", diff.Source)
+	assert.Equal(t, "staging", diff.Target)
+}
+
+func TestDiffSchemaSnapshotsChanges(t *testing.T) {
+	source := sampleSnapshot("prod")
+	target := sampleSnapshot("staging")
+
+	delete(target.Tables, "audit_log")
+	target.Tables["orders"] = TableSnapshot{Columns: []ColumnInfo{{Name: "id", Type: "integer"}}}
+
+	users := target.Tables["users"]
+	users.Columns = []ColumnInfo{
+		{Name: "id", Type: "bigint"},
+		{Name: "email", Type: "character varying(255)", Nullable: false},
+		{Name: "created_at", Type: "timestamp with time zone", Default: "now()"},
+	}
+	users.Indexes = append(users.Indexes, IndexInfo{Name: "users_email_idx", Table: "users", Columns: []string{"email"}, Method: "btree"})
+	users.Constraints = nil
+	target.Tables["users"] = users
+
+	diff := DiffSchemaSnapshots(source, target)
+	require.False(t, diff.Empty())
+	assert.Equal(t, []string{"orders"}, diff.AddedTables)
+	assert.Equal(t, []string{"audit_log"}, diff.RemovedTables)
+	require.Len(t, diff.ChangedTables, 1)
+
+	table := diff.ChangedTables[0]
+	assert.Equal(t, "users", table.Table)
+	require.Len(t, table.AddedColumns, 1)
+	assert.Equal(t, "created_at", table.AddedColumns[0].Name)
+	assert.Empty(t, table.RemovedColumns)
+
+	changes := make(map[string]map[string]ValueChange)
+	for _, change := range table.ChangedColumns {
+		changes[change.Name] = change.Changes
+	}
+	assert.Equal(t, ValueChange{From: "integer", To: "bigint"}, changes["id"]["type"])
+	assert.Equal(t, ValueChange{From: true, To: false}, changes["email"]["nullable"])
+	assert.Len(t, changes["id"], 1)
+
+	require.Len(t, table.AddedIndexes, 1)
+	assert.Equal(t, "users_email_idx", table.AddedIndexes[0].Name)
+	require.Len(t, table.RemovedConstraints, 1)
+	assert.Equal(t, "users_pkey", table.RemovedConstraints[0].Name)
+}
+
+func TestDiffSchemaSnapshotsJSONRoundTrip(t *testing.T) {
+	snapshot := sampleSnapshot("prod")
+	// Empty column lists come back from JSON as nil
+	snapshot.Tables["audit_log"] = TableSnapshot{
+		Columns: []ColumnInfo{{Name: "id", Type: "bigint"}},
+		Indexes: []IndexInfo{{Name: "audit_expr_idx", Columns: []string{}, Definition: "lower(note)"}},
+	}
+
+	data, err := json.Marshal(snapshot)
+	require.NoError(t, err)
+	var loaded SchemaSnapshot
+	require.NoError(t, json.Unmarshal(data, &loaded))
+
+	assert.True(t, DiffSchemaSnapshots(&loaded, snapshot).Empty())
+}
@@ -70,12 +70,18 @@ func (w *safeStdioWriter) Sync() error {
 	return nil
 }
 
-// Initialize sets up the logger with the specified level
+// Initialize sets up the logger with the specified level, reading the
+// transport mode from the TRANSPORT_MODE environment variable
 func Initialize(level string) {
+	InitializeWithTransport(level, os.Getenv("TRANSPORT_MODE"))
+}
+
+// InitializeWithTransport sets up the logger with the specified level for the
+// given transport mode; in stdio mode nothing is written to stdout
+func InitializeWithTransport(level, transportMode string) {
 	setLogLevel(level)
 
 	// Check if we're in stdio mode
-	transportMode := os.Getenv("TRANSPORT_MODE")
 	isStdioMode = transportMode == "stdio"
 
 	if isStdioMode {
@@ -3,33 +3,41 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"database/sql/driver"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/FreePeak/db-mcp-server/internal/domain"
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+	"github.com/FreePeak/db-mcp-server/pkg/db"
 	"github.com/FreePeak/db-mcp-server/pkg/dbtools"
 )
 
-// TODO: Implement caching layer for database metadata to improve performance
 // TODO: Add observability with tracing and detailed metrics
 // TODO: Improve concurrency handling with proper locking or atomic operations
 // TODO: Consider using an interface-based approach for better testability
 // TODO: Add comprehensive integration tests for different database types
 
 // DatabaseRepository implements domain.DatabaseRepository
-type DatabaseRepository struct{}
+type DatabaseRepository struct {
+	cache *MetadataCache
+}
 
-// NewDatabaseRepository creates a new database repository
-func NewDatabaseRepository() *DatabaseRepository {
-	return &DatabaseRepository{}
+// NewDatabaseRepository creates a new database repository that caches schema
+// metadata for cacheTTL; zero disables the cache
+func NewDatabaseRepository(cacheTTL time.Duration) *DatabaseRepository {
+	return &DatabaseRepository{cache: NewMetadataCache(cacheTTL)}
 }
 
-// GetDatabase retrieves a database by ID
+// GetDatabase retrieves a database by ID. DDL executed through the returned
+// database invalidates the connection's cached metadata.
 func (r *DatabaseRepository) GetDatabase(id string) (domain.Database, error) {
 	db, err := dbtools.GetDatabase(id)
 	if err != nil {
 		return nil, err
 	}
-	return &DatabaseAdapter{db: db}, nil
+	return &DatabaseAdapter{db: db, onDDL: func() { r.cache.Invalidate(id) }}, nil
 }
 
 // ListDatabases returns a list of available database IDs
@@ -60,33 +68,426 @@ func (r *DatabaseRepository) GetDatabaseType(id string) (string, error) {
 	}
 }
 
-// DatabaseAdapter adapts the db.Database to the domain.Database interface
+// GetSchemaComponent retrieves a schema component such as tables, indexes or
+// views for a database by ID, scoped to a schema. Tables, columns,
+// relationships and hypertables are served from the metadata cache.
+func (r *DatabaseRepository) GetSchemaComponent(ctx context.Context, id, component, schema, table string) (map[string]interface{}, error) {
+	if !cachedComponents[component] {
+		return dbtools.DescribeSchema(ctx, id, component, schema, table)
+	}
+
+	key := componentKey(component, schema, table)
+	if result, ok := r.cache.Get(id, key); ok {
+		return result, nil
+	}
+
+	result, err := dbtools.DescribeSchema(ctx, id, component, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	r.cache.Set(id, key, result)
+	return result, nil
+}
+
+// InvalidateSchemaCache drops the cached metadata of a database
+func (r *DatabaseRepository) InvalidateSchemaCache(id string) {
+	r.cache.Invalidate(id)
+}
+
+// ResolveSchema applies a connection's default_schema and schema_allowlist
+// to a requested schema
+func (r *DatabaseRepository) ResolveSchema(id, schema string) (string, error) {
+	return dbtools.ResolveSchema(id, schema)
+}
+
+// GetMigrationsDir returns the migrations directory configured for a database
+func (r *DatabaseRepository) GetMigrationsDir(id string) (string, error) {
+	return dbtools.MigrationsDir(id)
+}
+
+// GetQueryTimeout returns the query timeout configured for a database
+func (r *DatabaseRepository) GetQueryTimeout(id string) (time.Duration, error) {
+	db, err := dbtools.GetDatabase(id)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(dbtools.GetDatabaseQueryTimeout(db)) * time.Millisecond, nil
+}
+
+// GetMaxStatementTimeout returns the longest statement timeout a call may set
+// on a database
+func (r *DatabaseRepository) GetMaxStatementTimeout(id string) (time.Duration, error) {
+	return dbtools.MaxStatementTimeout(id)
+}
+
+// GetPerformanceAnalyzer returns the analyzer of the queries run on a
+// database, shared with the dbtools query and execute tools
+func (r *DatabaseRepository) GetPerformanceAnalyzer(id string) (domain.PerformanceAnalyzer, error) {
+	if _, err := dbtools.GetDatabase(id); err != nil {
+		return nil, err
+	}
+	return NewPerformanceAnalyzer(dbtools.GetPerformanceAnalyzer(id)), nil
+}
+
+// GetWorkloadStatistics reads up to limit statements from the database's
+// own statement statistics, ranked by orderBy
+func (r *DatabaseRepository) GetWorkloadStatistics(ctx context.Context, id, orderBy string, limit int) (*domain.WorkloadStatistics, error) {
+	stats, err := dbtools.GetWorkloadStatistics(ctx, id, orderBy, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := &domain.WorkloadStatistics{
+		Source:    stats.Source,
+		Available: stats.Available,
+		Reason:    stats.Reason,
+		OrderBy:   orderBy,
+		Queries:   make([]domain.QueryFingerprint, 0, len(stats.Queries)),
+	}
+	for _, m := range stats.Queries {
+		result.Queries = append(result.Queries, queryFingerprint(m))
+	}
+	return result, nil
+}
+
+// ExplainQuery plans a query without executing it and flags the issues of
+// its plan
+func (r *DatabaseRepository) ExplainQuery(ctx context.Context, id, query string) (*domain.QueryPlan, error) {
+	db, err := dbtools.GetDatabase(id)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := dbtools.ExplainPlan(ctx, db, query, false)
+	if err != nil {
+		return nil, err
+	}
+	result := &domain.QueryPlan{Analyzed: plan.Analyzed, Plan: plan.String()}
+	for _, issue := range plan.Issues() {
+		result.Issues = append(result.Issues, domain.PlanIssue{
+			Kind:       issue.Kind,
+			Relation:   issue.Relation,
+			Message:    issue.Message,
+			Suggestion: issue.Suggestion,
+		})
+	}
+	return result, nil
+}
+
+// AdviseIndexes proposes indexes for the workload of a database and flags
+// its unused and duplicate indexes
+func (r *DatabaseRepository) AdviseIndexes(ctx context.Context, id, schema string, opts domain.IndexAdviceOptions) (*domain.IndexAdvice, error) {
+	advice, err := dbtools.AdviseIndexes(ctx, id, schema, dbtools.IndexAdviceOptions{
+		Source:   opts.Source,
+		Limit:    opts.Limit,
+		MinRows:  opts.MinRows,
+		Validate: opts.Validate,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := &domain.IndexAdvice{
+		Source:     advice.Source,
+		Schema:     advice.Schema,
+		Planned:    advice.Planned,
+		Skipped:    advice.Skipped,
+		Validated:  advice.Validated,
+		Proposals:  make([]domain.IndexProposal, 0, len(advice.Proposals)),
+		Unused:     indexFindings(advice.Unused),
+		Duplicates: indexFindings(advice.Duplicates),
+		Notes:      advice.Notes,
+	}
+	for _, p := range advice.Proposals {
+		result.Proposals = append(result.Proposals, domain.IndexProposal{
+			Table:           p.Table,
+			Columns:         p.Columns,
+			Statement:       p.Statement,
+			Rationale:       p.Rationale,
+			Fingerprints:    p.Fingerprints,
+			Calls:           p.Calls,
+			RowsScanned:     p.RowsScanned,
+			RowsReturned:    p.RowsReturned,
+			EstimatedSaving: milliseconds(p.EstimatedSaving),
+			CostBefore:      p.CostBefore,
+			CostAfter:       p.CostAfter,
+		})
+	}
+	return result, nil
+}
+
+// indexFindings converts unused or duplicate indexes
+func indexFindings(findings []dbtools.IndexFinding) []domain.IndexFinding {
+	result := make([]domain.IndexFinding, 0, len(findings))
+	for _, f := range findings {
+		result = append(result, domain.IndexFinding(f))
+	}
+	return result
+}
+
+// GetLockWaits lists the sessions of a database waiting for locks with the
+// sessions blocking them
+func (r *DatabaseRepository) GetLockWaits(ctx context.Context, id string) ([]domain.LockWait, error) {
+	waits, err := dbtools.GetLockWaits(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.LockWait, 0, len(waits))
+	for _, w := range waits {
+		result = append(result, domain.LockWait{
+			WaitingPID:       w.WaitingPID,
+			WaitingUser:      w.WaitingUser,
+			WaitingQuery:     w.WaitingQuery,
+			WaitDuration:     milliseconds(w.WaitDuration),
+			LockType:         w.LockType,
+			LockMode:         w.LockMode,
+			Object:           w.Object,
+			BlockingPID:      w.BlockingPID,
+			BlockingUser:     w.BlockingUser,
+			BlockingQuery:    w.BlockingQuery,
+			BlockingState:    w.BlockingState,
+			BlockingDuration: milliseconds(w.BlockingDuration),
+		})
+	}
+	return result, nil
+}
+
+// GetActivity lists the live sessions of a database
+func (r *DatabaseRepository) GetActivity(ctx context.Context, id string, filter domain.ActivityFilter) ([]domain.SessionActivity, error) {
+	sessions, err := dbtools.GetActivity(ctx, id, dbtools.ActivityFilter{
+		PID:         filter.PID,
+		State:       filter.State,
+		User:        filter.User,
+		MinDuration: time.Duration(filter.MinDuration * float64(time.Millisecond)),
+		Limit:       filter.Limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.SessionActivity, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, domain.SessionActivity{
+			PID:                 s.PID,
+			User:                s.User,
+			Database:            s.Database,
+			Application:         s.Application,
+			ClientAddr:          s.ClientAddr,
+			State:               s.State,
+			Query:               s.Query,
+			Duration:            milliseconds(s.Duration),
+			TransactionDuration: milliseconds(s.TransactionDuration),
+			WaitEventType:       s.WaitEventType,
+			WaitEvent:           s.WaitEvent,
+		})
+	}
+	return result, nil
+}
+
+// GetStorageStats reports the disk usage of the tables and indexes of a
+// schema
+func (r *DatabaseRepository) GetStorageStats(ctx context.Context, id, schema string, opts domain.StorageOptions) (*domain.StorageReport, error) {
+	report, err := dbtools.GetStorageStats(ctx, id, schema, dbtools.StorageOptions{OrderBy: opts.OrderBy, Limit: opts.Limit})
+	if err != nil {
+		return nil, err
+	}
+	result := &domain.StorageReport{
+		Schema:          report.Schema,
+		OrderBy:         report.OrderBy,
+		Tables:          report.Tables,
+		TotalBytes:      report.TotalBytes,
+		DatabaseSize:    report.DatabaseSize,
+		HypertablesSize: report.HypertablesSize,
+		TopTables:       make([]domain.TableStorage, 0, len(report.TopTables)),
+		TopIndexes:      make([]domain.IndexStorage, 0, len(report.TopIndexes)),
+		Notes:           report.Notes,
+	}
+	for _, t := range report.TopTables {
+		result.TopTables = append(result.TopTables, domain.TableStorage(t))
+	}
+	for _, index := range report.TopIndexes {
+		result.TopIndexes = append(result.TopIndexes, domain.IndexStorage(index))
+	}
+	for _, h := range report.Hypertables {
+		hypertable := domain.HypertableStorage{
+			Table:            h.Table,
+			ChunkCount:       h.ChunkCount,
+			CompressedChunks: h.CompressedChunks,
+			TableBytes:       h.TableBytes,
+			IndexBytes:       h.IndexBytes,
+			ToastBytes:       h.ToastBytes,
+			TotalBytes:       h.TotalBytes,
+			Chunks:           make([]domain.ChunkStorage, 0, len(h.Chunks)),
+		}
+		for _, c := range h.Chunks {
+			hypertable.Chunks = append(hypertable.Chunks, domain.ChunkStorage{
+				Name:       c.ChunkSchema + "." + c.ChunkName,
+				RangeStart: c.RangeStart,
+				RangeEnd:   c.RangeEnd,
+				Compressed: c.IsCompressed,
+				TotalBytes: c.TotalBytes,
+				IndexBytes: c.IndexBytes,
+			})
+		}
+		result.Hypertables = append(result.Hypertables, hypertable)
+	}
+	return result, nil
+}
+
+// SignalSession cancels the query of a database session or terminates it
+func (r *DatabaseRepository) SignalSession(ctx context.Context, id string, pid int64, signal string) error {
+	return dbtools.SignalSession(ctx, id, pid, signal)
+}
+
+// GetJoinPath finds how to join tables through the foreign keys of a schema,
+// read from the cached relationships component
+func (r *DatabaseRepository) GetJoinPath(ctx context.Context, id, schema string, tables []string, withQuery bool) (*domain.JoinPath, error) {
+	dbType, err := r.GetDatabaseType(id)
+	if err != nil {
+		return nil, err
+	}
+	result, err := r.GetSchemaComponent(ctx, id, "relationships", schema, "")
+	if err != nil {
+		return nil, err
+	}
+	relationships, _ := result["relationships"].([]map[string]interface{})
+	resolved, _ := result["schema"].(string)
+
+	path, err := dbtools.FindJoinPath(dbType, resolved, relationships, tables)
+	if err != nil {
+		return nil, err
+	}
+
+	joinPath := &domain.JoinPath{Schema: path.Schema, From: path.From, Via: path.Via, Joins: make([]domain.JoinStep, 0, len(path.Joins))}
+	for _, step := range path.Joins {
+		joinPath.Joins = append(joinPath.Joins, domain.JoinStep{Table: step.Table, To: step.To, Constraint: step.Constraint, On: step.On})
+	}
+	if withQuery {
+		if joinPath.Query, err = path.Query(dbType); err != nil {
+			return nil, err
+		}
+	}
+	return joinPath, nil
+}
+
+// resetTimeout bounds resetting the statement timeout of a connection
+const resetTimeout = 2 * time.Second
+
+// DatabaseAdapter adapts the db.Database to the domain.Database interface.
+// A statement timeout set on the context with domain.WithStatementTimeout is
+// enforced by the server: PostgreSQL statements run on a connection whose
+// statement_timeout is set for them and reset afterwards, and MySQL SELECTs
+// get a MAX_EXECUTION_TIME hint.
 type DatabaseAdapter struct {
 	db interface {
 		Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 		Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 		BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+		DriverName() string
+		DB() *sql.DB
 	}
+	// onDDL is called after a statement that may change the schema succeeds
+	onDDL func()
 }
 
 // Query executes a query on the database
 func (a *DatabaseAdapter) Query(ctx context.Context, query string, args ...interface{}) (domain.Rows, error) {
-	rows, err := a.db.Query(ctx, query, args...)
+	if timeout, ok := domain.StatementTimeout(ctx); ok {
+		switch a.db.DriverName() {
+		case "postgres":
+			return a.queryWithTimeout(ctx, timeout, query, args...)
+		case "mysql":
+			query = db.WithMaxExecutionTime(query, timeout)
+		}
+	}
+
+	rows, err := db.QueryPinned(ctx, a.db, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	return &RowsAdapter{rows: rows}, nil
 }
 
+// queryWithTimeout runs a PostgreSQL query on a connection limited to
+// timeout, released when the rows are closed
+func (a *DatabaseAdapter) queryWithTimeout(ctx context.Context, timeout time.Duration, query string, args ...interface{}) (domain.Rows, error) {
+	conn, err := a.connWithTimeout(ctx, timeout)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		releaseConn(conn)
+		return nil, err
+	}
+	return &RowsAdapter{rows: &db.Rows{Rows: rows}, conn: conn}, nil
+}
+
 // Exec executes a statement on the database
 func (a *DatabaseAdapter) Exec(ctx context.Context, statement string, args ...interface{}) (domain.Result, error) {
-	result, err := a.db.Exec(ctx, statement, args...)
+	var result sql.Result
+	var err error
+	timeout, ok := domain.StatementTimeout(ctx)
+	switch {
+	case ok && a.db.DriverName() == "postgres":
+		result, err = a.execWithTimeout(ctx, timeout, statement, args...)
+	case ok && a.db.DriverName() == "mysql":
+		result, err = a.db.Exec(ctx, db.WithMaxExecutionTime(statement, timeout), args...)
+	default:
+		result, err = a.db.Exec(ctx, statement, args...)
+	}
 	if err != nil {
 		return nil, err
 	}
+	if a.onDDL != nil && isDDL(statement) {
+		a.onDDL()
+	}
 	return &ResultAdapter{result: result}, nil
 }
 
+// execWithTimeout runs a PostgreSQL statement on a connection limited to
+// timeout. The statement runs outside a transaction block, so VACUUM or
+// CREATE INDEX CONCURRENTLY work as without a timeout.
+func (a *DatabaseAdapter) execWithTimeout(ctx context.Context, timeout time.Duration, statement string, args ...interface{}) (sql.Result, error) {
+	conn, err := a.connWithTimeout(ctx, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer releaseConn(conn)
+	return conn.ExecContext(ctx, statement, args...)
+}
+
+// connWithTimeout takes a connection from the pool whose PostgreSQL statements
+// the server stops after timeout. Its other settings, such as the
+// connection's lock_timeout, are left as they are.
+func (a *DatabaseAdapter) connWithTimeout(ctx context.Context, timeout time.Duration) (*sql.Conn, error) {
+	sqlDB := a.db.DB()
+	if sqlDB == nil {
+		return nil, db.ErrNoDatabase
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, db.SetStatementTimeout(timeout)); err != nil {
+		releaseConn(conn)
+		return nil, fmt.Errorf("failed to set statement timeout: %w", err)
+	}
+	return conn, nil
+}
+
+// releaseConn resets the statement timeout of a connection to the one it was
+// opened with and returns it to the pool. A connection that cannot be reset is
+// closed instead, so that the timeout does not leak into other calls.
+func releaseConn(conn *sql.Conn) {
+	ctx, cancel := context.WithTimeout(context.Background(), resetTimeout)
+	defer cancel()
+
+	if _, err := conn.ExecContext(ctx, db.ResetStatementTimeout); err != nil {
+		logger.Warn("Failed to reset statement timeout, closing the connection: %v", err)
+		_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	}
+	if err := conn.Close(); err != nil && !errors.Is(err, sql.ErrConnDone) {
+		logger.Error("Error releasing connection: %v", err)
+	}
+}
+
 // Begin starts a new transaction
 func (a *DatabaseAdapter) Begin(ctx context.Context, opts *domain.TxOptions) (domain.Tx, error) {
 	txOpts := &sql.TxOptions{}
@@ -94,21 +495,27 @@ func (a *DatabaseAdapter) Begin(ctx context.Context, opts *domain.TxOptions) (do
 		txOpts.ReadOnly = opts.ReadOnly
 	}
 
-	tx, err := a.db.BeginTx(ctx, txOpts)
+	tx, err := db.BeginPinnedTx(ctx, a.db, txOpts)
 	if err != nil {
 		return nil, err
 	}
-	return &TxAdapter{tx: tx}, nil
+	return &TxAdapter{tx: tx, driver: a.db.DriverName(), onDDL: a.onDDL}, nil
 }
 
 // RowsAdapter adapts sql.Rows to domain.Rows
 type RowsAdapter struct {
-	rows *sql.Rows
+	rows *db.Rows
+	conn *sql.Conn // set when the query runs on a connection of its own
 }
 
-// Close closes the rows
+// Close closes the rows, releasing the connection of the query if it has one
 func (a *RowsAdapter) Close() error {
-	return a.rows.Close()
+	err := a.rows.Close()
+	if a.conn != nil {
+		releaseConn(a.conn)
+		a.conn = nil
+	}
+	return err
 }
 
 // Columns returns the column names
@@ -146,14 +553,24 @@ func (a *ResultAdapter) LastInsertId() (int64, error) {
 	return a.result.LastInsertId()
 }
 
-// TxAdapter adapts sql.Tx to domain.Tx
+// TxAdapter adapts sql.Tx to domain.Tx. A statement timeout on the context
+// applies to the rest of a PostgreSQL transaction.
 type TxAdapter struct {
-	tx *sql.Tx
+	tx     *db.Tx
+	driver string
+	onDDL  func()
+	ddl    bool // set once the transaction has executed DDL
 }
 
 // Commit commits the transaction
 func (a *TxAdapter) Commit() error {
-	return a.tx.Commit()
+	if err := a.tx.Commit(); err != nil {
+		return err
+	}
+	if a.ddl && a.onDDL != nil {
+		a.onDDL()
+	}
+	return nil
 }
 
 // Rollback rolls back the transaction
@@ -163,18 +580,47 @@ func (a *TxAdapter) Rollback() error {
 
 // Query executes a query within the transaction
 func (a *TxAdapter) Query(ctx context.Context, query string, args ...interface{}) (domain.Rows, error) {
+	query, err := a.applyTimeout(ctx, query)
+	if err != nil {
+		return nil, err
+	}
 	rows, err := a.tx.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
-	return &RowsAdapter{rows: rows}, nil
+	return &RowsAdapter{rows: &db.Rows{Rows: rows}}, nil
 }
 
 // Exec executes a statement within the transaction
 func (a *TxAdapter) Exec(ctx context.Context, statement string, args ...interface{}) (domain.Result, error) {
+	statement, err := a.applyTimeout(ctx, statement)
+	if err != nil {
+		return nil, err
+	}
 	result, err := a.tx.ExecContext(ctx, statement, args...)
 	if err != nil {
 		return nil, err
 	}
+	if isDDL(statement) {
+		a.ddl = true
+	}
 	return &ResultAdapter{result: result}, nil
 }
+
+// applyTimeout enforces the statement timeout of a context on a statement run
+// in the transaction, returning the statement to run
+func (a *TxAdapter) applyTimeout(ctx context.Context, statement string) (string, error) {
+	timeout, ok := domain.StatementTimeout(ctx)
+	if !ok {
+		return statement, nil
+	}
+	switch a.driver {
+	case "postgres":
+		if _, err := a.tx.ExecContext(ctx, db.SetLocalStatementTimeout(timeout)); err != nil {
+			return "", fmt.Errorf("failed to set statement timeout: %w", err)
+		}
+	case "mysql":
+		return db.WithMaxExecutionTime(statement, timeout), nil
+	}
+	return statement, nil
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+)
+
+// recordingDriver records the statements each of its connections runs, like
+// a PostgreSQL server that refuses VACUUM in a transaction block
+type recordingDriver struct {
+	mu        sync.Mutex
+	conns     int
+	log       []string
+	failReset bool
+}
+
+func (d *recordingDriver) Connect(context.Context) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.conns++
+	return &recordingConn{d: d, id: d.conns}, nil
+}
+
+func (d *recordingDriver) Driver() driver.Driver { return nil }
+
+func (d *recordingDriver) record(id int, statement string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log = append(d.log, fmt.Sprintf("%d: %s", id, statement))
+}
+
+func (d *recordingDriver) statements() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.log...)
+}
+
+type recordingConn struct {
+	d    *recordingDriver
+	id   int
+	inTx bool
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) {
+	return recordingStmt{c, query}, nil
+}
+func (c *recordingConn) Close() error {
+	c.d.record(c.id, "CLOSE")
+	return nil
+}
+func (c *recordingConn) Begin() (driver.Tx, error) {
+	c.d.record(c.id, "BEGIN")
+	c.inTx = true
+	return recordingTx{c}, nil
+}
+
+type recordingTx struct{ c *recordingConn }
+
+func (tx recordingTx) Commit() error   { return tx.end("COMMIT") }
+func (tx recordingTx) Rollback() error { return tx.end("ROLLBACK") }
+func (tx recordingTx) end(statement string) error {
+	tx.c.inTx = false
+	tx.c.d.record(tx.c.id, statement)
+	return nil
+}
+
+type recordingStmt struct {
+	c     *recordingConn
+	query string
+}
+
+func (s recordingStmt) Close() error  { return nil }
+func (s recordingStmt) NumInput() int { return -1 }
+func (s recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.d.record(s.c.id, s.query)
+	if s.query == "VACUUM orders" && s.c.inTx {
+		return nil, errors.New("VACUUM cannot run inside a transaction block")
+	}
+	if s.query == db.ResetStatementTimeout && s.c.d.failReset {
+		return nil, errors.New("server closed the connection unexpectedly")
+	}
+	return driver.RowsAffected(0), nil
+}
+func (s recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.c.d.record(s.c.id, s.query)
+	return &recordingRows{}, nil
+}
+
+type recordingRows struct{ done bool }
+
+func (r *recordingRows) Columns() []string { return []string{"id"} }
+func (r *recordingRows) Close() error      { return nil }
+func (r *recordingRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0] = int64(1)
+	r.done = true
+	return nil
+}
+
+// recordingDatabase serves the database of a DatabaseAdapter from a
+// recordingDriver
+type recordingDatabase struct{ sqlDB *sql.DB }
+
+func (r *recordingDatabase) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return r.sqlDB.QueryContext(ctx, query, args...)
+}
+func (r *recordingDatabase) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return r.sqlDB.ExecContext(ctx, query, args...)
+}
+func (r *recordingDatabase) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return r.sqlDB.BeginTx(ctx, opts)
+}
+func (r *recordingDatabase) DriverName() string { return "postgres" }
+func (r *recordingDatabase) DB() *sql.DB        { return r.sqlDB }
+
+func newRecordingAdapter() (*DatabaseAdapter, *recordingDriver) {
+	logger.InitializeWithWriter("error", os.Stderr)
+	d := &recordingDriver{}
+	return &DatabaseAdapter{db: &recordingDatabase{sqlDB: sql.OpenDB(d)}}, d
+}
+
+func TestExecWithStatementTimeout(t *testing.T) {
+	adapter, d := newRecordingAdapter()
+	defer adapter.db.DB().Close()
+
+	ctx := domain.WithStatementTimeout(context.Background(), 1500*time.Millisecond)
+	_, err := adapter.Exec(ctx, "VACUUM orders")
+	require.NoError(t, err)
+
+	// The statement runs outside a transaction block and the timeout is
+	// reset before the connection goes back to the pool
+	assert.Equal(t, []string{
+		"1: SET statement_timeout = 1500",
+		"1: VACUUM orders",
+		"1: RESET statement_timeout",
+	}, d.statements())
+	assert.Equal(t, 0, adapter.db.DB().Stats().InUse)
+}
+
+func TestQueryWithStatementTimeout(t *testing.T) {
+	adapter, d := newRecordingAdapter()
+	defer adapter.db.DB().Close()
+
+	ctx := domain.WithStatementTimeout(context.Background(), 2*time.Second)
+	rows, err := adapter.Query(ctx, "SELECT id FROM orders")
+	require.NoError(t, err)
+	for rows.Next() {
+	}
+	assert.Equal(t, []string{
+		"1: SET statement_timeout = 2000",
+		"1: SELECT id FROM orders",
+	}, d.statements())
+
+	require.NoError(t, rows.Close())
+	assert.Equal(t, "1: RESET statement_timeout", d.statements()[2])
+	assert.Equal(t, 0, adapter.db.DB().Stats().InUse)
+}
+
+func TestStatementTimeoutConnectionClosedWhenResetFails(t *testing.T) {
+	adapter, d := newRecordingAdapter()
+	defer adapter.db.DB().Close()
+	d.failReset = true
+
+	ctx := domain.WithStatementTimeout(context.Background(), time.Second)
+	_, err := adapter.Exec(ctx, "UPDATE orders SET status = 'open'")
+	require.NoError(t, err)
+
+	// A connection still limited by the timeout never goes back to the pool
+	assert.Equal(t, []string{
+		"1: SET statement_timeout = 1000",
+		"1: UPDATE orders SET status = 'open'",
+		"1: RESET statement_timeout",
+		"1: CLOSE",
+	}, d.statements())
+	assert.Equal(t, 0, adapter.db.DB().Stats().OpenConnections)
+}
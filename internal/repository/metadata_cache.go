@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/pkg/dbtools"
+)
+
+// cachedComponents are the schema components kept in the metadata cache
+var cachedComponents = map[string]bool{
+	"tables":        true,
+	"columns":       true,
+	"relationships": true,
+	"hypertables":   true,
+	"summary":       true,
+	"erd":           true,
+	"full":          true,
+}
+
+// MetadataCache holds schema metadata per connection until it expires or the
+// connection is invalidated
+type MetadataCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.RWMutex
+	entries map[string]map[string]cacheEntry // connection ID -> key -> entry
+}
+
+type cacheEntry struct {
+	value   map[string]interface{}
+	expires time.Time
+}
+
+// NewMetadataCache creates a metadata cache. A ttl of zero or less disables
+// caching.
+func NewMetadataCache(ttl time.Duration) *MetadataCache {
+	return &MetadataCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]map[string]cacheEntry),
+	}
+}
+
+// Get returns a copy of a cached value that has not expired
+func (c *MetadataCache) Get(id, key string) (map[string]interface{}, bool) {
+	if c.ttl <= 0 {
+		return nil, false
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	entry, ok := c.entries[id][key]
+	if !ok || !c.now().Before(entry.expires) {
+		return nil, false
+	}
+	return copyMap(entry.value), true
+}
+
+// Set stores a copy of a value for a connection
+func (c *MetadataCache) Set(id, key string, value map[string]interface{}) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.entries[id] == nil {
+		c.entries[id] = make(map[string]cacheEntry)
+	}
+	c.entries[id][key] = cacheEntry{value: copyMap(value), expires: c.now().Add(c.ttl)}
+}
+
+// Invalidate drops everything cached for a connection
+func (c *MetadataCache) Invalidate(id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, id)
+}
+
+// componentKey builds the cache key for a schema component request
+func componentKey(component, schema, table string) string {
+	return component + "\x00" + schema + "\x00" + table
+}
+
+// ddlKeywords start statements that change schema metadata or privileges
+var ddlKeywords = []string{"CREATE", "ALTER", "DROP", "RENAME", "COMMENT", "TRUNCATE", "GRANT", "REVOKE"}
+
+// isDDL reports whether any statement of a query may change schema metadata,
+// ignoring comments and literals. Calls to TimescaleDB's create_hypertable
+// count as DDL too.
+func isDDL(query string) bool {
+	for _, statement := range dbtools.Statements(query) {
+		upper := strings.ToUpper(strings.TrimLeft(statement, " \t\r\n("))
+		for _, keyword := range ddlKeywords {
+			if strings.HasPrefix(upper, keyword) {
+				return true
+			}
+		}
+		if strings.Contains(upper, "CREATE_HYPERTABLE") {
+			return true
+		}
+	}
+	return false
+}
+
+// copyValue copies the maps and slices of a cached value so that callers
+// cannot change the cache
+func copyValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return copyMap(v)
+	case []map[string]interface{}:
+		copied := make([]map[string]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyMap(item)
+		}
+		return copied
+	case []interface{}:
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = copyValue(item)
+		}
+		return copied
+	case []string:
+		return append([]string(nil), v...)
+	default:
+		return value
+	}
+}
+
+// copyMap copies a map and the maps and slices it holds
+func copyMap(value map[string]interface{}) map[string]interface{} {
+	if value == nil {
+		return nil
+	}
+	copied := make(map[string]interface{}, len(value))
+	for key, item := range value {
+		copied[key] = copyValue(item)
+	}
+	return copied
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetadataCacheExpiry(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewMetadataCache(time.Minute)
+	cache.now = func() time.Time { return now }
+
+	key := componentKey("tables", "public", "")
+	value := map[string]interface{}{"tables": []string{"users"}}
+	cache.Set("pg", key, value)
+
+	got, ok := cache.Get("pg", key)
+	assert.True(t, ok)
+	assert.Equal(t, value, got)
+
+	_, ok = cache.Get("pg", componentKey("tables", "sales", ""))
+	assert.False(t, ok, "keys include the schema")
+	_, ok = cache.Get("mysql", key)
+	assert.False(t, ok, "entries are per connection")
+
+	now = now.Add(time.Minute)
+	_, ok = cache.Get("pg", key)
+	assert.False(t, ok, "entry should expire after the TTL")
+}
+
+func TestMetadataCacheReturnsCopies(t *testing.T) {
+	cache := NewMetadataCache(time.Minute)
+	key := componentKey("tables", "", "")
+	value := map[string]interface{}{"tables": []map[string]interface{}{{"table_name": "users"}}}
+	cache.Set("pg", key, value)
+	value["tables"].([]map[string]interface{})[0]["table_name"] = "changed"
+
+	got, ok := cache.Get("pg", key)
+	assert.True(t, ok)
+	got["tables"].([]map[string]interface{})[0]["table_name"] = "changed again"
+	got["count"] = 1
+
+	got, _ = cache.Get("pg", key)
+	assert.Equal(t, map[string]interface{}{"tables": []map[string]interface{}{{"table_name": "users"}}}, got)
+}
+
+func TestMetadataCacheInvalidate(t *testing.T) {
+	cache := NewMetadataCache(time.Minute)
+	cache.Set("pg", componentKey("tables", "", ""), map[string]interface{}{})
+	cache.Set("mysql", componentKey("tables", "", ""), map[string]interface{}{})
+
+	cache.Invalidate("pg")
+
+	_, ok := cache.Get("pg", componentKey("tables", "", ""))
+	assert.False(t, ok)
+	_, ok = cache.Get("mysql", componentKey("tables", "", ""))
+	assert.True(t, ok)
+}
+
+func TestMetadataCacheDisabled(t *testing.T) {
+	cache := NewMetadataCache(0)
+	cache.Set("pg", "key", map[string]interface{}{})
+	_, ok := cache.Get("pg", "key")
+	assert.False(t, ok)
+}
+
+func TestIsDDL(t *testing.T) {
+	for _, statement := range []string{
+		"CREATE TABLE t (id int)",
+		"  alter table t add column x int",
+		"DROP INDEX idx",
+		"COMMENT ON TABLE t IS 'x'",
+		"RENAME TABLE a TO b",
+		"SELECT create_hypertable('metrics', 'time')",
+		"TRUNCATE orders",
+		"GRANT SELECT ON t TO reporting",
+		"REVOKE ALL ON t FROM public",
+		"-- add a column\nALTER TABLE t ADD COLUMN x int",
+		"/* migration */ DROP TABLE t",
+		"INSERT INTO t VALUES (1); CREATE INDEX idx ON t (id)",
+	} {
+		assert.True(t, isDDL(statement), statement)
+	}
+	for _, statement := range []string{
+		"INSERT INTO t VALUES (1)",
+		"UPDATE t SET created = now()",
+		"DELETE FROM t",
+		"SELECT * FROM t",
+		"SELECT 'DROP TABLE t'",
+		"-- CREATE TABLE t\nSELECT 1",
+	} {
+		assert.False(t, isDDL(statement), statement)
+	}
+}
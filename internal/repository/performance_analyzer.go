@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+	"github.com/FreePeak/db-mcp-server/pkg/dbtools"
+)
+
+// PerformanceAnalyzer implements domain.PerformanceAnalyzer for one
+// connection on top of its dbtools analyzer
+type PerformanceAnalyzer struct {
+	analyzer *dbtools.PerformanceAnalyzer
+}
+
+// NewPerformanceAnalyzer adapts a dbtools analyzer
+func NewPerformanceAnalyzer(analyzer *dbtools.PerformanceAnalyzer) *PerformanceAnalyzer {
+	return &PerformanceAnalyzer{analyzer: analyzer}
+}
+
+// RecordQuery records a query that just finished after duration, having
+// returned or affected rows
+func (a *PerformanceAnalyzer) RecordQuery(query string, params []interface{}, duration time.Duration, rows int64, err error) {
+	a.analyzer.RecordQuery(query, params, time.Now().Add(-duration), duration, rows, err)
+}
+
+// GetSlowQueries returns up to limit of the recorded slow queries, slowest
+// first
+func (a *PerformanceAnalyzer) GetSlowQueries(limit int) ([]domain.SlowQuery, error) {
+	records := a.analyzer.GetSlowQueries(limit)
+	slow := make([]domain.SlowQuery, 0, len(records))
+	for _, record := range records {
+		slow = append(slow, domain.SlowQuery{
+			Query:     record.Query,
+			Duration:  milliseconds(record.Duration),
+			Timestamp: record.StartTime.Format(time.RFC3339),
+			Error:     record.Error,
+		})
+	}
+	return slow, nil
+}
+
+// GetMetrics returns the totals since the last reset
+func (a *PerformanceAnalyzer) GetMetrics() (domain.PerformanceMetrics, error) {
+	stats := a.analyzer.GetStats()
+	metrics := domain.PerformanceMetrics{
+		TotalQueries:  stats.TotalQueries,
+		MaxDuration:   milliseconds(stats.MaxDuration),
+		SlowQueries:   stats.SlowQueries,
+		Errors:        stats.Errors,
+		Threshold:     int(a.analyzer.GetSlowThreshold().Milliseconds()),
+		LastResetTime: stats.ResetTime.Format(time.RFC3339),
+	}
+	if stats.TotalQueries > 0 {
+		metrics.AvgDuration = milliseconds(stats.TotalDuration / time.Duration(stats.TotalQueries))
+	}
+	return metrics, nil
+}
+
+// GetTopQueries returns up to limit query fingerprints ranked by orderBy
+func (a *PerformanceAnalyzer) GetTopQueries(orderBy string, limit int) ([]domain.QueryFingerprint, error) {
+	metrics, err := a.analyzer.TopQueries(orderBy, limit)
+	if err != nil {
+		return nil, err
+	}
+	fingerprints := make([]domain.QueryFingerprint, 0, len(metrics))
+	for _, m := range metrics {
+		fingerprints = append(fingerprints, queryFingerprint(m))
+	}
+	return fingerprints, nil
+}
+
+// AnalyzeQuery returns the recorded executions of a query's fingerprint and
+// suggestions for it. The EXPLAIN plan is left to the caller, which holds
+// the connection.
+func (a *PerformanceAnalyzer) AnalyzeQuery(query string) (domain.QueryAnalysis, error) {
+	analysis := domain.QueryAnalysis{
+		Query:       query,
+		Fingerprint: dbtools.QueryFingerprint(query),
+		Suggestions: dbtools.AnalyzeQuery(query),
+	}
+	if metrics := a.analyzer.GetQueryMetrics(query); metrics != nil {
+		analysis.Executions = metrics.Count
+		analysis.AvgDuration = milliseconds(metrics.AvgDuration)
+		analysis.P95Duration = milliseconds(metrics.P95Duration)
+		analysis.MaxDuration = milliseconds(metrics.MaxDuration)
+	}
+	return analysis, nil
+}
+
+// Reset clears the history, fingerprints and totals
+func (a *PerformanceAnalyzer) Reset() error {
+	a.analyzer.Reset()
+	return nil
+}
+
+// SetThreshold sets the slow query threshold in milliseconds
+func (a *PerformanceAnalyzer) SetThreshold(threshold int) error {
+	if threshold <= 0 {
+		return fmt.Errorf("slow query threshold must be positive, got %d ms", threshold)
+	}
+	a.analyzer.SetSlowThreshold(time.Duration(threshold) * time.Millisecond)
+	return nil
+}
+
+// queryFingerprint converts the metrics of a fingerprint
+func queryFingerprint(m *dbtools.QueryMetrics) domain.QueryFingerprint {
+	fingerprint := domain.QueryFingerprint{
+		Fingerprint: m.Fingerprint,
+		Query:       m.Query,
+		Calls:       m.Count,
+		Errors:      m.Errors,
+		Rows:        m.Rows,
+		TotalTime:   milliseconds(m.TotalDuration),
+		MeanTime:    milliseconds(m.AvgDuration),
+		MinTime:     milliseconds(m.MinDuration),
+		MaxTime:     milliseconds(m.MaxDuration),
+		P50Time:     milliseconds(m.P50Duration),
+		P95Time:     milliseconds(m.P95Duration),
+		P99Time:     milliseconds(m.P99Duration),
+
+		RowsExamined: m.RowsExamined,
+		BlocksHit:    m.BlocksHit,
+		BlocksRead:   m.BlocksRead,
+		IOTime:       milliseconds(m.IOTime),
+	}
+	if !m.LastExecuted.IsZero() {
+		fingerprint.LastSeen = m.LastExecuted.Format(time.RFC3339)
+	}
+	return fingerprint
+}
+
+// milliseconds converts a duration to fractional milliseconds
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000.0
+}
+
+// GetPlanRegressions returns the frequent queries whose plan regressed from
+// the baseline kept in the connection's plan store
+func (a *PerformanceAnalyzer) GetPlanRegressions() ([]domain.PlanRegression, error) {
+	tracker := a.analyzer.PlanTracker()
+	if tracker == nil {
+		return nil, errPlanTrackingDisabled
+	}
+	regressions := tracker.Regressions()
+	result := make([]domain.PlanRegression, 0, len(regressions))
+	for _, r := range regressions {
+		result = append(result, domain.PlanRegression{
+			Fingerprint:   r.Fingerprint,
+			Query:         r.Query,
+			Kind:          r.Kind,
+			BaselineCost:  r.Baseline.Cost,
+			LatestCost:    r.Latest.Cost,
+			BaselinePlan:  r.Baseline.Plan,
+			LatestPlan:    r.Latest.Plan,
+			BaselineSince: r.Baseline.CheckedAt.Format(time.RFC3339),
+			CheckedAt:     r.Latest.CheckedAt.Format(time.RFC3339),
+		})
+	}
+	return result, nil
+}
+
+// AcceptPlan makes the latest plan of a query fingerprint its baseline
+func (a *PerformanceAnalyzer) AcceptPlan(fingerprint string) error {
+	tracker := a.analyzer.PlanTracker()
+	if tracker == nil {
+		return errPlanTrackingDisabled
+	}
+	return tracker.Accept(fingerprint)
+}
+
+// errPlanTrackingDisabled is returned for plan regressions of a connection
+// without a plan store
+var errPlanTrackingDisabled = errors.New("plan regressions are not tracked; set plan_store for the connection")
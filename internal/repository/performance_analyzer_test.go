@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+	"github.com/FreePeak/db-mcp-server/pkg/dbtools"
+)
+
+func TestPerformanceAnalyzer(t *testing.T) {
+	logger.InitializeWithWriter("error", os.Stderr)
+	analyzer := NewPerformanceAnalyzer(dbtools.NewPerformanceAnalyzer())
+	require.NoError(t, analyzer.SetThreshold(100))
+
+	analyzer.RecordQuery("SELECT * FROM users WHERE id = 1", nil, 20*time.Millisecond, 1, nil)
+	analyzer.RecordQuery("SELECT * FROM users WHERE id = 2", nil, 40*time.Millisecond, 1, nil)
+	analyzer.RecordQuery("SELECT * FROM orders", nil, 300*time.Millisecond, 250, nil)
+	analyzer.RecordQuery("UPDATE users SET name = 'x'", nil, 150*time.Millisecond, 0, errors.New("deadlock detected"))
+
+	slow, err := analyzer.GetSlowQueries(10)
+	require.NoError(t, err)
+	require.Len(t, slow, 2)
+	assert.Equal(t, "SELECT * FROM orders", slow[0].Query)
+	assert.Equal(t, 300.0, slow[0].Duration)
+	assert.Equal(t, "deadlock detected", slow[1].Error)
+
+	slow, err = analyzer.GetSlowQueries(1)
+	require.NoError(t, err)
+	assert.Len(t, slow, 1)
+
+	metrics, err := analyzer.GetMetrics()
+	require.NoError(t, err)
+	assert.Equal(t, 4, metrics.TotalQueries)
+	assert.Equal(t, 127.5, metrics.AvgDuration)
+	assert.Equal(t, 300.0, metrics.MaxDuration)
+	assert.Equal(t, 2, metrics.SlowQueries)
+	assert.Equal(t, 1, metrics.Errors)
+	assert.Equal(t, 100, metrics.Threshold)
+
+	top, err := analyzer.GetTopQueries("calls", 1)
+	require.NoError(t, err)
+	require.Len(t, top, 1)
+	assert.Equal(t, "SELECT * FROM users WHERE id = ?", top[0].Query)
+	assert.Equal(t, 2, top[0].Calls)
+	assert.Equal(t, int64(2), top[0].Rows)
+	assert.Equal(t, 60.0, top[0].TotalTime)
+	assert.Equal(t, 40.0, top[0].P95Time)
+
+	// Executions are matched with their literals normalized
+	analysis, err := analyzer.AnalyzeQuery("SELECT * FROM users WHERE id = 7")
+	require.NoError(t, err)
+	assert.Equal(t, 2, analysis.Executions)
+	assert.Equal(t, 30.0, analysis.AvgDuration)
+	assert.Contains(t, analysis.Suggestions, "Avoid using SELECT * - specify only the columns you need")
+
+	require.NoError(t, analyzer.Reset())
+	metrics, err = analyzer.GetMetrics()
+	require.NoError(t, err)
+	assert.Zero(t, metrics.TotalQueries)
+	assert.Equal(t, 100, metrics.Threshold, "reset keeps the threshold")
+
+	assert.Error(t, analyzer.SetThreshold(0))
+}
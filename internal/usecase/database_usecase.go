@@ -8,6 +8,7 @@ import (
 
 	"github.com/FreePeak/db-mcp-server/internal/domain"
 	"github.com/FreePeak/db-mcp-server/internal/logger"
+	"github.com/FreePeak/db-mcp-server/pkg/db"
 )
 
 // TODO: Improve error handling with custom error types and better error messages
@@ -16,54 +17,78 @@ import (
 // TODO: Add request validation layer before processing in usecases
 // TODO: Implement proper context propagation and timeout handling
 
+// tableQuery is a catalog query together with its bound arguments
+type tableQuery struct {
+	query string
+	args  []interface{}
+}
+
 // QueryFactory provides database-specific queries
 type QueryFactory interface {
-	GetTablesQueries() []string
+	GetTablesQueries() []tableQuery
 }
 
 // PostgresQueryFactory creates queries for PostgreSQL
-type PostgresQueryFactory struct{}
+type PostgresQueryFactory struct {
+	// Schema is the schema to list; empty means public
+	Schema string
+}
 
-func (f *PostgresQueryFactory) GetTablesQueries() []string {
-	return []string{
+func (f *PostgresQueryFactory) GetTablesQueries() []tableQuery {
+	schema := f.Schema
+	if schema == "" {
+		schema = "public"
+	}
+	args := []interface{}{schema}
+	return []tableQuery{
 		// Primary PostgreSQL query using pg_catalog (most reliable)
-		"SELECT tablename AS table_name FROM pg_catalog.pg_tables WHERE schemaname = 'public'",
+		{"SELECT tablename AS table_name FROM pg_catalog.pg_tables WHERE schemaname = $1", args},
 		// Fallback 1: Using information_schema
-		"SELECT table_name FROM information_schema.tables WHERE table_schema = 'public'",
+		{"SELECT table_name FROM information_schema.tables WHERE table_schema = $1", args},
 		// Fallback 2: Using pg_class for relations
-		"SELECT relname AS table_name FROM pg_catalog.pg_class WHERE relkind = 'r' AND relnamespace = (SELECT oid FROM pg_catalog.pg_namespace WHERE nspname = 'public')",
+		{"SELECT relname AS table_name FROM pg_catalog.pg_class WHERE relkind = 'r' AND relnamespace = (SELECT oid FROM pg_catalog.pg_namespace WHERE nspname = $1)", args},
 	}
 }
 
 // MySQLQueryFactory creates queries for MySQL
-type MySQLQueryFactory struct{}
+type MySQLQueryFactory struct {
+	// Schema is the database to list; empty means the connected database
+	Schema string
+}
 
-func (f *MySQLQueryFactory) GetTablesQueries() []string {
-	return []string{
-		// Primary MySQL query
-		"SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE()",
-		// Fallback MySQL query
-		"SHOW TABLES",
+func (f *MySQLQueryFactory) GetTablesQueries() []tableQuery {
+	if f.Schema == "" {
+		return []tableQuery{
+			// Primary MySQL query
+			{query: "SELECT table_name AS table_name FROM information_schema.tables WHERE table_schema = DATABASE()"},
+			// Fallback MySQL query
+			{query: "SHOW TABLES"},
+		}
+	}
+	return []tableQuery{
+		{"SELECT table_name AS table_name FROM information_schema.tables WHERE table_schema = ?", []interface{}{f.Schema}},
+		{query: "SHOW TABLES FROM " + db.QuoteIdentifier("mysql", f.Schema)},
 	}
 }
 
 // GenericQueryFactory creates generic queries for unknown database types
 type GenericQueryFactory struct{}
 
-func (f *GenericQueryFactory) GetTablesQueries() []string {
-	return []string{
-		"SELECT table_name FROM information_schema.tables WHERE table_schema = 'public'",
-		"SELECT table_name FROM information_schema.tables",
+func (f *GenericQueryFactory) GetTablesQueries() []tableQuery {
+	return []tableQuery{
+		{query: "SELECT table_name FROM information_schema.tables WHERE table_schema = 'public'"},
+		{query: "SELECT table_name FROM information_schema.tables"},
 	}
 }
 
 // NewQueryFactory creates the appropriate query factory for the database type
-func NewQueryFactory(dbType string) QueryFactory {
+// and schema
+func NewQueryFactory(dbType, schema string) QueryFactory {
 	switch dbType {
 	case "postgres":
-		return &PostgresQueryFactory{}
+		return &PostgresQueryFactory{Schema: schema}
 	case "mysql":
-		return &MySQLQueryFactory{}
+		return &MySQLQueryFactory{Schema: schema}
 	default:
 		logger.Warn("Unknown database type: %s, will use generic query factory", dbType)
 		return &GenericQueryFactory{}
@@ -71,18 +96,18 @@ func NewQueryFactory(dbType string) QueryFactory {
 }
 
 // executeQueriesWithFallback tries multiple queries until one succeeds
-func executeQueriesWithFallback(ctx context.Context, db domain.Database, queries []string) (domain.Rows, error) {
+func executeQueriesWithFallback(ctx context.Context, db domain.Database, queries []tableQuery) (domain.Rows, error) {
 	var lastErr error
 	var rows domain.Rows
 
-	for i, query := range queries {
+	for i, q := range queries {
 		var err error
-		rows, err = db.Query(ctx, query)
+		rows, err = db.Query(ctx, q.query, q.args...)
 		if err == nil {
 			return rows, nil // Query succeeded
 		}
 		lastErr = err
-		logger.Warn("Query %d failed: %s - Error: %v", i+1, query, err)
+		logger.Warn("Query %d failed: %s - Error: %v", i+1, q.query, err)
 	}
 
 	// All queries failed
@@ -91,7 +116,8 @@ func executeQueriesWithFallback(ctx context.Context, db domain.Database, queries
 
 // DatabaseUseCase defines operations for managing database functionality
 type DatabaseUseCase struct {
-	repo domain.DatabaseRepository
+	repo           domain.DatabaseRepository
+	sessionControl string // session signals tools may send: off, cancel or terminate
 }
 
 // NewDatabaseUseCase creates a new database use case
@@ -107,9 +133,9 @@ func (uc *DatabaseUseCase) ListDatabases() []string {
 }
 
 // GetDatabaseInfo returns information about a database
-func (uc *DatabaseUseCase) GetDatabaseInfo(dbID string) (map[string]interface{}, error) {
+func (uc *DatabaseUseCase) GetDatabaseInfo(ctx context.Context, dbID string) (map[string]interface{}, error) {
 	// Get database connection
-	db, err := uc.repo.GetDatabase(dbID)
+	database, err := uc.repo.GetDatabase(dbID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get database: %w", err)
 	}
@@ -120,15 +146,20 @@ func (uc *DatabaseUseCase) GetDatabaseInfo(dbID string) (map[string]interface{},
 		return nil, fmt.Errorf("failed to get database type: %w", err)
 	}
 
+	// Scope the listing to the connection's default schema
+	schema, err := uc.repo.ResolveSchema(dbID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve schema: %w", err)
+	}
+
 	// Create appropriate query factory based on database type
-	factory := NewQueryFactory(dbType)
+	factory := NewQueryFactory(dbType, schema)
 
 	// Get queries for tables
 	tableQueries := factory.GetTablesQueries()
 
 	// Execute queries with fallback
-	ctx := context.Background()
-	rows, err := executeQueriesWithFallback(ctx, db, tableQueries)
+	rows, err := executeQueriesWithFallback(ctx, database, tableQueries)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get schema information: %w", err)
 	}
@@ -181,23 +212,71 @@ func (uc *DatabaseUseCase) GetDatabaseInfo(dbID string) (map[string]interface{},
 	result := map[string]interface{}{
 		"database": dbID,
 		"dbType":   dbType,
+		"schema":   schema,
 		"tables":   tables,
 	}
 
 	return result, nil
 }
 
-// ExecuteQuery executes a SQL query and returns the formatted results
+// GetSchemaComponent returns a single schema component (tables, columns,
+// indexes, constraints, views, sequences, triggers, functions, ...) for a
+// database, scoped to a schema
+func (uc *DatabaseUseCase) GetSchemaComponent(ctx context.Context, dbID, component, schema, table string) (map[string]interface{}, error) {
+	result, err := uc.repo.GetSchemaComponent(ctx, dbID, component, schema, table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s for database %s: %w", component, dbID, err)
+	}
+	return result, nil
+}
+
+// GetJoinPath finds the shortest chain of foreign key joins connecting the
+// tables, optionally with a SELECT skeleton over them
+func (uc *DatabaseUseCase) GetJoinPath(ctx context.Context, dbID, schema string, tables []string, withQuery bool) (*domain.JoinPath, error) {
+	path, err := uc.repo.GetJoinPath(ctx, dbID, schema, tables, withQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find join path for database %s: %w", dbID, err)
+	}
+	return path, nil
+}
+
+// InvalidateSchemaCache drops cached schema metadata so the next schema
+// request reads the catalogs again
+func (uc *DatabaseUseCase) InvalidateSchemaCache(dbID string) {
+	uc.repo.InvalidateSchemaCache(dbID)
+}
+
+// ResolveSchema applies the connection's schema defaults and allowlist to a
+// requested schema
+func (uc *DatabaseUseCase) ResolveSchema(dbID, schema string) (string, error) {
+	resolved, err := uc.repo.ResolveSchema(dbID, schema)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve schema for database %s: %w", dbID, err)
+	}
+	return resolved, nil
+}
+
+// ExecuteQuery executes a SQL query and returns the formatted results. The
+// execution is recorded by the database's performance analyzer.
 func (uc *DatabaseUseCase) ExecuteQuery(ctx context.Context, dbID, query string, params []interface{}) (string, error) {
+	start := time.Now()
+	result, rowCount, err := uc.executeQuery(ctx, dbID, query, params)
+	uc.recordQuery(dbID, query, params, time.Since(start), rowCount, err)
+	return result, err
+}
+
+// executeQuery runs a query and formats its rows, also returning how many
+// were read
+func (uc *DatabaseUseCase) executeQuery(ctx context.Context, dbID, query string, params []interface{}) (string, int64, error) {
 	db, err := uc.repo.GetDatabase(dbID)
 	if err != nil {
-		return "", fmt.Errorf("failed to get database: %w", err)
+		return "", 0, fmt.Errorf("failed to get database: %w", err)
 	}
 
 	// Execute query
 	rows, err := db.Query(ctx, query, params...)
 	if err != nil {
-		return "", fmt.Errorf("query execution failed: %w", err)
+		return "", 0, fmt.Errorf("query execution failed: %w", err)
 	}
 	defer func() {
 		if closeErr := rows.Close(); closeErr != nil {
@@ -208,7 +287,7 @@ func (uc *DatabaseUseCase) ExecuteQuery(ctx context.Context, dbID, query string,
 	// Process results into a readable format
 	columns, err := rows.Columns()
 	if err != nil {
-		return "", fmt.Errorf("failed to get column names: %w", err)
+		return "", 0, fmt.Errorf("failed to get column names: %w", err)
 	}
 
 	// Format results as text
@@ -230,7 +309,7 @@ func (uc *DatabaseUseCase) ExecuteQuery(ctx context.Context, dbID, query string,
 		rowCount++
 		scanErr := rows.Scan(valuePtrs...)
 		if scanErr != nil {
-			return "", fmt.Errorf("failed to scan row: %w", scanErr)
+			return "", int64(rowCount), fmt.Errorf("failed to scan row: %w", scanErr)
 		}
 
 		// Convert to strings and print
@@ -252,14 +331,15 @@ func (uc *DatabaseUseCase) ExecuteQuery(ctx context.Context, dbID, query string,
 	}
 
 	if err = rows.Err(); err != nil {
-		return "", fmt.Errorf("error reading rows: %w", err)
+		return "", int64(rowCount), fmt.Errorf("error reading rows: %w", err)
 	}
 
 	resultText.WriteString(fmt.Sprintf("\nTotal rows: %d", rowCount))
-	return resultText.String(), nil
+	return resultText.String(), int64(rowCount), nil
 }
 
-// ExecuteStatement executes a SQL statement (INSERT, UPDATE, DELETE)
+// ExecuteStatement executes a SQL statement (INSERT, UPDATE, DELETE). The
+// execution is recorded by the database's performance analyzer.
 func (uc *DatabaseUseCase) ExecuteStatement(ctx context.Context, dbID, statement string, params []interface{}) (string, error) {
 	db, err := uc.repo.GetDatabase(dbID)
 	if err != nil {
@@ -267,8 +347,11 @@ func (uc *DatabaseUseCase) ExecuteStatement(ctx context.Context, dbID, statement
 	}
 
 	// Execute statement
+	start := time.Now()
 	result, err := db.Exec(ctx, statement, params...)
+	duration := time.Since(start)
 	if err != nil {
+		uc.recordQuery(dbID, statement, params, duration, 0, err)
 		return "", fmt.Errorf("statement execution failed: %w", err)
 	}
 
@@ -277,6 +360,7 @@ func (uc *DatabaseUseCase) ExecuteStatement(ctx context.Context, dbID, statement
 	if err != nil {
 		rowsAffected = 0
 	}
+	uc.recordQuery(dbID, statement, params, duration, rowsAffected, nil)
 
 	// Get last insert ID (if applicable)
 	lastInsertID, err := result.LastInsertId()
@@ -338,6 +422,32 @@ func timeNowUnix() int64 {
 	return time.Now().Unix()
 }
 
+// GetQueryTimeout returns the default timeout of the calls made to a database
+func (uc *DatabaseUseCase) GetQueryTimeout(dbID string) (time.Duration, error) {
+	timeout, err := uc.repo.GetQueryTimeout(dbID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get query timeout for database %s: %w", dbID, err)
+	}
+	return timeout, nil
+}
+
+// WithStatementTimeout returns a context whose statements on a database the
+// server stops after timeout. The timeout may not exceed the connection's
+// max_statement_timeout_ms, which defaults to its query timeout.
+func (uc *DatabaseUseCase) WithStatementTimeout(ctx context.Context, dbID string, timeout time.Duration) (context.Context, error) {
+	if timeout <= 0 {
+		return nil, fmt.Errorf("statement timeout must be positive, got %s", timeout)
+	}
+	limit, err := uc.repo.GetMaxStatementTimeout(dbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get statement timeout limit for database %s: %w", dbID, err)
+	}
+	if timeout > limit {
+		return nil, fmt.Errorf("statement timeout %s exceeds the maximum of %s for database %s", timeout, limit, dbID)
+	}
+	return domain.WithStatementTimeout(ctx, timeout), nil
+}
+
 // GetDatabaseType returns the type of a database by ID
 func (uc *DatabaseUseCase) GetDatabaseType(dbID string) (string, error) {
 	return uc.repo.GetDatabaseType(dbID)
@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+func TestWithStatementTimeout(t *testing.T) {
+	uc, _ := newMigrationUseCase(t, "postgres", nil)
+
+	ctx, err := uc.WithStatementTimeout(context.Background(), "test_db", 2*time.Second)
+	require.NoError(t, err)
+	timeout, ok := domain.StatementTimeout(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, 2*time.Second, timeout)
+
+	// The fake repository allows up to 10 seconds
+	_, err = uc.WithStatementTimeout(context.Background(), "test_db", time.Minute)
+	assert.ErrorContains(t, err, "exceeds the maximum of 10s")
+	_, err = uc.WithStatementTimeout(context.Background(), "test_db", 0)
+	assert.ErrorContains(t, err, "must be positive")
+}
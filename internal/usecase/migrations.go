@@ -0,0 +1,511 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+)
+
+const (
+	// migrationsTable holds the applied version in a single row, in the same
+	// layout golang-migrate uses
+	migrationsTable = "schema_migrations"
+
+	// noTransactionMarker, as the first line of a PostgreSQL migration, runs
+	// it outside a transaction, e.g. for CREATE INDEX CONCURRENTLY
+	noTransactionMarker = "-- migrate:no-transaction"
+)
+
+// migrationFilePattern matches <version>_<name>.up.sql and .down.sql files
+var migrationFilePattern = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// dollarQuoteTag matches the opening tag of a PostgreSQL dollar-quoted string
+var dollarQuoteTag = regexp.MustCompile(`^\$([A-Za-z_][A-Za-z0-9_]*)?\$`)
+
+// LoadMigrations reads the migrations of a directory ordered by version.
+// Files not named like a migration are ignored.
+func LoadMigrations(dir string) ([]domain.Migration, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations directory: %w", err)
+	}
+
+	byVersion := make(map[int64]*domain.Migration)
+	for _, entry := range entries {
+		match := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %s: versions are positive integers", entry.Name())
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &domain.Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		} else if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration version %d is used by both %s and %s", version, migration.Name, match[2])
+		}
+
+		path := filepath.Join(dir, entry.Name())
+		file := &migration.UpFile
+		if match[3] == "down" {
+			file = &migration.DownFile
+		}
+		if *file != "" {
+			return nil, fmt.Errorf("migration version %d has two %s files: %s and %s", version, match[3], filepath.Base(*file), entry.Name())
+		}
+		*file = path
+	}
+
+	migrations := make([]domain.Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.UpFile == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// MigrationStatus reports the applied migration version of a database and
+// which migrations in its migrations_dir are applied or pending
+func (uc *DatabaseUseCase) MigrationStatus(ctx context.Context, dbID string) (*domain.MigrationStatus, error) {
+	run, err := uc.openMigrations(dbID)
+	if err != nil {
+		return nil, err
+	}
+	version, dirty, err := run.currentVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &domain.MigrationStatus{
+		Database:  dbID,
+		Directory: run.dir,
+		Version:   version,
+		Dirty:     dirty,
+		Applied:   []domain.Migration{},
+		Pending:   []domain.Migration{},
+	}
+	for _, migration := range run.migrations {
+		if migration.Version <= version {
+			status.Applied = append(status.Applied, migration)
+		} else {
+			status.Pending = append(status.Pending, migration)
+		}
+	}
+	return status, nil
+}
+
+// PlanMigrations lists, with their SQL, the migrations Migrate would run for
+// the same direction and steps, without changing the database
+func (uc *DatabaseUseCase) PlanMigrations(ctx context.Context, dbID, direction string, steps int) (*domain.MigrationPlan, error) {
+	run, err := uc.openMigrations(dbID)
+	if err != nil {
+		return nil, err
+	}
+	version, _, err := run.currentVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plan, _, err := run.plan(version, direction, steps)
+	return plan, err
+}
+
+// Migrate applies pending migrations (direction "up", all of them when steps
+// is zero or less) or reverts applied ones (direction "down", one when steps
+// is zero or less). Each migration and its version update run in one
+// transaction on PostgreSQL. MySQL commits DDL implicitly, so the version is
+// marked dirty while a migration runs and a failure has to be fixed by hand.
+func (uc *DatabaseUseCase) Migrate(ctx context.Context, dbID, direction string, steps int) (*domain.MigrationPlan, error) {
+	run, err := uc.openMigrations(dbID)
+	if err != nil {
+		return nil, err
+	}
+	if err := run.ensureTable(ctx); err != nil {
+		return nil, err
+	}
+	version, dirty, err := run.currentVersion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if dirty {
+		return nil, fmt.Errorf("database %s is dirty at migration version %d: repair the failed migration by hand, then force the version", dbID, version)
+	}
+
+	plan, targets, err := run.plan(version, direction, steps)
+	if err != nil {
+		return nil, err
+	}
+	for i := range plan.Steps {
+		step := &plan.Steps[i]
+		logger.Info("Migrating %s %s: %s", dbID, direction, step.File)
+		if err := run.apply(ctx, *step, targets[i]); err != nil {
+			return nil, fmt.Errorf("migration %s failed after %d of %d migrations: %w", step.File, i, len(plan.Steps), err)
+		}
+		step.SQL = ""
+	}
+	return plan, nil
+}
+
+// ForceMigrationVersion records a version as applied and clean without running
+// any migration, to recover from a failed non-transactional migration
+func (uc *DatabaseUseCase) ForceMigrationVersion(ctx context.Context, dbID string, version int64) error {
+	run, err := uc.openMigrations(dbID)
+	if err != nil {
+		return err
+	}
+	if err := run.ensureTable(ctx); err != nil {
+		return err
+	}
+	return run.setVersion(ctx, run.database, version, false)
+}
+
+// migrationRun holds what migrating one database needs
+type migrationRun struct {
+	dbID       string
+	dir        string
+	dbType     string
+	database   domain.Database
+	migrations []domain.Migration
+}
+
+// execer runs statements on a database or inside a transaction
+type execer interface {
+	Exec(ctx context.Context, statement string, args ...interface{}) (domain.Result, error)
+}
+
+// openMigrations loads the migrations of a database
+func (uc *DatabaseUseCase) openMigrations(dbID string) (*migrationRun, error) {
+	dir, err := uc.repo.GetMigrationsDir(dbID)
+	if err != nil {
+		return nil, err
+	}
+	dbType, err := uc.repo.GetDatabaseType(dbID)
+	if err != nil {
+		return nil, err
+	}
+	if dbType != "postgres" && dbType != "mysql" {
+		return nil, fmt.Errorf("migrations are not supported for %s databases", dbType)
+	}
+	database, err := uc.repo.GetDatabase(dbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database: %w", err)
+	}
+	migrations, err := LoadMigrations(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &migrationRun{dbID: dbID, dir: dir, dbType: dbType, database: database, migrations: migrations}, nil
+}
+
+// plan selects the migrations to run from a version and the version each one
+// leaves the database at
+func (r *migrationRun) plan(version int64, direction string, steps int) (*domain.MigrationPlan, []int64, error) {
+	var selected []domain.Migration
+	var targets []int64
+
+	switch direction {
+	case "up":
+		for _, migration := range r.migrations {
+			if migration.Version > version && (steps <= 0 || len(selected) < steps) {
+				selected = append(selected, migration)
+				targets = append(targets, migration.Version)
+			}
+		}
+	case "down":
+		if steps <= 0 {
+			steps = 1
+		}
+		var applied []domain.Migration
+		for i := len(r.migrations) - 1; i >= 0; i-- {
+			if r.migrations[i].Version <= version {
+				applied = append(applied, r.migrations[i])
+			}
+		}
+		if version > 0 && (len(applied) == 0 || applied[0].Version != version) {
+			return nil, nil, fmt.Errorf("applied migration version %d has no file in %s", version, r.dir)
+		}
+		for i := 0; i < len(applied) && i < steps; i++ {
+			if applied[i].DownFile == "" {
+				return nil, nil, fmt.Errorf("migration %d_%s has no down file", applied[i].Version, applied[i].Name)
+			}
+			selected = append(selected, applied[i])
+			target := int64(0)
+			if i+1 < len(applied) {
+				target = applied[i+1].Version
+			}
+			targets = append(targets, target)
+		}
+	default:
+		return nil, nil, fmt.Errorf("invalid migration direction %q: use up or down", direction)
+	}
+
+	plan := &domain.MigrationPlan{
+		Database:    r.dbID,
+		Direction:   direction,
+		FromVersion: version,
+		ToVersion:   version,
+		Steps:       []domain.MigrationStep{},
+	}
+	for i, migration := range selected {
+		file := migration.UpFile
+		if direction == "down" {
+			file = migration.DownFile
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read migration: %w", err)
+		}
+		script := string(data)
+		plan.Steps = append(plan.Steps, domain.MigrationStep{
+			Version:       migration.Version,
+			Name:          migration.Name,
+			File:          filepath.Base(file),
+			Transactional: r.dbType == "postgres" && !strings.HasPrefix(strings.TrimSpace(script), noTransactionMarker),
+			SQL:           script,
+		})
+		plan.ToVersion = targets[i]
+	}
+	return plan, targets, nil
+}
+
+// apply runs one migration and records the version it leaves the database at
+func (r *migrationRun) apply(ctx context.Context, step domain.MigrationStep, target int64) error {
+	if step.Transactional {
+		tx, err := r.database.Begin(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("failed to begin transaction: %w", err)
+		}
+		if err := r.execScript(ctx, tx, step.SQL, false); err != nil {
+			r.rollback(tx)
+			return err
+		}
+		if err := r.setVersion(ctx, tx, target, false); err != nil {
+			r.rollback(tx)
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("failed to commit migration: %w", err)
+		}
+		return nil
+	}
+
+	// Without a transaction a failure leaves the database marked dirty
+	if err := r.setVersion(ctx, r.database, target, true); err != nil {
+		return err
+	}
+	if err := r.execScript(ctx, r.database, step.SQL, true); err != nil {
+		return err
+	}
+	return r.setVersion(ctx, r.database, target, false)
+}
+
+// rollback rolls back a failed migration's transaction
+func (r *migrationRun) rollback(tx domain.Tx) {
+	if err := tx.Rollback(); err != nil {
+		logger.Error("error rolling back migration of %s: %v", r.dbID, err)
+	}
+}
+
+// execScript runs a migration script. PostgreSQL runs a transactional script
+// in one call; otherwise statements run one by one, as MySQL accepts only one
+// statement per call and PostgreSQL runs a multi-statement call as a single
+// implicit transaction.
+func (r *migrationRun) execScript(ctx context.Context, ex execer, script string, split bool) error {
+	statements := []string{script}
+	if split || r.dbType == "mysql" {
+		statements = splitSQLScript(script, r.dbType)
+	}
+	for _, statement := range statements {
+		if strings.TrimSpace(statement) == "" {
+			continue
+		}
+		if _, err := ex.Exec(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureTable creates the migrations table if it does not exist
+func (r *migrationRun) ensureTable(ctx context.Context) error {
+	_, err := r.database.Exec(ctx, "CREATE TABLE IF NOT EXISTS "+migrationsTable+" (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("failed to create %s table: %w", migrationsTable, err)
+	}
+	return nil
+}
+
+// currentVersion reads the applied version; a missing table means none
+func (r *migrationRun) currentVersion(ctx context.Context) (int64, bool, error) {
+	current := "current_schema()"
+	if r.dbType == "mysql" {
+		current = "DATABASE()"
+	}
+	var count int64
+	found, err := scanFirstRow(ctx, r.database, fmt.Sprintf(
+		"SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = %s AND table_name = '%s'", current, migrationsTable), &count)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to look up %s table: %w", migrationsTable, err)
+	}
+	if !found || count == 0 {
+		return 0, false, nil
+	}
+
+	var version int64
+	var dirty bool
+	if _, err := scanFirstRow(ctx, r.database, "SELECT version, dirty FROM "+migrationsTable+" LIMIT 1", &version, &dirty); err != nil {
+		return 0, false, fmt.Errorf("failed to read migration version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// setVersion replaces the recorded version; version 0 of a clean database
+// leaves the table empty
+func (r *migrationRun) setVersion(ctx context.Context, ex execer, version int64, dirty bool) error {
+	if _, err := ex.Exec(ctx, "DELETE FROM "+migrationsTable); err != nil {
+		return fmt.Errorf("failed to update migration version: %w", err)
+	}
+	if version == 0 && !dirty {
+		return nil
+	}
+	insert := "INSERT INTO " + migrationsTable + " (version, dirty) VALUES ($1, $2)"
+	if r.dbType == "mysql" {
+		insert = "INSERT INTO " + migrationsTable + " (version, dirty) VALUES (?, ?)"
+	}
+	if _, err := ex.Exec(ctx, insert, version, dirty); err != nil {
+		return fmt.Errorf("failed to update migration version: %w", err)
+	}
+	return nil
+}
+
+// scanFirstRow scans the first row of a query, reporting whether there was one
+func scanFirstRow(ctx context.Context, database domain.Database, query string, dest ...interface{}) (bool, error) {
+	rows, err := database.Query(ctx, query)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Error("error closing rows: %v", err)
+		}
+	}()
+
+	if !rows.Next() {
+		return false, rows.Err()
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// splitSQLScript splits a script into statements. Semicolons inside quotes,
+// comments and PostgreSQL dollar-quoted bodies do not end a statement; MySQL
+// scripts may change the delimiter with DELIMITER lines like the mysql client.
+// Statements holding only comments are dropped.
+func splitSQLScript(script, dbType string) []string {
+	var statements []string
+	var current strings.Builder
+	hasCode := false
+	delimiter := ";"
+	mysql := dbType == "mysql"
+
+	flush := func() {
+		if hasCode {
+			statements = append(statements, strings.TrimSpace(current.String()))
+		}
+		current.Reset()
+		hasCode = false
+	}
+	lineEnd := func(i int) int {
+		if end := strings.IndexByte(script[i:], '\n'); end >= 0 {
+			return i + end + 1
+		}
+		return len(script)
+	}
+
+	for i := 0; i < len(script); {
+		rest := script[i:]
+		lineStart := strings.LastIndexByte(script[:i], '\n') + 1
+		atLineStart := strings.TrimSpace(script[lineStart:i]) == ""
+
+		switch {
+		case mysql && atLineStart && len(rest) > 10 && strings.EqualFold(rest[:10], "DELIMITER "):
+			flush()
+			end := lineEnd(i)
+			delimiter = strings.TrimSpace(script[i+10 : end])
+			i = end
+		case strings.HasPrefix(rest, "--") || (mysql && rest[0] == '#'):
+			end := lineEnd(i)
+			current.WriteString(script[i:end])
+			i = end
+		case strings.HasPrefix(rest, "/*"):
+			end := len(script)
+			if close := strings.Index(rest[2:], "*/"); close >= 0 {
+				end = i + 2 + close + 2
+			}
+			current.WriteString(script[i:end])
+			i = end
+		case rest[0] == '\'' || rest[0] == '"' || rest[0] == '`':
+			end := quotedEnd(script, i, mysql)
+			current.WriteString(script[i:end])
+			hasCode = true
+			i = end
+		case !mysql && rest[0] == '$' && dollarQuoteTag.MatchString(rest):
+			tag := dollarQuoteTag.FindString(rest)
+			end := len(script)
+			if close := strings.Index(rest[len(tag):], tag); close >= 0 {
+				end = i + len(tag) + close + len(tag)
+			}
+			current.WriteString(script[i:end])
+			hasCode = true
+			i = end
+		case strings.HasPrefix(rest, delimiter):
+			flush()
+			i += len(delimiter)
+		default:
+			if !unicode.IsSpace(rune(rest[0])) {
+				hasCode = true
+			}
+			current.WriteByte(rest[0])
+			i++
+		}
+	}
+	flush()
+	return statements
+}
+
+// quotedEnd returns the index just past the quoted string or identifier
+// starting at i. Doubled quotes escape a quote; MySQL also allows backslash
+// escapes in strings.
+func quotedEnd(script string, i int, backslashEscapes bool) int {
+	quote := script[i]
+	for j := i + 1; j < len(script); j++ {
+		switch {
+		case backslashEscapes && quote != '`' && script[j] == '\\':
+			j++
+		case script[j] == quote:
+			if j+1 < len(script) && script[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(script)
+}
@@ -0,0 +1,327 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+)
+
+// migrationState is what the fake database keeps of schema_migrations
+type migrationState struct {
+	tableExists bool
+	hasRow      bool
+	version     int64
+	dirty       bool
+	executed    []string
+}
+
+// fakeMigrationDB is a database that records statements and understands the
+// schema_migrations statements of the runner. A statement containing failOn
+// fails.
+type fakeMigrationDB struct {
+	state     migrationState
+	failOn    string
+	rollbacks int
+}
+
+func (d *fakeMigrationDB) Query(ctx context.Context, query string, args ...interface{}) (domain.Rows, error) {
+	switch {
+	case strings.Contains(query, "COUNT(*)"):
+		count := int64(0)
+		if d.state.tableExists {
+			count = 1
+		}
+		return &fakeRows{values: [][]interface{}{{count}}}, nil
+	case strings.HasPrefix(query, "SELECT version, dirty"):
+		if !d.state.hasRow {
+			return &fakeRows{}, nil
+		}
+		return &fakeRows{values: [][]interface{}{{d.state.version, d.state.dirty}}}, nil
+	}
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
+
+func (d *fakeMigrationDB) Exec(ctx context.Context, statement string, args ...interface{}) (domain.Result, error) {
+	return nil, d.state.exec(d.failOn, statement, args)
+}
+
+func (d *fakeMigrationDB) Begin(ctx context.Context, opts *domain.TxOptions) (domain.Tx, error) {
+	state := d.state
+	state.executed = append([]string(nil), d.state.executed...)
+	return &fakeMigrationTx{db: d, state: state}, nil
+}
+
+func (s *migrationState) exec(failOn, statement string, args []interface{}) error {
+	if failOn != "" && strings.Contains(statement, failOn) {
+		return fmt.Errorf("syntax error near %q", failOn)
+	}
+	switch {
+	case strings.HasPrefix(statement, "CREATE TABLE IF NOT EXISTS schema_migrations"):
+		s.tableExists = true
+		return nil
+	case statement == "DELETE FROM schema_migrations":
+		s.hasRow = false
+		return nil
+	case strings.HasPrefix(statement, "INSERT INTO schema_migrations"):
+		s.hasRow, s.version, s.dirty = true, args[0].(int64), args[1].(bool)
+		return nil
+	}
+	s.executed = append(s.executed, statement)
+	return nil
+}
+
+// fakeMigrationTx applies its changes to the database on commit only
+type fakeMigrationTx struct {
+	db    *fakeMigrationDB
+	state migrationState
+}
+
+func (t *fakeMigrationTx) Commit() error {
+	t.db.state = t.state
+	return nil
+}
+
+func (t *fakeMigrationTx) Rollback() error {
+	t.db.rollbacks++
+	return nil
+}
+
+func (t *fakeMigrationTx) Query(ctx context.Context, query string, args ...interface{}) (domain.Rows, error) {
+	return nil, fmt.Errorf("unexpected query %q", query)
+}
+
+func (t *fakeMigrationTx) Exec(ctx context.Context, statement string, args ...interface{}) (domain.Result, error) {
+	return nil, t.state.exec(t.db.failOn, statement, args)
+}
+
+type fakeRows struct {
+	values [][]interface{}
+	pos    int
+}
+
+func (r *fakeRows) Close() error { return nil }
+func (r *fakeRows) Err() error   { return nil }
+func (r *fakeRows) Next() bool   { r.pos++; return r.pos <= len(r.values) }
+func (r *fakeRows) Columns() ([]string, error) {
+	if len(r.values) == 0 {
+		return nil, nil
+	}
+	return make([]string, len(r.values[0])), nil
+}
+func (r *fakeRows) Scan(dest ...interface{}) error {
+	for i, value := range r.values[r.pos-1] {
+		switch d := dest[i].(type) {
+		case *int64:
+			*d = value.(int64)
+		case *bool:
+			*d = value.(bool)
+		case *interface{}:
+			*d = value
+		}
+	}
+	return nil
+}
+
+// fakeMigrationRepo serves one database and its migrations directory. The
+// repository methods the tests do not use are left to the embedded nil
+// interface.
+type fakeMigrationRepo struct {
+	domain.DatabaseRepository
+
+	db       *fakeMigrationDB
+	dbType   string
+	dir      string
+	analyzer domain.PerformanceAnalyzer
+	plan     *domain.QueryPlan
+	locks    []domain.LockWait
+	sessions []domain.SessionActivity
+	signals  []string
+}
+
+func (r *fakeMigrationRepo) GetDatabase(id string) (domain.Database, error)  { return r.db, nil }
+func (r *fakeMigrationRepo) ListDatabases() []string                         { return []string{"main"} }
+func (r *fakeMigrationRepo) GetDatabaseType(id string) (string, error)       { return r.dbType, nil }
+func (r *fakeMigrationRepo) ResolveSchema(id, schema string) (string, error) { return schema, nil }
+func (r *fakeMigrationRepo) InvalidateSchemaCache(id string)                 {}
+func (r *fakeMigrationRepo) GetMigrationsDir(id string) (string, error)      { return r.dir, nil }
+func (r *fakeMigrationRepo) GetQueryTimeout(id string) (time.Duration, error) {
+	return 30 * time.Second, nil
+}
+func (r *fakeMigrationRepo) GetMaxStatementTimeout(id string) (time.Duration, error) {
+	return 10 * time.Second, nil
+}
+func (r *fakeMigrationRepo) ExplainQuery(ctx context.Context, id, query string) (*domain.QueryPlan, error) {
+	if r.plan == nil {
+		return nil, fmt.Errorf("no plan")
+	}
+	return r.plan, nil
+}
+func (r *fakeMigrationRepo) GetLockWaits(ctx context.Context, id string) ([]domain.LockWait, error) {
+	return r.locks, nil
+}
+func (r *fakeMigrationRepo) GetActivity(ctx context.Context, id string, filter domain.ActivityFilter) ([]domain.SessionActivity, error) {
+	return r.sessions, nil
+}
+func (r *fakeMigrationRepo) GetStorageStats(ctx context.Context, id, schema string, opts domain.StorageOptions) (*domain.StorageReport, error) {
+	return &domain.StorageReport{Schema: schema, OrderBy: opts.OrderBy}, nil
+}
+func (r *fakeMigrationRepo) SignalSession(ctx context.Context, id string, pid int64, signal string) error {
+	r.signals = append(r.signals, fmt.Sprintf("%s %d", signal, pid))
+	return nil
+}
+func (r *fakeMigrationRepo) GetPerformanceAnalyzer(id string) (domain.PerformanceAnalyzer, error) {
+	if r.analyzer == nil {
+		return nil, fmt.Errorf("no performance analyzer")
+	}
+	return r.analyzer, nil
+}
+
+func writeMigrations(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644))
+	}
+	return dir
+}
+
+func newMigrationUseCase(t *testing.T, dbType string, files map[string]string) (*DatabaseUseCase, *fakeMigrationDB) {
+	logger.InitializeWithWriter("error", os.Stderr)
+	db := &fakeMigrationDB{}
+	return NewDatabaseUseCase(&fakeMigrationRepo{db: db, dbType: dbType, dir: writeMigrations(t, files)}), db
+}
+
+var exampleMigrations = map[string]string{
+	"000001_create_users.up.sql":   "CREATE TABLE users (id int);\nCREATE INDEX users_id ON users (id);\n",
+	"000001_create_users.down.sql": "DROP TABLE users;\n",
+	"000002_add_email.up.sql":      "ALTER TABLE users ADD COLUMN email text;\n",
+	"000002_add_email.down.sql":    "ALTER TABLE users DROP COLUMN email;\n",
+	"000003_backfill.up.sql":       "UPDATE users SET email = '';\n",
+	"README.md":                    "not a migration",
+}
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations(writeMigrations(t, exampleMigrations))
+	require.NoError(t, err)
+	require.Len(t, migrations, 3)
+	assert.Equal(t, int64(1), migrations[0].Version)
+	assert.Equal(t, "create_users", migrations[0].Name)
+	assert.Equal(t, "000003_backfill.up.sql", filepath.Base(migrations[2].UpFile))
+	assert.Empty(t, migrations[2].DownFile)
+
+	_, err = LoadMigrations(writeMigrations(t, map[string]string{"1_a.up.sql": "", "001_a.up.sql": ""}))
+	assert.ErrorContains(t, err, "two up files")
+
+	_, err = LoadMigrations(writeMigrations(t, map[string]string{"1_a.down.sql": ""}))
+	assert.ErrorContains(t, err, "has no up file")
+}
+
+func TestMigratePostgres(t *testing.T) {
+	uc, db := newMigrationUseCase(t, "postgres", exampleMigrations)
+	ctx := context.Background()
+
+	status, err := uc.MigrationStatus(ctx, "main")
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), status.Version)
+	assert.Len(t, status.Pending, 3)
+
+	plan, err := uc.PlanMigrations(ctx, "main", "up", 2)
+	require.NoError(t, err)
+	require.Len(t, plan.Steps, 2)
+	assert.Equal(t, int64(2), plan.ToVersion)
+	assert.True(t, plan.Steps[0].Transactional)
+	assert.Contains(t, plan.Steps[0].SQL, "CREATE TABLE users")
+	assert.Empty(t, db.state.executed, "planning runs nothing")
+
+	plan, err = uc.Migrate(ctx, "main", "up", 0)
+	require.NoError(t, err)
+	assert.Len(t, plan.Steps, 3)
+	assert.Empty(t, plan.Steps[0].SQL)
+	// A transactional script runs in one call
+	assert.Equal(t, "CREATE TABLE users (id int);\nCREATE INDEX users_id ON users (id);\n", db.state.executed[0])
+	assert.Equal(t, int64(3), db.state.version)
+	assert.False(t, db.state.dirty)
+
+	// Version 3 has no down file
+	_, err = uc.Migrate(ctx, "main", "down", 1)
+	assert.ErrorContains(t, err, "has no down file")
+
+	require.NoError(t, uc.ForceMigrationVersion(ctx, "main", 2))
+	plan, err = uc.Migrate(ctx, "main", "down", 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"000002_add_email.down.sql", "000001_create_users.down.sql"},
+		[]string{plan.Steps[0].File, plan.Steps[1].File})
+	assert.Equal(t, int64(0), plan.ToVersion)
+	assert.False(t, db.state.hasRow)
+}
+
+func TestMigratePostgresFailureRollsBack(t *testing.T) {
+	uc, db := newMigrationUseCase(t, "postgres", exampleMigrations)
+	db.failOn = "ADD COLUMN"
+
+	_, err := uc.Migrate(context.Background(), "main", "up", 0)
+	require.ErrorContains(t, err, "migration 000002_add_email.up.sql failed after 1 of 3 migrations")
+	assert.Equal(t, 1, db.rollbacks)
+	assert.Equal(t, int64(1), db.state.version)
+	assert.False(t, db.state.dirty)
+}
+
+func TestMigrateMySQLSplitsAndMarksDirty(t *testing.T) {
+	uc, db := newMigrationUseCase(t, "mysql", exampleMigrations)
+	ctx := context.Background()
+
+	_, err := uc.Migrate(ctx, "main", "up", 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"CREATE TABLE users (id int)", "CREATE INDEX users_id ON users (id)"}, db.state.executed)
+
+	db.failOn = "ADD COLUMN"
+	_, err = uc.Migrate(ctx, "main", "up", 0)
+	require.Error(t, err)
+	assert.Equal(t, int64(2), db.state.version)
+	assert.True(t, db.state.dirty)
+
+	// A dirty database has to be repaired and forced first
+	db.failOn = ""
+	_, err = uc.Migrate(ctx, "main", "up", 0)
+	assert.ErrorContains(t, err, "is dirty at migration version 2")
+}
+
+func TestMigrateInvalidDirection(t *testing.T) {
+	uc, _ := newMigrationUseCase(t, "postgres", exampleMigrations)
+	_, err := uc.PlanMigrations(context.Background(), "main", "sideways", 0)
+	assert.ErrorContains(t, err, "invalid migration direction")
+}
+
+func TestSplitSQLScript(t *testing.T) {
+	pg := `-- migrate:no-transaction
+CREATE FUNCTION touch() RETURNS trigger AS $body$
+BEGIN NEW.updated_at = now(); RETURN NEW; END;
+$body$ LANGUAGE plpgsql;
+INSERT INTO notes VALUES ('a;b', "x;y"); /* trailing; comment */
+-- only a comment;
+`
+	assert.Equal(t, []string{
+		"-- migrate:no-transaction\nCREATE FUNCTION touch() RETURNS trigger AS $body$\nBEGIN NEW.updated_at = now(); RETURN NEW; END;\n$body$ LANGUAGE plpgsql",
+		"INSERT INTO notes VALUES ('a;b', \"x;y\")",
+	}, splitSQLScript(pg, "postgres"))
+
+	mysql := "INSERT INTO t VALUES ('it\\'s;', 'o''k;'); # done;\n" +
+		"DELIMITER //\n" +
+		"CREATE TRIGGER t_bi BEFORE INSERT ON t FOR EACH ROW BEGIN SET NEW.a = 1; END//\n" +
+		"DELIMITER ;\n" +
+		"SELECT `a;b` FROM t;"
+	assert.Equal(t, []string{
+		"INSERT INTO t VALUES ('it\\'s;', 'o''k;')",
+		"CREATE TRIGGER t_bi BEFORE INSERT ON t FOR EACH ROW BEGIN SET NEW.a = 1; END",
+		"SELECT `a;b` FROM t",
+	}, splitSQLScript(mysql, "mysql"))
+}
@@ -0,0 +1,90 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+)
+
+// GetPerformanceAnalyzer returns the analyzer of the queries run on a
+// database through this use case
+func (uc *DatabaseUseCase) GetPerformanceAnalyzer(dbID string) (domain.PerformanceAnalyzer, error) {
+	analyzer, err := uc.repo.GetPerformanceAnalyzer(dbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get performance analyzer for database %s: %w", dbID, err)
+	}
+	return analyzer, nil
+}
+
+// GetWorkloadStatistics reads up to limit statements from the statement
+// statistics the database keeps itself, ranked by orderBy (total_time by
+// default)
+func (uc *DatabaseUseCase) GetWorkloadStatistics(ctx context.Context, dbID, orderBy string, limit int) (*domain.WorkloadStatistics, error) {
+	if orderBy == "" {
+		orderBy = "total_time"
+	}
+	stats, err := uc.repo.GetWorkloadStatistics(ctx, dbID, orderBy, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workload statistics for database %s: %w", dbID, err)
+	}
+	return stats, nil
+}
+
+// AnalyzeQuery reports the recorded executions of a query with suggestions
+// and its EXPLAIN plan. The query is only planned, not executed, so it is safe
+// for data-modifying statements; the issues of the plan add suggestions.
+func (uc *DatabaseUseCase) AnalyzeQuery(ctx context.Context, dbID, query string) (*domain.QueryAnalysis, error) {
+	analyzer, err := uc.GetPerformanceAnalyzer(dbID)
+	if err != nil {
+		return nil, err
+	}
+	analysis, err := analyzer.AnalyzeQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to analyze query: %w", err)
+	}
+
+	plan, err := uc.repo.ExplainQuery(ctx, dbID, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	analysis.ExplainPlan = plan.Plan
+	analysis.PlanIssues = plan.Issues
+	for _, issue := range plan.Issues {
+		analysis.Suggestions = append(analysis.Suggestions, issue.Suggestion)
+	}
+	return &analysis, nil
+}
+
+// AdviseIndexes proposes indexes for the full scans of a database's workload
+// and flags its unused and duplicate indexes
+func (uc *DatabaseUseCase) AdviseIndexes(ctx context.Context, dbID, schema string, opts domain.IndexAdviceOptions) (*domain.IndexAdvice, error) {
+	advice, err := uc.repo.AdviseIndexes(ctx, dbID, schema, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to advise indexes for database %s: %w", dbID, err)
+	}
+	return advice, nil
+}
+
+// GetStorageStats reports the disk usage of the tables and indexes of a
+// schema, worst offenders first
+func (uc *DatabaseUseCase) GetStorageStats(ctx context.Context, dbID, schema string, opts domain.StorageOptions) (*domain.StorageReport, error) {
+	report, err := uc.repo.GetStorageStats(ctx, dbID, schema, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage statistics for database %s: %w", dbID, err)
+	}
+	return report, nil
+}
+
+// recordQuery records an execution that returned or affected rows with the
+// database's analyzer. Queries on unknown databases are not recorded.
+func (uc *DatabaseUseCase) recordQuery(dbID, query string, params []interface{}, duration time.Duration, rows int64, err error) {
+	analyzer, analyzerErr := uc.repo.GetPerformanceAnalyzer(dbID)
+	if analyzerErr != nil {
+		logger.Debug("Not recording query on %s: %v", dbID, analyzerErr)
+		return
+	}
+	analyzer.RecordQuery(query, params, duration, rows, err)
+}
@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+// recordingAnalyzer keeps the queries recorded with it
+type recordingAnalyzer struct {
+	queries []string
+	rows    []int64
+	errors  int
+}
+
+func (a *recordingAnalyzer) RecordQuery(query string, params []interface{}, duration time.Duration, rows int64, err error) {
+	a.queries = append(a.queries, query)
+	a.rows = append(a.rows, rows)
+	if err != nil {
+		a.errors++
+	}
+}
+func (a *recordingAnalyzer) GetSlowQueries(limit int) ([]domain.SlowQuery, error) { return nil, nil }
+func (a *recordingAnalyzer) GetMetrics() (domain.PerformanceMetrics, error) {
+	return domain.PerformanceMetrics{}, nil
+}
+func (a *recordingAnalyzer) GetTopQueries(orderBy string, limit int) ([]domain.QueryFingerprint, error) {
+	return nil, nil
+}
+func (a *recordingAnalyzer) AnalyzeQuery(query string) (domain.QueryAnalysis, error) {
+	return domain.QueryAnalysis{Query: query, Executions: len(a.queries)}, nil
+}
+func (a *recordingAnalyzer) Reset() error                     { return nil }
+func (a *recordingAnalyzer) SetThreshold(threshold int) error { return nil }
+func (a *recordingAnalyzer) GetPlanRegressions() ([]domain.PlanRegression, error) {
+	return nil, nil
+}
+func (a *recordingAnalyzer) AcceptPlan(fingerprint string) error { return nil }
+
+func TestExecuteQueryRecordsPerformance(t *testing.T) {
+	uc, db := newMigrationUseCase(t, "postgres", nil)
+	analyzer := &recordingAnalyzer{}
+	uc.repo.(*fakeMigrationRepo).analyzer = analyzer
+	db.state.hasRow = true
+
+	_, err := uc.ExecuteQuery(context.Background(), "main", "SELECT version, dirty FROM schema_migrations", nil)
+	require.NoError(t, err)
+	_, err = uc.ExecuteQuery(context.Background(), "main", "SELECT broken", nil)
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"SELECT version, dirty FROM schema_migrations", "SELECT broken"}, analyzer.queries)
+	assert.Equal(t, []int64{1, 0}, analyzer.rows)
+	assert.Equal(t, 1, analyzer.errors)
+
+	// The plan is part of the analysis, so a query that cannot be explained fails
+	_, err = uc.AnalyzeQuery(context.Background(), "main", "SELECT 1")
+	assert.ErrorContains(t, err, "failed to explain query")
+
+	issue := domain.PlanIssue{Kind: "seq-scan", Relation: "orders", Message: "Sequential scan of orders reads 120000 rows", Suggestion: "Add a LIMIT"}
+	uc.repo.(*fakeMigrationRepo).plan = &domain.QueryPlan{Plan: "-> Seq Scan on orders", Issues: []domain.PlanIssue{issue}}
+	analysis, err := uc.AnalyzeQuery(context.Background(), "main", "SELECT * FROM orders")
+	require.NoError(t, err)
+	assert.Equal(t, "-> Seq Scan on orders", analysis.ExplainPlan)
+	assert.Equal(t, []domain.PlanIssue{issue}, analysis.PlanIssues)
+	assert.Equal(t, []string{"Add a LIMIT"}, analysis.Suggestions, "plan issues add their suggestions")
+}
@@ -0,0 +1,419 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ERDFormats lists the diagram languages GetSchemaERD renders
+var ERDFormats = []string{"mermaid", "plantuml", "dot"}
+
+var (
+	// mermaidName matches entity names Mermaid accepts unquoted
+	mermaidName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+	// diagramUnsafe matches characters not allowed in Mermaid attribute types
+	// and PlantUML aliases
+	diagramUnsafe = regexp.MustCompile(`[^A-Za-z0-9_()\[\]-]+`)
+)
+
+// GetSchemaERD renders the tables and foreign keys of a schema as an entity
+// relationship diagram in Mermaid, PlantUML or Graphviz DOT. With a table,
+// only the tables within depth foreign key hops of it are drawn (depth 1 when
+// zero or less).
+func (uc *DatabaseUseCase) GetSchemaERD(ctx context.Context, dbID, schema, table, format string, depth int) (string, error) {
+	if format == "" {
+		format = "mermaid"
+	}
+	if format != "mermaid" && format != "plantuml" && format != "dot" {
+		return "", fmt.Errorf("invalid diagram format %q: use one of %s", format, strings.Join(ERDFormats, ", "))
+	}
+
+	model, err := uc.repo.GetSchemaComponent(ctx, dbID, "erd", schema, table)
+	if err != nil {
+		return "", fmt.Errorf("failed to get ER diagram for database %s: %w", dbID, err)
+	}
+	diagram := erdFromModel(model)
+
+	if focus, _ := model["table"].(string); focus != "" {
+		if depth <= 0 {
+			depth = 1
+		}
+		if err := diagram.focus(focus, depth); err != nil {
+			return "", err
+		}
+	}
+
+	switch format {
+	case "plantuml":
+		return diagram.plantUML(), nil
+	case "dot":
+		return diagram.dot(), nil
+	default:
+		return diagram.mermaid(), nil
+	}
+}
+
+// erdDiagram is the graph of tables and foreign keys to draw
+type erdDiagram struct {
+	tables    []*erdTable
+	relations []*erdRelation
+}
+
+// erdTable is an entity; tables of other schemas that are only referenced
+// have no columns
+type erdTable struct {
+	name    string
+	columns []erdColumn
+}
+
+// erdColumn is an entity attribute
+type erdColumn struct {
+	name       string
+	dataType   string
+	nullable   bool
+	primaryKey bool
+	foreignKey bool
+	unique     bool
+}
+
+// erdRelation is a foreign key from the child table to the parent table
+type erdRelation struct {
+	child    string
+	parent   string
+	columns  []string
+	optional bool // a child row may have no parent: a key column is nullable
+	oneToOne bool // the key columns are unique in the child table
+}
+
+// erdFromModel converts an erd component result into a diagram
+func erdFromModel(model map[string]interface{}) *erdDiagram {
+	diagram := &erdDiagram{}
+	byName := make(map[string]*erdTable)
+
+	rows, _ := model["tables"].([]map[string]interface{})
+	for _, row := range rows {
+		table := &erdTable{}
+		table.name, _ = row["table_name"].(string)
+		columns, _ := row["columns"].([]map[string]interface{})
+		for _, column := range columns {
+			c := erdColumn{primaryKey: isTruthy(column["is_primary_key"])}
+			c.name, _ = column["column_name"].(string)
+			c.dataType, _ = column["column_type"].(string)
+			if c.dataType == "" {
+				c.dataType, _ = column["data_type"].(string)
+			}
+			nullable, _ := column["is_nullable"].(string)
+			c.nullable = strings.EqualFold(nullable, "YES")
+			table.columns = append(table.columns, c)
+		}
+		diagram.tables = append(diagram.tables, table)
+		byName[table.name] = table
+	}
+
+	// Unique column sets, keyed by table and sorted column list
+	uniqueSets := make(map[string]bool)
+	keyColumns := make(map[string][]string)
+	var keyOrder []string
+	uniqueRows, _ := model["unique_keys"].([]map[string]interface{})
+	for _, row := range uniqueRows {
+		tableName, _ := row["table_name"].(string)
+		indexName, _ := row["index_name"].(string)
+		column, _ := row["column_name"].(string)
+		key := tableName + "\x00" + indexName
+		if _, ok := keyColumns[key]; !ok {
+			keyOrder = append(keyOrder, key)
+		}
+		keyColumns[key] = append(keyColumns[key], column)
+	}
+	for _, key := range keyOrder {
+		tableName := strings.SplitN(key, "\x00", 2)[0]
+		columns := keyColumns[key]
+		uniqueSets[columnSetKey(tableName, columns)] = true
+		if table, ok := byName[tableName]; ok && len(columns) == 1 {
+			for i := range table.columns {
+				if table.columns[i].name == columns[0] && !table.columns[i].primaryKey {
+					table.columns[i].unique = true
+				}
+			}
+		}
+	}
+
+	// Foreign keys arrive one row per column pair
+	schema, _ := model["schema"].(string)
+	byConstraint := make(map[string]*erdRelation)
+	relationships, _ := model["relationships"].([]map[string]interface{})
+	for _, rel := range relationships {
+		tableName, _ := rel["table_name"].(string)
+		constraint, _ := rel["constraint_name"].(string)
+		column, _ := rel["column_name"].(string)
+		foreignTable, _ := rel["foreign_table_name"].(string)
+		foreignSchema, _ := rel["foreign_table_schema"].(string)
+		if tableName == "" || foreignTable == "" {
+			continue
+		}
+		parent := foreignTable
+		if foreignSchema != "" && schema != "" && foreignSchema != schema {
+			parent = foreignSchema + "." + foreignTable
+		}
+
+		key := tableName + "\x00" + constraint
+		relation, ok := byConstraint[key]
+		if !ok {
+			relation = &erdRelation{child: tableName, parent: parent}
+			byConstraint[key] = relation
+			diagram.relations = append(diagram.relations, relation)
+		}
+		if !containsString(relation.columns, column) {
+			relation.columns = append(relation.columns, column)
+		}
+	}
+
+	for _, relation := range diagram.relations {
+		child := byName[relation.child]
+		if child == nil {
+			continue
+		}
+		for i := range child.columns {
+			if containsString(relation.columns, child.columns[i].name) {
+				child.columns[i].foreignKey = true
+				if child.columns[i].nullable {
+					relation.optional = true
+				}
+			}
+		}
+		relation.oneToOne = uniqueSets[columnSetKey(relation.child, relation.columns)]
+		if byName[relation.parent] == nil {
+			parent := &erdTable{name: relation.parent}
+			diagram.tables = append(diagram.tables, parent)
+			byName[parent.name] = parent
+		}
+	}
+	return diagram
+}
+
+// focus keeps only the tables within depth foreign key hops of a table
+func (d *erdDiagram) focus(table string, depth int) error {
+	found := false
+	for _, t := range d.tables {
+		if t.name == table {
+			found = true
+		}
+	}
+	if !found {
+		return fmt.Errorf("table %s not found in schema", table)
+	}
+
+	keep := map[string]bool{table: true}
+	frontier := []string{table}
+	for hop := 0; hop < depth && len(frontier) > 0; hop++ {
+		var next []string
+		for _, name := range frontier {
+			for _, relation := range d.relations {
+				for _, neighbour := range []string{relation.parent, relation.child} {
+					if (relation.child == name || relation.parent == name) && !keep[neighbour] {
+						keep[neighbour] = true
+						next = append(next, neighbour)
+					}
+				}
+			}
+		}
+		frontier = next
+	}
+
+	tables := d.tables[:0]
+	for _, t := range d.tables {
+		if keep[t.name] {
+			tables = append(tables, t)
+		}
+	}
+	d.tables = tables
+
+	relations := d.relations[:0]
+	for _, relation := range d.relations {
+		if keep[relation.child] && keep[relation.parent] {
+			relations = append(relations, relation)
+		}
+	}
+	d.relations = relations
+	return nil
+}
+
+// keyLabels lists a column's PK, FK and UK markers
+func (c erdColumn) keyLabels() []string {
+	var labels []string
+	if c.primaryKey {
+		labels = append(labels, "PK")
+	}
+	if c.foreignKey {
+		labels = append(labels, "FK")
+	}
+	if c.unique {
+		labels = append(labels, "UK")
+	}
+	return labels
+}
+
+// crowsFoot returns the parent and child ends of a relation in the crow's
+// foot notation shared by Mermaid and PlantUML
+func (r *erdRelation) crowsFoot() (string, string) {
+	parent, child := "||", "o{"
+	if r.optional {
+		parent = "|o"
+	}
+	if r.oneToOne {
+		child = "o|"
+	}
+	return parent, child
+}
+
+// label names a relation by its key columns
+func (r *erdRelation) label() string {
+	return strings.Join(r.columns, ", ")
+}
+
+// mermaid renders the diagram as a Mermaid erDiagram
+func (d *erdDiagram) mermaid() string {
+	var out strings.Builder
+	out.WriteString("erDiagram\n")
+	for _, table := range d.tables {
+		if len(table.columns) == 0 {
+			fmt.Fprintf(&out, "    %s {\n    }\n", mermaidEntity(table.name))
+			continue
+		}
+		fmt.Fprintf(&out, "    %s {\n", mermaidEntity(table.name))
+		for _, c := range table.columns {
+			fmt.Fprintf(&out, "        %s %s", diagramIdentifier(c.dataType), diagramIdentifier(c.name))
+			if labels := c.keyLabels(); len(labels) > 0 {
+				out.WriteString(" " + strings.Join(labels, ", "))
+			}
+			out.WriteString("\n")
+		}
+		out.WriteString("    }\n")
+	}
+	for _, r := range d.relations {
+		parentEnd, childEnd := r.crowsFoot()
+		fmt.Fprintf(&out, "    %s %s--%s %s : %q\n", mermaidEntity(r.parent), parentEnd, childEnd, mermaidEntity(r.child), r.label())
+	}
+	return out.String()
+}
+
+// plantUML renders the diagram as a PlantUML entity diagram
+func (d *erdDiagram) plantUML() string {
+	var out strings.Builder
+	out.WriteString("@startuml\nhide circle\nskinparam linetype ortho\n\n")
+	for _, table := range d.tables {
+		fmt.Fprintf(&out, "entity %q as %s {\n", table.name, diagramIdentifier(table.name))
+		// Key columns go above the separator, mandatory columns are starred
+		var keys, others []erdColumn
+		for _, c := range table.columns {
+			if c.primaryKey {
+				keys = append(keys, c)
+			} else {
+				others = append(others, c)
+			}
+		}
+		for i, group := range [][]erdColumn{keys, others} {
+			if i == 1 && len(keys) > 0 {
+				out.WriteString("  --\n")
+			}
+			for _, c := range group {
+				mandatory := "  "
+				if !c.nullable {
+					mandatory = "* "
+				}
+				fmt.Fprintf(&out, "  %s%s : %s", mandatory, c.name, c.dataType)
+				for _, label := range c.keyLabels() {
+					out.WriteString(" <<" + label + ">>")
+				}
+				out.WriteString("\n")
+			}
+		}
+		out.WriteString("}\n\n")
+	}
+	for _, r := range d.relations {
+		parentEnd, childEnd := r.crowsFoot()
+		fmt.Fprintf(&out, "%s %s--%s %s : %s\n", diagramIdentifier(r.parent), parentEnd, childEnd, diagramIdentifier(r.child), r.label())
+	}
+	out.WriteString("@enduml\n")
+	return out.String()
+}
+
+// dot renders the diagram as a Graphviz digraph with HTML table nodes and
+// edges from child to parent labelled with their cardinality
+func (d *erdDiagram) dot() string {
+	var out strings.Builder
+	out.WriteString("digraph erd {\n    graph [rankdir=LR];\n    node [shape=plaintext];\n    edge [arrowhead=none];\n\n")
+	for _, table := range d.tables {
+		fmt.Fprintf(&out, "    %s [label=<<table border=\"0\" cellborder=\"1\" cellspacing=\"0\">", dotID(table.name))
+		fmt.Fprintf(&out, "<tr><td bgcolor=\"lightgrey\"><b>%s</b></td></tr>", html.EscapeString(table.name))
+		for _, c := range table.columns {
+			cell := html.EscapeString(c.name + " " + c.dataType)
+			if labels := c.keyLabels(); len(labels) > 0 {
+				cell += " <i>" + strings.Join(labels, ", ") + "</i>"
+			}
+			fmt.Fprintf(&out, "<tr><td align=\"left\">%s</td></tr>", cell)
+		}
+		out.WriteString("</table>>];\n")
+	}
+	if len(d.relations) > 0 {
+		out.WriteString("\n")
+	}
+	for _, r := range d.relations {
+		parentEnd, childEnd := "1", "0..*"
+		if r.optional {
+			parentEnd = "0..1"
+		}
+		if r.oneToOne {
+			childEnd = "0..1"
+		}
+		fmt.Fprintf(&out, "    %s -> %s [label=%s, taillabel=%q, headlabel=%q];\n",
+			dotID(r.child), dotID(r.parent), dotID(r.label()), childEnd, parentEnd)
+	}
+	out.WriteString("}\n")
+	return out.String()
+}
+
+// mermaidEntity quotes entity names Mermaid does not accept bare
+func mermaidEntity(name string) string {
+	if mermaidName.MatchString(name) {
+		return name
+	}
+	return `"` + strings.ReplaceAll(name, `"`, "'") + `"`
+}
+
+// diagramIdentifier replaces characters not allowed in Mermaid attributes and
+// PlantUML aliases, e.g. "timestamp with time zone" becomes
+// timestamp_with_time_zone
+func diagramIdentifier(s string) string {
+	s = diagramUnsafe.ReplaceAllString(s, "_")
+	if s == "" {
+		return "_"
+	}
+	return s
+}
+
+// dotID quotes a Graphviz identifier
+func dotID(s string) string {
+	return `"` + strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"`, `\"`) + `"`
+}
+
+// columnSetKey identifies a table's column set regardless of column order
+func columnSetKey(table string, columns []string) string {
+	sorted := append([]string(nil), columns...)
+	sort.Strings(sorted)
+	return table + "\x00" + strings.Join(sorted, "\x00")
+}
+
+// containsString reports whether a slice holds a string
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func erdModel() map[string]interface{} {
+	column := func(name, columnType, nullable string, pk bool) map[string]interface{} {
+		return map[string]interface{}{"column_name": name, "column_type": columnType, "is_nullable": nullable, "is_primary_key": pk}
+	}
+	fk := func(constraint, table, column, foreignTable, foreignColumn string) map[string]interface{} {
+		return map[string]interface{}{"constraint_name": constraint, "table_schema": "public", "table_name": table, "column_name": column,
+			"foreign_table_schema": "public", "foreign_table_name": foreignTable, "foreign_column_name": foreignColumn}
+	}
+	return map[string]interface{}{
+		"schema": "public",
+		"tables": []map[string]interface{}{
+			{"table_name": "customers", "columns": []map[string]interface{}{
+				column("id", "integer", "NO", true), column("email", "character varying(255)", "NO", false),
+			}},
+			{"table_name": "orders", "columns": []map[string]interface{}{
+				column("id", "bigint", "NO", true), column("customer_id", "integer", "NO", false),
+				column("coupon_id", "integer", "YES", false),
+			}},
+			{"table_name": "invoices", "columns": []map[string]interface{}{
+				column("id", "bigint", "NO", true), column("order_id", "bigint", "NO", false),
+			}},
+			{"table_name": "coupons", "columns": []map[string]interface{}{column("id", "integer", "NO", true)}},
+			{"table_name": "audit_log", "columns": []map[string]interface{}{column("id", "bigint", "NO", true)}},
+		},
+		"relationships": []map[string]interface{}{
+			fk("orders_customer_id_fkey", "orders", "customer_id", "customers", "id"),
+			fk("orders_coupon_id_fkey", "orders", "coupon_id", "coupons", "id"),
+			fk("invoices_order_id_fkey", "invoices", "order_id", "orders", "id"),
+		},
+		"unique_keys": []map[string]interface{}{
+			{"table_name": "customers", "index_name": "customers_pkey", "column_name": "id"},
+			{"table_name": "customers", "index_name": "customers_email_key", "column_name": "email"},
+			{"table_name": "invoices", "index_name": "invoices_order_id_key", "column_name": "order_id"},
+		},
+	}
+}
+
+func TestERDCardinality(t *testing.T) {
+	diagram := erdFromModel(erdModel())
+	require.Len(t, diagram.relations, 3)
+
+	customer, coupon, invoice := diagram.relations[0], diagram.relations[1], diagram.relations[2]
+	assert.False(t, customer.optional)
+	assert.False(t, customer.oneToOne)
+	assert.True(t, coupon.optional, "nullable key columns make the parent optional")
+	assert.True(t, invoice.oneToOne, "unique key columns make the relation one-to-one")
+}
+
+func TestERDMermaid(t *testing.T) {
+	out := erdFromModel(erdModel()).mermaid()
+	assert.Contains(t, out, "erDiagram\n    customers {\n        integer id PK\n        character_varying(255) email UK\n    }\n")
+	assert.Contains(t, out, "        integer coupon_id FK\n")
+	assert.Contains(t, out, `    customers ||--o{ orders : "customer_id"`)
+	assert.Contains(t, out, `    coupons |o--o{ orders : "coupon_id"`)
+	assert.Contains(t, out, `    orders ||--o| invoices : "order_id"`)
+}
+
+func TestERDPlantUMLAndDot(t *testing.T) {
+	diagram := erdFromModel(erdModel())
+
+	uml := diagram.plantUML()
+	assert.Contains(t, uml, "@startuml\n")
+	assert.Contains(t, uml, "entity \"orders\" as orders {\n  * id : bigint <<PK>>\n  --\n  * customer_id : integer <<FK>>\n    coupon_id : integer <<FK>>\n}")
+	assert.Contains(t, uml, "orders ||--o| invoices : order_id\n")
+	assert.Contains(t, uml, "@enduml\n")
+
+	dot := diagram.dot()
+	assert.Contains(t, dot, "digraph erd {")
+	assert.Contains(t, dot, `<tr><td align="left">customer_id integer <i>FK</i></td></tr>`)
+	assert.Contains(t, dot, `"orders" -> "coupons" [label="coupon_id", taillabel="0..*", headlabel="0..1"];`)
+	assert.Contains(t, dot, `"invoices" -> "orders" [label="order_id", taillabel="0..1", headlabel="1"];`)
+}
+
+func TestERDFocus(t *testing.T) {
+	diagram := erdFromModel(erdModel())
+	require.NoError(t, diagram.focus("customers", 1))
+	var names []string
+	for _, table := range diagram.tables {
+		names = append(names, table.name)
+	}
+	assert.Equal(t, []string{"customers", "orders"}, names)
+	assert.Len(t, diagram.relations, 1)
+
+	diagram = erdFromModel(erdModel())
+	require.NoError(t, diagram.focus("customers", 2))
+	assert.Len(t, diagram.tables, 4)
+	assert.Len(t, diagram.relations, 3)
+
+	assert.Error(t, erdFromModel(erdModel()).focus("missing", 1))
+}
@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+)
+
+// GetSchemaSnapshot introspects the tables, columns, indexes and constraints
+// (including foreign keys) of a database schema
+func (uc *DatabaseUseCase) GetSchemaSnapshot(ctx context.Context, dbID, schema string) (*domain.SchemaSnapshot, error) {
+	components := make(map[string]map[string]interface{})
+	for _, component := range []string{"summary", "indexes", "constraints", "relationships"} {
+		result, err := uc.repo.GetSchemaComponent(ctx, dbID, component, schema, "")
+		if err != nil {
+			return nil, fmt.Errorf("failed to snapshot %s for database %s: %w", component, dbID, err)
+		}
+		components[component] = result
+	}
+
+	summary := components["summary"]
+	snapshot := &domain.SchemaSnapshot{
+		Database: dbID,
+		TakenAt:  time.Now().UTC(),
+		Tables:   make(map[string]domain.TableSnapshot),
+	}
+	snapshot.DBType, _ = summary["dbType"].(string)
+	snapshot.Schema, _ = summary["schema"].(string)
+
+	tables, _ := summary["tables"].([]map[string]interface{})
+	for _, row := range tables {
+		name, _ := row["table_name"].(string)
+		table := domain.TableSnapshot{Columns: []domain.ColumnInfo{}}
+		columns, _ := row["columns"].([]map[string]interface{})
+		for _, column := range columns {
+			info := domain.ColumnInfo{
+				Name:     stringValue(column["column_name"]),
+				Type:     stringValue(column["column_type"]),
+				Nullable: strings.EqualFold(stringValue(column["is_nullable"]), "YES"),
+				Default:  stringValue(column["column_default"]),
+			}
+			if info.Type == "" {
+				info.Type = stringValue(column["data_type"])
+			}
+			table.Columns = append(table.Columns, info)
+		}
+		snapshot.Tables[name] = table
+	}
+
+	indexes, _ := components["indexes"]["indexes"].([]map[string]interface{})
+	for _, row := range indexes {
+		index := domain.IndexInfo{
+			Name:      stringValue(row["index_name"]),
+			Table:     stringValue(row["table_name"]),
+			Columns:   splitColumnList(row["columns"]),
+			Unique:    isTruthy(row["is_unique"]),
+			Primary:   isTruthy(row["is_primary"]),
+			Method:    strings.ToLower(stringValue(row["method"])),
+			Predicate: stringValue(row["predicate"]),
+		}
+		if len(index.Columns) == 0 {
+			index.Definition = stringValue(row["definition"])
+		}
+		addToTable(snapshot, index.Table, func(t *domain.TableSnapshot) { t.Indexes = append(t.Indexes, index) })
+	}
+
+	constraints, _ := components["constraints"]["constraints"].([]map[string]interface{})
+	for _, row := range constraints {
+		constraint := domain.ConstraintInfo{
+			Name:       stringValue(row["constraint_name"]),
+			Type:       stringValue(row["constraint_type"]),
+			Table:      stringValue(row["table_name"]),
+			Columns:    splitColumnList(row["columns"]),
+			Definition: stringValue(row["definition"]),
+		}
+		addToTable(snapshot, constraint.Table, func(t *domain.TableSnapshot) { t.Constraints = append(t.Constraints, constraint) })
+	}
+
+	for _, fk := range foreignKeyConstraints(components["relationships"]) {
+		addToTable(snapshot, fk.Table, func(t *domain.TableSnapshot) { t.Constraints = append(t.Constraints, fk) })
+	}
+
+	return snapshot, nil
+}
+
+// foreignKeyConstraints groups the per-column rows of the relationships
+// component into one constraint per foreign key
+func foreignKeyConstraints(relationships map[string]interface{}) []domain.ConstraintInfo {
+	rows, _ := relationships["relationships"].([]map[string]interface{})
+	byKey := make(map[string]*domain.ConstraintInfo)
+	var keys []string
+	for _, row := range rows {
+		table := stringValue(row["table_name"])
+		name := stringValue(row["constraint_name"])
+		key := table + "." + name
+
+		fk, ok := byKey[key]
+		if !ok {
+			referenced := stringValue(row["foreign_table_name"])
+			if schema := stringValue(row["foreign_table_schema"]); schema != "" && schema != stringValue(row["table_schema"]) {
+				referenced = schema + "." + referenced
+			}
+			fk = &domain.ConstraintInfo{Name: name, Type: "FOREIGN KEY", Table: table, ReferencedTable: referenced}
+			byKey[key] = fk
+			keys = append(keys, key)
+		}
+		fk.Columns = appendUnique(fk.Columns, stringValue(row["column_name"]))
+		fk.ReferencedColumns = appendUnique(fk.ReferencedColumns, stringValue(row["foreign_column_name"]))
+	}
+
+	sort.Strings(keys)
+	constraints := make([]domain.ConstraintInfo, 0, len(keys))
+	for _, key := range keys {
+		constraints = append(constraints, *byKey[key])
+	}
+	return constraints
+}
+
+// addToTable updates a table of the snapshot, ignoring objects of tables the
+// column listing did not report
+func addToTable(snapshot *domain.SchemaSnapshot, name string, update func(*domain.TableSnapshot)) {
+	table, ok := snapshot.Tables[name]
+	if !ok {
+		return
+	}
+	update(&table)
+	snapshot.Tables[name] = table
+}
+
+// splitColumnList splits a "a, b" column list as reported by the catalogs
+func splitColumnList(value interface{}) []string {
+	list := stringValue(value)
+	if list == "" {
+		return nil
+	}
+	parts := strings.Split(list, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
+
+// appendUnique appends a value unless the slice already holds it
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// stringValue formats a catalog value as a string, mapping NULL to ""
+func stringValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", value)
+}
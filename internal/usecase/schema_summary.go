@@ -0,0 +1,376 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// DefaultSummaryBudget is the character budget of a schema summary when
+	// the caller sets none, roughly 2000 tokens
+	DefaultSummaryBudget = 8000
+
+	// summaryColumnLimit is how many columns a table line shows before the
+	// rest are counted as "+N"
+	summaryColumnLimit = 6
+)
+
+// summaryStopWords are question words that say nothing about the schema
+var summaryStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "from": true,
+	"what": true, "which": true, "who": true, "how": true, "many": true,
+	"much": true, "per": true, "all": true, "are": true, "was": true,
+	"were": true, "has": true, "have": true, "show": true, "list": true,
+	"get": true, "find": true, "each": true, "that": true, "this": true,
+	"there": true, "their": true, "did": true, "does": true,
+}
+
+// GetSchemaSummary renders a compact, DDL-like overview of a schema: one
+// line per table with its key columns, foreign key edges and row estimate.
+// Tables are ranked by relevance to the optional question and the output is
+// cut to maxChars characters (DefaultSummaryBudget when zero or less).
+func (uc *DatabaseUseCase) GetSchemaSummary(ctx context.Context, dbID, schema, question string, maxChars int) (string, error) {
+	model, err := uc.repo.GetSchemaComponent(ctx, dbID, "summary", schema, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to get summary for database %s: %w", dbID, err)
+	}
+	if maxChars <= 0 {
+		maxChars = DefaultSummaryBudget
+	}
+	return renderSchemaSummary(model, question, maxChars), nil
+}
+
+// summaryTable is a table as shown in a schema summary
+type summaryTable struct {
+	name        string
+	description string
+	rows        int64
+	hasRows     bool
+	columns     []summaryColumn
+	neighbours  map[string]bool // tables linked by a foreign key
+	score       int
+}
+
+// summaryColumn is a column as shown in a schema summary
+type summaryColumn struct {
+	name       string
+	dataType   string
+	primaryKey bool
+	references string // "table.column" for foreign keys
+	matched    bool   // the column name matches the question
+}
+
+// renderSchemaSummary formats a summary component result within maxChars
+func renderSchemaSummary(model map[string]interface{}, question string, maxChars int) string {
+	tables := summaryTables(model)
+	keywords := questionKeywords(question)
+	rankSummaryTables(tables, keywords)
+
+	schema, _ := model["schema"].(string)
+	dbType, _ := model["dbType"].(string)
+	relationships, _ := model["relationships"].([]map[string]interface{})
+
+	var header strings.Builder
+	header.WriteString("-- " + dbType + " schema")
+	if schema != "" {
+		header.WriteString(" " + schema)
+	}
+	fmt.Fprintf(&header, ": %d tables, %d foreign key columns", len(tables), len(relationships))
+	if len(keywords) > 0 {
+		fmt.Fprintf(&header, "; ranked by relevance to %q", question)
+	}
+	header.WriteString("\n")
+
+	lines := make([]string, len(tables))
+	total := header.Len()
+	for i, table := range tables {
+		lines[i] = renderSummaryTable(table) + "\n"
+		total += len(lines[i])
+	}
+
+	var out strings.Builder
+	out.WriteString(header.String())
+	if total <= maxChars {
+		for _, line := range lines {
+			out.WriteString(line)
+		}
+		return out.String()
+	}
+
+	// Keep room for the note listing what was left out
+	reserve := maxChars / 4
+	if reserve > 400 {
+		reserve = 400
+	}
+	shown := 0
+	for _, line := range lines {
+		if out.Len()+len(line) > maxChars-reserve {
+			break
+		}
+		out.WriteString(line)
+		shown++
+	}
+
+	note := fmt.Sprintf("-- omitted %d of %d tables to stay within %d characters:", len(tables)-shown, len(tables), maxChars)
+	for i, table := range tables[shown:] {
+		name := " " + table.name
+		if i > 0 {
+			name = "," + name
+		}
+		if out.Len()+len(note)+len(name)+len(" ...\n") > maxChars {
+			note += " ..."
+			break
+		}
+		note += name
+	}
+	out.WriteString(note + "\n")
+	return out.String()
+}
+
+// summaryTables converts a summary component result into tables with their
+// foreign keys attached
+func summaryTables(model map[string]interface{}) []*summaryTable {
+	rows, _ := model["tables"].([]map[string]interface{})
+	tables := make([]*summaryTable, 0, len(rows))
+	byName := make(map[string]*summaryTable)
+	for _, row := range rows {
+		table := &summaryTable{neighbours: make(map[string]bool)}
+		table.name, _ = row["table_name"].(string)
+		if description, ok := row["description"].(string); ok {
+			table.description = description
+		}
+		table.rows, table.hasRows = toInt64(row["row_estimate"])
+
+		columns, _ := row["columns"].([]map[string]interface{})
+		for _, column := range columns {
+			c := summaryColumn{primaryKey: isTruthy(column["is_primary_key"])}
+			c.name, _ = column["column_name"].(string)
+			c.dataType, _ = column["data_type"].(string)
+			table.columns = append(table.columns, c)
+		}
+		tables = append(tables, table)
+		byName[table.name] = table
+	}
+
+	relationships, _ := model["relationships"].([]map[string]interface{})
+	for _, rel := range relationships {
+		tableName, _ := rel["table_name"].(string)
+		columnName, _ := rel["column_name"].(string)
+		foreignTable, _ := rel["foreign_table_name"].(string)
+		foreignColumn, _ := rel["foreign_column_name"].(string)
+		tableSchema, _ := rel["table_schema"].(string)
+		foreignSchema, _ := rel["foreign_table_schema"].(string)
+
+		table, ok := byName[tableName]
+		if !ok || foreignTable == "" {
+			continue
+		}
+		target := foreignTable
+		if foreignSchema != "" && foreignSchema != tableSchema {
+			target = foreignSchema + "." + foreignTable
+		}
+		for i := range table.columns {
+			if table.columns[i].name == columnName {
+				table.columns[i].references = target + "." + foreignColumn
+			}
+		}
+		table.neighbours[foreignTable] = true
+		if other, ok := byName[foreignTable]; ok {
+			other.neighbours[tableName] = true
+		}
+	}
+	return tables
+}
+
+// rankSummaryTables orders tables by relevance to the keywords. Without
+// keywords, or between equally relevant tables, the best connected and then
+// the largest tables come first.
+func rankSummaryTables(tables []*summaryTable, keywords []string) {
+	if len(keywords) > 0 {
+		for _, table := range tables {
+			for _, keyword := range keywords {
+				if identifierMatches(table.name, keyword) {
+					table.score += 10
+				}
+				if strings.Contains(strings.ToLower(table.description), keyword) {
+					table.score += 2
+				}
+				for i := range table.columns {
+					if identifierMatches(table.columns[i].name, keyword) {
+						table.columns[i].matched = true
+						table.score += 3
+					}
+				}
+			}
+		}
+
+		// Tables joined to relevant tables are likely needed in the query too
+		direct := make(map[string]bool)
+		for _, table := range tables {
+			if table.score > 0 {
+				direct[table.name] = true
+			}
+		}
+		for _, table := range tables {
+			for neighbour := range table.neighbours {
+				if direct[neighbour] {
+					table.score++
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(tables, func(i, j int) bool {
+		a, b := tables[i], tables[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if len(a.neighbours) != len(b.neighbours) {
+			return len(a.neighbours) > len(b.neighbours)
+		}
+		if a.rows != b.rows {
+			return a.rows > b.rows
+		}
+		return a.name < b.name
+	})
+}
+
+// renderSummaryTable formats a table as a single DDL-like line, e.g.
+//
+//	orders(id bigint PK, customer_id integer -> customers.id, +4) ~1.2M rows -- One row per checkout
+func renderSummaryTable(table *summaryTable) string {
+	var shown []string
+	hidden := 0
+	isKey := func(c summaryColumn) bool { return c.primaryKey || c.references != "" || c.matched }
+
+	// Key and matching columns always show; others fill the remaining slots
+	keys := 0
+	for _, c := range table.columns {
+		if isKey(c) {
+			keys++
+		}
+	}
+	free := summaryColumnLimit - keys
+	for _, c := range table.columns {
+		if !isKey(c) {
+			if free <= 0 {
+				hidden++
+				continue
+			}
+			free--
+		}
+		column := c.name
+		if c.dataType != "" {
+			column += " " + c.dataType
+		}
+		if c.primaryKey {
+			column += " PK"
+		}
+		if c.references != "" {
+			column += " -> " + c.references
+		}
+		shown = append(shown, column)
+	}
+	if hidden > 0 {
+		shown = append(shown, "+"+strconv.Itoa(hidden))
+	}
+
+	line := table.name + "(" + strings.Join(shown, ", ") + ")"
+	if table.hasRows {
+		line += " ~" + humanCount(table.rows) + " rows"
+	}
+	if table.description != "" {
+		line += " -- " + strings.Join(strings.Fields(table.description), " ")
+	}
+	return line
+}
+
+// questionKeywords extracts lower-case search words from a question
+func questionKeywords(question string) []string {
+	words := strings.FieldsFunc(strings.ToLower(question), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	})
+	var keywords []string
+	seen := make(map[string]bool)
+	for _, word := range words {
+		if len(word) < 3 || summaryStopWords[word] || seen[word] {
+			continue
+		}
+		seen[word] = true
+		keywords = append(keywords, word)
+	}
+	return keywords
+}
+
+// identifierMatches reports whether a keyword names an identifier or one of
+// its underscore-separated parts, tolerating plural suffixes
+func identifierMatches(identifier, keyword string) bool {
+	for _, part := range strings.Split(strings.ToLower(identifier), "_") {
+		if len(part) < 3 {
+			continue
+		}
+		long, short := part, keyword
+		if len(short) > len(long) {
+			long, short = short, long
+		}
+		if strings.HasPrefix(long, short) && len(long)-len(short) <= 2 {
+			return true
+		}
+	}
+	return false
+}
+
+// humanCount formats a row count as 950, 12K, 1.2M or 3.4B
+func humanCount(n int64) string {
+	format := func(value float64, suffix string) string {
+		s := strconv.FormatFloat(value, 'f', 1, 64)
+		return strings.TrimSuffix(s, ".0") + suffix
+	}
+	switch {
+	case n >= 1_000_000_000:
+		return format(float64(n)/1e9, "B")
+	case n >= 1_000_000:
+		return format(float64(n)/1e6, "M")
+	case n >= 1_000:
+		return format(float64(n)/1e3, "K")
+	default:
+		return strconv.FormatInt(n, 10)
+	}
+}
+
+// toInt64 converts a numeric catalog value to int64
+func toInt64(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int64:
+		return v, true
+	case int:
+		return int64(v), true
+	case uint64:
+		return int64(v), true
+	case float64:
+		return int64(v), true
+	case string:
+		n, err := strconv.ParseInt(v, 10, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// isTruthy interprets boolean catalog values, which MySQL returns as 0 or 1
+func isTruthy(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	case int:
+		return v != 0
+	case string:
+		return v == "1" || strings.EqualFold(v, "true") || strings.EqualFold(v, "yes")
+	}
+	return false
+}
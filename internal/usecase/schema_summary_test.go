@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func summaryModel() map[string]interface{} {
+	column := func(name, dataType string, pk bool) map[string]interface{} {
+		return map[string]interface{}{"column_name": name, "data_type": dataType, "is_primary_key": pk}
+	}
+	return map[string]interface{}{
+		"dbType": "postgres",
+		"schema": "public",
+		"tables": []map[string]interface{}{
+			{"table_name": "audit_log", "row_estimate": int64(5_400_000), "columns": []map[string]interface{}{
+				column("id", "bigint", true), column("message", "text", false),
+			}},
+			{"table_name": "customers", "row_estimate": int64(1200), "description": "People who buy things", "columns": []map[string]interface{}{
+				column("id", "integer", true), column("email", "text", false),
+			}},
+			{"table_name": "orders", "row_estimate": int64(48_000), "columns": []map[string]interface{}{
+				column("id", "integer", true), column("customer_id", "integer", false),
+				column("total", "numeric", false), column("status", "text", false),
+			}},
+			{"table_name": "settings", "row_estimate": nil, "columns": []map[string]interface{}{
+				column("key", "text", true), column("a", "text", false), column("b", "text", false),
+				column("c", "text", false), column("d", "text", false), column("e", "text", false),
+				column("f", "text", false),
+			}},
+		},
+		"relationships": []map[string]interface{}{
+			{"table_schema": "public", "table_name": "orders", "column_name": "customer_id",
+				"foreign_table_schema": "public", "foreign_table_name": "customers", "foreign_column_name": "id"},
+		},
+	}
+}
+
+func TestRenderSchemaSummary(t *testing.T) {
+	out := renderSchemaSummary(summaryModel(), "", DefaultSummaryBudget)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 5)
+
+	assert.Equal(t, "-- postgres schema public: 4 tables, 1 foreign key columns", lines[0])
+	// Connected tables first, then by size
+	assert.Equal(t, "orders(id integer PK, customer_id integer -> customers.id, total numeric, status text) ~48K rows", lines[1])
+	assert.Equal(t, "customers(id integer PK, email text) ~1.2K rows -- People who buy things", lines[2])
+	assert.Equal(t, "audit_log(id bigint PK, message text) ~5.4M rows", lines[3])
+	assert.Equal(t, "settings(key text PK, a text, b text, c text, d text, e text, +1)", lines[4])
+}
+
+func TestRenderSchemaSummaryRanksByQuestion(t *testing.T) {
+	out := renderSchemaSummary(summaryModel(), "Which customer emails have the most audit messages?", DefaultSummaryBudget)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+
+	assert.Contains(t, lines[0], `ranked by relevance to "Which customer emails have the most audit messages?"`)
+	assert.True(t, strings.HasPrefix(lines[1], "customers("), lines[1])
+	assert.True(t, strings.HasPrefix(lines[2], "audit_log("), lines[2])
+	// Joined to a relevant table
+	assert.True(t, strings.HasPrefix(lines[3], "orders("), lines[3])
+}
+
+func TestRenderSchemaSummaryBudget(t *testing.T) {
+	out := renderSchemaSummary(summaryModel(), "orders", 300)
+	assert.LessOrEqual(t, len(out), 300)
+	assert.Contains(t, out, "\norders(")
+	assert.True(t, strings.HasSuffix(out, "-- omitted 3 of 4 tables to stay within 300 characters: customers, audit_log, settings\n"), out)
+
+	// Names that do not fit are elided
+	out = renderSchemaSummary(summaryModel(), "orders", 260)
+	assert.LessOrEqual(t, len(out), 260)
+	assert.True(t, strings.HasSuffix(out, " ...\n"), out)
+}
+
+func TestQuestionKeywords(t *testing.T) {
+	assert.Equal(t, []string{"revenue", "customer", "2024"}, questionKeywords("What is the revenue per customer in 2024? per customer"))
+	assert.Empty(t, questionKeywords(""))
+}
+
+func TestIdentifierMatches(t *testing.T) {
+	assert.True(t, identifierMatches("order_items", "orders"))
+	assert.True(t, identifierMatches("customer_id", "customers"))
+	assert.True(t, identifierMatches("addresses", "address"))
+	assert.False(t, identifierMatches("id", "identity"))
+	assert.False(t, identifierMatches("status", "state"))
+}
+
+func TestHumanCount(t *testing.T) {
+	assert.Equal(t, "950", humanCount(950))
+	assert.Equal(t, "12K", humanCount(12_000))
+	assert.Equal(t, "1.5M", humanCount(1_500_000))
+	assert.Equal(t, "3B", humanCount(3_000_000_000))
+}
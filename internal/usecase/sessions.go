@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+)
+
+// SetSessionControl sets which signals tools may send to database sessions:
+// off (the default), cancel, or terminate, which also allows cancel
+func (uc *DatabaseUseCase) SetSessionControl(policy string) {
+	uc.sessionControl = policy
+}
+
+// checkSessionControl refuses signals the session control policy does not
+// allow
+func (uc *DatabaseUseCase) checkSessionControl(signal string) error {
+	switch signal {
+	case db.SessionCancel:
+		if uc.sessionControl == db.SessionCancel || uc.sessionControl == db.SessionTerminate {
+			return nil
+		}
+	case db.SessionTerminate:
+		if uc.sessionControl == db.SessionTerminate {
+			return nil
+		}
+	default:
+		return fmt.Errorf("invalid session signal %q: use cancel or terminate", signal)
+	}
+	return fmt.Errorf("sending %s to sessions is not allowed; set security.session_control to %s to allow it", signal, signal)
+}
+
+// GetLockWaits lists the sessions of a database waiting for locks with the
+// sessions blocking them
+func (uc *DatabaseUseCase) GetLockWaits(ctx context.Context, dbID string) ([]domain.LockWait, error) {
+	waits, err := uc.repo.GetLockWaits(ctx, dbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock waits for database %s: %w", dbID, err)
+	}
+	return waits, nil
+}
+
+// SignalBlockingSession cancels the query of a session blocking others, or
+// terminates the session, as the session control policy allows. Sessions
+// that block no one are refused.
+func (uc *DatabaseUseCase) SignalBlockingSession(ctx context.Context, dbID string, pid int64, signal string) error {
+	if err := uc.checkSessionControl(signal); err != nil {
+		return err
+	}
+	waits, err := uc.GetLockWaits(ctx, dbID)
+	if err != nil {
+		return err
+	}
+	blocked := 0
+	for _, wait := range waits {
+		if wait.BlockingPID == pid {
+			blocked++
+		}
+	}
+	if blocked == 0 {
+		return fmt.Errorf("session %d is not blocking any session on database %s", pid, dbID)
+	}
+
+	if err := uc.repo.SignalSession(ctx, dbID, pid, signal); err != nil {
+		return fmt.Errorf("failed to signal session on database %s: %w", dbID, err)
+	}
+	logger.Warn("Sent %s to session %d on %s, which blocked %d waits", signal, pid, dbID, blocked)
+	return nil
+}
+
+// GetActivity lists the live sessions of a database, longest in their
+// current state first
+func (uc *DatabaseUseCase) GetActivity(ctx context.Context, dbID string, filter domain.ActivityFilter) ([]domain.SessionActivity, error) {
+	sessions, err := uc.repo.GetActivity(ctx, dbID, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session activity for database %s: %w", dbID, err)
+	}
+	return sessions, nil
+}
+
+// CancelQuery cancels the running query of a session of a database, as the
+// session control policy allows
+func (uc *DatabaseUseCase) CancelQuery(ctx context.Context, dbID string, pid int64) error {
+	if err := uc.checkSessionControl(db.SessionCancel); err != nil {
+		return err
+	}
+	sessions, err := uc.GetActivity(ctx, dbID, domain.ActivityFilter{PID: pid})
+	if err != nil {
+		return err
+	}
+	if len(sessions) == 0 {
+		return fmt.Errorf("session %d is not a session of database %s", pid, dbID)
+	}
+	if sessions[0].State != "active" {
+		return fmt.Errorf("session %d has no running query (state %s)", pid, sessions[0].State)
+	}
+
+	if err := uc.repo.SignalSession(ctx, dbID, pid, db.SessionCancel); err != nil {
+		return fmt.Errorf("failed to signal session on database %s: %w", dbID, err)
+	}
+	logger.Warn("Cancelled the query of session %d on %s after %.0f ms: %s", pid, dbID, sessions[0].Duration, sessions[0].Query)
+	return nil
+}
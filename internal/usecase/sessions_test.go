@@ -0,0 +1,57 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/domain"
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+)
+
+func TestSignalBlockingSession(t *testing.T) {
+	uc, _ := newMigrationUseCase(t, "postgres", nil)
+	repo := uc.repo.(*fakeMigrationRepo)
+	repo.locks = []domain.LockWait{
+		{WaitingPID: 101, BlockingPID: 100},
+		{WaitingPID: 102, BlockingPID: 101},
+	}
+	ctx := context.Background()
+
+	// Session control is off by default
+	err := uc.SignalBlockingSession(ctx, "test_db", 100, db.SessionCancel)
+	assert.ErrorContains(t, err, "set security.session_control to cancel")
+
+	uc.SetSessionControl(db.SessionCancel)
+	require.NoError(t, uc.SignalBlockingSession(ctx, "test_db", 100, db.SessionCancel))
+	err = uc.SignalBlockingSession(ctx, "test_db", 100, db.SessionTerminate)
+	assert.ErrorContains(t, err, "set security.session_control to terminate")
+
+	uc.SetSessionControl(db.SessionTerminate)
+	require.NoError(t, uc.SignalBlockingSession(ctx, "test_db", 101, db.SessionTerminate))
+	err = uc.SignalBlockingSession(ctx, "test_db", 102, db.SessionTerminate)
+	assert.ErrorContains(t, err, "not blocking any session")
+	assert.ErrorContains(t, uc.SignalBlockingSession(ctx, "test_db", 100, "kill"), "invalid session signal")
+
+	assert.Equal(t, []string{"cancel 100", "terminate 101"}, repo.signals)
+}
+
+func TestCancelQuery(t *testing.T) {
+	uc, _ := newMigrationUseCase(t, "postgres", nil)
+	repo := uc.repo.(*fakeMigrationRepo)
+	ctx := context.Background()
+
+	assert.ErrorContains(t, uc.CancelQuery(ctx, "test_db", 100), "set security.session_control to cancel")
+
+	uc.SetSessionControl(db.SessionCancel)
+	assert.ErrorContains(t, uc.CancelQuery(ctx, "test_db", 100), "not a session of database test_db")
+
+	repo.sessions = []domain.SessionActivity{{PID: 100, State: "idle"}}
+	assert.ErrorContains(t, uc.CancelQuery(ctx, "test_db", 100), "has no running query")
+
+	repo.sessions = []domain.SessionActivity{{PID: 100, State: "active", Query: "SELECT pg_sleep(600)"}}
+	require.NoError(t, uc.CancelQuery(ctx, "test_db", 100))
+	assert.Equal(t, []string{"cancel 100"}, repo.signals)
+}
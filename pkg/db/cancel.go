@@ -0,0 +1,259 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// Session signals, which are also the session control policies allowing them
+const (
+	SessionCancel    = "cancel"    // cancel the running query of a session
+	SessionTerminate = "terminate" // close a session, rolling back its transaction
+)
+
+// cancelTimeout bounds cancelling a statement on the server
+const cancelTimeout = 2 * time.Second
+
+// sessionIDQueries read the id the server knows a connection's session by
+var sessionIDQueries = map[string]string{
+	"postgres": "SELECT pg_backend_pid()",
+	"mysql":    "SELECT CONNECTION_ID()",
+}
+
+// sessionActiveQueries report whether the session with an id runs a statement
+var sessionActiveQueries = map[string]string{
+	"postgres": "SELECT state = 'active' FROM pg_catalog.pg_stat_activity WHERE pid = $1",
+	"mysql":    "SELECT COMMAND = 'Query' FROM information_schema.PROCESSLIST WHERE ID = ?",
+}
+
+// sessionPollInterval is how often a cancelled session is checked until it
+// no longer runs a statement
+const sessionPollInterval = 50 * time.Millisecond
+
+// Tx is a transaction started by BeginPinnedTx. When the database cancels
+// statements on the server, the transaction runs on a connection pinned for
+// it, and the statement it is running when its context ends is cancelled.
+// Commit and Rollback return the connection to the pool.
+type Tx struct {
+	*sql.Tx
+	release func()
+}
+
+// Rows are the rows of a query started by QueryPinned. Close returns the
+// connection of the query to the pool.
+type Rows struct {
+	*sql.Rows
+	release func()
+}
+
+// Close closes the rows
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	if r.release != nil {
+		r.release()
+	}
+	return err
+}
+
+// Row is the row of a query started by QueryRowPinned. Scan returns the
+// connection of the query to the pool.
+type Row struct {
+	*sql.Row
+	release func()
+}
+
+// Scan copies the columns of the row into dest
+func (r *Row) Scan(dest ...interface{}) error {
+	err := r.Row.Scan(dest...)
+	if r.release != nil {
+		r.release()
+	}
+	return err
+}
+
+// QueryPinned runs a query on database with its QueryPinned method, and a
+// plain query on databases without one
+func QueryPinned(ctx context.Context, database interface {
+	Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}, query string, args ...interface{}) (*Rows, error) {
+	if pinned, ok := database.(interface {
+		QueryPinned(ctx context.Context, query string, args ...interface{}) (*Rows, error)
+	}); ok {
+		return pinned.QueryPinned(ctx, query, args...)
+	}
+	rows, err := database.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{Rows: rows}, nil
+}
+
+// QueryRowPinned runs a single-row query on database with its QueryRowPinned
+// method, and a plain query on databases without one
+func QueryRowPinned(ctx context.Context, database interface {
+	QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row
+}, query string, args ...interface{}) (*Row, error) {
+	if pinned, ok := database.(interface {
+		QueryRowPinned(ctx context.Context, query string, args ...interface{}) (*Row, error)
+	}); ok {
+		return pinned.QueryRowPinned(ctx, query, args...)
+	}
+	row := database.QueryRow(ctx, query, args...)
+	if row == nil {
+		return nil, ErrNoDatabase
+	}
+	return &Row{Row: row}, nil
+}
+
+// BeginPinnedTx starts a transaction on database with its BeginPinnedTx
+// method, and a plain transaction on databases without one
+func BeginPinnedTx(ctx context.Context, database interface {
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
+}, opts *sql.TxOptions) (*Tx, error) {
+	if pinned, ok := database.(interface {
+		BeginPinnedTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error)
+	}); ok {
+		return pinned.BeginPinnedTx(ctx, opts)
+	}
+	tx, err := database.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx}, nil
+}
+
+// Commit commits the transaction
+func (tx *Tx) Commit() error {
+	err := tx.Tx.Commit()
+	tx.done()
+	return err
+}
+
+// Rollback rolls back the transaction
+func (tx *Tx) Rollback() error {
+	err := tx.Tx.Rollback()
+	tx.done()
+	return err
+}
+
+// done releases the connection of the transaction
+func (tx *Tx) done() {
+	if tx.release != nil {
+		tx.release()
+	}
+}
+
+// session is a connection pinned for a statement or a transaction, with the
+// id of its session on the server
+type session struct {
+	d    *database
+	conn *sql.Conn
+	id   int64
+	stop func() bool // stops cancelling when the context ends
+
+	mu       sync.Mutex
+	released bool
+}
+
+// cancelsStatements reports whether statements run with ctx are cancelled on
+// the server when it ends. lib/pq sends PostgreSQL a cancel request itself,
+// but go-sql-driver/mysql only drops the connection, which leaves the
+// statement running, so the session is cancelled by its id as
+// security.cancel_statements allows.
+func (d *database) cancelsStatements(ctx context.Context) bool {
+	_, ok := sessionIDQueries[d.driverName]
+	return ok && d.config.CancelStatements && ctx.Done() != nil
+}
+
+// pin takes a connection from the pool and reads its session id. The
+// statement it runs is cancelled when ctx ends until the session is released.
+func (d *database) pin(ctx context.Context) (*session, error) {
+	conn, err := d.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := &session{d: d, conn: conn}
+	if err := conn.QueryRowContext(ctx, sessionIDQueries[d.driverName]).Scan(&s.id); err != nil {
+		s.close()
+		return nil, fmt.Errorf("failed to read session id: %w", err)
+	}
+	// The context may end before stop is set
+	s.mu.Lock()
+	s.stop = context.AfterFunc(ctx, func() { s.release(true) })
+	s.mu.Unlock()
+	return s, nil
+}
+
+// release returns the connection of a session to the pool, cancelling the
+// statement it runs first when cancel is set. The connection is only
+// returned once the cancel is done, so the id cannot belong to another
+// statement by then.
+func (s *session) release(cancel bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.released {
+		return
+	}
+	s.released = true
+	s.stop()
+
+	if cancel {
+		ctx, cancel := context.WithTimeout(context.Background(), cancelTimeout)
+		if err := s.d.cancelSession(ctx, s.id); err != nil {
+			logger.Warn("%v", err)
+		} else {
+			logger.Info("Cancelled the statement of session %d on the server", s.id)
+		}
+		cancel()
+	}
+	// Close waits for open rows or a transaction on the connection to end
+	s.close()
+}
+
+// close returns the connection of a session to the pool
+func (s *session) close() {
+	if err := s.conn.Close(); err != nil {
+		logger.Error("Error releasing connection: %v", err)
+	}
+}
+
+// cancelSession cancels the statement a session is running through another
+// connection of the pool, and waits until the session no longer runs it
+func (d *database) cancelSession(ctx context.Context, id int64) error {
+	if d.driverName == "mysql" {
+		// KILL takes no placeholders; the id is an integer
+		if _, err := d.db.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", id)); err != nil {
+			return fmt.Errorf("failed to cancel the statement of session %d: %w", id, err)
+		}
+	} else {
+		var cancelled bool
+		if err := d.db.QueryRowContext(ctx, "SELECT pg_catalog.pg_cancel_backend($1)", id).Scan(&cancelled); err != nil {
+			return fmt.Errorf("failed to cancel the statement of session %d: %w", id, err)
+		}
+		if !cancelled {
+			return fmt.Errorf("failed to cancel the statement of session %d: it is not running", id)
+		}
+	}
+
+	for {
+		var active bool
+		err := d.db.QueryRowContext(ctx, sessionActiveQueries[d.driverName], id).Scan(&active)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && !active) {
+			return nil
+		}
+		if err != nil && ctx.Err() == nil {
+			return fmt.Errorf("failed to check session %d after cancelling its statement: %w", id, err)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("session %d is still running its statement after it was cancelled", id)
+		case <-time.After(sessionPollInterval):
+		}
+	}
+}
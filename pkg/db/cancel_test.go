@@ -0,0 +1,350 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+)
+
+// sessionDriver is a MySQL-like driver whose connections have session ids.
+// SLEEP statements, and the rows of SLEEP queries, run until KILL QUERY
+// cancels them or 300ms pass. A session that ignores kills keeps running.
+type sessionDriver struct {
+	mu      sync.Mutex
+	nextID  int64
+	kills   map[int64]chan struct{}
+	running map[int64]bool
+	ignored map[int64]bool
+	log     []string
+}
+
+func (d *sessionDriver) Connect(context.Context) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.nextID++
+	if d.kills == nil {
+		d.kills = make(map[int64]chan struct{})
+	}
+	d.kills[d.nextID] = make(chan struct{})
+	if d.running == nil {
+		d.running = make(map[int64]bool)
+	}
+	return &sessionConn{d: d, id: d.nextID}, nil
+}
+
+func (d *sessionDriver) Driver() driver.Driver { return nil }
+
+func (d *sessionDriver) record(id int64, query string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log = append(d.log, fmt.Sprintf("%d: %s", id, query))
+}
+
+func (d *sessionDriver) statements() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.log...)
+}
+
+func (d *sessionDriver) kill(id int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.ignored[id] {
+		return
+	}
+	if kill, ok := d.kills[id]; ok {
+		close(kill)
+		delete(d.kills, id)
+	}
+	delete(d.running, id)
+}
+
+func (d *sessionDriver) setRunning(id int64, running bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if running {
+		d.running[id] = true
+	} else {
+		delete(d.running, id)
+	}
+}
+
+func (d *sessionDriver) isRunning(id int64) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.running[id]
+}
+
+func (d *sessionDriver) killed(id int64) <-chan struct{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.kills[id]
+}
+
+type sessionConn struct {
+	d  *sessionDriver
+	id int64
+}
+
+func (c *sessionConn) Prepare(query string) (driver.Stmt, error) { return sessionStmt{c, query}, nil }
+func (c *sessionConn) Close() error                              { return nil }
+func (c *sessionConn) Begin() (driver.Tx, error)                 { return sessionTx{c}, nil }
+
+type sessionTx struct{ c *sessionConn }
+
+func (tx sessionTx) Commit() error   { return nil }
+func (tx sessionTx) Rollback() error { return nil }
+
+type sessionStmt struct {
+	c     *sessionConn
+	query string
+}
+
+func (s sessionStmt) Close() error  { return nil }
+func (s sessionStmt) NumInput() int { return -1 }
+func (s sessionStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.c.d.record(s.c.id, s.query)
+	var id int64
+	if _, err := fmt.Sscanf(s.query, "KILL QUERY %d", &id); err == nil {
+		s.c.d.kill(id)
+	}
+	if strings.Contains(s.query, "SLEEP") {
+		if err := s.c.sleep(); err != nil {
+			return nil, err
+		}
+	}
+	return driver.RowsAffected(0), nil
+}
+func (s sessionStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.c.d.record(s.c.id, s.query)
+	if strings.Contains(s.query, "PROCESSLIST") {
+		return &sessionRows{value: s.c.d.isRunning(args[0].(int64))}, nil
+	}
+	return &sessionRows{c: s.c, value: s.c.id, sleep: strings.Contains(s.query, "SLEEP")}, nil
+}
+
+// sleep waits until the statement of the connection is killed or 300ms pass
+func (c *sessionConn) sleep() error {
+	c.d.setRunning(c.id, true)
+	defer c.d.setRunning(c.id, false)
+	select {
+	case <-c.d.killed(c.id):
+		return fmt.Errorf("query execution was interrupted")
+	case <-time.After(300 * time.Millisecond):
+		return nil
+	}
+}
+
+type sessionRows struct {
+	c     *sessionConn
+	value driver.Value
+	sleep bool
+	done  bool
+}
+
+func (r *sessionRows) Columns() []string { return []string{"id"} }
+func (r *sessionRows) Close() error      { return nil }
+func (r *sessionRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	if r.sleep {
+		if err := r.c.sleep(); err != nil {
+			return err
+		}
+	}
+	dest[0] = r.value
+	r.done = true
+	return nil
+}
+
+func newSessionDatabase(cancelStatements bool) (*database, *sessionDriver) {
+	logger.InitializeWithWriter("error", os.Stderr)
+	d := &sessionDriver{}
+	return &database{
+		config:     Config{CancelStatements: cancelStatements},
+		db:         sql.OpenDB(d),
+		driverName: "mysql",
+	}, d
+}
+
+func TestExecCancelsPinnedSession(t *testing.T) {
+	database, d := newSessionDatabase(true)
+	defer database.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := database.Exec(ctx, "SELECT SLEEP(60)")
+	require.Error(t, err)
+	assert.Less(t, time.Since(start), 300*time.Millisecond)
+
+	// The id is read on the connection that runs the statement and only that
+	// session is cancelled, from another connection
+	assert.Equal(t, []string{
+		"1: SELECT CONNECTION_ID()",
+		"1: SELECT SLEEP(60)",
+		"2: KILL QUERY 1",
+		"2: SELECT COMMAND = 'Query' FROM information_schema.PROCESSLIST WHERE ID = ?",
+	}, d.statements())
+	assert.Equal(t, 0, database.db.Stats().InUse)
+}
+
+func TestExecDoesNotCancelFinishedStatements(t *testing.T) {
+	database, d := newSessionDatabase(true)
+	defer database.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	_, err := database.Exec(ctx, "UPDATE users SET active = 1")
+	require.NoError(t, err)
+	cancel()
+
+	assert.Equal(t, []string{
+		"1: SELECT CONNECTION_ID()",
+		"1: UPDATE users SET active = 1",
+	}, d.statements())
+	assert.Equal(t, 0, database.db.Stats().InUse)
+}
+
+func TestExecWithoutCancelStatements(t *testing.T) {
+	database, d := newSessionDatabase(false)
+	defer database.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := database.Exec(ctx, "SELECT SLEEP(1)")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1: SELECT SLEEP(1)"}, d.statements())
+}
+
+func TestQueryCancelsPinnedSessionWhileRowsAreRead(t *testing.T) {
+	database, d := newSessionDatabase(true)
+	defer database.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	rows, err := database.QueryPinned(ctx, "SELECT SLEEP(60)")
+	require.NoError(t, err)
+	assert.False(t, rows.Next())
+	require.Error(t, rows.Err())
+	require.NoError(t, rows.Close())
+
+	assert.Equal(t, []string{
+		"1: SELECT CONNECTION_ID()",
+		"1: SELECT SLEEP(60)",
+		"2: KILL QUERY 1",
+		"2: SELECT COMMAND = 'Query' FROM information_schema.PROCESSLIST WHERE ID = ?",
+	}, d.statements())
+	assert.Equal(t, 0, database.db.Stats().InUse)
+}
+
+func TestQueryRowCancelsPinnedSession(t *testing.T) {
+	database, d := newSessionDatabase(true)
+	defer database.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	row, err := database.QueryRowPinned(ctx, "SELECT SLEEP(60)")
+	require.NoError(t, err)
+	var id int64
+	require.Error(t, row.Scan(&id))
+
+	assert.Equal(t, []string{
+		"1: SELECT CONNECTION_ID()",
+		"1: SELECT SLEEP(60)",
+		"2: KILL QUERY 1",
+		"2: SELECT COMMAND = 'Query' FROM information_schema.PROCESSLIST WHERE ID = ?",
+	}, d.statements())
+	assert.Equal(t, 0, database.db.Stats().InUse)
+}
+
+func TestQueryReleasesConnectionOnClose(t *testing.T) {
+	database, d := newSessionDatabase(true)
+	defer database.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rows, err := database.QueryPinned(ctx, "SELECT id FROM users")
+	require.NoError(t, err)
+	assert.Equal(t, 1, database.db.Stats().InUse)
+	require.NoError(t, rows.Close())
+	cancel()
+
+	assert.Equal(t, []string{
+		"1: SELECT CONNECTION_ID()",
+		"1: SELECT id FROM users",
+	}, d.statements())
+	assert.Equal(t, 0, database.db.Stats().InUse)
+}
+
+func TestTxCancelsPinnedSession(t *testing.T) {
+	database, d := newSessionDatabase(true)
+	defer database.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	tx, err := database.BeginPinnedTx(ctx, nil)
+	require.NoError(t, err)
+	_, err = tx.ExecContext(ctx, "SELECT SLEEP(60)")
+	require.Error(t, err)
+	_ = tx.Rollback()
+
+	assert.Equal(t, []string{
+		"1: SELECT CONNECTION_ID()",
+		"1: SELECT SLEEP(60)",
+		"2: KILL QUERY 1",
+		"2: SELECT COMMAND = 'Query' FROM information_schema.PROCESSLIST WHERE ID = ?",
+	}, d.statements())
+	assert.Equal(t, 0, database.db.Stats().InUse)
+}
+
+func TestCancelSessionStillRunning(t *testing.T) {
+	database, d := newSessionDatabase(true)
+	defer database.Close()
+
+	d.ignored = map[int64]bool{1: true}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		_, _ = database.db.ExecContext(ctx, "SELECT SLEEP(60)")
+	}()
+	require.Eventually(t, func() bool { return d.isRunning(1) }, time.Second, 10*time.Millisecond)
+
+	cancelCtx, cancelCancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancelCancel()
+	err := database.cancelSession(cancelCtx, 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "session 1 is still running its statement")
+}
+
+func TestTxReleasesConnection(t *testing.T) {
+	database, _ := newSessionDatabase(true)
+	defer database.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tx, err := database.BeginPinnedTx(ctx, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 1, database.db.Stats().InUse)
+	require.NoError(t, tx.Commit())
+	assert.Equal(t, 0, database.db.Stats().InUse)
+}
@@ -0,0 +1,337 @@
+package db
+
+import (
+	"bytes"
+	_ "embed" // for the embedded config schema
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/BurntSushi/toml"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFormat identifies the encoding of a configuration file
+type ConfigFormat string
+
+// Supported configuration file formats
+const (
+	ConfigFormatJSON ConfigFormat = "json"
+	ConfigFormatYAML ConfigFormat = "yaml"
+	ConfigFormatTOML ConfigFormat = "toml"
+)
+
+//go:embed config.schema.json
+var configSchemaJSON string
+
+var (
+	configSchemaOnce sync.Once
+	configSchema     *jsonschema.Schema
+	configSchemaErr  error
+)
+
+// ConfigProblem describes a single configuration error at a given path
+type ConfigProblem struct {
+	Path    string
+	Message string
+}
+
+// String formats the problem as "path: message"
+func (p ConfigProblem) String() string {
+	if p.Path == "" {
+		return p.Message
+	}
+	return fmt.Sprintf("%s: %s", p.Path, p.Message)
+}
+
+// ConfigValidationError is returned when a configuration fails validation
+type ConfigValidationError struct {
+	Problems []ConfigProblem
+}
+
+// Error lists every problem found in the configuration
+func (e *ConfigValidationError) Error() string {
+	lines := make([]string, 0, len(e.Problems))
+	for _, p := range e.Problems {
+		lines = append(lines, p.String())
+	}
+	return fmt.Sprintf("invalid database configuration (%d problems): %s", len(e.Problems), strings.Join(lines, "; "))
+}
+
+// ConfigFormatFromPath picks the configuration format from the file extension
+func ConfigFormatFromPath(path string) (ConfigFormat, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json", "":
+		return ConfigFormatJSON, nil
+	case ".yaml", ".yml":
+		return ConfigFormatYAML, nil
+	case ".toml":
+		return ConfigFormatTOML, nil
+	default:
+		return "", fmt.Errorf("unsupported config file extension %q (use .json, .yaml, .yml or .toml)", filepath.Ext(path))
+	}
+}
+
+// LoadMultiDBConfigFile reads, validates and parses a configuration file
+func LoadMultiDBConfigFile(path string) (*MultiDBConfig, error) {
+	format, err := ConfigFormatFromPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	return ParseMultiDBConfig(data, format)
+}
+
+// ParseMultiDBConfig decodes a configuration in the given format, validates it
+// against the embedded JSON Schema and returns the parsed configuration.
+// Validation failures are reported as a *ConfigValidationError.
+func ParseMultiDBConfig(data []byte, format ConfigFormat) (*MultiDBConfig, error) {
+	normalized, err := NormalizeConfig(data, format)
+	if err != nil {
+		return nil, err
+	}
+
+	problems, err := validateNormalizedConfig(normalized)
+	if err != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		return nil, &ConfigValidationError{Problems: problems}
+	}
+
+	var config MultiDBConfig
+	if err := json.Unmarshal(normalized, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+
+	return &config, nil
+}
+
+// ValidateMultiDBConfig returns every problem found in the configuration.
+// The error is only set when the document cannot be decoded at all.
+func ValidateMultiDBConfig(data []byte, format ConfigFormat) ([]ConfigProblem, error) {
+	normalized, err := NormalizeConfig(data, format)
+	if err != nil {
+		return nil, err
+	}
+	return validateNormalizedConfig(normalized)
+}
+
+// normalizeConfig converts a YAML, TOML or JSON document into canonical JSON
+func NormalizeConfig(data []byte, format ConfigFormat) ([]byte, error) {
+	var doc interface{}
+
+	switch format {
+	case ConfigFormatJSON:
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON config: %w", err)
+		}
+	case ConfigFormatYAML:
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("failed to parse YAML config: %w", err)
+		}
+	case ConfigFormatTOML:
+		var m map[string]interface{}
+		if _, err := toml.Decode(string(data), &m); err != nil {
+			return nil, fmt.Errorf("failed to parse TOML config: %w", err)
+		}
+		doc = m
+	default:
+		return nil, fmt.Errorf("unsupported config format: %s", format)
+	}
+
+	normalized, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to normalize config: %w", err)
+	}
+	return normalized, nil
+}
+
+// validateNormalizedConfig runs schema and semantic checks on canonical JSON
+func validateNormalizedConfig(normalized []byte) ([]ConfigProblem, error) {
+	schema, err := loadConfigSchema()
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(normalized))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to decode config: %w", err)
+	}
+
+	var problems []ConfigProblem
+	if err := schema.Validate(doc); err != nil {
+		var verr *jsonschema.ValidationError
+		if !errors.As(err, &verr) {
+			return nil, fmt.Errorf("failed to validate config: %w", err)
+		}
+		problems = append(problems, schemaProblems(verr)...)
+	}
+
+	problems = append(problems, duplicateIDProblems(doc)...)
+	problems = append(problems, defaultSchemaProblems(doc)...)
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Path < problems[j].Path
+	})
+
+	return problems, nil
+}
+
+// loadConfigSchema compiles the embedded schema once
+func loadConfigSchema() (*jsonschema.Schema, error) {
+	configSchemaOnce.Do(func() {
+		configSchema, configSchemaErr = jsonschema.CompileString("config.schema.json", configSchemaJSON)
+	})
+	if configSchemaErr != nil {
+		return nil, fmt.Errorf("failed to compile config schema: %w", configSchemaErr)
+	}
+	return configSchema, nil
+}
+
+// schemaProblems flattens a validation error tree into its leaf problems
+func schemaProblems(verr *jsonschema.ValidationError) []ConfigProblem {
+	if len(verr.Causes) == 0 {
+		path := jsonPointerToPath(verr.InstanceLocation)
+
+		// Report each missing required property at its own path
+		if names, ok := strings.CutPrefix(verr.Message, "missing properties: "); ok {
+			var problems []ConfigProblem
+			for _, name := range strings.Split(names, ", ") {
+				problems = append(problems, ConfigProblem{
+					Path:    joinConfigPath(path, strings.Trim(name, "'")),
+					Message: "required property is missing",
+				})
+			}
+			return problems
+		}
+
+		return []ConfigProblem{{Path: path, Message: verr.Message}}
+	}
+
+	var problems []ConfigProblem
+	for _, cause := range verr.Causes {
+		problems = append(problems, schemaProblems(cause)...)
+	}
+	return problems
+}
+
+// duplicateIDProblems reports connection IDs that appear more than once
+func duplicateIDProblems(doc interface{}) []ConfigProblem {
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	conns, ok := root["connections"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var problems []ConfigProblem
+	seen := make(map[string]int)
+	for i, raw := range conns {
+		conn, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id, ok := conn["id"].(string)
+		if !ok || id == "" {
+			continue
+		}
+		if first, exists := seen[id]; exists {
+			problems = append(problems, ConfigProblem{
+				Path:    fmt.Sprintf("connections[%d].id", i),
+				Message: fmt.Sprintf("duplicate connection id %q (first defined at connections[%d])", id, first),
+			})
+			continue
+		}
+		seen[id] = i
+	}
+	return problems
+}
+
+// joinConfigPath appends a property name to a display path
+func joinConfigPath(path, name string) string {
+	if path == "(root)" {
+		return name
+	}
+	return path + "." + name
+}
+
+// jsonPointerToPath converts "/connections/0/host" to "connections[0].host"
+func jsonPointerToPath(ptr string) string {
+	if ptr == "" || ptr == "/" {
+		return "(root)"
+	}
+
+	var b strings.Builder
+	for _, token := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		if _, err := strconv.Atoi(token); err == nil {
+			b.WriteString("[" + token + "]")
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(token)
+	}
+	return b.String()
+}
+
+// defaultSchemaProblems reports default schemas that their own allowlist rejects
+func defaultSchemaProblems(doc interface{}) []ConfigProblem {
+	root, ok := doc.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	conns, ok := root["connections"].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var problems []ConfigProblem
+	for i, raw := range conns {
+		conn, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		schema, ok := conn["default_schema"].(string)
+		if !ok || schema == "" {
+			continue
+		}
+		allowlist, ok := conn["schema_allowlist"].([]interface{})
+		if !ok || len(allowlist) == 0 {
+			continue
+		}
+
+		allowed := false
+		for _, entry := range allowlist {
+			if entry == schema {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			problems = append(problems, ConfigProblem{
+				Path:    fmt.Sprintf("connections[%d].default_schema", i),
+				Message: fmt.Sprintf("default schema %q is not in schema_allowlist", schema),
+			})
+		}
+	}
+	return problems
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMultiDBConfigFormats(t *testing.T) {
+	tests := []struct {
+		name   string
+		format ConfigFormat
+		data   string
+	}{
+		{
+			name:   "json",
+			format: ConfigFormatJSON,
+			data: `{"connections": [
+				{"id": "pg", "type": "postgres", "host": "localhost", "port": 5432, "ssl_mode": "require",
+				 "options": {"timezone": "UTC"}}
+			]}`,
+		},
+		{
+			name:   "yaml",
+			format: ConfigFormatYAML,
+			data: `
+# primary database
+connections:
+  - id: pg
+    type: postgres
+    host: localhost
+    port: 5432
+    ssl_mode: require
+    options:
+      timezone: UTC
+`,
+		},
+		{
+			name:   "toml",
+			format: ConfigFormatTOML,
+			data: `
+# primary database
+[[connections]]
+id = "pg"
+type = "postgres"
+host = "localhost"
+port = 5432
+ssl_mode = "require"
+
+[connections.options]
+timezone = "UTC"
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseMultiDBConfig([]byte(tt.data), tt.format)
+			require.NoError(t, err)
+			require.Len(t, cfg.Connections, 1)
+
+			conn := cfg.Connections[0]
+			assert.Equal(t, "pg", conn.ID)
+			assert.Equal(t, "postgres", conn.Type)
+			assert.Equal(t, 5432, conn.Port)
+			assert.Equal(t, "require", conn.SSLMode)
+			assert.Equal(t, "UTC", conn.Options["timezone"])
+		})
+	}
+}
+
+func TestValidateMultiDBConfigReportsAllProblems(t *testing.T) {
+	data := `
+connections:
+  - id: main
+    type: mysql
+    host: localhost
+  - id: main
+    type: oracle
+    host: db
+  - id: other
+    type: postgres
+    port: 70000
+  - id: scoped
+    type: postgres
+    host: db
+    default_schema: hr
+    schema_allowlist: [public, sales]
+`
+	problems, err := ValidateMultiDBConfig([]byte(data), ConfigFormatYAML)
+	require.NoError(t, err)
+
+	paths := make(map[string]string)
+	for _, p := range problems {
+		paths[p.Path] = p.Message
+	}
+
+	assert.Contains(t, paths, "connections[1].id", "duplicate id")
+	assert.Contains(t, paths["connections[1].id"], "duplicate connection id")
+	assert.Contains(t, paths, "connections[1].type", "unknown type")
+	assert.Contains(t, paths, "connections[2].host", "missing host")
+	assert.Contains(t, paths, "connections[2].port", "port out of range")
+	assert.Contains(t, paths, "connections[3].default_schema", "default schema outside allowlist")
+}
+
+func TestParseMultiDBConfigValidationError(t *testing.T) {
+	_, err := ParseMultiDBConfig([]byte(`{"connections": [{"id": "x", "type": "mysql"}]}`), ConfigFormatJSON)
+	require.Error(t, err)
+
+	var verr *ConfigValidationError
+	require.True(t, errors.As(err, &verr))
+	assert.Equal(t, []ConfigProblem{{Path: "connections[0].host", Message: "required property is missing"}}, verr.Problems)
+}
+
+func TestLoadMultiDBConfigFile(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "config.yml")
+	require.NoError(t, os.WriteFile(path, []byte("connections:\n  - {id: a, type: mysql, host: h}\n"), 0o600))
+
+	cfg, err := LoadMultiDBConfigFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "a", cfg.Connections[0].ID)
+
+	_, err = LoadMultiDBConfigFile(filepath.Join(dir, "config.ini"))
+	assert.Error(t, err)
+}
+
+func TestJSONPointerToPath(t *testing.T) {
+	assert.Equal(t, "(root)", jsonPointerToPath(""))
+	assert.Equal(t, "connections", jsonPointerToPath("/connections"))
+	assert.Equal(t, "connections[0].options.a/b", jsonPointerToPath("/connections/0/options/a~1b"))
+}
@@ -45,7 +45,7 @@ type Config struct {
 	Password string
 	Name     string
 
-	// Additional PostgreSQL specific options
+	// TLS and connection options (PostgreSQL and MySQL)
 	SSLMode            PostgresSSLMode
 	SSLCert            string
 	SSLKey             string
@@ -56,6 +56,14 @@ type Config struct {
 	TargetSessionAttrs string            // for PostgreSQL 10+
 	Options            map[string]string // Extra connection options
 
+	// Session defaults enforced by the server, in milliseconds
+	StatementTimeout int
+	LockTimeout      int
+
+	// CancelStatements cancels statements whose context ends on the server,
+	// by the id of their session
+	CancelStatements bool
+
 	// Connection pool settings
 	MaxOpenConns    int
 	MaxIdleConns    int
@@ -167,6 +175,14 @@ func buildPostgresConnStr(config Config) string {
 		params = append(params, fmt.Sprintf("target_session_attrs=%s", config.TargetSessionAttrs))
 	}
 
+	// Server-side limits, sent as run-time parameters of every session
+	if config.StatementTimeout > 0 {
+		params = append(params, fmt.Sprintf("statement_timeout=%d", config.StatementTimeout))
+	}
+	if config.LockTimeout > 0 {
+		params = append(params, fmt.Sprintf("lock_timeout=%d", config.LockTimeout))
+	}
+
 	// Add any additional options from the map
 	if config.Options != nil {
 		for key, value := range config.Options {
@@ -189,8 +205,11 @@ func NewDatabase(config Config) (Database, error) {
 	switch config.Type {
 	case "mysql":
 		driverName = "mysql"
-		dsn = fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?parseTime=true",
-			config.User, config.Password, config.Host, config.Port, config.Name)
+		mysqlDSN, err := buildMySQLConnStr(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build mysql connection string: %w", err)
+		}
+		dsn = mysqlDSN
 	case "postgres":
 		driverName = "postgres"
 		dsn = buildPostgresConnStr(config)
@@ -264,6 +283,33 @@ func (d *database) Query(ctx context.Context, query string, args ...interface{})
 	return d.db.QueryContext(ctx, query, args...)
 }
 
+// QueryPinned executes a query that returns rows. When the database cancels
+// statements on the server, the query is cancelled there if its context ends
+// before the rows are closed.
+func (d *database) QueryPinned(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	if d.db == nil {
+		return nil, ErrNoDatabase
+	}
+	if !d.cancelsStatements(ctx) {
+		rows, err := d.db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return nil, err
+		}
+		return &Rows{Rows: rows}, nil
+	}
+
+	s, err := d.pin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.conn.QueryContext(ctx, query, args...)
+	if err != nil {
+		s.release(ctx.Err() != nil)
+		return nil, err
+	}
+	return &Rows{Rows: rows, release: func() { s.release(false) }}, nil
+}
+
 // QueryRow executes a query that is expected to return at most one row
 func (d *database) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
 	if d.db == nil {
@@ -272,12 +318,42 @@ func (d *database) QueryRow(ctx context.Context, query string, args ...interface
 	return d.db.QueryRowContext(ctx, query, args...)
 }
 
-// Exec executes a query without returning any rows
+// QueryRowPinned executes a query that is expected to return at most one row.
+// When the database cancels statements on the server, the query is cancelled
+// there if its context ends before the row is scanned.
+func (d *database) QueryRowPinned(ctx context.Context, query string, args ...interface{}) (*Row, error) {
+	if d.db == nil {
+		return nil, ErrNoDatabase
+	}
+	if !d.cancelsStatements(ctx) {
+		return &Row{Row: d.db.QueryRowContext(ctx, query, args...)}, nil
+	}
+
+	s, err := d.pin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	row := s.conn.QueryRowContext(ctx, query, args...)
+	return &Row{Row: row, release: func() { s.release(false) }}, nil
+}
+
+// Exec executes a query without returning any rows. When the database cancels
+// statements on the server, a statement whose context ends is cancelled there.
 func (d *database) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
 	if d.db == nil {
 		return nil, ErrNoDatabase
 	}
-	return d.db.ExecContext(ctx, query, args...)
+	if !d.cancelsStatements(ctx) {
+		return d.db.ExecContext(ctx, query, args...)
+	}
+
+	s, err := d.pin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.conn.ExecContext(ctx, query, args...)
+	s.release(err != nil && ctx.Err() != nil)
+	return result, err
 }
 
 // BeginTx starts a transaction
@@ -288,6 +364,34 @@ func (d *database) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, e
 	return d.db.BeginTx(ctx, opts)
 }
 
+// BeginPinnedTx starts a transaction whose running statement is cancelled on
+// the server when ctx ends, when the database cancels statements there. The
+// transaction must be finished with its own Commit or Rollback, which return
+// its connection to the pool.
+func (d *database) BeginPinnedTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	if d.db == nil {
+		return nil, ErrNoDatabase
+	}
+	if !d.cancelsStatements(ctx) {
+		tx, err := d.db.BeginTx(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &Tx{Tx: tx}, nil
+	}
+
+	s, err := d.pin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := s.conn.BeginTx(ctx, opts)
+	if err != nil {
+		s.release(false)
+		return nil, err
+	}
+	return &Tx{Tx: tx, release: func() { s.release(false) }}, nil
+}
+
 // DB returns the underlying database connection
 func (d *database) DB() *sql.DB {
 	return d.db
@@ -303,8 +407,7 @@ func (d *database) ConnectionString() string {
 	// Return masked DSN (hide password)
 	switch d.config.Type {
 	case "mysql":
-		return fmt.Sprintf("%s:***@tcp(%s:%d)/%s",
-			d.config.User, d.config.Host, d.config.Port, d.config.Name)
+		return maskedMySQLConnStr(d.dsn)
 	case "postgres":
 		// Create a sanitized version of the connection string
 		params := make([]string, 0)
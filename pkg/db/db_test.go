@@ -155,3 +155,14 @@ func TestUsingMockDatabase(t *testing.T) {
 	assert.Equal(t, "mock", mockDB.DriverName())
 	assert.Equal(t, "mock://localhost/testdb", mockDB.ConnectionString())
 }
+
+func TestBuildPostgresConnStrTimeouts(t *testing.T) {
+	config := Config{Type: "postgres", Host: "localhost", Port: 5432, User: "user", Name: "testdb"}
+	assert.NotContains(t, buildPostgresConnStr(config), "statement_timeout")
+
+	config.StatementTimeout = 5000
+	config.LockTimeout = 1000
+	connStr := buildPostgresConnStr(config)
+	assert.Contains(t, connStr, " statement_timeout=5000")
+	assert.Contains(t, connStr, " lock_timeout=1000")
+}
@@ -0,0 +1,87 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+)
+
+// QuoteIdentifier quotes an identifier for the given database type. Names are
+// always quoted, so keywords of any server version are safe to use.
+func QuoteIdentifier(dbType, name string) string {
+	if dbType == "mysql" {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// QuoteQualifiedName quotes an object name and, when given, its schema
+func QuoteQualifiedName(dbType, schema, name string) string {
+	if schema == "" {
+		return QuoteIdentifier(dbType, name)
+	}
+	return QuoteIdentifier(dbType, schema) + "." + QuoteIdentifier(dbType, name)
+}
+
+// QuoteLiteral quotes a value as a SQL string literal
+func QuoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// ParseQualifiedName splits a name of the form "schema.object" into its
+// parts. Either part may be wrapped in double quotes or backticks, which is
+// only needed when the name itself contains a dot; unquoted parts are taken
+// literally.
+func ParseQualifiedName(name string) (schema, object string, err error) {
+	var parts []string
+	var current strings.Builder
+	var quote rune
+	quoted := false
+
+	runes := []rune(strings.TrimSpace(name))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0 && r == quote:
+			// A doubled quote character is an escaped quote
+			if i+1 < len(runes) && runes[i+1] == quote {
+				current.WriteRune(r)
+				i++
+				continue
+			}
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case (r == '"' || r == '`') && current.Len() == 0 && !quoted:
+			quote = r
+			quoted = true
+		case r == '.':
+			parts = append(parts, current.String())
+			current.Reset()
+			quoted = false
+		default:
+			if quoted {
+				return "", "", fmt.Errorf("invalid name %q: unexpected %q after quoted identifier", name, r)
+			}
+			current.WriteRune(r)
+		}
+	}
+	if quote != 0 {
+		return "", "", fmt.Errorf("invalid name %q: unterminated quoted identifier", name)
+	}
+	parts = append(parts, current.String())
+
+	for _, part := range parts {
+		if part == "" {
+			return "", "", fmt.Errorf("invalid name %q: empty identifier", name)
+		}
+	}
+
+	switch len(parts) {
+	case 1:
+		return "", parts[0], nil
+	case 2:
+		return parts[0], parts[1], nil
+	default:
+		return "", "", fmt.Errorf("invalid name %q: expected [schema.]name", name)
+	}
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuoteIdentifier(t *testing.T) {
+	assert.Equal(t, `"users"`, QuoteIdentifier("postgres", "users"))
+	assert.Equal(t, `"Users"`, QuoteIdentifier("postgres", "Users"))
+	assert.Equal(t, `"order"`, QuoteIdentifier("postgres", "order"))
+	assert.Equal(t, `"a""b"`, QuoteIdentifier("postgres", `a"b`))
+	assert.Equal(t, "`my-table`", QuoteIdentifier("mysql", "my-table"))
+	assert.Equal(t, "`a``b`", QuoteIdentifier("mysql", "a`b"))
+
+	// Keywords missing from any hand-kept list are quoted too
+	for _, word := range []string{"rank", "groups", "range", "read", "rows", "system", "window", "current_role", "localtime", "variadic"} {
+		assert.Equal(t, `"`+word+`"`, QuoteIdentifier("postgres", word))
+		assert.Equal(t, "`"+word+"`", QuoteIdentifier("mysql", word))
+	}
+}
+
+func TestQuoteQualifiedName(t *testing.T) {
+	assert.Equal(t, `"users"`, QuoteQualifiedName("postgres", "", "users"))
+	assert.Equal(t, `"sales"."Orders"`, QuoteQualifiedName("postgres", "sales", "Orders"))
+	assert.Equal(t, "`sales-eu`.`orders`", QuoteQualifiedName("mysql", "sales-eu", "orders"))
+}
+
+func TestQuoteLiteral(t *testing.T) {
+	assert.Equal(t, "'it''s'", QuoteLiteral("it's"))
+}
+
+func TestParseQualifiedName(t *testing.T) {
+	tests := []struct {
+		in     string
+		schema string
+		object string
+	}{
+		{"users", "", "users"},
+		{"sales.orders", "sales", "orders"},
+		{`"my.schema"."My Table"`, "my.schema", "My Table"},
+		{"`sales`.`a``b`", "sales", "a`b"},
+		{`"a""b"`, "", `a"b`},
+	}
+	for _, tt := range tests {
+		schema, object, err := ParseQualifiedName(tt.in)
+		require.NoError(t, err, tt.in)
+		assert.Equal(t, tt.schema, schema, tt.in)
+		assert.Equal(t, tt.object, object, tt.in)
+	}
+
+	for _, bad := range []string{"", "a.", ".b", "a.b.c", `"open`, `"a"b`} {
+		_, _, err := ParseQualifiedName(bad)
+		assert.Error(t, err, bad)
+	}
+}
+
+func TestDatabaseConnectionConfigResolveSchema(t *testing.T) {
+	open := DatabaseConnectionConfig{ID: "pg", Type: "postgres"}
+	schema, err := open.ResolveSchema("")
+	require.NoError(t, err)
+	assert.Equal(t, "", schema)
+
+	withDefault := DatabaseConnectionConfig{ID: "pg", Type: "postgres", DefaultSchema: "sales"}
+	schema, err = withDefault.ResolveSchema("")
+	require.NoError(t, err)
+	assert.Equal(t, "sales", schema)
+	schema, err = withDefault.ResolveSchema("hr")
+	require.NoError(t, err)
+	assert.Equal(t, "hr", schema)
+
+	restricted := DatabaseConnectionConfig{ID: "pg", Type: "postgres", SchemaAllowlist: []string{"public"}}
+	schema, err = restricted.ResolveSchema("")
+	require.NoError(t, err)
+	assert.Equal(t, "public", schema)
+	_, err = restricted.ResolveSchema("hr")
+	assert.EqualError(t, err, `schema "hr" is not allowed for connection pg`)
+
+	mysql := DatabaseConnectionConfig{ID: "my", Type: "mysql", Name: "shop", SchemaAllowlist: []string{"shop"}}
+	schema, err = mysql.ResolveSchema("")
+	require.NoError(t, err)
+	assert.Equal(t, "shop", schema)
+}
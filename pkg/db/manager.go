@@ -19,7 +19,7 @@ type DatabaseConnectionConfig struct {
 	Password string `json:"password"`
 	Name     string `json:"name"`
 
-	// PostgreSQL specific options
+	// TLS and connection options (ssl_* and options apply to MySQL too)
 	SSLMode            string            `json:"ssl_mode,omitempty"`
 	SSLCert            string            `json:"ssl_cert,omitempty"`
 	SSLKey             string            `json:"ssl_key,omitempty"`
@@ -30,6 +30,30 @@ type DatabaseConnectionConfig struct {
 	TargetSessionAttrs string            `json:"target_session_attrs,omitempty"`
 	Options            map[string]string `json:"options,omitempty"`
 
+	// Schema scoping: default_schema is used when a tool call names no schema,
+	// schema_allowlist (when set) limits the schemas tools may touch
+	DefaultSchema   string   `json:"default_schema,omitempty"`
+	SchemaAllowlist []string `json:"schema_allowlist,omitempty"`
+
+	// DataDictionary is a YAML file with table and column descriptions that
+	// add to or override the comments stored in the database
+	DataDictionary string `json:"data_dictionary,omitempty"`
+
+	// MigrationsDir holds the numbered up/down SQL migrations of the connection
+	MigrationsDir string `json:"migrations_dir,omitempty"`
+
+	// PlanStore is a JSON file keeping the plans of frequent queries, checked
+	// for regressions; plans are not tracked without it
+	PlanStore string `json:"plan_store,omitempty"`
+
+	// Server-side limits in milliseconds: statement_timeout and lock_timeout
+	// on PostgreSQL, max_execution_time and innodb_lock_wait_timeout on MySQL.
+	// Calls may override the statement timeout up to max_statement_timeout_ms,
+	// which defaults to query_timeout.
+	StatementTimeout    int `json:"statement_timeout_ms,omitempty"`
+	LockTimeout         int `json:"lock_timeout_ms,omitempty"`
+	MaxStatementTimeout int `json:"max_statement_timeout_ms,omitempty"`
+
 	// Connection pool settings
 	MaxOpenConns    int `json:"max_open_conns,omitempty"`
 	MaxIdleConns    int `json:"max_idle_conns,omitempty"`
@@ -37,6 +61,49 @@ type DatabaseConnectionConfig struct {
 	ConnMaxIdleTime int `json:"conn_max_idle_time_seconds,omitempty"` // in seconds
 }
 
+// ResolveSchema returns the schema an operation should use. An empty request
+// falls back to default_schema. When a schema_allowlist is configured, an
+// empty result is replaced by the driver default (public for PostgreSQL, the
+// connected database for MySQL) and the schema must be in the allowlist.
+func (c DatabaseConnectionConfig) ResolveSchema(requested string) (string, error) {
+	schema := requested
+	if schema == "" {
+		schema = c.DefaultSchema
+	}
+	if len(c.SchemaAllowlist) == 0 {
+		return schema, nil
+	}
+
+	if schema == "" {
+		schema = c.driverDefaultSchema()
+	}
+	if !c.SchemaAllowed(schema) {
+		return "", fmt.Errorf("schema %q is not allowed for connection %s", schema, c.ID)
+	}
+	return schema, nil
+}
+
+// SchemaAllowed reports whether a schema passes the connection's allowlist
+func (c DatabaseConnectionConfig) SchemaAllowed(schema string) bool {
+	if len(c.SchemaAllowlist) == 0 {
+		return true
+	}
+	for _, allowed := range c.SchemaAllowlist {
+		if allowed == schema {
+			return true
+		}
+	}
+	return false
+}
+
+// driverDefaultSchema returns the schema unqualified names resolve to
+func (c DatabaseConnectionConfig) driverDefaultSchema() string {
+	if c.Type == "postgres" {
+		return "public"
+	}
+	return c.Name
+}
+
 // MultiDBConfig represents the configuration for multiple database connections
 type MultiDBConfig struct {
 	Connections []DatabaseConnectionConfig `json:"connections"`
@@ -44,9 +111,10 @@ type MultiDBConfig struct {
 
 // Manager manages multiple database connections
 type Manager struct {
-	mu          sync.RWMutex
-	connections map[string]Database
-	configs     map[string]DatabaseConnectionConfig
+	mu               sync.RWMutex
+	connections      map[string]Database
+	configs          map[string]DatabaseConnectionConfig
+	cancelStatements bool
 }
 
 // NewDBManager creates a new database manager
@@ -57,6 +125,14 @@ func NewDBManager() *Manager {
 	}
 }
 
+// SetCancelStatements sets whether the databases connected from now on cancel
+// statements whose context ends on the server
+func (m *Manager) SetCancelStatements(enabled bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.cancelStatements = enabled
+}
+
 // LoadConfig loads database configurations from JSON
 func (m *Manager) LoadConfig(configJSON []byte) error {
 	var config MultiDBConfig
@@ -100,21 +176,22 @@ func (m *Manager) Connect() error {
 			Name:     cfg.Name,
 		}
 
+		// TLS, timeouts and extra options apply to both drivers
+		dbConfig.SSLMode = PostgresSSLMode(cfg.SSLMode)
+		dbConfig.SSLCert = cfg.SSLCert
+		dbConfig.SSLKey = cfg.SSLKey
+		dbConfig.SSLRootCert = cfg.SSLRootCert
+		dbConfig.ConnectTimeout = cfg.ConnectTimeout
+		dbConfig.QueryTimeout = cfg.QueryTimeout
+		dbConfig.StatementTimeout = cfg.StatementTimeout
+		dbConfig.LockTimeout = cfg.LockTimeout
+		dbConfig.CancelStatements = m.cancelStatements
+		dbConfig.Options = cfg.Options
+
 		// Set PostgreSQL-specific options if this is a PostgreSQL database
 		if cfg.Type == "postgres" {
-			dbConfig.SSLMode = PostgresSSLMode(cfg.SSLMode)
-			dbConfig.SSLCert = cfg.SSLCert
-			dbConfig.SSLKey = cfg.SSLKey
-			dbConfig.SSLRootCert = cfg.SSLRootCert
 			dbConfig.ApplicationName = cfg.ApplicationName
-			dbConfig.ConnectTimeout = cfg.ConnectTimeout
-			dbConfig.QueryTimeout = cfg.QueryTimeout
 			dbConfig.TargetSessionAttrs = cfg.TargetSessionAttrs
-			dbConfig.Options = cfg.Options
-		} else if cfg.Type == "mysql" {
-			// Set MySQL-specific options
-			dbConfig.ConnectTimeout = cfg.ConnectTimeout
-			dbConfig.QueryTimeout = cfg.QueryTimeout
 		}
 
 		// Connection pool settings
@@ -163,6 +240,18 @@ func (m *Manager) GetDatabase(id string) (Database, error) {
 	return db, nil
 }
 
+// GetConnectionConfig returns the configuration of a database by its ID
+func (m *Manager) GetConnectionConfig(id string) (DatabaseConnectionConfig, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	cfg, exists := m.configs[id]
+	if !exists {
+		return DatabaseConnectionConfig{}, fmt.Errorf("database configuration %s not found", id)
+	}
+	return cfg, nil
+}
+
 // GetDatabaseType returns the type of a database by its ID
 func (m *Manager) GetDatabaseType(id string) (string, error) {
 	m.mu.RLock()
@@ -0,0 +1,298 @@
+package db
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+// MySQL option keys that map onto typed fields of mysql.Config instead of
+// being passed through as connection parameters
+const (
+	mysqlOptCharset      = "charset"
+	mysqlOptCollation    = "collation"
+	mysqlOptLoc          = "loc"
+	mysqlOptReadTimeout  = "read_timeout"
+	mysqlOptWriteTimeout = "write_timeout"
+)
+
+// buildMySQLConnStr builds a MySQL DSN with TLS, timeouts and extra options.
+//
+// SSLMode follows the PostgreSQL naming so both drivers share one config surface:
+//   - disable: plain TCP
+//   - prefer: TLS when the server supports it, plaintext otherwise
+//   - require: TLS without certificate verification (unless SSLRootCert is set,
+//     in which case it behaves like verify-ca, matching libpq)
+//   - verify-ca: TLS, server certificate must chain to SSLRootCert
+//   - verify-full: verify-ca plus hostname verification
+//
+// Options with the keys charset, collation, loc, read_timeout and write_timeout
+// are applied to the driver config; "time_zone" and any other key are passed
+// through as DSN parameters, which the driver either understands itself or
+// sends to the server as session variables.
+func buildMySQLConnStr(config Config) (string, error) {
+	cfg := mysql.NewConfig()
+	cfg.User = config.User
+	cfg.Passwd = config.Password
+	cfg.Net = "tcp"
+	cfg.Addr = net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	cfg.DBName = config.Name
+	cfg.ParseTime = true
+
+	if config.ConnectTimeout > 0 {
+		cfg.Timeout = time.Duration(config.ConnectTimeout) * time.Second
+	}
+
+	if err := configureMySQLTLS(cfg, config); err != nil {
+		return "", err
+	}
+
+	// Server-side limits, set as session variables on connect. MySQL counts
+	// lock waits in whole seconds.
+	extra := url.Values{}
+	if config.StatementTimeout > 0 {
+		extra.Set("max_execution_time", strconv.Itoa(config.StatementTimeout))
+	}
+	if config.LockTimeout > 0 {
+		extra.Set("innodb_lock_wait_timeout", strconv.Itoa((config.LockTimeout+999)/1000))
+	}
+	options := normalizeMySQLOptions(config.Options)
+	for key, value := range options {
+		switch key {
+		case mysqlOptCharset:
+			cfg.Apply(mysql.Charset(value, options[mysqlOptCollation]))
+		case mysqlOptCollation:
+			if _, ok := options[mysqlOptCharset]; !ok {
+				cfg.Collation = value
+			}
+		case mysqlOptLoc:
+			loc, err := time.LoadLocation(value)
+			if err != nil {
+				return "", fmt.Errorf("invalid mysql option %s=%q: %w", key, value, err)
+			}
+			cfg.Loc = loc
+		case mysqlOptReadTimeout:
+			d, err := parseMySQLDuration(value)
+			if err != nil {
+				return "", fmt.Errorf("invalid mysql option %s=%q: %w", key, value, err)
+			}
+			cfg.ReadTimeout = d
+		case mysqlOptWriteTimeout:
+			d, err := parseMySQLDuration(value)
+			if err != nil {
+				return "", fmt.Errorf("invalid mysql option %s=%q: %w", key, value, err)
+			}
+			cfg.WriteTimeout = d
+		case "time_zone":
+			extra.Set(key, quoteMySQLSessionValue(value))
+		default:
+			extra.Set(key, value)
+		}
+	}
+
+	dsn := cfg.FormatDSN()
+	if len(extra) > 0 {
+		sep := "?"
+		if strings.Contains(dsn, "?") {
+			sep = "&"
+		}
+		dsn += sep + extra.Encode()
+	}
+
+	// Round-trip through the driver parser so invalid options fail at
+	// configuration time instead of on first connect
+	if _, err := mysql.ParseDSN(dsn); err != nil {
+		return "", fmt.Errorf("invalid mysql connection options: %w", err)
+	}
+
+	return dsn, nil
+}
+
+// normalizeMySQLOptions lowercases the keys of the options the server builds
+// into the driver config, so that they are found however they are spelled.
+// Other keys are kept as written since driver parameters such as
+// allowNativePasswords are case-sensitive.
+func normalizeMySQLOptions(options map[string]string) map[string]string {
+	normalized := make(map[string]string, len(options))
+	for key, value := range options {
+		switch lower := strings.ToLower(key); lower {
+		case mysqlOptCharset, mysqlOptCollation, mysqlOptLoc, mysqlOptReadTimeout, mysqlOptWriteTimeout, "time_zone":
+			normalized[lower] = value
+		default:
+			normalized[key] = value
+		}
+	}
+	return normalized
+}
+
+// configureMySQLTLS translates the SSL settings into the driver TLS options,
+// registering a custom tls.Config when certificates are involved
+func configureMySQLTLS(cfg *mysql.Config, config Config) error {
+	hasCerts := config.SSLRootCert != "" || config.SSLCert != "" || config.SSLKey != ""
+
+	switch config.SSLMode {
+	case "":
+		if !hasCerts {
+			return nil
+		}
+		// Certificates without an explicit mode imply full verification
+		return registerMySQLTLS(cfg, config, SSLVerifyFull)
+	case SSLDisable:
+		cfg.TLSConfig = "false"
+		return nil
+	case SSLPrefer:
+		if !hasCerts {
+			cfg.TLSConfig = "preferred"
+			return nil
+		}
+		cfg.AllowFallbackToPlaintext = true
+		return registerMySQLTLS(cfg, config, SSLRequire)
+	case SSLRequire:
+		if !hasCerts {
+			cfg.TLSConfig = "skip-verify"
+			return nil
+		}
+		if config.SSLRootCert != "" {
+			return registerMySQLTLS(cfg, config, SSLVerifyCA)
+		}
+		return registerMySQLTLS(cfg, config, SSLRequire)
+	case SSLVerifyCA, SSLVerifyFull:
+		return registerMySQLTLS(cfg, config, config.SSLMode)
+	default:
+		return fmt.Errorf("unsupported ssl_mode for mysql: %s", config.SSLMode)
+	}
+}
+
+// registerMySQLTLS builds a tls.Config for the given verification mode and
+// registers it with the driver under a key derived from the connection
+func registerMySQLTLS(cfg *mysql.Config, config Config, mode PostgresSSLMode) error {
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: config.Host,
+	}
+
+	if config.SSLRootCert != "" {
+		pem, err := os.ReadFile(config.SSLRootCert)
+		if err != nil {
+			return fmt.Errorf("failed to read ssl_root_cert %s: %w", config.SSLRootCert, err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no valid certificates found in ssl_root_cert %s", config.SSLRootCert)
+		}
+		tlsConfig.RootCAs = pool
+	} else if mode == SSLVerifyCA {
+		return fmt.Errorf("ssl_mode %s requires ssl_root_cert", mode)
+	}
+
+	if config.SSLCert != "" || config.SSLKey != "" {
+		if config.SSLCert == "" || config.SSLKey == "" {
+			return errors.New("ssl_cert and ssl_key must be set together")
+		}
+		cert, err := tls.LoadX509KeyPair(config.SSLCert, config.SSLKey)
+		if err != nil {
+			return fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	switch mode {
+	case SSLRequire:
+		tlsConfig.InsecureSkipVerify = true // #nosec G402 -- explicit ssl_mode=require
+	case SSLVerifyCA:
+		// Verify the chain but not the hostname, which crypto/tls cannot do
+		// on its own
+		roots := tlsConfig.RootCAs
+		tlsConfig.InsecureSkipVerify = true // #nosec G402 -- chain verified below
+		tlsConfig.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			return verifyCertChain(rawCerts, roots)
+		}
+	}
+
+	key := mysqlTLSKey(config, mode)
+	if err := mysql.RegisterTLSConfig(key, tlsConfig); err != nil {
+		return fmt.Errorf("failed to register mysql tls config: %w", err)
+	}
+	cfg.TLSConfig = key
+
+	return nil
+}
+
+// verifyCertChain checks that the peer certificate chains to one of the roots
+func verifyCertChain(rawCerts [][]byte, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return errors.New("server presented no certificates")
+	}
+
+	certs := make([]*x509.Certificate, 0, len(rawCerts))
+	for _, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return fmt.Errorf("failed to parse server certificate: %w", err)
+		}
+		certs = append(certs, cert)
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	return err
+}
+
+// mysqlTLSKey derives a stable, DSN-safe registration key for a TLS config
+func mysqlTLSKey(config Config, mode PostgresSSLMode) string {
+	h := sha256.New()
+	for _, part := range []string{
+		config.Host, strconv.Itoa(config.Port), config.User, config.Name,
+		string(mode), config.SSLRootCert, config.SSLCert, config.SSLKey,
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return "dbmcp-" + hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// parseMySQLDuration accepts either a Go duration ("30s") or plain seconds ("30")
+func parseMySQLDuration(value string) (time.Duration, error) {
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// quoteMySQLSessionValue quotes a session variable value unless already quoted
+func quoteMySQLSessionValue(value string) string {
+	if strings.HasPrefix(value, "'") && strings.HasSuffix(value, "'") && len(value) > 1 {
+		return value
+	}
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// maskedMySQLConnStr returns the DSN with the password replaced
+func maskedMySQLConnStr(dsn string) string {
+	cfg, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "unknown"
+	}
+	if cfg.Passwd != "" {
+		cfg.Passwd = "***"
+	}
+	return cfg.FormatDSN()
+}
@@ -0,0 +1,168 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMySQLConnStr(t *testing.T) {
+	base := Config{
+		Type:           "mysql",
+		Host:           "db.example.com",
+		Port:           3306,
+		User:           "user",
+		Password:       "p@ss:word",
+		Name:           "testdb",
+		ConnectTimeout: 5,
+	}
+
+	t.Run("basic", func(t *testing.T) {
+		dsn, err := buildMySQLConnStr(base)
+		require.NoError(t, err)
+
+		cfg, err := mysql.ParseDSN(dsn)
+		require.NoError(t, err)
+		assert.Equal(t, "user", cfg.User)
+		assert.Equal(t, "p@ss:word", cfg.Passwd)
+		assert.Equal(t, "db.example.com:3306", cfg.Addr)
+		assert.Equal(t, "testdb", cfg.DBName)
+		assert.True(t, cfg.ParseTime)
+		assert.Equal(t, 5*time.Second, cfg.Timeout)
+		assert.Empty(t, cfg.TLSConfig)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		c := base
+		c.Options = map[string]string{
+			"charset":       "utf8mb4",
+			"collation":     "utf8mb4_unicode_ci",
+			"loc":           "UTC",
+			"read_timeout":  "30",
+			"write_timeout": "10s",
+			"time_zone":     "+00:00",
+			"sql_mode":      "'STRICT_ALL_TABLES'",
+		}
+
+		dsn, err := buildMySQLConnStr(c)
+		require.NoError(t, err)
+
+		cfg, err := mysql.ParseDSN(dsn)
+		require.NoError(t, err)
+		assert.Equal(t, "utf8mb4_unicode_ci", cfg.Collation)
+		assert.Equal(t, time.UTC, cfg.Loc)
+		assert.Equal(t, 30*time.Second, cfg.ReadTimeout)
+		assert.Equal(t, 10*time.Second, cfg.WriteTimeout)
+		assert.Equal(t, "'+00:00'", cfg.Params["time_zone"])
+		assert.Equal(t, "'STRICT_ALL_TABLES'", cfg.Params["sql_mode"])
+		assert.Contains(t, dsn, "charset=utf8mb4")
+	})
+
+	t.Run("option keys in any case", func(t *testing.T) {
+		c := base
+		c.Options = map[string]string{
+			"charset":              "utf8mb4",
+			"Collation":            "utf8mb4_unicode_ci",
+			"allowNativePasswords": "true",
+		}
+
+		dsn, err := buildMySQLConnStr(c)
+		require.NoError(t, err)
+
+		cfg, err := mysql.ParseDSN(dsn)
+		require.NoError(t, err)
+		assert.Equal(t, "utf8mb4_unicode_ci", cfg.Collation)
+		assert.Contains(t, dsn, "charset=utf8mb4")
+		assert.True(t, cfg.AllowNativePasswords)
+	})
+
+	t.Run("statement and lock timeouts", func(t *testing.T) {
+		c := base
+		c.StatementTimeout = 5000
+		c.LockTimeout = 1500
+
+		dsn, err := buildMySQLConnStr(c)
+		require.NoError(t, err)
+
+		cfg, err := mysql.ParseDSN(dsn)
+		require.NoError(t, err)
+		assert.Equal(t, "5000", cfg.Params["max_execution_time"])
+		assert.Equal(t, "2", cfg.Params["innodb_lock_wait_timeout"])
+	})
+
+	t.Run("invalid option", func(t *testing.T) {
+		c := base
+		c.Options = map[string]string{"read_timeout": "soon"}
+
+		_, err := buildMySQLConnStr(c)
+		assert.Error(t, err)
+	})
+
+	sslTests := []struct {
+		mode     PostgresSSLMode
+		expected string
+	}{
+		{SSLDisable, "false"},
+		{SSLPrefer, "preferred"},
+		{SSLRequire, "skip-verify"},
+	}
+	for _, tt := range sslTests {
+		t.Run("ssl mode "+string(tt.mode), func(t *testing.T) {
+			c := base
+			c.SSLMode = tt.mode
+
+			dsn, err := buildMySQLConnStr(c)
+			require.NoError(t, err)
+
+			cfg, err := mysql.ParseDSN(dsn)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cfg.TLSConfig)
+		})
+	}
+
+	t.Run("verify-ca requires root cert", func(t *testing.T) {
+		c := base
+		c.SSLMode = SSLVerifyCA
+
+		_, err := buildMySQLConnStr(c)
+		assert.Error(t, err)
+	})
+
+	t.Run("missing root cert file", func(t *testing.T) {
+		c := base
+		c.SSLMode = SSLVerifyFull
+		c.SSLRootCert = "/nonexistent/ca.pem"
+
+		_, err := buildMySQLConnStr(c)
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported ssl mode", func(t *testing.T) {
+		c := base
+		c.SSLMode = "bogus"
+
+		_, err := buildMySQLConnStr(c)
+		assert.Error(t, err)
+	})
+}
+
+func TestMySQLConnectionStringMasksPassword(t *testing.T) {
+	database, err := NewDatabase(Config{
+		Type:     "mysql",
+		Host:     "localhost",
+		Port:     3306,
+		User:     "user",
+		Password: "secret",
+		Name:     "testdb",
+		SSLMode:  SSLRequire,
+	})
+	require.NoError(t, err)
+
+	connStr := database.ConnectionString()
+	assert.NotContains(t, connStr, "secret")
+	assert.Contains(t, connStr, "***")
+	assert.Contains(t, connStr, "tls=skip-verify")
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ResetStatementTimeout returns the statement_timeout of a PostgreSQL session
+// to the value it was opened with
+const ResetStatementTimeout = "RESET statement_timeout"
+
+// SetStatementTimeout returns the statement that limits the statements of a
+// PostgreSQL session to timeout until ResetStatementTimeout
+func SetStatementTimeout(timeout time.Duration) string {
+	return fmt.Sprintf("SET statement_timeout = %d", timeoutMillis(timeout))
+}
+
+// SetLocalStatementTimeout returns the statement that limits the statements
+// of the rest of a PostgreSQL transaction to timeout
+func SetLocalStatementTimeout(timeout time.Duration) string {
+	return fmt.Sprintf("SET LOCAL statement_timeout = %d", timeoutMillis(timeout))
+}
+
+// WithMaxExecutionTime adds a MAX_EXECUTION_TIME optimizer hint to a MySQL
+// SELECT, merging it into a hint comment that follows the SELECT keyword.
+// MySQL applies the limit to SELECT statements only, so other statements are
+// returned unchanged.
+func WithMaxExecutionTime(query string, timeout time.Duration) string {
+	trimmed := strings.TrimLeft(query, " \t\r\n")
+	if len(trimmed) < len("SELECT") || !strings.EqualFold(trimmed[:len("SELECT")], "SELECT") {
+		return query
+	}
+	rest := trimmed[len("SELECT"):]
+	if rest != "" && !strings.ContainsAny(rest[:1], " \t\r\n/") {
+		// An identifier that starts with select, not the keyword
+		return query
+	}
+
+	hint := fmt.Sprintf("MAX_EXECUTION_TIME(%d)", timeoutMillis(timeout))
+	prefix := query[:len(query)-len(trimmed)] + trimmed[:len("SELECT")]
+	body := strings.TrimLeft(rest, " \t\r\n")
+	if strings.HasPrefix(body, "/*+") {
+		return prefix + " /*+ " + hint + " " + strings.TrimLeft(body[len("/*+"):], " ")
+	}
+	return prefix + " /*+ " + hint + " */ " + body
+}
+
+// timeoutMillis returns a timeout in whole milliseconds, at least 1 since 0
+// disables the server limits
+func timeoutMillis(timeout time.Duration) int64 {
+	if ms := timeout.Milliseconds(); ms > 0 {
+		return ms
+	}
+	return 1
+}
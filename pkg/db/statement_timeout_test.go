@@ -0,0 +1,37 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetStatementTimeout(t *testing.T) {
+	assert.Equal(t, "SET statement_timeout = 2500", SetStatementTimeout(2500*time.Millisecond))
+	assert.Equal(t, "SET statement_timeout = 1", SetStatementTimeout(0))
+}
+
+func TestSetLocalStatementTimeout(t *testing.T) {
+	assert.Equal(t, "SET LOCAL statement_timeout = 2500", SetLocalStatementTimeout(2500*time.Millisecond))
+	assert.Equal(t, "SET LOCAL statement_timeout = 1", SetLocalStatementTimeout(0))
+}
+
+func TestWithMaxExecutionTime(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{"select", "SELECT * FROM orders", "SELECT /*+ MAX_EXECUTION_TIME(2000) */ * FROM orders"},
+		{"leading space and case", "\n  select id from orders", "\n  select /*+ MAX_EXECUTION_TIME(2000) */ id from orders"},
+		{"existing hint", "SELECT /*+ NO_INDEX_MERGE(t) */ * FROM t", "SELECT /*+ MAX_EXECUTION_TIME(2000) NO_INDEX_MERGE(t) */ * FROM t"},
+		{"update", "UPDATE orders SET paid = 1", "UPDATE orders SET paid = 1"},
+		{"not the keyword", "selected_rows", "selected_rows"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, WithMaxExecutionTime(tt.query, 2*time.Second))
+		})
+	}
+}
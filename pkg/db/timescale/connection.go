@@ -34,6 +34,27 @@ func NewTimescaleDB(config DBConfig) (*DB, error) {
 	}, nil
 }
 
+// NewTimescaleDBFromDatabase wraps an open PostgreSQL connection, detecting
+// the TimescaleDB extension on it
+func NewTimescaleDBFromDatabase(ctx context.Context, database db.Database) (*DB, error) {
+	t := &DB{
+		Database: database,
+		config:   DBConfig{UseTimescaleDB: true},
+	}
+
+	var version string
+	err := database.QueryRow(ctx, "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'").Scan(&version)
+	if err == sql.ErrNoRows {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to check TimescaleDB extension: %w", err)
+	}
+	t.extVersion = version
+	t.isTimescaleDB = true
+	return t, nil
+}
+
 // Connect establishes a connection and verifies TimescaleDB availability
 func (t *DB) Connect() error {
 	// Connect to PostgreSQL
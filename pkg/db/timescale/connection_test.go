@@ -338,3 +338,27 @@ func TestTimescaleDB_ConnectNoExtension(t *testing.T) {
 		t.Error("Expected isTimescaleDB to be false, got true")
 	}
 }
+
+func TestNewTimescaleDBFromDatabase(t *testing.T) {
+	ctx := context.Background()
+	mockDB := NewMockDB()
+	mockDB.RegisterQueryResult("SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'", "2.11.0", nil)
+
+	tsdb, err := NewTimescaleDBFromDatabase(ctx, mockDB)
+	if err != nil {
+		t.Fatalf("Failed to wrap database: %v", err)
+	}
+	if !tsdb.IsTimescaleDB() || tsdb.ExtVersion() != "2.11.0" {
+		t.Errorf("Expected TimescaleDB 2.11.0, got %v %q", tsdb.IsTimescaleDB(), tsdb.ExtVersion())
+	}
+
+	mockDB = NewMockDB()
+	mockDB.RegisterQueryResult("SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'", nil, sql.ErrNoRows)
+	tsdb, err = NewTimescaleDBFromDatabase(ctx, mockDB)
+	if err != nil {
+		t.Fatalf("Failed to wrap database: %v", err)
+	}
+	if tsdb.IsTimescaleDB() {
+		t.Error("Expected isTimescaleDB to be false without the extension")
+	}
+}
@@ -3,6 +3,7 @@ package timescale
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 )
 
@@ -338,6 +339,87 @@ func (t *DB) RecentChunks(ctx context.Context, tableName string, limit int) (int
 	return result, nil
 }
 
+// ChunkSize is the disk usage of a hypertable chunk
+type ChunkSize struct {
+	ChunkSchema  string
+	ChunkName    string
+	RangeStart   string
+	RangeEnd     string
+	IsCompressed bool
+	TableBytes   int64
+	IndexBytes   int64
+	ToastBytes   int64
+	TotalBytes   int64
+}
+
+// ChunkSizes returns the disk usage of the chunks of a hypertable, most
+// recent first
+func (t *DB) ChunkSizes(ctx context.Context, schemaName, tableName string) ([]ChunkSize, error) {
+	if !t.isTimescaleDB {
+		return nil, fmt.Errorf("TimescaleDB extension not available")
+	}
+
+	query := `
+		SELECT c.chunk_schema, c.chunk_name, c.range_start::text AS range_start, c.range_end::text AS range_end,
+			c.is_compressed, s.table_bytes, s.index_bytes, s.toast_bytes, s.total_bytes
+		FROM timescaledb_information.chunks c
+		JOIN chunks_detailed_size(format('%I.%I', $1::text, $2::text)::regclass) s
+			ON s.chunk_schema = c.chunk_schema AND s.chunk_name = c.chunk_name
+		WHERE c.hypertable_schema = $1 AND c.hypertable_name = $2
+		ORDER BY c.range_end DESC
+	`
+
+	result, err := t.ExecuteSQL(ctx, query, schemaName, tableName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chunk sizes: %w", err)
+	}
+
+	rows, ok := result.([]map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected result type from database query")
+	}
+
+	chunks := make([]ChunkSize, 0, len(rows))
+	for _, row := range rows {
+		chunk := ChunkSize{
+			ChunkSchema: fmt.Sprintf("%v", row["chunk_schema"]),
+			ChunkName:   fmt.Sprintf("%v", row["chunk_name"]),
+			TableBytes:  toInt64(row["table_bytes"]),
+			IndexBytes:  toInt64(row["index_bytes"]),
+			ToastBytes:  toInt64(row["toast_bytes"]),
+			TotalBytes:  toInt64(row["total_bytes"]),
+		}
+		if row["range_start"] != nil {
+			chunk.RangeStart = fmt.Sprintf("%v", row["range_start"])
+		}
+		if row["range_end"] != nil {
+			chunk.RangeEnd = fmt.Sprintf("%v", row["range_end"])
+		}
+		if compressed, ok := row["is_compressed"].(bool); ok {
+			chunk.IsCompressed = compressed
+		}
+		chunks = append(chunks, chunk)
+	}
+
+	return chunks, nil
+}
+
+// toInt64 converts a numeric column to int64
+func toInt64(value interface{}) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	case string:
+		n, _ := strconv.ParseInt(v, 10, 64)
+		return n
+	}
+	return 0
+}
+
 // CreateHypertable is a helper function to create a hypertable with the given configuration options.
 // This is exported for use by other packages.
 func CreateHypertable(ctx context.Context, db *DB, table, timeColumn string, opts ...HypertableOption) error {
@@ -479,3 +479,44 @@ func TestRecentChunks(t *testing.T) {
 		t.Error("Expected query error, got nil")
 	}
 }
+
+func TestChunkSizes(t *testing.T) {
+	tsdb, mockDB := MockTimescaleDB(t)
+	ctx := context.Background()
+
+	mockDB.RegisterQueryResult("FROM timescaledb_information.chunks c", []map[string]interface{}{
+		{
+			"chunk_schema":  "_timescaledb_internal",
+			"chunk_name":    "_hyper_1_2_chunk",
+			"range_start":   "2023-01-02 00:00:00+00",
+			"range_end":     "2023-01-03 00:00:00+00",
+			"is_compressed": true,
+			"table_bytes":   int64(8192),
+			"index_bytes":   int64(16384),
+			"toast_bytes":   int64(0),
+			"total_bytes":   int64(24576),
+		},
+	}, nil)
+
+	chunks, err := tsdb.ChunkSizes(ctx, "public", "metrics")
+	if err != nil {
+		t.Fatalf("Failed to get chunk sizes: %v", err)
+	}
+	if len(chunks) != 1 {
+		t.Fatalf("Expected 1 chunk, got %d", len(chunks))
+	}
+	if chunks[0].ChunkName != "_hyper_1_2_chunk" || !chunks[0].IsCompressed || chunks[0].TotalBytes != 24576 {
+		t.Errorf("Unexpected chunk: %+v", chunks[0])
+	}
+
+	query, args := mockDB.GetLastQuery()
+	AssertQueryContains(t, query, "chunks_detailed_size")
+	if len(args) != 2 || args[0] != "public" || args[1] != "metrics" {
+		t.Errorf("Expected schema and table arguments, got %v", args)
+	}
+
+	tsdb.isTimescaleDB = false
+	if _, err := tsdb.ChunkSizes(ctx, "public", "metrics"); err == nil {
+		t.Error("Expected error when TimescaleDB is not available, got nil")
+	}
+}
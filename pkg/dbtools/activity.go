@@ -0,0 +1,157 @@
+package dbtools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+)
+
+// defaultActivityLimit is the number of sessions listed when no limit is set
+const defaultActivityLimit = 50
+
+// SessionActivity is a live session of a database
+type SessionActivity struct {
+	PID                 int64
+	User                string
+	Database            string
+	Application         string
+	ClientAddr          string
+	State               string        // active, idle, idle in transaction, ...
+	Query               string        // the running or, for idle sessions, the last query
+	Duration            time.Duration // time in the current state
+	TransactionDuration time.Duration // age of the open transaction, if any
+	WaitEventType       string
+	WaitEvent           string
+}
+
+// ActivityFilter selects the sessions listed by GetActivity
+type ActivityFilter struct {
+	PID         int64         // session to keep
+	State       string        // state to keep, case-insensitive
+	User        string        // user to keep
+	MinDuration time.Duration // shortest time in the current state to keep
+	Limit       int           // most sessions to list, longest first
+}
+
+// pgActivityQuery lists the client sessions of the database, other than the
+// one running it
+const pgActivityQuery = `
+	SELECT pid, usename AS user_name, datname AS database_name, application_name,
+	       COALESCE(client_addr::text, 'local') AS client_addr, COALESCE(state, '') AS state, query,
+	       EXTRACT(EPOCH FROM now() - CASE WHEN state = 'active' THEN query_start ELSE state_change END) * 1000 AS duration_ms,
+	       EXTRACT(EPOCH FROM now() - xact_start) * 1000 AS xact_ms,
+	       wait_event_type, wait_event
+	FROM pg_catalog.pg_stat_activity
+	WHERE datname = current_database() AND pid <> pg_backend_pid() AND backend_type = 'client backend'`
+
+// mysqlActivityQuery lists the sessions of the server, other than the one
+// running it, with states named as on PostgreSQL; the thread state, such as
+// a metadata lock wait, is the wait event
+const mysqlActivityQuery = `
+	SELECT p.ID AS pid, p.USER AS user_name, p.DB AS database_name, '' AS application_name,
+	       p.HOST AS client_addr,
+	       CASE WHEN p.COMMAND = 'Query' THEN 'active'
+	            WHEN p.COMMAND = 'Sleep' AND t.trx_id IS NOT NULL THEN 'idle in transaction'
+	            WHEN p.COMMAND = 'Sleep' THEN 'idle'
+	            ELSE LOWER(p.COMMAND) END AS state,
+	       COALESCE(p.INFO, t.trx_query) AS query, p.TIME * 1000 AS duration_ms,
+	       TIMESTAMPDIFF(MICROSECOND, t.trx_started, NOW()) / 1000 AS xact_ms,
+	       '' AS wait_event_type, p.STATE AS wait_event
+	FROM information_schema.PROCESSLIST p
+	LEFT JOIN information_schema.INNODB_TRX t ON t.trx_mysql_thread_id = p.ID
+	WHERE p.ID <> CONNECTION_ID() AND p.COMMAND NOT IN ('Daemon', 'Binlog Dump', 'Binlog Dump GTID')`
+
+// GetActivity lists the live sessions of a database, longest in their
+// current state first
+func GetActivity(ctx context.Context, dbID string, filter ActivityFilter) ([]SessionActivity, error) {
+	database, err := GetDatabase(dbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database: %w", err)
+	}
+	return activity(ctx, database, filter)
+}
+
+// activity reads the sessions of a database
+func activity(ctx context.Context, database db.Database, filter ActivityFilter) ([]SessionActivity, error) {
+	q, err := activityQuery(database.DriverName(), filter)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := database.Query(ctx, q.query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session activity: %w", err)
+	}
+	defer cleanupRows(rows)
+	results, err := rowsToMaps(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read session activity: %w", err)
+	}
+
+	sessions := make([]SessionActivity, 0, len(results))
+	for _, row := range results {
+		sessions = append(sessions, SessionActivity{
+			PID:                 int64(numberValue(row["pid"])),
+			User:                stringValue(row["user_name"], ""),
+			Database:            stringValue(row["database_name"], ""),
+			Application:         stringValue(row["application_name"], ""),
+			ClientAddr:          stringValue(row["client_addr"], ""),
+			State:               stringValue(row["state"], ""),
+			Query:               stringValue(row["query"], ""),
+			Duration:            millisecondsDuration(row["duration_ms"]),
+			TransactionDuration: millisecondsDuration(row["xact_ms"]),
+			WaitEventType:       stringValue(row["wait_event_type"], ""),
+			WaitEvent:           stringValue(row["wait_event"], ""),
+		})
+	}
+	return sessions, nil
+}
+
+// activityQuery builds the activity query of a driver, filtering the
+// sessions on their aliased columns
+func activityQuery(driver string, filter ActivityFilter) (queryWithArgs, error) {
+	var base string
+	switch driver {
+	case "postgres":
+		base = pgActivityQuery
+	case "mysql":
+		base = mysqlActivityQuery
+	default:
+		return queryWithArgs{}, fmt.Errorf("session activity is not supported for %s databases", driver)
+	}
+
+	var conditions []string
+	var args []interface{}
+	placeholder := func(value interface{}) string {
+		args = append(args, value)
+		if driver == "postgres" {
+			return fmt.Sprintf("$%d", len(args))
+		}
+		return "?"
+	}
+	if filter.PID > 0 {
+		conditions = append(conditions, "s.pid = "+placeholder(filter.PID))
+	}
+	if filter.State != "" {
+		conditions = append(conditions, "LOWER(s.state) = "+placeholder(strings.ToLower(filter.State)))
+	}
+	if filter.User != "" {
+		conditions = append(conditions, "s.user_name = "+placeholder(filter.User))
+	}
+	if filter.MinDuration > 0 {
+		conditions = append(conditions, "s.duration_ms >= "+placeholder(float64(filter.MinDuration)/float64(time.Millisecond)))
+	}
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultActivityLimit
+	}
+
+	query := "SELECT * FROM (" + base + "\n) s"
+	if len(conditions) > 0 {
+		query += "\nWHERE " + strings.Join(conditions, " AND ")
+	}
+	query += fmt.Sprintf("\nORDER BY s.duration_ms DESC, s.pid\nLIMIT %d", limit)
+	return queryWithArgs{query: query, args: args}, nil
+}
@@ -0,0 +1,37 @@
+package dbtools
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivityQuery(t *testing.T) {
+	q, err := activityQuery("postgres", ActivityFilter{State: "Active", User: "app", MinDuration: 1500 * time.Millisecond})
+	require.NoError(t, err)
+	assert.Contains(t, q.query, "FROM pg_catalog.pg_stat_activity")
+	assert.Contains(t, q.query, "\nWHERE LOWER(s.state) = $1 AND s.user_name = $2 AND s.duration_ms >= $3\n")
+	assert.True(t, strings.HasSuffix(q.query, "\nLIMIT 50"))
+	assert.Equal(t, []interface{}{"active", "app", 1500.0}, q.args)
+
+	q, err = activityQuery("mysql", ActivityFilter{MinDuration: time.Minute, Limit: 5})
+	require.NoError(t, err)
+	assert.Contains(t, q.query, "FROM information_schema.PROCESSLIST p")
+	assert.Contains(t, q.query, "\nWHERE s.duration_ms >= ?\nORDER BY s.duration_ms DESC, s.pid\nLIMIT 5")
+	assert.Equal(t, []interface{}{60000.0}, q.args)
+
+	q, err = activityQuery("postgres", ActivityFilter{PID: 42})
+	require.NoError(t, err)
+	assert.Contains(t, q.query, "\nWHERE s.pid = $1\n")
+
+	q, err = activityQuery("postgres", ActivityFilter{})
+	require.NoError(t, err)
+	assert.NotContains(t, q.query, "\nWHERE")
+	assert.Empty(t, q.args)
+
+	_, err = activityQuery("sqlite", ActivityFilter{})
+	assert.ErrorContains(t, err, "not supported")
+}
@@ -0,0 +1,112 @@
+package dbtools
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DataDictionary holds table and column descriptions kept outside the
+// database. Its descriptions add to, and take precedence over, the comments
+// stored in the catalogs.
+//
+// Table keys are either plain table names or schema-qualified as
+// "schema.table"; a qualified key wins over a plain one.
+//
+//	tables:
+//	  orders:
+//	    description: One row per checkout
+//	    columns:
+//	      status: pending, paid, shipped or cancelled
+type DataDictionary struct {
+	Tables map[string]TableDictionary `yaml:"tables"`
+}
+
+// TableDictionary describes a table and its columns
+type TableDictionary struct {
+	Description string            `yaml:"description"`
+	Columns     map[string]string `yaml:"columns"`
+}
+
+// LoadDataDictionary reads a data dictionary YAML file
+func LoadDataDictionary(path string) (*DataDictionary, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read data dictionary %s: %w", path, err)
+	}
+
+	var dict DataDictionary
+	if err := yaml.Unmarshal(data, &dict); err != nil {
+		return nil, fmt.Errorf("failed to parse data dictionary %s: %w", path, err)
+	}
+	return &dict, nil
+}
+
+// table looks up the entry for a table, preferring a schema-qualified key
+func (d *DataDictionary) table(schema, name string) (TableDictionary, bool) {
+	if schema != "" {
+		if entry, ok := d.Tables[schema+"."+name]; ok {
+			return entry, true
+		}
+	}
+	entry, ok := d.Tables[name]
+	return entry, ok
+}
+
+// Apply adds dictionary descriptions to a tables, columns, summary or full
+// schema component result. Other components are left unchanged.
+func (d *DataDictionary) Apply(component, schema, table string, result map[string]interface{}) {
+	switch component {
+	case "tables", "summary":
+		d.applyTables(schema, result["tables"])
+	case "columns":
+		d.applyColumns(schema, table, result["columns"])
+	case "full":
+		d.applyTables(schema, result["tables"])
+		tables, _ := result["schema"].(map[string]interface{})
+		for name, columns := range tables {
+			if columnsMap, ok := columns.(map[string]interface{}); ok {
+				d.applyColumns(schema, name, columnsMap["columns"])
+			}
+		}
+	}
+}
+
+// applyTables sets the description of each table row found in the dictionary
+func (d *DataDictionary) applyTables(schema string, rows interface{}) {
+	tables, _ := rows.([]map[string]interface{})
+	for _, row := range tables {
+		name, _ := row["table_name"].(string)
+		if entry, ok := d.table(schema, name); ok && entry.Description != "" {
+			row["description"] = entry.Description
+		}
+	}
+}
+
+// applyColumns sets the description of each column row found in the
+// dictionary entry for table
+func (d *DataDictionary) applyColumns(schema, table string, rows interface{}) {
+	entry, ok := d.table(schema, table)
+	if !ok {
+		return
+	}
+	columns, _ := rows.([]map[string]interface{})
+	for _, row := range columns {
+		name, _ := row["column_name"].(string)
+		if description := entry.Columns[name]; description != "" {
+			row["description"] = description
+		}
+	}
+}
+
+// withDescriptions makes sure every row carries a description key, so callers
+// see the same shape whether or not the database stores comments
+func withDescriptions(rows []map[string]interface{}) []map[string]interface{} {
+	for _, row := range rows {
+		if _, ok := row["description"]; !ok {
+			row["description"] = nil
+		}
+	}
+	return rows
+}
@@ -0,0 +1,80 @@
+package dbtools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeDataDictionary(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "data_dictionary.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadDataDictionary(t *testing.T) {
+	path := writeDataDictionary(t, `
+tables:
+  orders:
+    description: One row per checkout
+    columns:
+      status: pending, paid or shipped
+  sales.orders:
+    description: Orders in the sales schema
+`)
+
+	dict, err := LoadDataDictionary(path)
+	require.NoError(t, err)
+	assert.Equal(t, "One row per checkout", dict.Tables["orders"].Description)
+	assert.Equal(t, "pending, paid or shipped", dict.Tables["orders"].Columns["status"])
+
+	_, err = LoadDataDictionary(filepath.Join(t.TempDir(), "missing.yaml"))
+	assert.Error(t, err)
+
+	_, err = LoadDataDictionary(writeDataDictionary(t, "tables: [oops"))
+	assert.Error(t, err)
+}
+
+func TestDataDictionaryApply(t *testing.T) {
+	dict := &DataDictionary{Tables: map[string]TableDictionary{
+		"orders":       {Description: "Orders", Columns: map[string]string{"status": "Lifecycle state"}},
+		"sales.orders": {Description: "Sales orders"},
+		"customers":    {Columns: map[string]string{"email": "Login address"}},
+	}}
+
+	tables := map[string]interface{}{"tables": []map[string]interface{}{
+		{"table_name": "orders", "description": "from COMMENT ON"},
+		{"table_name": "customers", "description": "Buyers"},
+		{"table_name": "audit", "description": nil},
+	}}
+	dict.Apply("tables", "public", "", tables)
+	rows := tables["tables"].([]map[string]interface{})
+	assert.Equal(t, "Orders", rows[0]["description"], "dictionary overrides database comments")
+	assert.Equal(t, "Buyers", rows[1]["description"], "entries without a description keep the comment")
+	assert.Nil(t, rows[2]["description"])
+
+	dict.Apply("tables", "sales", "", tables)
+	assert.Equal(t, "Sales orders", rows[0]["description"], "qualified keys win")
+
+	columns := map[string]interface{}{"columns": []map[string]interface{}{
+		{"column_name": "id", "description": nil},
+		{"column_name": "status", "description": nil},
+	}}
+	dict.Apply("columns", "public", "orders", columns)
+	assert.Nil(t, columns["columns"].([]map[string]interface{})[0]["description"])
+	assert.Equal(t, "Lifecycle state", columns["columns"].([]map[string]interface{})[1]["description"])
+
+	full := map[string]interface{}{
+		"tables": []map[string]interface{}{{"table_name": "customers"}},
+		"schema": map[string]interface{}{
+			"customers": map[string]interface{}{"columns": []map[string]interface{}{{"column_name": "email"}}},
+		},
+	}
+	dict.Apply("full", "public", "", full)
+	customerColumns := full["schema"].(map[string]interface{})["customers"].(map[string]interface{})["columns"]
+	assert.Equal(t, "Login address", customerColumns.([]map[string]interface{})[0]["description"])
+}
@@ -5,9 +5,6 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"os"
-	"path/filepath"
-	"strconv"
 	"strings"
 	"time"
 
@@ -35,11 +32,24 @@ const (
 
 // Config represents database configuration
 type Config struct {
-	ConfigFile  string
+	// MultiDBConfig holds the resolved connections, normally produced by the
+	// layered configuration loader in internal/config
+	MultiDBConfig *db.MultiDBConfig
+	// ConfigFile is loaded when MultiDBConfig is not set
+	ConfigFile string
+	// CancelStatements cancels statements whose context ends on the server
+	// with pg_cancel_backend or KILL QUERY, as security.cancel_statements allows
+	CancelStatements bool
+
+	// Deprecated: set MultiDBConfig instead. Connections are used when neither
+	// MultiDBConfig nor ConfigFile provides any.
 	Connections []ConnectionConfig
 }
 
 // ConnectionConfig represents a single database connection configuration
+//
+// Deprecated: use db.DatabaseConnectionConfig, which also carries TLS,
+// timeout, schema and pool settings.
 type ConnectionConfig struct {
 	ID       string       `json:"id"`
 	Type     DatabaseType `json:"type"`
@@ -51,10 +61,35 @@ type ConnectionConfig struct {
 }
 
 // MultiDBConfig represents configuration for multiple database connections
+//
+// Deprecated: use db.MultiDBConfig.
 type MultiDBConfig struct {
 	Connections []ConnectionConfig `json:"connections"`
 }
 
+// ToDBConfig converts the configuration to the db package format
+func (c MultiDBConfig) ToDBConfig() *db.MultiDBConfig {
+	return &db.MultiDBConfig{Connections: toDBConnectionConfigs(c.Connections)}
+}
+
+// toDBConnectionConfigs converts deprecated connection configs to the db
+// package format
+func toDBConnectionConfigs(conns []ConnectionConfig) []db.DatabaseConnectionConfig {
+	result := make([]db.DatabaseConnectionConfig, 0, len(conns))
+	for _, conn := range conns {
+		result = append(result, db.DatabaseConnectionConfig{
+			ID:       conn.ID,
+			Type:     string(conn.Type),
+			Host:     conn.Host,
+			Port:     conn.Port,
+			Name:     conn.Name,
+			User:     conn.User,
+			Password: conn.Password,
+		})
+	}
+	return result
+}
+
 // Database connection manager (singleton)
 var (
 	dbManager *db.Manager
@@ -76,96 +111,31 @@ func InitDatabase(cfg *Config) error {
 	// Create database manager
 	dbManager = db.NewDBManager()
 
-	var multiDBConfig *MultiDBConfig
+	if cfg == nil {
+		return fmt.Errorf("no database configuration provided")
+	}
+	dbManager.SetCancelStatements(cfg.CancelStatements)
 
-	// If config file is provided, load it
-	if cfg != nil && cfg.ConfigFile != "" {
-		// Read config file
-		configData, err := os.ReadFile(cfg.ConfigFile)
+	multiDBConfig := cfg.MultiDBConfig
+	if (multiDBConfig == nil || len(multiDBConfig.Connections) == 0) && cfg.ConfigFile != "" {
+		fileConfig, err := db.LoadMultiDBConfigFile(cfg.ConfigFile)
 		if err != nil {
-			logger.Warn("Warning: failed to read config file %s: %v", cfg.ConfigFile, err)
-			// Don't return error, try other methods
-		} else {
-			// Parse config
-			multiDBConfig = &MultiDBConfig{}
-			if err := json.Unmarshal(configData, multiDBConfig); err != nil {
-				logger.Warn("Warning: failed to parse config file %s: %v", cfg.ConfigFile, err)
-				// Don't return error, try other methods
-			} else {
-				logger.Info("Loaded database config from file: %s", cfg.ConfigFile)
-				// Debug logging of connection details
-				for i, conn := range multiDBConfig.Connections {
-					logger.Info("Connection [%d]: ID=%s, Type=%s, Host=%s, Port=%d, Name=%s",
-						i, conn.ID, conn.Type, conn.Host, conn.Port, conn.Name)
-				}
-			}
+			return fmt.Errorf("failed to load config file %s: %w", cfg.ConfigFile, err)
 		}
+		multiDBConfig = fileConfig
 	}
-
-	// If config was not loaded from file, try direct connections config
-	if multiDBConfig == nil || len(multiDBConfig.Connections) == 0 {
-		if cfg != nil && len(cfg.Connections) > 0 {
-			// Use connections from direct config
-			multiDBConfig = &MultiDBConfig{
-				Connections: cfg.Connections,
-			}
-			logger.Info("Using database connections from direct configuration")
-		} else {
-			// Try to load from environment variable
-			dbConfigJSON := os.Getenv("DB_CONFIG")
-			if dbConfigJSON != "" {
-				multiDBConfig = &MultiDBConfig{}
-				if err := json.Unmarshal([]byte(dbConfigJSON), multiDBConfig); err != nil {
-					logger.Warn("Warning: failed to parse DB_CONFIG environment variable: %v", err)
-					// Don't return error, try legacy method
-				} else {
-					logger.Info("Loaded database config from DB_CONFIG environment variable")
-				}
-			}
-		}
+	if (multiDBConfig == nil || len(multiDBConfig.Connections) == 0) && len(cfg.Connections) > 0 {
+		multiDBConfig = MultiDBConfig{Connections: cfg.Connections}.ToDBConfig()
 	}
 
-	// If no config loaded yet, try legacy single connection from environment
 	if multiDBConfig == nil || len(multiDBConfig.Connections) == 0 {
-		// Create a single connection from environment variables
-		dbType := os.Getenv("DB_TYPE")
-		if dbType == "" {
-			dbType = "mysql" // Default type
-		}
-
-		dbHost := os.Getenv("DB_HOST")
-		dbPortStr := os.Getenv("DB_PORT")
-		dbUser := os.Getenv("DB_USER")
-		dbPassword := os.Getenv("DB_PASSWORD")
-		dbName := os.Getenv("DB_NAME")
-
-		// If we have basic connection details, create a config
-		if dbHost != "" && dbUser != "" {
-			dbPort, err := strconv.Atoi(dbPortStr)
-			if err != nil || dbPort == 0 {
-				dbPort = 3306 // Default MySQL port
-			}
-
-			multiDBConfig = &MultiDBConfig{
-				Connections: []ConnectionConfig{
-					{
-						ID:       "default",
-						Type:     DatabaseType(dbType),
-						Host:     dbHost,
-						Port:     dbPort,
-						Name:     dbName,
-						User:     dbUser,
-						Password: dbPassword,
-					},
-				},
-			}
-			logger.Info("Created database config from environment variables")
-		}
+		return fmt.Errorf("no database configuration provided")
 	}
 
-	// If still no config, return error
-	if multiDBConfig == nil || len(multiDBConfig.Connections) == 0 {
-		return fmt.Errorf("no database configuration provided")
+	// Debug logging of connection details
+	for i, conn := range multiDBConfig.Connections {
+		logger.Info("Connection [%d]: ID=%s, Type=%s, Host=%s, Port=%d, Name=%s",
+			i, conn.ID, conn.Type, conn.Host, conn.Port, conn.Name)
 	}
 
 	// Convert config to JSON for loading
@@ -183,6 +153,18 @@ func InitDatabase(cfg *Config) error {
 		return fmt.Errorf("failed to connect to databases: %w", err)
 	}
 
+	// Track the plans of connections with a plan store
+	for _, conn := range multiDBConfig.Connections {
+		if conn.PlanStore == "" {
+			continue
+		}
+		tracker, err := NewPlanTracker(conn.ID, conn.PlanStore)
+		if err != nil {
+			return err
+		}
+		GetPerformanceAnalyzer(conn.ID).SetPlanTracker(tracker)
+	}
+
 	// Log connected databases
 	dbs := dbManager.ListDatabases()
 	logger.Info("Connected to %d databases: %v", len(dbs), dbs)
@@ -508,109 +490,6 @@ func getArrayParam(params map[string]interface{}, key string) ([]interface{}, bo
 	return nil, false
 }
 
-// _loadConfigFromFile loads database configuration from a file (currently unused)
-func _loadConfigFromFile(cfg *Config) (*db.MultiDBConfig, error) {
-	if cfg.ConfigFile == "" {
-		return nil, fmt.Errorf("no config file specified")
-	}
-
-	// If path is not absolute, make it absolute
-	absPath := cfg.ConfigFile
-	if !filepath.IsAbs(absPath) {
-		var err error
-		absPath, err = filepath.Abs(absPath)
-		if err != nil {
-			return nil, fmt.Errorf("failed to resolve absolute path: %w", err)
-		}
-	}
-
-	// Read configuration file
-	configData, err := os.ReadFile(absPath)
-	if err != nil {
-		logger.Warn("Warning: failed to read config file %s: %v", cfg.ConfigFile, err)
-		return nil, err
-	}
-
-	// Parse JSON
-	var dbConfig db.MultiDBConfig
-	if err := json.Unmarshal(configData, &dbConfig); err != nil {
-		logger.Warn("Warning: failed to parse config file %s: %v", cfg.ConfigFile, err)
-		return nil, err
-	}
-	logger.Info("Loaded database config from file: %s", cfg.ConfigFile)
-	// Debug logging of connection details
-	for i, conn := range dbConfig.Connections {
-		logger.Info("Connection [%d]: ID=%s, Type=%s, Host=%s, Port=%d, Name=%s",
-			i, conn.ID, conn.Type, conn.Host, conn.Port, conn.Name)
-	}
-
-	return &dbConfig, nil
-}
-
-// _getEnv gets an environment variable or returns a default value (currently unused)
-func _getEnv(key, defaultValue string) string {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
-	}
-	return value
-}
-
-// _getIntEnv gets an environment variable as an integer or returns a default value (currently unused)
-func _getIntEnv(key string, defaultValue int) int {
-	value := os.Getenv(key)
-	if value == "" {
-		return defaultValue
-	}
-	intValue, err := strconv.Atoi(value)
-	if err != nil {
-		return defaultValue
-	}
-	return intValue
-}
-
-// _loadConfigFromEnv loads database configuration from the environment (currently unused)
-func _loadConfigFromEnv() (*db.MultiDBConfig, error) {
-	// Check if DB_CONFIG environment variable is set
-	dbConfigEnv := os.Getenv("DB_CONFIG")
-	if dbConfigEnv != "" {
-		var dbConfig db.MultiDBConfig
-		if err := json.Unmarshal([]byte(dbConfigEnv), &dbConfig); err != nil {
-			logger.Warn("Warning: failed to parse DB_CONFIG environment variable: %v", err)
-			return nil, err
-		}
-		logger.Info("Loaded database config from DB_CONFIG environment variable")
-		return &dbConfig, nil
-	}
-
-	// Create config from individual environment variables
-	// Load database configuration from environment variables
-	dbType := _getEnv("DB_TYPE", "mysql")
-	dbHost := _getEnv("DB_HOST", "localhost")
-	dbPort := _getIntEnv("DB_PORT", 3306)
-	dbUser := _getEnv("DB_USER", "")
-	dbPass := _getEnv("DB_PASSWORD", "")
-	dbName := _getEnv("DB_NAME", "")
-
-	// Create a default configuration with a single connection
-	dbConfig := &db.MultiDBConfig{
-		Connections: []db.DatabaseConnectionConfig{
-			{
-				ID:       "default",
-				Type:     dbType,
-				Host:     dbHost,
-				Port:     dbPort,
-				User:     dbUser,
-				Password: dbPass,
-				Name:     dbName,
-			},
-		},
-	}
-
-	logger.Info("Created database config from environment variables")
-	return dbConfig, nil
-}
-
 // GetDatabaseQueryTimeout returns the query timeout for a database in milliseconds
 func GetDatabaseQueryTimeout(db db.Database) int {
 	// Get the query timeout from the database configuration
@@ -626,6 +505,26 @@ func GetDatabaseQueryTimeout(db db.Database) int {
 	return defaultTimeout
 }
 
+// MaxStatementTimeout returns the longest statement timeout a call may ask
+// for on a database: max_statement_timeout_ms, or the query timeout
+func MaxStatementTimeout(dbID string) (time.Duration, error) {
+	if dbManager == nil {
+		return 0, fmt.Errorf("database manager not initialized")
+	}
+	cfg, err := dbManager.GetConnectionConfig(dbID)
+	if err != nil {
+		return 0, err
+	}
+	if cfg.MaxStatementTimeout > 0 {
+		return time.Duration(cfg.MaxStatementTimeout) * time.Millisecond, nil
+	}
+	database, err := GetDatabase(dbID)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(GetDatabaseQueryTimeout(database)) * time.Millisecond, nil
+}
+
 // RegisterMCPDatabaseTools registers database tools specifically formatted for MCP compatibility
 func RegisterMCPDatabaseTools(registry *tools.Registry) error {
 	// Get available databases
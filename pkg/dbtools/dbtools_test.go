@@ -8,6 +8,8 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
 )
 
 // MockDB is a mock implementation of the db.Database interface
@@ -208,3 +210,14 @@ func TestExec(t *testing.T) {
 // 2. That failed database connections are reported with status "disconnected"
 // 3. That latency measurements are included in the response
 // 4. That it works with multiple database connections
+
+func TestMultiDBConfigToDBConfig(t *testing.T) {
+	legacy := MultiDBConfig{Connections: []ConnectionConfig{
+		{ID: "main", Type: Postgres, Host: "localhost", Port: 5432, Name: "app", User: "app", Password: "secret"},
+	}}
+
+	converted := legacy.ToDBConfig()
+	assert.Equal(t, []db.DatabaseConnectionConfig{
+		{ID: "main", Type: "postgres", Host: "localhost", Port: 5432, Name: "app", User: "app", Password: "secret"},
+	}, converted.Connections)
+}
@@ -90,7 +90,7 @@ func handleExecute(ctx context.Context, params map[string]interface{}) (interfac
 	}
 
 	// Get the performance analyzer
-	analyzer := GetPerformanceAnalyzer()
+	analyzer := GetPerformanceAnalyzer(databaseID)
 
 	// Execute statement with performance tracking
 	var result interface{}
@@ -0,0 +1,760 @@
+package dbtools
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// Defaults of the index advisor
+const (
+	defaultAdviceQueries = 20
+	defaultAdviceMinRows = 1000
+	maxIndexNameLength   = 63
+)
+
+// IndexAdviceOptions tune the index advisor
+type IndexAdviceOptions struct {
+	Source   string  // auto (default), history or statements
+	Limit    int     // workload queries to plan, 20 by default
+	MinRows  float64 // smallest table worth an index, 1000 rows by default
+	Validate bool    // check proposals with HypoPG hypothetical indexes on PostgreSQL
+}
+
+// IndexAdvice proposes indexes for the full scans of a workload and flags
+// indexes that cost writes without serving reads
+type IndexAdvice struct {
+	Source     string // history, pg_stat_statements or performance_schema
+	Schema     string
+	Planned    int  // workload queries whose plans were read
+	Skipped    int  // workload queries that could not be planned
+	Validated  bool // whether proposals were checked with HypoPG
+	Proposals  []IndexProposal
+	Unused     []IndexFinding
+	Duplicates []IndexFinding
+	Notes      []string
+}
+
+// IndexProposal is an index that would replace full scans of the workload
+type IndexProposal struct {
+	Table           string
+	Columns         []string // equality columns first, then one range column
+	Statement       string
+	Rationale       string
+	Fingerprints    []string
+	Calls           int
+	RowsScanned     float64       // rows full scans read per execution
+	RowsReturned    float64       // rows the scans keep per execution
+	EstimatedSaving time.Duration // workload time spent reading rows the index would skip
+	CostBefore      float64       // plan cost without and with a hypothetical index, when validated
+	CostAfter       float64
+
+	sample planSample
+}
+
+// IndexFinding is an existing index worth dropping
+type IndexFinding struct {
+	Table      string
+	Index      string
+	Definition string
+	Reason     string
+	SizeBytes  int64
+	Statement  string
+}
+
+// planSample is a workload query to plan. Generic samples are normalized
+// queries planned without parameter values.
+type planSample struct {
+	query   string
+	args    []interface{}
+	generic bool
+}
+
+// indexInfo is an existing index
+type indexInfo struct {
+	table      string
+	name       string
+	columns    []string
+	unique     bool
+	primary    bool
+	predicate  string
+	method     string
+	definition string
+}
+
+// indexCandidate is the index a full scan in one plan calls for
+type indexCandidate struct {
+	table     string
+	columns   []string
+	condition string
+	scanned   float64
+	returned  float64
+}
+
+// planQueryer runs EXPLAIN on the connection pool or on the session holding
+// hypothetical indexes
+type planQueryer interface {
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// databaseQueryer runs queries on the connection pool of a database
+type databaseQueryer struct {
+	database db.Database
+}
+
+func (q databaseQueryer) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return q.database.Query(ctx, query, args...)
+}
+
+// AdviseIndexes plans the heaviest queries of a connection's workload and
+// proposes indexes for their selective full scans of tables in a schema,
+// then lists the unused and duplicate indexes of the schema
+func AdviseIndexes(ctx context.Context, dbID, schema string, opts IndexAdviceOptions) (*IndexAdvice, error) {
+	database, err := GetDatabase(dbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database: %w", err)
+	}
+	resolved, err := ResolveSchema(dbID, schema)
+	if err != nil {
+		return nil, err
+	}
+	return adviseIndexes(ctx, database, GetPerformanceAnalyzer(dbID), resolved, opts)
+}
+
+// adviseIndexes builds the index advice of a database
+func adviseIndexes(ctx context.Context, database db.Database, analyzer *PerformanceAnalyzer, schema string, opts IndexAdviceOptions) (*IndexAdvice, error) {
+	driver := database.DriverName()
+	if driver != "postgres" && driver != "mysql" {
+		return nil, fmt.Errorf("index advice is not supported for %s databases", driver)
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = defaultAdviceQueries
+	}
+	if opts.MinRows <= 0 {
+		opts.MinRows = defaultAdviceMinRows
+	}
+	if driver == "postgres" {
+		schema = (&PostgresStrategy{Schema: schema}).schema()
+	}
+
+	advice := &IndexAdvice{Schema: schema}
+	workload, err := adviceWorkload(ctx, database, analyzer, opts, advice)
+	if err != nil {
+		return nil, err
+	}
+	tables, err := tableRowEstimates(ctx, database, schema)
+	if err != nil {
+		return nil, err
+	}
+	indexes, err := indexInventory(ctx, database, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	generic := false
+	if driver == "postgres" {
+		var version int
+		if err := database.QueryRow(ctx, "SELECT current_setting('server_version_num')::int").Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to read the server version: %w", err)
+		}
+		generic = version >= 160000
+	}
+
+	proposals := make(map[string]*IndexProposal)
+	var order []string
+	for _, m := range workload {
+		sample, ok := workloadSample(m, generic)
+		if !ok {
+			advice.Skipped++
+			continue
+		}
+		plan, err := explainSample(ctx, databaseQueryer{database}, driver, sample)
+		if err != nil {
+			logger.Debug("Index advice could not plan %s: %v", m.Fingerprint, err)
+			advice.Skipped++
+			continue
+		}
+		advice.Planned++
+
+		for _, c := range indexCandidates(plan, tables, opts.MinRows) {
+			if coveredByIndex(indexes, c.table, c.columns) {
+				continue
+			}
+			key := c.table + "(" + strings.Join(c.columns, ",") + ")"
+			p, ok := proposals[key]
+			if !ok {
+				p = &IndexProposal{
+					Table:     c.table,
+					Columns:   c.columns,
+					Statement: createIndexStatement(driver, schema, c.table, c.columns),
+					Rationale: fmt.Sprintf("Full scans of %s read about %.0f rows to keep %.0f on %s",
+						c.table, c.scanned, c.returned, c.condition),
+					sample: sample,
+				}
+				proposals[key] = p
+				order = append(order, key)
+			}
+			p.Fingerprints = append(p.Fingerprints, m.Fingerprint)
+			p.Calls += m.Count
+			if c.scanned > p.RowsScanned {
+				p.RowsScanned, p.RowsReturned = c.scanned, c.returned
+			}
+			p.EstimatedSaving += time.Duration(float64(m.TotalDuration) * (1 - c.returned/c.scanned))
+		}
+	}
+	for _, key := range order {
+		advice.Proposals = append(advice.Proposals, *proposals[key])
+	}
+	sort.SliceStable(advice.Proposals, func(i, j int) bool {
+		a, b := advice.Proposals[i], advice.Proposals[j]
+		if a.EstimatedSaving != b.EstimatedSaving {
+			return a.EstimatedSaving > b.EstimatedSaving
+		}
+		return a.RowsScanned-a.RowsReturned > b.RowsScanned-b.RowsReturned
+	})
+	if advice.Skipped > 0 && driver == "postgres" && !generic {
+		advice.Notes = append(advice.Notes, "normalized pg_stat_statements queries can only be planned from PostgreSQL 16; queries run through this server are planned from samples")
+	}
+
+	if opts.Validate {
+		validateWithHypoPG(ctx, database, advice)
+	}
+
+	unused, err := unusedIndexes(ctx, database, schema)
+	if err != nil {
+		advice.Notes = append(advice.Notes, fmt.Sprintf("unused indexes are not available: %v", err))
+	}
+	advice.Unused = unused
+	advice.Duplicates = duplicateIndexes(indexes, driver, schema)
+	return advice, nil
+}
+
+// adviceWorkload returns the fingerprints to plan: the database's statement
+// statistics when available, otherwise the queries run through this server
+func adviceWorkload(ctx context.Context, database db.Database, analyzer *PerformanceAnalyzer, opts IndexAdviceOptions, advice *IndexAdvice) ([]*QueryMetrics, error) {
+	switch opts.Source {
+	case "", "auto", "statements":
+		stats, err := workloadStatistics(ctx, database, "total_time", opts.Limit)
+		if err != nil {
+			return nil, err
+		}
+		if stats.Available && (len(stats.Queries) > 0 || opts.Source == "statements") {
+			advice.Source = stats.Source
+			return stats.Queries, nil
+		}
+		if opts.Source == "statements" {
+			return nil, fmt.Errorf("statement statistics are not available: %s", stats.Reason)
+		}
+		if stats.Reason != "" {
+			advice.Notes = append(advice.Notes, "using the queries run through this server: "+stats.Reason)
+		}
+	case "history":
+	default:
+		return nil, fmt.Errorf("invalid source %q: use auto, history or statements", opts.Source)
+	}
+
+	advice.Source = "history"
+	metrics, err := analyzer.TopQueries("total_time", opts.Limit)
+	if err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// workloadSample picks what to plan for a fingerprint: a recorded execution,
+// or on PostgreSQL 16 the normalized query as a generic plan. Only queries
+// that read rows through a WHERE clause are worth planning.
+func workloadSample(m *QueryMetrics, generic bool) (planSample, bool) {
+	sample := planSample{query: m.Sample, args: m.SampleParams}
+	if sample.query == "" {
+		if !generic || m.Query == "" {
+			return planSample{}, false
+		}
+		sample = planSample{query: m.Query, generic: true}
+	}
+	match := firstKeywordPattern.FindStringSubmatch(sample.query)
+	if match == nil {
+		return planSample{}, false
+	}
+	switch strings.ToUpper(match[1]) {
+	case "SELECT", "WITH", "UPDATE", "DELETE":
+		return sample, true
+	}
+	return planSample{}, false
+}
+
+// explainSample plans a sample without executing it
+func explainSample(ctx context.Context, q planQueryer, driver string, sample planSample) (*QueryPlan, error) {
+	explain, err := explainStatement(driver, sample.query, false)
+	if err != nil {
+		return nil, err
+	}
+	if sample.generic {
+		explain = "EXPLAIN (FORMAT JSON, GENERIC_PLAN) " + sample.query
+	}
+	rows, err := q.QueryContext(ctx, explain, sample.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	return readPlan(rows, driver)
+}
+
+// tableRowEstimates returns the estimated row counts of the tables in a
+// schema, from the planner statistics
+func tableRowEstimates(ctx context.Context, database db.Database, schema string) (map[string]float64, error) {
+	var q queryWithArgs
+	if database.DriverName() == "mysql" {
+		q = (&MySQLStrategy{Schema: schema}).scoped(`
+			SELECT table_name AS table_name, table_rows AS row_estimate
+			FROM information_schema.tables
+			WHERE table_schema = {schema} AND table_type = 'BASE TABLE'`)
+	} else {
+		q = (&PostgresStrategy{Schema: schema}).scoped(`
+			SELECT c.relname AS table_name, GREATEST(c.reltuples, 0) AS row_estimate
+			FROM pg_catalog.pg_class c
+			JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND c.relkind IN ('r', 'p', 'm')`)
+	}
+
+	rows, err := database.Query(ctx, q.query, q.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table row estimates: %w", err)
+	}
+	defer cleanupRows(rows)
+	results, err := rowsToMaps(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get table row estimates: %w", err)
+	}
+
+	tables := make(map[string]float64, len(results))
+	for _, row := range results {
+		tables[stringValue(row["table_name"], "")] = numberValue(row["row_estimate"])
+	}
+	return tables, nil
+}
+
+// indexInventory lists the indexes of a schema
+func indexInventory(ctx context.Context, database db.Database, schema string) ([]indexInfo, error) {
+	result, err := getSchemaObjects(ctx, database, "indexes", schema, "")
+	if err != nil {
+		return nil, err
+	}
+	rows, _ := result["indexes"].([]map[string]interface{})
+
+	indexes := make([]indexInfo, 0, len(rows))
+	for _, row := range rows {
+		index := indexInfo{
+			table:      stringValue(row["table_name"], ""),
+			name:       stringValue(row["index_name"], ""),
+			unique:     truthy(row["is_unique"]),
+			primary:    truthy(row["is_primary"]),
+			predicate:  stringValue(row["predicate"], ""),
+			method:     strings.ToLower(stringValue(row["method"], "")),
+			definition: stringValue(row["definition"], ""),
+		}
+		for _, column := range strings.Split(stringValue(row["columns"], ""), ",") {
+			if column = strings.TrimSpace(column); column != "" {
+				index.columns = append(index.columns, column)
+			}
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// truthy reads a boolean column, which MySQL returns as a number
+func truthy(value interface{}) bool {
+	if b, ok := value.(bool); ok {
+		return b
+	}
+	return numberValue(value) != 0
+}
+
+// indexCandidates finds the selective full scans of a plan on tables of at
+// least minRows rows and the columns an index would need to replace them.
+// The inner side of a nested loop is also matched on its join condition.
+func indexCandidates(plan *QueryPlan, tables map[string]float64, minRows float64) []indexCandidate {
+	var candidates []indexCandidate
+	var walk func(node *PlanNode, joinFilter string, loops float64)
+	walk = func(node *PlanNode, joinFilter string, loops float64) {
+		if node.Operation == "Seq Scan" || node.Operation == "Full Table Scan" {
+			if c, ok := scanCandidate(node, joinFilter, loops, tables, minRows); ok {
+				candidates = append(candidates, c)
+			}
+		}
+		for i, child := range node.Children {
+			switch {
+			case node.Operation == "Nested Loop" && i > 0:
+				walk(child, node.JoinFilter, outputRows(node.Children[i-1]))
+			case node.Operation == "Materialize":
+				walk(child, joinFilter, loops)
+			default:
+				walk(child, "", 1)
+			}
+		}
+	}
+	if plan.Root != nil {
+		walk(plan.Root, "", 1)
+	}
+	return candidates
+}
+
+// scanCandidate returns the index a full scan calls for, if its conditions
+// keep few of the table's rows
+func scanCandidate(node *PlanNode, joinFilter string, loops float64, tables map[string]float64, minRows float64) (indexCandidate, bool) {
+	tableRows, ok := tables[node.Relation]
+	if !ok || tableRows < minRows {
+		return indexCandidate{}, false
+	}
+	if node.EstimatedRows > tableRows*selectiveFilter {
+		return indexCandidate{}, false
+	}
+
+	alias := node.Alias
+	if alias == "" {
+		alias = node.Relation
+	}
+	var conditions []string
+	for _, condition := range []string{node.Filter, joinFilter} {
+		if condition != "" {
+			conditions = append(conditions, condition)
+		}
+	}
+	condition := strings.Join(conditions, " AND ")
+	equality, ranges := conditionColumns(condition, alias)
+	columns := equality
+	for _, column := range ranges {
+		if !containsString(columns, column) {
+			columns = append(columns, column)
+			break
+		}
+	}
+	if len(columns) == 0 {
+		return indexCandidate{}, false
+	}
+
+	loops = maxFloat(maxFloat(loops, node.Loops), 1)
+	return indexCandidate{
+		table:     node.Relation,
+		columns:   columns,
+		condition: condition,
+		scanned:   tableRows * loops,
+		returned:  node.EstimatedRows * loops,
+	}, true
+}
+
+// Patterns conditionColumns reads plan conditions with
+var (
+	conjunctionPattern = regexp.MustCompile(`(?i)\s+AND\s+`)
+	disjunctionPattern = regexp.MustCompile(`(?i)\s+OR\s+`)
+	castPattern        = regexp.MustCompile(`::"?[A-Za-z_][\w ]*?"?(\[\])?(\s+(varying|without time zone|with time zone|precision))?([)\s,]|$)`)
+	callPattern        = regexp.MustCompile(`\b([A-Za-z_]\w*)\s*\(`)
+	comparisonPattern  = regexp.MustCompile(`(?i)^([A-Za-z_][\w.]*)\s*(<>|!=|<=|>=|=|<|>|\bIN\b|\bBETWEEN\b|\bIS\b)\s*(.*)$`)
+	columnRefPattern   = regexp.MustCompile(`^[A-Za-z_][\w.]*$`)
+)
+
+// conditionKeywords may precede a parenthesis without being a function call
+var conditionKeywords = map[string]bool{"ANY": true, "ALL": true, "IN": true, "AND": true, "OR": true, "NOT": true}
+
+// conditionColumns returns the columns of a relation that a plan condition
+// compares for equality and by range. Conditions under OR, on expressions
+// and with <>, LIKE or IS NOT cannot use a plain index and are ignored.
+func conditionColumns(condition, alias string) (equality, ranges []string) {
+	condition = stringPattern.ReplaceAllString(condition, "''")
+	for _, conjunct := range conjunctionPattern.Split(condition, -1) {
+		if disjunctionPattern.MatchString(conjunct) {
+			continue
+		}
+		conjunct = castPattern.ReplaceAllString(conjunct, "$4")
+		if hasFunctionCall(conjunct) {
+			continue
+		}
+		conjunct = strings.NewReplacer("(", " ", ")", " ", `"`, "", "`", "").Replace(conjunct)
+		match := comparisonPattern.FindStringSubmatch(strings.TrimSpace(conjunct))
+		if match == nil {
+			continue
+		}
+		column, operator, value := match[1], strings.ToUpper(match[2]), strings.TrimSpace(match[3])
+
+		name, own := ownColumn(column, alias)
+		if !own && operator == "=" && columnRefPattern.MatchString(value) {
+			// A join condition written from the other side
+			name, own = ownColumn(value, alias)
+		}
+		if !own {
+			continue
+		}
+		switch {
+		case operator == "=" || operator == "IN" || (operator == "IS" && strings.HasPrefix(strings.ToUpper(value), "NULL")):
+			if !containsString(equality, name) {
+				equality = append(equality, name)
+			}
+		case operator == "<" || operator == ">" || operator == "<=" || operator == ">=" || operator == "BETWEEN":
+			if !containsString(ranges, name) {
+				ranges = append(ranges, name)
+			}
+		}
+	}
+	return equality, ranges
+}
+
+// hasFunctionCall reports whether a condition applies a function
+func hasFunctionCall(condition string) bool {
+	for _, match := range callPattern.FindAllStringSubmatch(condition, -1) {
+		if !conditionKeywords[strings.ToUpper(match[1])] {
+			return true
+		}
+	}
+	return false
+}
+
+// ownColumn returns the column name of a reference that is unqualified or
+// qualified with the relation's alias
+func ownColumn(reference, alias string) (string, bool) {
+	parts := strings.Split(reference, ".")
+	name := parts[len(parts)-1]
+	if name == "" || conditionKeywords[strings.ToUpper(name)] || strings.EqualFold(name, "NULL") {
+		return "", false
+	}
+	if len(parts) > 1 && parts[len(parts)-2] != alias {
+		return "", false
+	}
+	return name, true
+}
+
+// coveredByIndex reports whether an index of a table already leads with the
+// columns of a candidate
+func coveredByIndex(indexes []indexInfo, table string, columns []string) bool {
+	for _, index := range indexes {
+		if index.table != table || index.predicate != "" || len(index.columns) < len(columns) {
+			continue
+		}
+		covered := true
+		for _, column := range columns {
+			if !containsString(index.columns[:len(columns)], column) {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+// createIndexStatement builds the CREATE INDEX statement of a proposal. On
+// PostgreSQL the index is built concurrently so writes are not blocked.
+func createIndexStatement(driver, schema, table string, columns []string) string {
+	name := "idx_" + table + "_" + strings.Join(columns, "_")
+	if len(name) > maxIndexNameLength {
+		name = name[:maxIndexNameLength]
+	}
+	quoted := make([]string, len(columns))
+	for i, column := range columns {
+		quoted[i] = db.QuoteIdentifier(driver, column)
+	}
+	concurrently := ""
+	if driver == "postgres" {
+		concurrently = " CONCURRENTLY"
+	}
+	return fmt.Sprintf("CREATE INDEX%s %s ON %s (%s);", concurrently, db.QuoteIdentifier(driver, name),
+		db.QuoteQualifiedName(driver, schema, table), strings.Join(quoted, ", "))
+}
+
+// dropIndexStatement builds the DROP INDEX statement of a finding
+func dropIndexStatement(driver, schema, table, index string) string {
+	if driver == "mysql" {
+		return fmt.Sprintf("DROP INDEX %s ON %s;", db.QuoteIdentifier(driver, index), db.QuoteQualifiedName(driver, schema, table))
+	}
+	return fmt.Sprintf("DROP INDEX CONCURRENTLY %s;", db.QuoteQualifiedName(driver, schema, index))
+}
+
+// validateWithHypoPG replans each proposal's sample with the index created
+// hypothetically by HypoPG, on one session, and drops proposals the planner
+// would not use
+func validateWithHypoPG(ctx context.Context, database db.Database, advice *IndexAdvice) {
+	if database.DriverName() != "postgres" {
+		advice.Notes = append(advice.Notes, "proposals can only be validated with HypoPG on PostgreSQL")
+		return
+	}
+	var installed bool
+	err := database.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'hypopg')").Scan(&installed)
+	if err != nil || !installed || database.DB() == nil {
+		advice.Notes = append(advice.Notes, "the hypopg extension is not installed, so proposals were not validated")
+		return
+	}
+
+	// Hypothetical indexes only exist in the session that created them
+	conn, err := database.DB().Conn(ctx)
+	if err != nil {
+		advice.Notes = append(advice.Notes, fmt.Sprintf("proposals were not validated: %v", err))
+		return
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "SELECT hypopg_reset()"); err != nil {
+			logger.Error("error resetting hypothetical indexes: %v", err)
+		}
+		if err := conn.Close(); err != nil {
+			logger.Error("error closing connection: %v", err)
+		}
+	}()
+
+	kept := advice.Proposals[:0]
+	for _, p := range advice.Proposals {
+		before, err := explainSample(ctx, conn, "postgres", p.sample)
+		if err == nil {
+			statement := strings.TrimSuffix(strings.Replace(p.Statement, " CONCURRENTLY", "", 1), ";")
+			_, err = conn.ExecContext(ctx, "SELECT * FROM hypopg_create_index($1)", statement)
+		}
+		var after *QueryPlan
+		if err == nil {
+			after, err = explainSample(ctx, conn, "postgres", p.sample)
+		}
+		if _, resetErr := conn.ExecContext(ctx, "SELECT hypopg_reset()"); resetErr != nil && err == nil {
+			err = resetErr
+		}
+		if err != nil {
+			advice.Notes = append(advice.Notes, fmt.Sprintf("could not validate %s: %v", p.Statement, err))
+			kept = append(kept, p)
+			continue
+		}
+
+		p.CostBefore, p.CostAfter = before.TotalCost, after.TotalCost
+		if p.CostAfter >= p.CostBefore {
+			advice.Notes = append(advice.Notes, fmt.Sprintf("dropped %s: the planner would not use it (cost %.2f)", p.Statement, p.CostBefore))
+			continue
+		}
+		kept = append(kept, p)
+	}
+	advice.Proposals = kept
+	advice.Validated = true
+}
+
+// unusedIndexes lists the indexes of a schema no query has read since
+// statistics were reset (PostgreSQL) or the server started (MySQL). Indexes
+// enforcing uniqueness are kept.
+func unusedIndexes(ctx context.Context, database db.Database, schema string) ([]IndexFinding, error) {
+	driver := database.DriverName()
+	var q queryWithArgs
+	var reason string
+	if driver == "mysql" {
+		q = (&MySQLStrategy{Schema: schema}).scoped(`
+			SELECT u.object_name AS table_name, u.index_name AS index_name
+			FROM sys.schema_unused_indexes u
+			WHERE u.object_schema = {schema}
+			ORDER BY u.object_name, u.index_name`)
+		reason = "no reads recorded by performance_schema since the server started"
+	} else {
+		q = (&PostgresStrategy{Schema: schema}).scoped(`
+			SELECT s.relname AS table_name, s.indexrelname AS index_name,
+			       pg_relation_size(s.indexrelid) AS size_bytes, pg_get_indexdef(s.indexrelid) AS definition
+			FROM pg_catalog.pg_stat_user_indexes s
+			JOIN pg_catalog.pg_index i ON i.indexrelid = s.indexrelid
+			WHERE s.schemaname = $1 AND s.idx_scan = 0 AND NOT i.indisunique AND NOT i.indisprimary
+			ORDER BY pg_relation_size(s.indexrelid) DESC`)
+		reason = "never scanned since statistics were reset; scans on replicas are not counted"
+	}
+
+	rows, err := database.Query(ctx, q.query, q.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanupRows(rows)
+	results, err := rowsToMaps(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := make([]IndexFinding, 0, len(results))
+	for _, row := range results {
+		table, index := stringValue(row["table_name"], ""), stringValue(row["index_name"], "")
+		findings = append(findings, IndexFinding{
+			Table:      table,
+			Index:      index,
+			Definition: stringValue(row["definition"], ""),
+			Reason:     reason,
+			SizeBytes:  int64(numberValue(row["size_bytes"])),
+			Statement:  dropIndexStatement(driver, schema, table, index),
+		})
+	}
+	return findings, nil
+}
+
+// duplicateIndexes lists the indexes whose columns are the same as, or lead,
+// another index of the same table and method. Partial indexes and indexes
+// enforcing uniqueness are kept.
+func duplicateIndexes(indexes []indexInfo, driver, schema string) []IndexFinding {
+	var findings []IndexFinding
+	for _, index := range indexes {
+		if index.unique || index.primary || index.predicate != "" || len(index.columns) == 0 {
+			continue
+		}
+		for _, other := range indexes {
+			if other.name == index.name || other.table != index.table || other.predicate != "" ||
+				other.method != index.method || len(other.columns) < len(index.columns) {
+				continue
+			}
+			if !equalStrings(other.columns[:len(index.columns)], index.columns) {
+				continue
+			}
+			identical := len(other.columns) == len(index.columns)
+			if identical && !other.unique && !other.primary && other.name > index.name {
+				// Report one of two identical plain indexes
+				continue
+			}
+
+			reason := fmt.Sprintf("its columns lead %s (%s)", other.name, strings.Join(other.columns, ", "))
+			if identical {
+				reason = fmt.Sprintf("same columns as %s", other.name)
+			}
+			findings = append(findings, IndexFinding{
+				Table:      index.table,
+				Index:      index.name,
+				Definition: index.definition,
+				Reason:     reason,
+				Statement:  dropIndexStatement(driver, schema, index.table, index.name),
+			})
+			break
+		}
+	}
+	return findings
+}
+
+// containsString reports whether a slice holds a value
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// equalStrings reports whether two slices hold the same values in order
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// maxFloat returns the larger of two numbers
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,144 @@
+package dbtools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConditionColumns(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition string
+		alias     string
+		equality  []string
+		ranges    []string
+	}{
+		{"equality and range", "((status = 'open'::text) AND (created_at >= '2024-01-01 00:00:00'::timestamp without time zone))", "o",
+			[]string{"status"}, []string{"created_at"}},
+		{"join condition", "(order_id = o.id)", "i", []string{"order_id"}, nil},
+		{"join condition from the other side", "(o.id = i.order_id)", "i", []string{"order_id"}, nil},
+		{"other relation", "(o.status = 'open'::text)", "i", nil, nil},
+		{"any and is null", "((id = ANY ('{1,2}'::integer[])) AND (deleted_at IS NULL))", "t", []string{"id", "deleted_at"}, nil},
+		{"mysql quoting", "((`shop`.`o`.`status` = 'open') and (`shop`.`o`.`total` > 100))", "o", []string{"status"}, []string{"total"}},
+		{"literal with keywords", "(note = 'a AND b = c'::text)", "n", []string{"note"}, nil},
+		{"disjunction", "((a = 1) OR (b = 2))", "t", nil, nil},
+		{"function", "(lower(email) = 'x'::text)", "u", nil, nil},
+		{"not indexable", "((status <> 'closed'::text) AND (name ~~ 'a%'::text) AND (x IS NOT NULL))", "t", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			equality, ranges := conditionColumns(tt.condition, tt.alias)
+			assert.Equal(t, tt.equality, equality)
+			assert.Equal(t, tt.ranges, ranges)
+		})
+	}
+}
+
+func TestIndexCandidates(t *testing.T) {
+	plan, err := ParsePostgresPlan([]byte(pgAnalyzedPlan))
+	require.NoError(t, err)
+
+	candidates := indexCandidates(plan, map[string]float64{"orders": 100000, "order_items": 42000}, 1000)
+	require.Len(t, candidates, 2)
+	assert.Equal(t, "orders", candidates[0].table)
+	assert.Equal(t, []string{"status"}, candidates[0].columns)
+	assert.Equal(t, 100000.0, candidates[0].scanned)
+	assert.Equal(t, "order_items", candidates[1].table)
+	assert.Equal(t, []string{"order_id"}, candidates[1].columns)
+	assert.Equal(t, 42000.0*2000, candidates[1].scanned, "the inner side is read once per outer row")
+
+	// Small tables are not worth an index
+	assert.Len(t, indexCandidates(plan, map[string]float64{"orders": 100000, "order_items": 500}, 1000), 1)
+
+	// Planned join condition of a nested loop over a materialized inner side
+	plan, err = ParsePostgresPlan([]byte(`[{"Plan": {"Node Type": "Nested Loop", "Plan Rows": 10, "Join Filter": "(u.id = e.user_id)",
+		"Plans": [
+			{"Node Type": "Index Scan", "Relation Name": "users", "Alias": "u", "Index Name": "users_pkey", "Plan Rows": 5},
+			{"Node Type": "Materialize", "Plan Rows": 2000, "Plans": [
+				{"Node Type": "Seq Scan", "Relation Name": "events", "Alias": "e", "Plan Rows": 2000}
+			]}
+		]}}]`))
+	require.NoError(t, err)
+	candidates = indexCandidates(plan, map[string]float64{"events": 120000}, 1000)
+	require.Len(t, candidates, 1)
+	assert.Equal(t, []string{"user_id"}, candidates[0].columns)
+	assert.Equal(t, 120000.0*5, candidates[0].scanned)
+
+	// A scan keeping most rows is better served by the table
+	plan, err = ParsePostgresPlan([]byte(`[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "events",
+		"Plan Rows": 90000, "Filter": "(kind = 'click'::text)"}}]`))
+	require.NoError(t, err)
+	assert.Empty(t, indexCandidates(plan, map[string]float64{"events": 120000}, 1000))
+}
+
+func TestCoveredByIndex(t *testing.T) {
+	indexes := []indexInfo{
+		{table: "orders", name: "idx_orders_customer_status", columns: []string{"customer_id", "status"}},
+		{table: "orders", name: "idx_orders_open", columns: []string{"created_at"}, predicate: "(status = 'open'::text)"},
+	}
+	assert.True(t, coveredByIndex(indexes, "orders", []string{"customer_id"}))
+	assert.True(t, coveredByIndex(indexes, "orders", []string{"status", "customer_id"}))
+	assert.False(t, coveredByIndex(indexes, "orders", []string{"status"}))
+	assert.False(t, coveredByIndex(indexes, "orders", []string{"created_at"}), "partial indexes only cover their predicate")
+	assert.False(t, coveredByIndex(indexes, "order_items", []string{"customer_id"}))
+}
+
+func TestDuplicateIndexes(t *testing.T) {
+	indexes := []indexInfo{
+		{table: "orders", name: "orders_pkey", columns: []string{"id"}, unique: true, primary: true, method: "btree"},
+		{table: "orders", name: "idx_orders_id", columns: []string{"id"}, method: "btree"},
+		{table: "orders", name: "idx_orders_customer", columns: []string{"customer_id"}, method: "btree"},
+		{table: "orders", name: "idx_orders_customer_status", columns: []string{"customer_id", "status"}, method: "btree"},
+		{table: "orders", name: "idx_orders_status_a", columns: []string{"status"}, method: "btree"},
+		{table: "orders", name: "idx_orders_status_b", columns: []string{"status"}, method: "btree"},
+		{table: "orders", name: "idx_orders_status_hash", columns: []string{"status"}, method: "hash"},
+		{table: "orders", name: "idx_orders_open", columns: []string{"customer_id"}, predicate: "(status = 'open'::text)", method: "btree"},
+	}
+
+	findings := duplicateIndexes(indexes, "postgres", "public")
+	reasons := make(map[string]string)
+	for _, finding := range findings {
+		reasons[finding.Index] = finding.Reason
+	}
+	assert.Equal(t, map[string]string{
+		"idx_orders_id":       "same columns as orders_pkey",
+		"idx_orders_customer": "its columns lead idx_orders_customer_status (customer_id, status)",
+		"idx_orders_status_b": "same columns as idx_orders_status_a",
+	}, reasons)
+	assert.Equal(t, `DROP INDEX CONCURRENTLY "public"."idx_orders_id";`, findings[0].Statement)
+
+	findings = duplicateIndexes(indexes[1:3], "mysql", "shop")
+	assert.Empty(t, findings)
+}
+
+func TestCreateIndexStatement(t *testing.T) {
+	assert.Equal(t, `CREATE INDEX CONCURRENTLY "idx_orders_status_created_at" ON "public"."orders" ("status", "created_at");`,
+		createIndexStatement("postgres", "public", "orders", []string{"status", "created_at"}))
+	assert.Equal(t, "CREATE INDEX `idx_orders_status` ON `shop`.`orders` (`status`);",
+		createIndexStatement("mysql", "shop", "orders", []string{"status"}))
+	assert.Equal(t, "DROP INDEX `idx_orders_status` ON `shop`.`orders`;", dropIndexStatement("mysql", "shop", "orders", "idx_orders_status"))
+
+	long := createIndexStatement("postgres", "public", "customer_subscription_events", []string{"subscription_id", "event_type", "created_at"})
+	assert.Contains(t, long, `"idx_customer_subscription_events_subscription_id_event_type_cre" ON`)
+}
+
+func TestWorkloadSample(t *testing.T) {
+	sample, ok := workloadSample(&QueryMetrics{Query: "SELECT * FROM t WHERE id = ?", Sample: "SELECT * FROM t WHERE id = $1",
+		SampleParams: []interface{}{7}}, false)
+	require.True(t, ok)
+	assert.Equal(t, "SELECT * FROM t WHERE id = $1", sample.query)
+	assert.Equal(t, []interface{}{7}, sample.args)
+	assert.False(t, sample.generic)
+
+	_, ok = workloadSample(&QueryMetrics{Query: "SELECT * FROM t WHERE id = $1"}, false)
+	assert.False(t, ok, "normalized queries need generic plans")
+
+	sample, ok = workloadSample(&QueryMetrics{Query: "SELECT * FROM t WHERE id = $1"}, true)
+	require.True(t, ok)
+	assert.True(t, sample.generic)
+
+	_, ok = workloadSample(&QueryMetrics{Sample: "INSERT INTO t VALUES (1)"}, true)
+	assert.False(t, ok)
+}
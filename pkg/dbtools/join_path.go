@@ -0,0 +1,289 @@
+package dbtools
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+)
+
+// joinPathQueryLimit caps the rows of a join path's SELECT skeleton
+const joinPathQueryLimit = 100
+
+// JoinPath is the chain of joins connecting a set of tables through their
+// foreign keys
+type JoinPath struct {
+	Schema string     // schema of unqualified table names
+	From   string     // first requested table
+	Joins  []JoinStep // each adds one table to those before it
+	Via    []string   // tables on the path that were not requested
+}
+
+// JoinStep joins a table through one foreign key
+type JoinStep struct {
+	Table      string // table joined
+	To         string // table already in the chain it joins to
+	Constraint string // foreign key followed
+	On         string // join condition
+}
+
+// joinTable is a table in the foreign key graph
+type joinTable struct {
+	schema string
+	name   string
+}
+
+// joinEdge is a foreign key between two tables, usable in both directions
+type joinEdge struct {
+	constraint    string
+	child, parent joinTable
+	columns       [][2]string // child column, parent column
+}
+
+// FindJoinPath finds the shortest chain of joins connecting tables through
+// the foreign keys in relationships, as returned by the relationships
+// component of schema. Tables may be qualified as schema.table; the chain
+// starts at the first one and adds each further table by its shortest path
+// to the tables joined so far. An empty schema means that of the
+// relationships, i.e. the driver default.
+func FindJoinPath(dbType, schema string, relationships []map[string]interface{}, tables []string) (*JoinPath, error) {
+	if len(tables) < 2 {
+		return nil, fmt.Errorf("at least two tables are required to find a join path")
+	}
+	if schema == "" && len(relationships) > 0 {
+		schema = stringValue(relationships[0]["table_schema"], "")
+	}
+
+	edges, byTable := joinGraph(schema, relationships)
+	display := func(t joinTable) string {
+		if t.schema == "" || t.schema == schema {
+			return t.name
+		}
+		return t.schema + "." + t.name
+	}
+	// ON clauses name tables without their schema, as they appear in FROM
+	column := func(t joinTable, name string) string {
+		return db.QuoteIdentifier(dbType, t.name) + "." + db.QuoteIdentifier(dbType, name)
+	}
+
+	targets := make([]joinTable, 0, len(tables))
+	requested := make(map[joinTable]bool)
+	for _, name := range tables {
+		table, err := findJoinTable(byTable, schema, name)
+		if err != nil {
+			return nil, err
+		}
+		if !requested[table] {
+			requested[table] = true
+			targets = append(targets, table)
+		}
+	}
+	if len(targets) < 2 {
+		return nil, fmt.Errorf("at least two different tables are required to find a join path")
+	}
+
+	path := &JoinPath{Schema: schema, From: display(targets[0]), Joins: []JoinStep{}, Via: []string{}}
+	joined := map[joinTable]bool{targets[0]: true}
+	for _, target := range targets[1:] {
+		if joined[target] {
+			continue
+		}
+		steps := shortestJoinPath(edges, joined, target)
+		if steps == nil {
+			return nil, fmt.Errorf("no foreign key path connects %s to %s", display(target), path.From)
+		}
+		for _, step := range steps {
+			var conditions []string
+			for _, pair := range step.edge.columns {
+				conditions = append(conditions, column(step.edge.child, pair[0])+" = "+column(step.edge.parent, pair[1]))
+			}
+			path.Joins = append(path.Joins, JoinStep{
+				Table:      display(step.table),
+				To:         display(step.from),
+				Constraint: step.edge.constraint,
+				On:         strings.Join(conditions, " AND "),
+			})
+			joined[step.table] = true
+			if !requested[step.table] {
+				path.Via = append(path.Via, display(step.table))
+			}
+		}
+	}
+	return path, nil
+}
+
+// Query builds a SELECT skeleton over the joined tables, limited to a
+// hundred rows, for the caller to narrow down
+func (p *JoinPath) Query(dbType string) (string, error) {
+	qualify := func(name string) string {
+		tableSchema, table, err := db.ParseQualifiedName(name)
+		if err != nil || tableSchema == "" {
+			tableSchema, table = p.Schema, name
+		}
+		return db.QuoteQualifiedName(dbType, tableSchema, table)
+	}
+
+	components := &QueryComponents{
+		Select: []string{"*"},
+		From:   qualify(p.From),
+		Limit:  joinPathQueryLimit,
+	}
+	for _, step := range p.Joins {
+		components.Joins = append(components.Joins, JoinClause{Type: "inner", Table: qualify(step.Table), On: step.On})
+	}
+	return buildQueryFromComponents(components)
+}
+
+// joinGraph builds the foreign key graph, merging the per-column rows of a
+// constraint into one edge
+func joinGraph(schema string, relationships []map[string]interface{}) ([]*joinEdge, map[string][]joinTable) {
+	var edges []*joinEdge
+	byKey := make(map[string]*joinEdge)
+	byTable := make(map[string][]joinTable)
+	seen := make(map[joinTable]bool)
+	add := func(t joinTable) {
+		if !seen[t] {
+			seen[t] = true
+			byTable[t.name] = append(byTable[t.name], t)
+		}
+	}
+
+	for _, rel := range relationships {
+		child := joinTable{schema: stringValue(rel["table_schema"], schema), name: stringValue(rel["table_name"], "")}
+		parent := joinTable{schema: stringValue(rel["foreign_table_schema"], child.schema), name: stringValue(rel["foreign_table_name"], "")}
+		childColumn := stringValue(rel["column_name"], "")
+		parentColumn := stringValue(rel["foreign_column_name"], "")
+		if child.name == "" || parent.name == "" || childColumn == "" || parentColumn == "" {
+			continue
+		}
+		constraint := stringValue(rel["constraint_name"], "")
+
+		key := child.schema + "\x00" + child.name + "\x00" + constraint
+		edge, ok := byKey[key]
+		if !ok {
+			edge = &joinEdge{constraint: constraint, child: child, parent: parent}
+			byKey[key] = edge
+			edges = append(edges, edge)
+			add(child)
+			add(parent)
+		}
+		pair := [2]string{childColumn, parentColumn}
+		duplicate := false
+		for _, existing := range edge.columns {
+			if existing == pair {
+				duplicate = true
+			}
+		}
+		if !duplicate {
+			edge.columns = append(edge.columns, pair)
+		}
+	}
+	return edges, byTable
+}
+
+// findJoinTable looks a possibly qualified table name up in the graph,
+// preferring the default schema for unqualified names
+func findJoinTable(byTable map[string][]joinTable, schema, name string) (joinTable, error) {
+	tableSchema, table, err := db.ParseQualifiedName(name)
+	if err != nil {
+		return joinTable{}, err
+	}
+	candidates := byTable[table]
+	for _, candidate := range candidates {
+		if (tableSchema != "" && candidate.schema == tableSchema) || (tableSchema == "" && candidate.schema == schema) {
+			return candidate, nil
+		}
+	}
+	if tableSchema == "" && len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	if tableSchema == "" && len(candidates) > 1 {
+		return joinTable{}, fmt.Errorf("table %s exists in several schemas; qualify it as schema.table", name)
+	}
+	return joinTable{}, fmt.Errorf("table %s not found among the tables with foreign keys", name)
+}
+
+// joinPathStep adds one table to a chain through an edge
+type joinPathStep struct {
+	table joinTable
+	from  joinTable
+	edge  *joinEdge
+}
+
+// shortestJoinPath runs a breadth-first search from the joined tables to the
+// target and returns the steps that reach it, or nil when none do
+func shortestJoinPath(edges []*joinEdge, joined map[joinTable]bool, target joinTable) []joinPathStep {
+	previous := make(map[joinTable]joinPathStep)
+	visited := make(map[joinTable]bool)
+	var queue []joinTable
+	for table := range joined {
+		visited[table] = true
+	}
+	// Start from the joined tables in edge order so results are stable
+	for _, edge := range edges {
+		for _, table := range []joinTable{edge.child, edge.parent} {
+			if joined[table] && !containsJoinTable(queue, table) {
+				queue = append(queue, table)
+			}
+		}
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == target {
+			break
+		}
+		for _, edge := range edges {
+			var next joinTable
+			switch current {
+			case edge.child:
+				next = edge.parent
+			case edge.parent:
+				next = edge.child
+			default:
+				continue
+			}
+			if visited[next] {
+				continue
+			}
+			visited[next] = true
+			previous[next] = joinPathStep{table: next, from: current, edge: edge}
+			queue = append(queue, next)
+		}
+	}
+
+	if _, ok := previous[target]; !ok {
+		return nil
+	}
+	var steps []joinPathStep
+	for table := target; !joined[table]; table = previous[table].from {
+		steps = append([]joinPathStep{previous[table]}, steps...)
+	}
+	return steps
+}
+
+// containsJoinTable reports whether a table is in a list
+func containsJoinTable(tables []joinTable, table joinTable) bool {
+	for _, t := range tables {
+		if t == table {
+			return true
+		}
+	}
+	return false
+}
+
+// stringValue returns a string column value, or fallback when it is missing
+func stringValue(value interface{}, fallback string) string {
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			return v
+		}
+	case []byte:
+		if len(v) > 0 {
+			return string(v)
+		}
+	}
+	return fallback
+}
@@ -0,0 +1,74 @@
+package dbtools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func joinRelationships() []map[string]interface{} {
+	fk := func(constraint, schema, table, column, foreignSchema, foreignTable, foreignColumn string) map[string]interface{} {
+		return map[string]interface{}{
+			"constraint_name": constraint, "table_schema": schema, "table_name": table, "column_name": column,
+			"foreign_table_schema": foreignSchema, "foreign_table_name": foreignTable, "foreign_column_name": foreignColumn,
+		}
+	}
+	return []map[string]interface{}{
+		fk("orders_customer_id_fkey", "public", "orders", "customer_id", "public", "customers", "id"),
+		fk("order_items_order_id_fkey", "public", "order_items", "order_id", "public", "orders", "id"),
+		fk("order_items_product_id_fkey", "public", "order_items", "product_id", "public", "products", "id"),
+		fk("shipments_item_fkey", "public", "shipments", "order_id", "public", "order_items", "order_id"),
+		fk("shipments_item_fkey", "public", "shipments", "line_no", "public", "order_items", "line_no"),
+		fk("events_customer_id_fkey", "audit", "events", "customer_id", "public", "customers", "id"),
+	}
+}
+
+func TestFindJoinPathThroughIntermediateTable(t *testing.T) {
+	path, err := FindJoinPath("postgres", "", joinRelationships(), []string{"customers", "products"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "public", path.Schema)
+	assert.Equal(t, "customers", path.From)
+	assert.Equal(t, []string{"orders", "order_items"}, path.Via)
+	require.Len(t, path.Joins, 3)
+	assert.Equal(t, JoinStep{Table: "orders", To: "customers", Constraint: "orders_customer_id_fkey", On: `"orders"."customer_id" = "customers"."id"`}, path.Joins[0])
+	assert.Equal(t, `"order_items"."order_id" = "orders"."id"`, path.Joins[1].On)
+	assert.Equal(t, "products", path.Joins[2].Table)
+
+	query, err := path.Query("postgres")
+	require.NoError(t, err)
+	assert.Equal(t, `SELECT * FROM "public"."customers"`+
+		` INNER JOIN "public"."orders" ON "orders"."customer_id" = "customers"."id"`+
+		` INNER JOIN "public"."order_items" ON "order_items"."order_id" = "orders"."id"`+
+		` INNER JOIN "public"."products" ON "order_items"."product_id" = "products"."id" LIMIT 100`, query)
+}
+
+func TestFindJoinPathCompositeAndQualified(t *testing.T) {
+	path, err := FindJoinPath("mysql", "public", joinRelationships(), []string{"shipments", "order_items", "audit.events"})
+	require.NoError(t, err)
+
+	assert.Equal(t, "`shipments`.`order_id` = `order_items`.`order_id` AND `shipments`.`line_no` = `order_items`.`line_no`", path.Joins[0].On)
+	last := path.Joins[len(path.Joins)-1]
+	assert.Equal(t, "audit.events", last.Table)
+	assert.Equal(t, "customers", last.To)
+
+	query, err := path.Query("mysql")
+	require.NoError(t, err)
+	assert.Contains(t, query, "INNER JOIN `audit`.`events` ON `events`.`customer_id` = `customers`.`id`")
+}
+
+func TestFindJoinPathErrors(t *testing.T) {
+	_, err := FindJoinPath("postgres", "", joinRelationships(), []string{"customers"})
+	assert.ErrorContains(t, err, "at least two tables")
+
+	_, err = FindJoinPath("postgres", "", joinRelationships(), []string{"customers", "invoices"})
+	assert.ErrorContains(t, err, "table invoices not found")
+
+	islands := append(joinRelationships(), map[string]interface{}{
+		"constraint_name": "tags_parent_fkey", "table_schema": "public", "table_name": "tags", "column_name": "parent_id",
+		"foreign_table_schema": "public", "foreign_table_name": "tag_groups", "foreign_column_name": "id",
+	})
+	_, err = FindJoinPath("postgres", "", islands, []string{"customers", "tags"})
+	assert.ErrorContains(t, err, "no foreign key path connects tags to customers")
+}
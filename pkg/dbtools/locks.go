@@ -0,0 +1,152 @@
+package dbtools
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+)
+
+// LockWait is a session waiting for a lock another session holds
+type LockWait struct {
+	WaitingPID       int64
+	WaitingUser      string
+	WaitingQuery     string
+	WaitDuration     time.Duration // since the waiting query started
+	LockType         string
+	LockMode         string
+	Object           string // locked relation, where known
+	BlockingPID      int64
+	BlockingUser     string
+	BlockingQuery    string // the running or, for idle sessions, the last query
+	BlockingState    string
+	BlockingDuration time.Duration // age of the blocking transaction
+}
+
+// pgLockWaitsQuery pairs each waiting session of the database with the
+// sessions blocking it, and the lock it waits for
+const pgLockWaitsQuery = `
+	SELECT w.pid AS waiting_pid, w.usename AS waiting_user, w.query AS waiting_query,
+	       EXTRACT(EPOCH FROM now() - w.query_start) * 1000 AS waiting_ms,
+	       l.locktype AS lock_type, l.mode AS lock_mode, l.relation::regclass::text AS object,
+	       b.pid AS blocking_pid, b.usename AS blocking_user, b.query AS blocking_query, b.state AS blocking_state,
+	       EXTRACT(EPOCH FROM now() - b.xact_start) * 1000 AS blocking_ms
+	FROM pg_catalog.pg_stat_activity w
+	CROSS JOIN LATERAL unnest(pg_catalog.pg_blocking_pids(w.pid)) AS blocker(pid)
+	JOIN pg_catalog.pg_stat_activity b ON b.pid = blocker.pid
+	LEFT JOIN pg_catalog.pg_locks l ON l.pid = w.pid AND NOT l.granted
+	WHERE w.datname = current_database()
+	ORDER BY waiting_ms DESC NULLS LAST, w.pid, b.pid`
+
+// mysqlLockWaitsQuery reads InnoDB row lock waits from the sys schema, which
+// is built on performance_schema.data_lock_waits in MySQL 8.0
+const mysqlLockWaitsQuery = `
+	SELECT w.waiting_pid AS waiting_pid, wp.user AS waiting_user, w.waiting_query AS waiting_query,
+	       w.wait_age_secs * 1000 AS waiting_ms,
+	       w.locked_type AS lock_type, w.waiting_lock_mode AS lock_mode, w.locked_table AS object,
+	       w.blocking_pid AS blocking_pid, bp.user AS blocking_user, w.blocking_query AS blocking_query,
+	       bp.command AS blocking_state, TIME_TO_SEC(w.blocking_trx_age) * 1000 AS blocking_ms
+	FROM sys.innodb_lock_waits w
+	LEFT JOIN information_schema.processlist wp ON wp.id = w.waiting_pid
+	LEFT JOIN information_schema.processlist bp ON bp.id = w.blocking_pid
+	ORDER BY w.wait_age_secs DESC`
+
+// GetLockWaits lists the sessions of a database waiting for locks, each with
+// a session blocking it
+func GetLockWaits(ctx context.Context, dbID string) ([]LockWait, error) {
+	database, err := GetDatabase(dbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database: %w", err)
+	}
+	return lockWaits(ctx, database)
+}
+
+// lockWaits reads the lock waits of a database
+func lockWaits(ctx context.Context, database db.Database) ([]LockWait, error) {
+	var query string
+	switch database.DriverName() {
+	case "postgres":
+		query = pgLockWaitsQuery
+	case "mysql":
+		query = mysqlLockWaitsQuery
+	default:
+		return nil, fmt.Errorf("lock inspection is not supported for %s databases", database.DriverName())
+	}
+
+	rows, err := database.Query(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock waits: %w", err)
+	}
+	defer cleanupRows(rows)
+	results, err := rowsToMaps(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read lock waits: %w", err)
+	}
+
+	waits := make([]LockWait, 0, len(results))
+	for _, row := range results {
+		waits = append(waits, LockWait{
+			WaitingPID:       int64(numberValue(row["waiting_pid"])),
+			WaitingUser:      stringValue(row["waiting_user"], ""),
+			WaitingQuery:     stringValue(row["waiting_query"], ""),
+			WaitDuration:     millisecondsDuration(row["waiting_ms"]),
+			LockType:         stringValue(row["lock_type"], ""),
+			LockMode:         stringValue(row["lock_mode"], ""),
+			Object:           stringValue(row["object"], ""),
+			BlockingPID:      int64(numberValue(row["blocking_pid"])),
+			BlockingUser:     stringValue(row["blocking_user"], ""),
+			BlockingQuery:    stringValue(row["blocking_query"], ""),
+			BlockingState:    stringValue(row["blocking_state"], ""),
+			BlockingDuration: millisecondsDuration(row["blocking_ms"]),
+		})
+	}
+	return waits, nil
+}
+
+// SignalSession cancels the running query of a session or terminates it.
+// On PostgreSQL the pid is a backend pid, on MySQL a processlist id.
+func SignalSession(ctx context.Context, dbID string, pid int64, signal string) error {
+	database, err := GetDatabase(dbID)
+	if err != nil {
+		return fmt.Errorf("failed to get database: %w", err)
+	}
+	return signalSession(ctx, database, pid, signal)
+}
+
+// signalSession signals a session of a database
+func signalSession(ctx context.Context, database db.Database, pid int64, signal string) error {
+	if signal != db.SessionCancel && signal != db.SessionTerminate {
+		return fmt.Errorf("invalid session signal %q: use cancel or terminate", signal)
+	}
+
+	switch database.DriverName() {
+	case "postgres":
+		function := "pg_cancel_backend"
+		if signal == db.SessionTerminate {
+			function = "pg_terminate_backend"
+		}
+		var signalled bool
+		if err := database.QueryRow(ctx, "SELECT pg_catalog."+function+"($1)", pid).Scan(&signalled); err != nil {
+			return fmt.Errorf("failed to %s session %d: %w", signal, pid, err)
+		}
+		if !signalled {
+			return fmt.Errorf("failed to %s session %d: it is not running", signal, pid)
+		}
+		return nil
+
+	case "mysql":
+		// KILL takes no placeholders; the id is an integer
+		statement := fmt.Sprintf("KILL QUERY %d", pid)
+		if signal == db.SessionTerminate {
+			statement = fmt.Sprintf("KILL CONNECTION %d", pid)
+		}
+		if _, err := database.Exec(ctx, statement); err != nil {
+			return fmt.Errorf("failed to %s session %d: %w", signal, pid, err)
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("session control is not supported for %s databases", database.DriverName())
+	}
+}
@@ -3,29 +3,73 @@ package dbtools
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
+	"math"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/FreePeak/db-mcp-server/pkg/logger"
 )
 
-// QueryMetrics stores performance metrics for a database query
+const (
+	// defaultMaxHistory is the number of executions kept in the history
+	defaultMaxHistory = 100
+	// maxFingerprints bounds the fingerprints tracked; the least recently
+	// executed one is dropped to make room for a new one
+	maxFingerprints = 1000
+	// maxLatencySamples bounds the latencies kept per fingerprint for its
+	// percentiles
+	maxLatencySamples = 1024
+)
+
+// QueryMetrics stores performance metrics for the executions of a query
+// fingerprint, i.e. a query with its literals replaced by placeholders
 type QueryMetrics struct {
-	Query         string        // SQL query text
+	Fingerprint   string        // Hash of the normalized query
+	Query         string        // Normalized query text
 	Count         int           // Number of times the query was executed
+	Errors        int           // Executions that failed
+	Rows          int64         // Rows returned or affected
+	RowsExamined  int64         // Rows read, where the database reports it
+	BlocksHit     int64         // Shared buffer hits, where the database reports them
+	BlocksRead    int64         // Blocks read from disk, where the database reports them
+	IOTime        time.Duration // Time spent reading and writing blocks
 	TotalDuration time.Duration // Total execution time
 	MinDuration   time.Duration // Minimum execution time
 	MaxDuration   time.Duration // Maximum execution time
 	AvgDuration   time.Duration // Average execution time
+	P50Duration   time.Duration // Median of the recent execution times
+	P95Duration   time.Duration // 95th percentile of the recent execution times
+	P99Duration   time.Duration // 99th percentile of the recent execution times
+	FirstExecuted time.Time     // When the query was first executed
 	LastExecuted  time.Time     // When the query was last executed
+	Sample        string        // A recent execution with its literals, for planning
+	SampleParams  []interface{} // The parameters of the sample
 }
 
-// PerformanceAnalyzer tracks query performance and provides optimization suggestions
+// PerformanceAnalyzer tracks the query performance of a connection and
+// provides optimization suggestions. It is safe for concurrent use.
 type PerformanceAnalyzer struct {
+	mu            sync.Mutex
 	slowThreshold time.Duration
-	queryHistory  []QueryRecord
-	maxHistory    int
+	history       queryHistory
+	fingerprints  map[string]*fingerprintStats
+	stats         PerformanceStats
+	plans         *PlanTracker // checks the plans of frequent queries, when configured
+}
+
+// PerformanceStats are the totals over every query recorded since the last
+// reset, including those that dropped out of the history
+type PerformanceStats struct {
+	TotalQueries  int
+	SlowQueries   int
+	Errors        int
+	TotalDuration time.Duration
+	MaxDuration   time.Duration
+	ResetTime     time.Time
 }
 
 // QueryRecord stores information about a query execution
@@ -34,39 +78,59 @@ type QueryRecord struct {
 	Params     []interface{} `json:"params"`
 	Duration   time.Duration `json:"duration"`
 	StartTime  time.Time     `json:"startTime"`
+	Rows       int64         `json:"rows"`
 	Error      string        `json:"error,omitempty"`
 	Optimized  bool          `json:"optimized"`
 	Suggestion string        `json:"suggestion,omitempty"`
 }
 
-// SQLIssueDetector detects potential issues in SQL queries
-type SQLIssueDetector struct {
-	patterns map[string]*regexp.Regexp
+// queryHistory is a ring buffer of the most recent executions
+type queryHistory struct {
+	records []QueryRecord
+	next    int // index the next record is written to
+	full    bool
 }
 
-// singleton instance
-var performanceAnalyzer *PerformanceAnalyzer
+// fingerprintStats aggregates the executions of a fingerprint. Latencies
+// is a ring buffer of the most recent execution times.
+type fingerprintStats struct {
+	metrics   QueryMetrics
+	latencies []time.Duration
+	next      int
+}
+
+// per-connection analyzers
+var (
+	analyzersMu sync.Mutex
+	analyzers   = make(map[string]*PerformanceAnalyzer)
+)
 
-// GetPerformanceAnalyzer returns the singleton performance analyzer
-func GetPerformanceAnalyzer() *PerformanceAnalyzer {
-	if performanceAnalyzer == nil {
-		performanceAnalyzer = NewPerformanceAnalyzer()
+// GetPerformanceAnalyzer returns the performance analyzer of a connection,
+// creating it on first use
+func GetPerformanceAnalyzer(dbID string) *PerformanceAnalyzer {
+	analyzersMu.Lock()
+	defer analyzersMu.Unlock()
+	analyzer, ok := analyzers[dbID]
+	if !ok {
+		analyzer = NewPerformanceAnalyzer()
+		analyzers[dbID] = analyzer
 	}
-	return performanceAnalyzer
+	return analyzer
 }
 
 // NewPerformanceAnalyzer creates a new performance analyzer
 func NewPerformanceAnalyzer() *PerformanceAnalyzer {
 	return &PerformanceAnalyzer{
 		slowThreshold: 500 * time.Millisecond, // Default: 500ms
-		queryHistory:  make([]QueryRecord, 0),
-		maxHistory:    100, // Default: store last 100 queries
+		history:       queryHistory{records: make([]QueryRecord, defaultMaxHistory)},
+		fingerprints:  make(map[string]*fingerprintStats),
+		stats:         PerformanceStats{ResetTime: time.Now()},
 	}
 }
 
 // LogSlowQuery logs a warning if a query takes longer than the slow query threshold
 func (pa *PerformanceAnalyzer) LogSlowQuery(query string, params []interface{}, duration time.Duration) {
-	if duration >= pa.slowThreshold {
+	if duration >= pa.GetSlowThreshold() {
 		paramStr := formatParams(params)
 		logger.Warn("Slow query detected (%.2fms): %s [params: %s]",
 			float64(duration.Microseconds())/1000.0,
@@ -75,108 +139,318 @@ func (pa *PerformanceAnalyzer) LogSlowQuery(query string, params []interface{},
 	}
 }
 
-// TrackQuery tracks the execution of a query and logs slow queries
+// TrackQuery tracks the execution of a query and logs slow queries. Rows
+// are taken from the rowCount or rowsAffected of a map result.
 func (pa *PerformanceAnalyzer) TrackQuery(ctx context.Context, query string, params []interface{}, exec func() (interface{}, error)) (interface{}, error) {
 	startTime := time.Now()
 	result, err := exec()
 	duration := time.Since(startTime)
 
-	// Create query record
+	var rows int64
+	if values, ok := result.(map[string]interface{}); ok {
+		switch count := values["rowCount"].(type) {
+		case int:
+			rows = int64(count)
+		case int64:
+			rows = count
+		}
+		if affected, ok := values["rowsAffected"].(int64); ok && affected > 0 {
+			rows = affected
+		}
+	}
+	pa.RecordQuery(query, params, startTime, duration, rows, err)
+	return result, err
+}
+
+// RecordQuery records a query execution that started at startTime, took
+// duration and returned or affected rows, logging it when it is slow
+func (pa *PerformanceAnalyzer) RecordQuery(query string, params []interface{}, startTime time.Time, duration time.Duration, rows int64, err error) {
 	record := QueryRecord{
 		Query:     query,
 		Params:    params,
 		Duration:  duration,
 		StartTime: startTime,
+		Rows:      rows,
+	}
+	if err != nil {
+		record.Error = err.Error()
 	}
 
-	// Check if query is slow
-	if duration >= pa.slowThreshold {
-		pa.LogSlowQuery(query, params, duration)
+	pa.mu.Lock()
+	slow := duration >= pa.slowThreshold
+	if slow {
 		record.Suggestion = "Query execution time exceeds threshold"
+		pa.stats.SlowQueries++
+	}
+	pa.stats.TotalQueries++
+	pa.stats.TotalDuration += duration
+	if duration > pa.stats.MaxDuration {
+		pa.stats.MaxDuration = duration
 	}
-
-	// Record error if any
 	if err != nil {
-		record.Error = err.Error()
+		pa.stats.Errors++
+	}
+	pa.history.add(record)
+	stats := pa.fingerprint(query, startTime)
+	stats.add(record)
+	plans := pa.plans
+	var metrics *QueryMetrics
+	if plans != nil && err == nil {
+		metrics = stats.snapshot()
 	}
+	pa.mu.Unlock()
 
-	// Add to history (keeping max size)
-	pa.queryHistory = append(pa.queryHistory, record)
-	if len(pa.queryHistory) > pa.maxHistory {
-		pa.queryHistory = pa.queryHistory[1:]
+	if slow {
+		pa.LogSlowQuery(query, params, duration)
+	}
+	if metrics != nil {
+		plans.observe(metrics)
 	}
+}
 
-	return result, err
+// SetPlanTracker makes the analyzer check the plans of frequent queries for
+// regressions
+func (pa *PerformanceAnalyzer) SetPlanTracker(tracker *PlanTracker) {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	pa.plans = tracker
 }
 
-// SQLIssueDetector methods
+// PlanTracker returns the plan tracker of the analyzer, or nil when plan
+// regressions are not tracked
+func (pa *PerformanceAnalyzer) PlanTracker() *PlanTracker {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	return pa.plans
+}
 
-// NewSQLIssueDetector creates a new SQL issue detector
-func NewSQLIssueDetector() *SQLIssueDetector {
-	detector := &SQLIssueDetector{
-		patterns: make(map[string]*regexp.Regexp),
+// fingerprint returns the stats of a query's fingerprint, making room for it
+// when it is new. The caller holds the lock.
+func (pa *PerformanceAnalyzer) fingerprint(query string, now time.Time) *fingerprintStats {
+	normalized := normalizeQuery(query)
+	stats, ok := pa.fingerprints[normalized]
+	if ok {
+		return stats
 	}
 
-	// Add known issue patterns
-	detector.AddPattern("cartesian-join", `SELECT.*FROM\s+(\w+)\s*,\s*(\w+)`)
-	detector.AddPattern("select-star", `SELECT\s+\*\s+FROM`)
-	detector.AddPattern("missing-where", `(DELETE\s+FROM|UPDATE)\s+\w+\s+(?:SET\s+(?:\w+\s*=\s*[^,]+)(?:\s*,\s*\w+\s*=\s*[^,]+)*\s*)*(;|\z)`)
-	detector.AddPattern("or-in-where", `WHERE.*\s+OR\s+`)
-	detector.AddPattern("in-with-many-items", `IN\s*\(\s*(?:'[^']*'\s*,\s*){10,}`)
-	detector.AddPattern("not-in", `NOT\s+IN\s*\(`)
-	detector.AddPattern("is-null", `IS\s+NULL`)
-	detector.AddPattern("function-on-column", `WHERE\s+\w+\s*\(\s*\w+\s*\)`)
-	detector.AddPattern("order-by-rand", `ORDER\s+BY\s+RAND\(\)`)
-	detector.AddPattern("group-by-number", `GROUP\s+BY\s+\d+`)
-	detector.AddPattern("having-without-group", `HAVING.*(?:(?:GROUP\s+BY.*$)|(?:$))`)
+	if len(pa.fingerprints) >= maxFingerprints {
+		var oldest string
+		for key, candidate := range pa.fingerprints {
+			if oldest == "" || candidate.metrics.LastExecuted.Before(pa.fingerprints[oldest].metrics.LastExecuted) {
+				oldest = key
+			}
+		}
+		delete(pa.fingerprints, oldest)
+	}
+	stats = &fingerprintStats{metrics: QueryMetrics{
+		Fingerprint:   fingerprintHash(normalized),
+		Query:         normalized,
+		FirstExecuted: now,
+	}}
+	pa.fingerprints[normalized] = stats
+	return stats
+}
 
-	return detector
+// GetSlowQueries returns up to limit recorded queries that reached the slow
+// query threshold, slowest first; a limit of zero or less returns them all
+func (pa *PerformanceAnalyzer) GetSlowQueries(limit int) []QueryRecord {
+	pa.mu.Lock()
+	var slow []QueryRecord
+	for _, record := range pa.history.list() {
+		if record.Duration >= pa.slowThreshold {
+			slow = append(slow, record)
+		}
+	}
+	pa.mu.Unlock()
+
+	sort.SliceStable(slow, func(i, j int) bool { return slow[i].Duration > slow[j].Duration })
+	if limit > 0 && len(slow) > limit {
+		slow = slow[:limit]
+	}
+	return slow
 }
 
-// AddPattern adds a pattern for detecting SQL issues
-func (d *SQLIssueDetector) AddPattern(name, pattern string) {
-	re, err := regexp.Compile("(?i)" + pattern)
-	if err != nil {
-		logger.Error("Error compiling regex pattern '%s': %v", pattern, err)
-		return
+// GetHistory returns the recorded executions still in the history, oldest
+// first
+func (pa *PerformanceAnalyzer) GetHistory() []QueryRecord {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	return pa.history.list()
+}
+
+// GetStats returns the totals recorded since the last reset
+func (pa *PerformanceAnalyzer) GetStats() PerformanceStats {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	return pa.stats
+}
+
+// GetQueryMetrics returns the metrics of the fingerprint of a query, or nil
+// when it has not been recorded
+func (pa *PerformanceAnalyzer) GetQueryMetrics(query string) *QueryMetrics {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	stats, ok := pa.fingerprints[normalizeQuery(query)]
+	if !ok {
+		return nil
 	}
-	d.patterns[name] = re
+	return stats.snapshot()
 }
 
-// DetectIssues detects issues in a SQL query
-func (d *SQLIssueDetector) DetectIssues(query string) map[string]string {
-	issues := make(map[string]string)
+// GetAllMetrics returns the metrics of every tracked fingerprint
+func (pa *PerformanceAnalyzer) GetAllMetrics() []*QueryMetrics {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	metrics := make([]*QueryMetrics, 0, len(pa.fingerprints))
+	for _, stats := range pa.fingerprints {
+		metrics = append(metrics, stats.snapshot())
+	}
+	return metrics
+}
+
+// queryMetricsOrders rank fingerprints for TopQueries, highest first
+var queryMetricsOrders = map[string]func(a, b *QueryMetrics) bool{
+	"total_time": func(a, b *QueryMetrics) bool { return a.TotalDuration > b.TotalDuration },
+	"mean_time":  func(a, b *QueryMetrics) bool { return a.AvgDuration > b.AvgDuration },
+	"max_time":   func(a, b *QueryMetrics) bool { return a.MaxDuration > b.MaxDuration },
+	"p95_time":   func(a, b *QueryMetrics) bool { return a.P95Duration > b.P95Duration },
+	"calls":      func(a, b *QueryMetrics) bool { return a.Count > b.Count },
+	"errors":     func(a, b *QueryMetrics) bool { return a.Errors > b.Errors },
+	"rows":       func(a, b *QueryMetrics) bool { return a.Rows > b.Rows },
+}
 
-	for name, pattern := range d.patterns {
-		if pattern.MatchString(query) {
-			issues[name] = d.getSuggestionForIssue(name)
+// TopQueries returns up to limit fingerprints ranked by orderBy: total_time
+// (the default), mean_time, max_time, p95_time, calls, errors or rows
+func (pa *PerformanceAnalyzer) TopQueries(orderBy string, limit int) ([]*QueryMetrics, error) {
+	if orderBy == "" {
+		orderBy = "total_time"
+	}
+	less, ok := queryMetricsOrders[orderBy]
+	if !ok {
+		return nil, fmt.Errorf("invalid order %q: use total_time, mean_time, max_time, p95_time, calls, errors or rows", orderBy)
+	}
+
+	metrics := pa.GetAllMetrics()
+	sort.SliceStable(metrics, func(i, j int) bool {
+		if less(metrics[i], metrics[j]) != less(metrics[j], metrics[i]) {
+			return less(metrics[i], metrics[j])
 		}
+		return metrics[i].Query < metrics[j].Query
+	})
+	if limit > 0 && len(metrics) > limit {
+		metrics = metrics[:limit]
 	}
+	return metrics, nil
+}
+
+// Reset clears all collected metrics
+func (pa *PerformanceAnalyzer) Reset() {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	pa.history = queryHistory{records: make([]QueryRecord, len(pa.history.records))}
+	pa.fingerprints = make(map[string]*fingerprintStats)
+	pa.stats = PerformanceStats{ResetTime: time.Now()}
+}
 
-	return issues
+// GetSlowThreshold returns the current slow query threshold
+func (pa *PerformanceAnalyzer) GetSlowThreshold() time.Duration {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	return pa.slowThreshold
 }
 
-// getSuggestionForIssue returns a suggestion for a detected issue
-func (d *SQLIssueDetector) getSuggestionForIssue(issue string) string {
-	suggestions := map[string]string{
-		"cartesian-join":       "Use explicit JOIN statements instead of comma-syntax joins to avoid unintended Cartesian products.",
-		"select-star":          "Specify exact columns needed instead of SELECT * to reduce network traffic and improve query execution.",
-		"missing-where":        "Add a WHERE clause to avoid affecting all rows in the table.",
-		"or-in-where":          "Consider using IN instead of multiple OR conditions for better performance.",
-		"in-with-many-items":   "Too many items in IN clause; consider a temporary table or a JOIN instead.",
-		"not-in":               "NOT IN with subqueries can be slow. Consider using NOT EXISTS or LEFT JOIN/IS NULL pattern.",
-		"is-null":              "IS NULL conditions prevent index usage. Consider redesigning to avoid NULL values if possible.",
-		"function-on-column":   "Applying functions to columns in WHERE clauses prevents index usage. Restructure if possible.",
-		"order-by-rand":        "ORDER BY RAND() causes full table scan and sort. Consider alternative randomization methods.",
-		"group-by-number":      "Using column position numbers in GROUP BY can be error-prone. Use explicit column names.",
-		"having-without-group": "HAVING without GROUP BY may indicate a logical error in query structure.",
+// SetSlowThreshold sets the slow query threshold
+func (pa *PerformanceAnalyzer) SetSlowThreshold(threshold time.Duration) {
+	pa.mu.Lock()
+	defer pa.mu.Unlock()
+	pa.slowThreshold = threshold
+}
+
+// add writes a record over the oldest one once the buffer is full
+func (h *queryHistory) add(record QueryRecord) {
+	h.records[h.next] = record
+	h.next = (h.next + 1) % len(h.records)
+	if h.next == 0 {
+		h.full = true
 	}
+}
 
-	if suggestion, ok := suggestions[issue]; ok {
-		return suggestion
+// list returns a copy of the records, oldest first
+func (h *queryHistory) list() []QueryRecord {
+	if !h.full {
+		return append([]QueryRecord(nil), h.records[:h.next]...)
 	}
+	return append(append([]QueryRecord(nil), h.records[h.next:]...), h.records[:h.next]...)
+}
 
-	return "Potential issue detected; review query for optimization opportunities."
+// add counts an execution of the fingerprint
+func (s *fingerprintStats) add(record QueryRecord) {
+	m := &s.metrics
+	if m.Count == 0 || record.Duration < m.MinDuration {
+		m.MinDuration = record.Duration
+	}
+	if record.Duration > m.MaxDuration {
+		m.MaxDuration = record.Duration
+	}
+	m.Count++
+	m.TotalDuration += record.Duration
+	m.Rows += record.Rows
+	if record.Error != "" {
+		m.Errors++
+	}
+	if record.StartTime.After(m.LastExecuted) {
+		m.LastExecuted = record.StartTime
+	}
+	if record.Error == "" || m.Sample == "" {
+		m.Sample, m.SampleParams = record.Query, record.Params
+	}
+
+	if len(s.latencies) < maxLatencySamples {
+		s.latencies = append(s.latencies, record.Duration)
+	} else {
+		s.latencies[s.next] = record.Duration
+		s.next = (s.next + 1) % maxLatencySamples
+	}
+}
+
+// snapshot returns a copy of the metrics with the average and percentiles
+// filled in
+func (s *fingerprintStats) snapshot() *QueryMetrics {
+	metrics := s.metrics
+	if metrics.Count > 0 {
+		metrics.AvgDuration = metrics.TotalDuration / time.Duration(metrics.Count)
+	}
+	sorted := append([]time.Duration(nil), s.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	metrics.P50Duration = percentile(sorted, 50)
+	metrics.P95Duration = percentile(sorted, 95)
+	metrics.P99Duration = percentile(sorted, 99)
+	return &metrics
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// QueryFingerprint returns the fingerprint of a query: a hash of its text
+// with literals replaced by placeholders
+func QueryFingerprint(query string) string {
+	return fingerprintHash(normalizeQuery(query))
+}
+
+// fingerprintHash hashes a normalized query
+func fingerprintHash(normalized string) string {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(normalized))
+	return fmt.Sprintf("%016x", h.Sum64())
 }
 
 // Helper functions
@@ -227,79 +501,11 @@ func StripComments(input string) string {
 	return singleLineRe.ReplaceAllString(withoutMultiLine, "")
 }
 
-// GetAllMetrics returns all collected metrics
-func (pa *PerformanceAnalyzer) GetAllMetrics() []*QueryMetrics {
-	// Group query history by normalized query text
-	queryMap := make(map[string]*QueryMetrics)
-
-	for _, record := range pa.queryHistory {
-		normalizedQuery := normalizeQuery(record.Query)
-
-		metrics, exists := queryMap[normalizedQuery]
-		if !exists {
-			metrics = &QueryMetrics{
-				Query:        record.Query,
-				MinDuration:  record.Duration,
-				MaxDuration:  record.Duration,
-				LastExecuted: record.StartTime,
-			}
-			queryMap[normalizedQuery] = metrics
-		}
-
-		// Update metrics
-		metrics.Count++
-		metrics.TotalDuration += record.Duration
-
-		if record.Duration < metrics.MinDuration {
-			metrics.MinDuration = record.Duration
-		}
-
-		if record.Duration > metrics.MaxDuration {
-			metrics.MaxDuration = record.Duration
-		}
-
-		if record.StartTime.After(metrics.LastExecuted) {
-			metrics.LastExecuted = record.StartTime
-		}
-	}
-
-	// Calculate averages and convert to slice
-	metrics := make([]*QueryMetrics, 0, len(queryMap))
-	for _, m := range queryMap {
-		m.AvgDuration = time.Duration(int64(m.TotalDuration) / int64(m.Count))
-		metrics = append(metrics, m)
-	}
-
-	return metrics
-}
-
-// Reset clears all collected metrics
-func (pa *PerformanceAnalyzer) Reset() {
-	pa.queryHistory = make([]QueryRecord, 0)
-}
-
-// GetSlowThreshold returns the current slow query threshold
-func (pa *PerformanceAnalyzer) GetSlowThreshold() time.Duration {
-	return pa.slowThreshold
-}
-
-// SetSlowThreshold sets the slow query threshold
-func (pa *PerformanceAnalyzer) SetSlowThreshold(threshold time.Duration) {
-	pa.slowThreshold = threshold
-}
-
-// AnalyzeQuery analyzes a SQL query and returns optimization suggestions
+// AnalyzeQuery returns general suggestions from the text of a query. The
+// issues of its plan are found by QueryPlan.Issues.
 func AnalyzeQuery(query string) []string {
-	// Create detector and get suggestions
-	detector := NewSQLIssueDetector()
-	issues := detector.DetectIssues(query)
-
-	suggestions := make([]string, 0, len(issues))
-	for _, suggestion := range issues {
-		suggestions = append(suggestions, suggestion)
-	}
+	var suggestions []string
 
-	// Add default suggestions for query patterns
 	if strings.Contains(strings.ToUpper(query), "SELECT *") {
 		suggestions = append(suggestions, "Avoid using SELECT * - specify only the columns you need")
 	}
@@ -325,26 +531,28 @@ func AnalyzeQuery(query string) []string {
 	return suggestions
 }
 
+// Patterns normalizeQuery replaces, compiled once as every recorded query is
+// normalized
+var (
+	whitespacePattern  = regexp.MustCompile(`\s+`)
+	numberPattern      = regexp.MustCompile(`\b\d+\b`)
+	stringPattern      = regexp.MustCompile(`'[^']*'`)
+	doubleQuotePattern = regexp.MustCompile(`"[^"]*"`)
+)
+
 // normalizeQuery standardizes SQL queries for comparison by replacing literals
 func normalizeQuery(query string) string {
 	// Trim and normalize whitespace
-	query = strings.TrimSpace(query)
-	wsRegex := regexp.MustCompile(`\s+`)
-	query = wsRegex.ReplaceAllString(query, " ")
+	query = whitespacePattern.ReplaceAllString(strings.TrimSpace(query), " ")
 
 	// Replace numeric literals
-	numRegex := regexp.MustCompile(`\b\d+\b`)
-	query = numRegex.ReplaceAllString(query, "?")
+	query = numberPattern.ReplaceAllString(query, "?")
 
 	// Replace string literals in single quotes
-	strRegex := regexp.MustCompile(`'[^']*'`)
-	query = strRegex.ReplaceAllString(query, "'?'")
+	query = stringPattern.ReplaceAllString(query, "'?'")
 
 	// Replace string literals in double quotes
-	dblQuoteRegex := regexp.MustCompile(`"[^"]*"`)
-	query = dblQuoteRegex.ReplaceAllString(query, "\"?\"")
-
-	return query
+	return doubleQuotePattern.ReplaceAllString(query, "\"?\"")
 }
 
 // TODO: Implement more sophisticated performance metrics and query analysis
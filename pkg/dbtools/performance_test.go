@@ -2,13 +2,19 @@ package dbtools
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/FreePeak/db-mcp-server/internal/logger"
 )
 
 func TestPerformanceAnalyzer(t *testing.T) {
-	// Get the global performance analyzer and reset it to ensure clean state
-	analyzer := GetPerformanceAnalyzer()
+	// Get the connection's performance analyzer and reset it to ensure clean state
+	analyzer := GetPerformanceAnalyzer("test")
 	analyzer.Reset()
 
 	// Ensure we restore previous state after test
@@ -161,3 +167,90 @@ func TestNormalizeQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestPerformanceAnalyzerFingerprints(t *testing.T) {
+	logger.InitializeWithWriter("error", os.Stderr)
+	analyzer := NewPerformanceAnalyzer()
+	start := time.Now()
+	for i := 1; i <= 100; i++ {
+		query := fmt.Sprintf("SELECT name FROM users WHERE id = %d", i)
+		analyzer.RecordQuery(query, nil, start, time.Duration(i)*time.Millisecond, 1, nil)
+	}
+	analyzer.RecordQuery("SELECT name FROM users WHERE id = 0", nil, start, time.Millisecond, 0, errors.New("timeout"))
+	analyzer.RecordQuery("DELETE FROM sessions", nil, start, 10*time.Second, 40, nil)
+
+	metrics := analyzer.GetQueryMetrics("SELECT name FROM users WHERE id = 42")
+	if metrics == nil {
+		t.Fatal("Expected metrics for the users fingerprint")
+	}
+	if metrics.Count != 101 || metrics.Errors != 1 || metrics.Rows != 100 {
+		t.Errorf("Expected 101 executions, 1 error and 100 rows, got %d, %d and %d", metrics.Count, metrics.Errors, metrics.Rows)
+	}
+	if metrics.P50Duration != 50*time.Millisecond || metrics.P95Duration != 95*time.Millisecond || metrics.P99Duration != 99*time.Millisecond {
+		t.Errorf("Unexpected percentiles p50=%v p95=%v p99=%v", metrics.P50Duration, metrics.P95Duration, metrics.P99Duration)
+	}
+	if metrics.Fingerprint != QueryFingerprint("SELECT name FROM users WHERE id = 7") {
+		t.Errorf("Expected the fingerprint to ignore literals, got %s", metrics.Fingerprint)
+	}
+
+	top, err := analyzer.TopQueries("total_time", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(top) != 1 || top[0].Query != "DELETE FROM sessions" {
+		t.Errorf("Expected the DELETE to rank first by total time, got %+v", top)
+	}
+	top, _ = analyzer.TopQueries("calls", 0)
+	if len(top) != 2 || top[0].Count != 101 {
+		t.Errorf("Expected the users query to rank first by calls, got %+v", top)
+	}
+	if _, err := analyzer.TopQueries("bogus", 0); err == nil {
+		t.Error("Expected an error for an unknown order")
+	}
+
+	// The history keeps the last executions only, while the totals keep counting
+	history := analyzer.GetHistory()
+	if len(history) != defaultMaxHistory {
+		t.Fatalf("Expected %d records in the history, got %d", defaultMaxHistory, len(history))
+	}
+	if history[len(history)-1].Query != "DELETE FROM sessions" || history[0].Query != "SELECT name FROM users WHERE id = 3" {
+		t.Errorf("Expected the history to hold the latest records oldest first, got %q ... %q", history[0].Query, history[len(history)-1].Query)
+	}
+	if stats := analyzer.GetStats(); stats.TotalQueries != 102 || stats.SlowQueries != 1 {
+		t.Errorf("Expected 102 queries with 1 slow, got %d and %d", stats.TotalQueries, stats.SlowQueries)
+	}
+
+	analyzer.Reset()
+	if len(analyzer.GetAllMetrics()) != 0 || len(analyzer.GetHistory()) != 0 {
+		t.Error("Expected reset to clear fingerprints and history")
+	}
+}
+
+func TestPerformanceAnalyzerConcurrentUse(t *testing.T) {
+	analyzer := NewPerformanceAnalyzer()
+	var wg sync.WaitGroup
+	for worker := 0; worker < 8; worker++ {
+		wg.Add(1)
+		go func(worker int) {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				analyzer.RecordQuery(fmt.Sprintf("SELECT * FROM t%d WHERE id = %d", worker%2, i), nil, time.Now(), time.Microsecond, 1, nil)
+				if i%50 == 0 {
+					analyzer.GetAllMetrics()
+					analyzer.GetSlowQueries(5)
+				}
+			}
+		}(worker)
+	}
+	wg.Wait()
+
+	if stats := analyzer.GetStats(); stats.TotalQueries != 1600 {
+		t.Errorf("Expected 1600 recorded queries, got %d", stats.TotalQueries)
+	}
+	if metrics := analyzer.GetAllMetrics(); len(metrics) != 2 {
+		t.Errorf("Expected 2 fingerprints, got %d", len(metrics))
+	}
+	if GetPerformanceAnalyzer("a") == GetPerformanceAnalyzer("b") || GetPerformanceAnalyzer("a") != GetPerformanceAnalyzer("a") {
+		t.Error("Expected one analyzer per connection")
+	}
+}
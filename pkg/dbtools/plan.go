@@ -0,0 +1,651 @@
+package dbtools
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// Thresholds of the plan advice
+const (
+	largeTableRows     = 10000 // rows read by a full scan worth flagging
+	nestedLoopRows     = 1000  // outer rows of a nested loop worth flagging
+	estimateMissFactor = 10    // actual/estimated rows ratio worth flagging
+	estimateMissRows   = 100   // rows below which estimate misses do not matter
+	selectiveFilter    = 0.1   // fraction of scanned rows a filter keeps to be selective
+)
+
+// Explain modes, saying whether a query was executed to get its plan
+const (
+	ExplainOnly            = "explain"          // planned, not executed
+	ExplainAnalyze         = "analyze"          // executed
+	ExplainAnalyzeRollback = "analyze-rollback" // executed in a transaction that was rolled back
+)
+
+// QueryPlan is an EXPLAIN plan parsed from PostgreSQL or MySQL JSON output
+type QueryPlan struct {
+	Driver        string    `json:"driver"`
+	Mode          string    `json:"mode"`
+	Note          string    `json:"note,omitempty"` // why the query was not executed
+	Analyzed      bool      `json:"analyzed"`       // whether the query was executed, so actual rows are known
+	TotalCost     float64   `json:"totalCost"`
+	PlanningTime  float64   `json:"planningTimeMs,omitempty"`
+	ExecutionTime float64   `json:"executionTimeMs,omitempty"`
+	Root          *PlanNode `json:"root"`
+}
+
+// PlanNode is one operation of a plan. Rows are per loop; Loops is how often
+// the node ran, or is expected to run on MySQL.
+type PlanNode struct {
+	Operation     string      `json:"operation"`
+	Relation      string      `json:"relation,omitempty"`
+	Alias         string      `json:"alias,omitempty"` // how the query refers to the relation
+	Index         string      `json:"index,omitempty"`
+	PossibleKeys  []string    `json:"possibleKeys,omitempty"`
+	Cost          float64     `json:"cost"`
+	EstimatedRows float64     `json:"estimatedRows"`
+	ActualRows    float64     `json:"actualRows,omitempty"`
+	Loops         float64     `json:"loops,omitempty"`
+	RowsExamined  float64     `json:"rowsExamined,omitempty"` // rows a scan reads before filtering
+	ActualTime    float64     `json:"actualTimeMs,omitempty"`
+	Filter        string      `json:"filter,omitempty"`
+	JoinFilter    string      `json:"joinFilter,omitempty"` // join condition checked by a nested loop
+	SortMethod    string      `json:"sortMethod,omitempty"`
+	SortSpace     float64     `json:"sortSpaceKb,omitempty"`
+	SortSpaceType string      `json:"sortSpaceType,omitempty"`
+	Children      []*PlanNode `json:"children,omitempty"`
+}
+
+// PlanIssue is a problem found in a plan with a suggestion to fix it
+type PlanIssue struct {
+	Kind       string `json:"kind"` // seq-scan, missing-index, row-estimate, disk-sort or nested-loop
+	Relation   string `json:"relation,omitempty"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion"`
+}
+
+// ExplainPlan runs EXPLAIN in the JSON format of the database and parses the
+// plan. With analyze, PostgreSQL executes the query to report actual rows and
+// buffers, whatever the query does; AnalyzePlan executes data-modifying
+// statements safely. MySQL always only plans the query.
+func ExplainPlan(ctx context.Context, database db.Database, query string, analyze bool) (*QueryPlan, error) {
+	explain, err := explainStatement(database.DriverName(), query, analyze)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := database.Query(ctx, explain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	plan, err := readPlan(rows, database.DriverName())
+	if err != nil {
+		return nil, err
+	}
+	plan.Mode = ExplainOnly
+	if plan.Analyzed {
+		plan.Mode = ExplainAnalyze
+	}
+	return plan, nil
+}
+
+// AnalyzePlan executes a query under EXPLAIN ANALYZE to get its plan with
+// actual rows. A data-modifying statement is executed in a transaction that is
+// always rolled back; sequences it advances stay advanced. On a read-only
+// connection such a statement cannot run, so it is only planned when fallback
+// is set and refused otherwise. MySQL plans are never executed as its JSON
+// format has no ANALYZE.
+func AnalyzePlan(ctx context.Context, database db.Database, query string, fallback bool) (*QueryPlan, error) {
+	if database.DriverName() != "postgres" {
+		plan, err := ExplainPlan(ctx, database, query, false)
+		if err != nil {
+			return nil, err
+		}
+		plan.Note = fmt.Sprintf("EXPLAIN ANALYZE has no JSON format on %s, so the query was only planned", database.DriverName())
+		return plan, nil
+	}
+	if !IsDataModifying(query) {
+		return ExplainPlan(ctx, database, query, true)
+	}
+
+	var readOnly string
+	if err := database.QueryRow(ctx, "SELECT current_setting('transaction_read_only')").Scan(&readOnly); err != nil {
+		return nil, fmt.Errorf("failed to check whether the connection is read-only: %w", err)
+	}
+	if readOnly == "on" {
+		if !fallback {
+			return nil, fmt.Errorf("cannot analyze a data-modifying statement on a read-only connection; set explainFallback to only plan it")
+		}
+		plan, err := ExplainPlan(ctx, database, query, false)
+		if err != nil {
+			return nil, err
+		}
+		plan.Note = "the connection is read-only, so the data-modifying statement was only planned"
+		return plan, nil
+	}
+
+	return analyzeInRollback(ctx, database, query)
+}
+
+// analyzeInRollback runs EXPLAIN ANALYZE on a statement in a transaction and
+// rolls it back
+func analyzeInRollback(ctx context.Context, database db.Database, query string) (*QueryPlan, error) {
+	explain, err := explainStatement(database.DriverName(), query, true)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := database.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		// A cancelled context already rolled the transaction back
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logger.Error("error rolling back analyzed statement: %v", err)
+		}
+	}()
+
+	rows, err := tx.QueryContext(ctx, explain)
+	if err != nil {
+		return nil, fmt.Errorf("failed to explain query: %w", err)
+	}
+	plan, err := readPlan(rows, database.DriverName())
+	if err != nil {
+		return nil, err
+	}
+	plan.Mode = ExplainAnalyzeRollback
+	return plan, nil
+}
+
+// explainStatement prefixes a query with the JSON EXPLAIN of a driver. Only
+// the first statement would be explained while the server still runs the
+// others, so queries with several statements are refused.
+func explainStatement(driver, query string, analyze bool) (string, error) {
+	if len(Statements(query)) > 1 {
+		return "", fmt.Errorf("only a single statement can be explained")
+	}
+	switch driver {
+	case "postgres":
+		if analyze {
+			return "EXPLAIN (FORMAT JSON, ANALYZE, BUFFERS) " + query, nil
+		}
+		return "EXPLAIN (FORMAT JSON) " + query, nil
+	case "mysql":
+		return "EXPLAIN FORMAT=JSON " + query, nil
+	default:
+		return "", fmt.Errorf("JSON plans are not supported for %s databases", driver)
+	}
+}
+
+// readPlan reads and closes the single-row result of a JSON EXPLAIN
+func readPlan(rows *sql.Rows, driver string) (*QueryPlan, error) {
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Error("error closing rows: %v", err)
+		}
+	}()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to explain query: %w", err)
+		}
+		return nil, fmt.Errorf("no explain plan returned")
+	}
+	var value interface{}
+	if err := rows.Scan(&value); err != nil {
+		return nil, fmt.Errorf("failed to scan explain plan: %w", err)
+	}
+	data, err := planBytes(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if driver == "mysql" {
+		return ParseMySQLPlan(data)
+	}
+	return ParsePostgresPlan(data)
+}
+
+// planBytes returns the JSON of a plan column, which drivers return as text
+// or already decoded
+func planBytes(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	case nil:
+		return nil, fmt.Errorf("empty explain plan")
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read explain plan: %w", err)
+		}
+		return data, nil
+	}
+}
+
+// pgPlanNode is a node of EXPLAIN (FORMAT JSON) output
+type pgPlanNode struct {
+	NodeType        string       `json:"Node Type"`
+	RelationName    string       `json:"Relation Name"`
+	Alias           string       `json:"Alias"`
+	IndexName       string       `json:"Index Name"`
+	TotalCost       float64      `json:"Total Cost"`
+	PlanRows        float64      `json:"Plan Rows"`
+	ActualRows      *float64     `json:"Actual Rows"`
+	ActualLoops     float64      `json:"Actual Loops"`
+	ActualTotalTime float64      `json:"Actual Total Time"`
+	Filter          string       `json:"Filter"`
+	JoinFilter      string       `json:"Join Filter"`
+	RowsRemoved     float64      `json:"Rows Removed by Filter"`
+	SortMethod      string       `json:"Sort Method"`
+	SortSpaceUsed   float64      `json:"Sort Space Used"`
+	SortSpaceType   string       `json:"Sort Space Type"`
+	Plans           []pgPlanNode `json:"Plans"`
+}
+
+// ParsePostgresPlan parses the output of EXPLAIN (FORMAT JSON)
+func ParsePostgresPlan(data []byte) (*QueryPlan, error) {
+	var explained []struct {
+		Plan          pgPlanNode `json:"Plan"`
+		PlanningTime  float64    `json:"Planning Time"`
+		ExecutionTime float64    `json:"Execution Time"`
+	}
+	if err := json.Unmarshal(data, &explained); err != nil {
+		return nil, fmt.Errorf("failed to parse PostgreSQL plan: %w", err)
+	}
+	if len(explained) == 0 || explained[0].Plan.NodeType == "" {
+		return nil, fmt.Errorf("failed to parse PostgreSQL plan: no plan found")
+	}
+
+	root := pgNode(explained[0].Plan)
+	return &QueryPlan{
+		Driver:        "postgres",
+		Analyzed:      explained[0].Plan.ActualRows != nil,
+		TotalCost:     root.Cost,
+		PlanningTime:  explained[0].PlanningTime,
+		ExecutionTime: explained[0].ExecutionTime,
+		Root:          root,
+	}, nil
+}
+
+// pgNode converts a PostgreSQL plan node and its children
+func pgNode(p pgPlanNode) *PlanNode {
+	node := &PlanNode{
+		Operation:     p.NodeType,
+		Relation:      p.RelationName,
+		Alias:         p.Alias,
+		Index:         p.IndexName,
+		Cost:          p.TotalCost,
+		EstimatedRows: p.PlanRows,
+		Loops:         p.ActualLoops,
+		ActualTime:    p.ActualTotalTime,
+		Filter:        p.Filter,
+		JoinFilter:    p.JoinFilter,
+		SortMethod:    p.SortMethod,
+		SortSpace:     p.SortSpaceUsed,
+		SortSpaceType: p.SortSpaceType,
+	}
+	if p.ActualRows != nil {
+		node.ActualRows = *p.ActualRows
+	}
+	if node.Loops == 0 {
+		node.Loops = 1
+	}
+	if strings.Contains(p.NodeType, "Scan") {
+		// Without ANALYZE the rows a filter removes are unknown, so the
+		// output estimate is the lower bound of the rows read
+		node.RowsExamined = p.PlanRows
+		if p.ActualRows != nil {
+			node.RowsExamined = *p.ActualRows + p.RowsRemoved
+		}
+	}
+	for _, child := range p.Plans {
+		node.Children = append(node.Children, pgNode(child))
+	}
+	return node
+}
+
+// ParseMySQLPlan parses the output of EXPLAIN FORMAT=JSON
+func ParseMySQLPlan(data []byte) (*QueryPlan, error) {
+	var explained map[string]interface{}
+	if err := json.Unmarshal(data, &explained); err != nil {
+		return nil, fmt.Errorf("failed to parse MySQL plan: %w", err)
+	}
+	block, ok := explained["query_block"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to parse MySQL plan: no query_block found")
+	}
+
+	root := mysqlBlock(block)
+	return &QueryPlan{Driver: "mysql", TotalCost: root.Cost, Root: root}, nil
+}
+
+// mysqlBlock converts a query_block
+func mysqlBlock(block map[string]interface{}) *PlanNode {
+	node := &PlanNode{Operation: "Query Block", Loops: 1}
+	if costInfo, ok := block["cost_info"].(map[string]interface{}); ok {
+		node.Cost = numberValue(costInfo["query_cost"])
+	}
+	node.Children = mysqlChildren(block)
+	if len(node.Children) == 1 {
+		node.EstimatedRows = outputRows(node.Children[0])
+	}
+	return node
+}
+
+// mysqlChildren converts the operations nested in a MySQL plan object
+func mysqlChildren(object map[string]interface{}) []*PlanNode {
+	var children []*PlanNode
+	if table, ok := object["table"].(map[string]interface{}); ok {
+		children = append(children, mysqlTable(table, 1))
+	}
+	if loop, ok := object["nested_loop"].([]interface{}); ok {
+		children = append(children, mysqlNestedLoop(loop))
+	}
+	operations := []struct{ key, operation string }{
+		{"ordering_operation", "Sort"},
+		{"grouping_operation", "Aggregate"},
+		{"duplicates_removal", "Unique"},
+		{"windowing", "Window"},
+	}
+	for _, op := range operations {
+		nested, ok := object[op.key].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		node := &PlanNode{Operation: op.operation, Loops: 1, Children: mysqlChildren(nested)}
+		if sorted, _ := nested["using_filesort"].(bool); sorted {
+			node.SortMethod = "filesort"
+		}
+		if len(node.Children) == 1 {
+			node.EstimatedRows = outputRows(node.Children[0])
+		}
+		children = append(children, node)
+	}
+	if union, ok := object["union_result"].(map[string]interface{}); ok {
+		node := &PlanNode{Operation: "Union", Loops: 1}
+		specs, _ := union["query_specifications"].([]interface{})
+		for _, spec := range specs {
+			if block, ok := spec.(map[string]interface{})["query_block"].(map[string]interface{}); ok {
+				node.Children = append(node.Children, mysqlBlock(block))
+			}
+		}
+		children = append(children, node)
+	}
+	for _, key := range []string{"attached_subqueries", "optimized_away_subqueries"} {
+		subqueries, _ := object[key].([]interface{})
+		for _, subquery := range subqueries {
+			if block, ok := subquery.(map[string]interface{})["query_block"].(map[string]interface{}); ok {
+				children = append(children, mysqlBlock(block))
+			}
+		}
+	}
+	return children
+}
+
+// mysqlNestedLoop converts a nested_loop join. Each table is read once per
+// row the tables before it produce.
+func mysqlNestedLoop(loop []interface{}) *PlanNode {
+	node := &PlanNode{Operation: "Nested Loop", Loops: 1}
+	prefixRows := 1.0
+	for _, item := range loop {
+		object, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		table, ok := object["table"].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		child := mysqlTable(table, prefixRows)
+		prefixRows = math.Max(outputRows(child), 1)
+		node.Children = append(node.Children, child)
+	}
+	node.EstimatedRows = prefixRows
+	return node
+}
+
+// mysqlAccessTypes names the MySQL access types
+var mysqlAccessTypes = map[string]string{
+	"ALL":         "Full Table Scan",
+	"index":       "Full Index Scan",
+	"range":       "Index Range Scan",
+	"ref":         "Index Lookup",
+	"eq_ref":      "Unique Index Lookup",
+	"ref_or_null": "Index Lookup",
+	"const":       "Constant Lookup",
+	"system":      "Constant Lookup",
+	"fulltext":    "Fulltext Index Lookup",
+}
+
+// mysqlTable converts a table access read once per prefix row
+func mysqlTable(table map[string]interface{}, prefixRows float64) *PlanNode {
+	accessType := stringValue(table["access_type"], "")
+	operation, ok := mysqlAccessTypes[accessType]
+	if !ok {
+		operation = accessType
+	}
+	node := &PlanNode{
+		Operation:    operation,
+		Relation:     stringValue(table["table_name"], ""),
+		Alias:        stringValue(table["table_name"], ""),
+		Index:        stringValue(table["key"], ""),
+		Loops:        prefixRows,
+		RowsExamined: numberValue(table["rows_examined_per_scan"]),
+		Filter:       stringValue(table["attached_condition"], ""),
+	}
+	if keys, ok := table["possible_keys"].([]interface{}); ok {
+		for _, key := range keys {
+			node.PossibleKeys = append(node.PossibleKeys, fmt.Sprintf("%v", key))
+		}
+	}
+	if costInfo, ok := table["cost_info"].(map[string]interface{}); ok {
+		node.Cost = numberValue(costInfo["prefix_cost"])
+	}
+	// rows_produced_per_join covers every loop
+	node.EstimatedRows = numberValue(table["rows_produced_per_join"]) / prefixRows
+	if subquery, ok := table["materialized_from_subquery"].(map[string]interface{}); ok {
+		if block, ok := subquery["query_block"].(map[string]interface{}); ok {
+			node.Children = append(node.Children, mysqlBlock(block))
+		}
+	}
+	return node
+}
+
+// outputRows is the number of rows a node produces over all its loops,
+// actual when known
+func outputRows(node *PlanNode) float64 {
+	rows := node.EstimatedRows
+	if node.ActualRows > 0 {
+		rows = node.ActualRows
+	}
+	return rows * math.Max(node.Loops, 1)
+}
+
+// usesIndex reports whether a node or one of its children reads an index
+func usesIndex(node *PlanNode) bool {
+	if node.Index != "" {
+		return true
+	}
+	for _, child := range node.Children {
+		if usesIndex(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// Issues flags sequential scans on large tables, scans a missing index would
+// avoid, row estimate misses, sorts spilling to disk and nested loops over
+// big inputs
+func (p *QueryPlan) Issues() []PlanIssue {
+	var issues []PlanIssue
+	var walk func(node *PlanNode)
+	walk = func(node *PlanNode) {
+		issues = append(issues, p.nodeIssues(node)...)
+		for _, child := range node.Children {
+			walk(child)
+		}
+	}
+	if p.Root != nil {
+		walk(p.Root)
+	}
+	return issues
+}
+
+// nodeIssues flags the problems of one node
+func (p *QueryPlan) nodeIssues(node *PlanNode) []PlanIssue {
+	var issues []PlanIssue
+
+	if (node.Operation == "Seq Scan" || node.Operation == "Full Table Scan") && node.RowsExamined >= largeTableRows {
+		returned := node.EstimatedRows
+		if p.Analyzed {
+			returned = node.ActualRows
+		}
+		switch {
+		case len(node.PossibleKeys) > 0:
+			issues = append(issues, PlanIssue{
+				Kind:     "missing-index",
+				Relation: node.Relation,
+				Message: fmt.Sprintf("Full scan of %s reads %.0f rows although indexes %s could be used",
+					node.Relation, node.RowsExamined, strings.Join(node.PossibleKeys, ", ")),
+				Suggestion: "Check the condition for functions or type conversions on the indexed columns, and run ANALYZE TABLE " + node.Relation,
+			})
+		case node.Filter != "" && returned <= node.RowsExamined*selectiveFilter:
+			issues = append(issues, PlanIssue{
+				Kind:     "missing-index",
+				Relation: node.Relation,
+				Message: fmt.Sprintf("Full scan of %s reads %.0f rows to return %.0f",
+					node.Relation, node.RowsExamined, returned),
+				Suggestion: fmt.Sprintf("Add an index on %s covering the columns of %s", node.Relation, node.Filter),
+			})
+		default:
+			issues = append(issues, PlanIssue{
+				Kind:       "seq-scan",
+				Relation:   node.Relation,
+				Message:    fmt.Sprintf("Sequential scan of %s reads %.0f rows", node.Relation, node.RowsExamined),
+				Suggestion: "Filter on indexed columns or add a LIMIT if the whole table is not needed",
+			})
+		}
+	}
+
+	if p.Analyzed && node.Relation != "" {
+		estimated, actual := math.Max(node.EstimatedRows, 1), math.Max(node.ActualRows, 1)
+		if math.Max(estimated, actual) >= estimateMissRows &&
+			(actual/estimated >= estimateMissFactor || estimated/actual >= estimateMissFactor) {
+			issues = append(issues, PlanIssue{
+				Kind:     "row-estimate",
+				Relation: node.Relation,
+				Message: fmt.Sprintf("%s on %s was estimated at %.0f rows but returned %.0f",
+					node.Operation, node.Relation, node.EstimatedRows, node.ActualRows),
+				Suggestion: fmt.Sprintf("Run ANALYZE on %s to refresh its statistics, or create extended statistics for correlated columns", node.Relation),
+			})
+		}
+	}
+
+	switch {
+	case node.SortSpaceType == "Disk" || strings.HasPrefix(node.SortMethod, "external"):
+		issues = append(issues, PlanIssue{
+			Kind:       "disk-sort",
+			Message:    fmt.Sprintf("Sort spilled %.0f kB to disk using %s", node.SortSpace, node.SortMethod),
+			Suggestion: "Increase work_mem for the query or add an index matching the ORDER BY",
+		})
+	case node.SortMethod == "filesort" && node.EstimatedRows >= largeTableRows:
+		issues = append(issues, PlanIssue{
+			Kind:       "disk-sort",
+			Message:    fmt.Sprintf("Filesort of about %.0f rows may spill to disk", node.EstimatedRows),
+			Suggestion: "Add an index matching the ORDER BY or increase sort_buffer_size",
+		})
+	}
+
+	if node.Operation == "Nested Loop" {
+		for i := 1; i < len(node.Children); i++ {
+			outer, inner := node.Children[i-1], node.Children[i]
+			if rows := outputRows(outer); rows >= nestedLoopRows && !usesIndex(inner) {
+				issues = append(issues, PlanIssue{
+					Kind:     "nested-loop",
+					Relation: planRelation(inner),
+					Message: fmt.Sprintf("Nested loop reads %s without an index for each of %.0f outer rows",
+						planRelation(inner), rows),
+					Suggestion: "Index the join columns of " + planRelation(inner) + " or rewrite the join so a hash join can be used",
+				})
+			}
+		}
+	}
+
+	return issues
+}
+
+// planRelation names the first relation read by a node
+func planRelation(node *PlanNode) string {
+	if node.Relation != "" {
+		return node.Relation
+	}
+	for _, child := range node.Children {
+		if relation := planRelation(child); relation != "" {
+			return relation
+		}
+	}
+	return node.Operation
+}
+
+// Shape returns a hash of the plan's operations, relations and indexes, so
+// plans that only differ in costs and row estimates share a shape
+func (p *QueryPlan) Shape() string {
+	var b strings.Builder
+	var write func(node *PlanNode)
+	write = func(node *PlanNode) {
+		fmt.Fprintf(&b, "%s|%s|%s(", node.Operation, node.Relation, node.Index)
+		for _, child := range node.Children {
+			write(child)
+		}
+		b.WriteString(")")
+	}
+	if p.Root != nil {
+		write(p.Root)
+	}
+	return fingerprintHash(b.String())
+}
+
+// String renders the plan as an indented tree
+func (p *QueryPlan) String() string {
+	var b strings.Builder
+	var write func(node *PlanNode, depth int)
+	write = func(node *PlanNode, depth int) {
+		b.WriteString(strings.Repeat("  ", depth))
+		b.WriteString("-> ")
+		b.WriteString(node.Operation)
+		if node.Relation != "" {
+			fmt.Fprintf(&b, " on %s", node.Relation)
+		}
+		if node.Index != "" {
+			fmt.Fprintf(&b, " using %s", node.Index)
+		}
+		fmt.Fprintf(&b, " (cost=%.2f rows=%.0f", node.Cost, node.EstimatedRows)
+		if p.Analyzed {
+			fmt.Fprintf(&b, ", actual rows=%.0f loops=%.0f time=%.3fms", node.ActualRows, node.Loops, node.ActualTime)
+		}
+		b.WriteString(")\n")
+		if node.Filter != "" {
+			fmt.Fprintf(&b, "%s   Filter: %s\n", strings.Repeat("  ", depth), node.Filter)
+		}
+		if node.JoinFilter != "" {
+			fmt.Fprintf(&b, "%s   Join Filter: %s\n", strings.Repeat("  ", depth), node.JoinFilter)
+		}
+		for _, child := range node.Children {
+			write(child, depth+1)
+		}
+	}
+	if p.Root != nil {
+		write(p.Root, 0)
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
@@ -0,0 +1,296 @@
+package dbtools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// Defaults of plan regression tracking
+const (
+	defaultPlanCheckCalls    = 10               // executions before a fingerprint's plan is tracked
+	defaultPlanCheckInterval = 10 * time.Minute // time between checks of a fingerprint's plan
+	defaultPlanCostFactor    = 2.0              // cost increase worth flagging
+	planCostMinIncrease      = 100              // cost units below which increases do not matter
+	maxPlanBaselines         = 500
+	planCheckTimeout         = 30 * time.Second
+)
+
+// Plan regression kinds
+const (
+	PlanShapeChanged = "shape" // the plan uses other operations, relations or indexes
+	PlanCostIncrease = "cost"  // the plan is the same but costs much more
+)
+
+// PlanSnapshot is the plan of a query at one point in time
+type PlanSnapshot struct {
+	Shape     string    `json:"shape"`
+	Cost      float64   `json:"cost"`
+	Plan      string    `json:"plan"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// PlanBaseline is the reference plan of a query fingerprint and the plan it
+// had when last checked
+type PlanBaseline struct {
+	Fingerprint string       `json:"fingerprint"`
+	Query       string       `json:"query"`
+	Baseline    PlanSnapshot `json:"baseline"`
+	Latest      PlanSnapshot `json:"latest"`
+}
+
+// PlanRegression is a fingerprint whose latest plan changed shape or costs
+// much more than its baseline
+type PlanRegression struct {
+	Kind string `json:"kind"`
+	PlanBaseline
+}
+
+// PlanTracker keeps the plans of a connection's frequent queries in a local
+// JSON store and flags plans that change after statistics changes or
+// deploys. It is safe for concurrent use.
+type PlanTracker struct {
+	MinCalls   int           // executions before a fingerprint's plan is tracked
+	Interval   time.Duration // time between checks of a fingerprint's plan
+	CostFactor float64       // cost ratio over the baseline flagged as a regression
+
+	mu        sync.Mutex
+	dbID      string
+	path      string
+	baselines map[string]*PlanBaseline
+	pending   map[string]bool // fingerprints being checked
+
+	// explain plans a sample; defaults to EXPLAIN on the connection
+	explain func(ctx context.Context, sample planSample) (*QueryPlan, error)
+}
+
+// planStore is the file format of a plan store
+type planStore struct {
+	Baselines []*PlanBaseline `json:"baselines"`
+}
+
+// NewPlanTracker creates the plan tracker of a connection, loading the
+// baselines stored at path. A missing file starts an empty store.
+func NewPlanTracker(dbID, path string) (*PlanTracker, error) {
+	t := &PlanTracker{
+		MinCalls:   defaultPlanCheckCalls,
+		Interval:   defaultPlanCheckInterval,
+		CostFactor: defaultPlanCostFactor,
+		dbID:       dbID,
+		path:       path,
+		baselines:  make(map[string]*PlanBaseline),
+		pending:    make(map[string]bool),
+	}
+	t.explain = t.explainOnConnection
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read plan store %s: %w", path, err)
+	}
+	var store planStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse plan store %s: %w", path, err)
+	}
+	for _, baseline := range store.Baselines {
+		t.baselines[baseline.Fingerprint] = baseline
+	}
+	return t, nil
+}
+
+// observe checks the plan of a fingerprint in the background when it has run
+// often enough and was not checked recently
+func (t *PlanTracker) observe(m *QueryMetrics) {
+	sample, ok := t.due(m, time.Now())
+	if !ok {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), planCheckTimeout)
+		defer cancel()
+		if err := t.check(ctx, m.Fingerprint, m.Query, sample); err != nil {
+			logger.Debug("Plan check of %s on %s failed: %v", m.Fingerprint, t.dbID, err)
+		}
+	}()
+}
+
+// due reports whether the plan of a fingerprint should be checked now and
+// marks it as being checked
+func (t *PlanTracker) due(m *QueryMetrics, now time.Time) (planSample, bool) {
+	if m.Count < t.MinCalls {
+		return planSample{}, false
+	}
+	sample, ok := workloadSample(m, false)
+	if !ok {
+		return planSample{}, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.pending[m.Fingerprint] {
+		return planSample{}, false
+	}
+	if baseline, ok := t.baselines[m.Fingerprint]; ok && now.Sub(baseline.Latest.CheckedAt) < t.Interval {
+		return planSample{}, false
+	}
+	t.pending[m.Fingerprint] = true
+	return sample, true
+}
+
+// check plans a sample of a fingerprint and records the plan
+func (t *PlanTracker) check(ctx context.Context, fingerprint, query string, sample planSample) error {
+	defer func() {
+		t.mu.Lock()
+		delete(t.pending, fingerprint)
+		t.mu.Unlock()
+	}()
+	plan, err := t.explain(ctx, sample)
+	if err != nil {
+		return err
+	}
+	_, err = t.Record(fingerprint, query, plan, time.Now())
+	return err
+}
+
+// explainOnConnection plans a sample on the tracker's connection
+func (t *PlanTracker) explainOnConnection(ctx context.Context, sample planSample) (*QueryPlan, error) {
+	database, err := GetDatabase(t.dbID)
+	if err != nil {
+		return nil, err
+	}
+	return explainSample(ctx, databaseQueryer{database}, database.DriverName(), sample)
+}
+
+// Record stores the plan a fingerprint has now. The first plan becomes the
+// baseline; a later plan that regressed from it is logged and returned, once
+// per new shape or when the regression starts.
+func (t *PlanTracker) Record(fingerprint, query string, plan *QueryPlan, now time.Time) (*PlanRegression, error) {
+	snapshot := PlanSnapshot{Shape: plan.Shape(), Cost: plan.TotalCost, Plan: plan.String(), CheckedAt: now}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	baseline, ok := t.baselines[fingerprint]
+	if !ok {
+		t.evict()
+		t.baselines[fingerprint] = &PlanBaseline{Fingerprint: fingerprint, Query: query, Baseline: snapshot, Latest: snapshot}
+		return nil, t.save()
+	}
+
+	before := t.regression(baseline)
+	previousShape := baseline.Latest.Shape
+	baseline.Latest = snapshot
+	regression := t.regression(baseline)
+	if err := t.save(); err != nil {
+		return nil, err
+	}
+	if regression == nil || (before != nil && before.Kind == regression.Kind && previousShape == snapshot.Shape) {
+		return nil, nil
+	}
+
+	if regression.Kind == PlanShapeChanged {
+		logger.Warn("Plan regression on %s: the plan of query %s changed shape (cost %.2f -> %.2f): %s",
+			t.dbID, fingerprint, baseline.Baseline.Cost, snapshot.Cost, query)
+	} else {
+		logger.Warn("Plan regression on %s: the plan of query %s costs %.2f, up from %.2f: %s",
+			t.dbID, fingerprint, snapshot.Cost, baseline.Baseline.Cost, query)
+	}
+	return regression, nil
+}
+
+// regression compares the latest plan of a fingerprint with its baseline
+func (t *PlanTracker) regression(baseline *PlanBaseline) *PlanRegression {
+	kind := ""
+	switch {
+	case baseline.Latest.Shape != baseline.Baseline.Shape:
+		kind = PlanShapeChanged
+	case baseline.Latest.Cost >= baseline.Baseline.Cost*t.CostFactor &&
+		baseline.Latest.Cost-baseline.Baseline.Cost >= planCostMinIncrease:
+		kind = PlanCostIncrease
+	default:
+		return nil
+	}
+	return &PlanRegression{Kind: kind, PlanBaseline: *baseline}
+}
+
+// Regressions returns the fingerprints whose latest plan regressed from
+// their baseline, most recently checked first
+func (t *PlanTracker) Regressions() []PlanRegression {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var regressions []PlanRegression
+	for _, baseline := range t.baselines {
+		if regression := t.regression(baseline); regression != nil {
+			regressions = append(regressions, *regression)
+		}
+	}
+	sort.Slice(regressions, func(i, j int) bool {
+		return regressions[i].Latest.CheckedAt.After(regressions[j].Latest.CheckedAt)
+	})
+	return regressions
+}
+
+// Accept makes the latest plan of a fingerprint its baseline, e.g. after a
+// deliberate change
+func (t *PlanTracker) Accept(fingerprint string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	baseline, ok := t.baselines[fingerprint]
+	if !ok {
+		return fmt.Errorf("no plan recorded for query fingerprint %s", fingerprint)
+	}
+	baseline.Baseline = baseline.Latest
+	return t.save()
+}
+
+// evict drops the least recently checked baseline when the store is full.
+// The caller holds the lock.
+func (t *PlanTracker) evict() {
+	if len(t.baselines) < maxPlanBaselines {
+		return
+	}
+	var oldest *PlanBaseline
+	for _, baseline := range t.baselines {
+		if oldest == nil || baseline.Latest.CheckedAt.Before(oldest.Latest.CheckedAt) {
+			oldest = baseline
+		}
+	}
+	delete(t.baselines, oldest.Fingerprint)
+}
+
+// save writes the store, replacing the file only once it is complete. The
+// caller holds the lock.
+func (t *PlanTracker) save() error {
+	store := planStore{Baselines: make([]*PlanBaseline, 0, len(t.baselines))}
+	for _, baseline := range t.baselines {
+		store.Baselines = append(store.Baselines, baseline)
+	}
+	sort.Slice(store.Baselines, func(i, j int) bool {
+		return store.Baselines[i].Fingerprint < store.Baselines[j].Fingerprint
+	})
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan store: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create plan store directory: %w", err)
+	}
+	tmp := t.path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write plan store %s: %w", t.path, err)
+	}
+	if err := os.Rename(tmp, t.path); err != nil {
+		return fmt.Errorf("failed to write plan store %s: %w", t.path, err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package dbtools
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/internal/logger"
+)
+
+// seqScanPlan and indexScanPlan are plans of the same query before and after
+// an index was dropped
+const (
+	indexScanPlan = `[{"Plan": {"Node Type": "Index Scan", "Relation Name": "orders", "Index Name": "idx_orders_status",
+		"Total Cost": 8.29, "Plan Rows": 1}}]`
+	seqScanPlan = `[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "orders", "Total Cost": 2041, "Plan Rows": 1,
+		"Filter": "(status = 'open'::text)"}}]`
+)
+
+func parsePlan(t *testing.T, data string) *QueryPlan {
+	t.Helper()
+	plan, err := ParsePostgresPlan([]byte(data))
+	require.NoError(t, err)
+	return plan
+}
+
+func TestPlanShape(t *testing.T) {
+	plan := parsePlan(t, indexScanPlan)
+	costlier := parsePlan(t, `[{"Plan": {"Node Type": "Index Scan", "Relation Name": "orders", "Index Name": "idx_orders_status",
+		"Total Cost": 950, "Plan Rows": 4000}}]`)
+	assert.Equal(t, plan.Shape(), costlier.Shape(), "costs and rows are not part of the shape")
+	assert.NotEqual(t, plan.Shape(), parsePlan(t, seqScanPlan).Shape())
+	assert.Len(t, plan.Shape(), 16)
+}
+
+func TestPlanTrackerRecord(t *testing.T) {
+	logger.InitializeWithWriter("error", os.Stderr)
+	path := filepath.Join(t.TempDir(), "plans", "orders.json")
+	tracker, err := NewPlanTracker("test_db", path)
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	query := "SELECT * FROM orders WHERE status = ?"
+
+	// The first plan is the baseline
+	regression, err := tracker.Record("a1", query, parsePlan(t, indexScanPlan), now)
+	require.NoError(t, err)
+	assert.Nil(t, regression)
+	assert.Empty(t, tracker.Regressions())
+
+	// A new shape is flagged once
+	regression, err = tracker.Record("a1", query, parsePlan(t, seqScanPlan), now.Add(time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, regression)
+	assert.Equal(t, PlanShapeChanged, regression.Kind)
+	assert.Equal(t, 8.29, regression.Baseline.Cost)
+	assert.Equal(t, 2041.0, regression.Latest.Cost)
+	regression, err = tracker.Record("a1", query, parsePlan(t, seqScanPlan), now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Nil(t, regression, "an ongoing regression is not logged again")
+	require.Len(t, tracker.Regressions(), 1)
+
+	// The store survives a restart
+	reloaded, err := NewPlanTracker("test_db", path)
+	require.NoError(t, err)
+	regressions := reloaded.Regressions()
+	require.Len(t, regressions, 1)
+	assert.Equal(t, "a1", regressions[0].Fingerprint)
+	assert.Equal(t, query, regressions[0].Query)
+	assert.Contains(t, regressions[0].Latest.Plan, "-> Seq Scan on orders")
+
+	// Accepting the plan makes it the baseline
+	require.NoError(t, reloaded.Accept("a1"))
+	assert.Empty(t, reloaded.Regressions())
+	assert.ErrorContains(t, reloaded.Accept("b2"), "no plan recorded")
+
+	// The same shape costing much more is a cost regression
+	regression, err = reloaded.Record("a1", query, parsePlan(t, `[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "orders",
+		"Total Cost": 6000, "Plan Rows": 1}}]`), now.Add(3*time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, regression)
+	assert.Equal(t, PlanCostIncrease, regression.Kind)
+}
+
+func TestPlanTrackerDue(t *testing.T) {
+	logger.InitializeWithWriter("error", os.Stderr)
+	tracker, err := NewPlanTracker("test_db", filepath.Join(t.TempDir(), "plans.json"))
+	require.NoError(t, err)
+	now := time.Now()
+	m := &QueryMetrics{Fingerprint: "a1", Query: "SELECT * FROM orders WHERE id = ?",
+		Sample: "SELECT * FROM orders WHERE id = $1", SampleParams: []interface{}{7}, Count: 9}
+
+	_, ok := tracker.due(m, now)
+	assert.False(t, ok, "infrequent queries are not tracked")
+
+	m.Count = 10
+	sample, ok := tracker.due(m, now)
+	require.True(t, ok)
+	assert.Equal(t, []interface{}{7}, sample.args)
+	_, ok = tracker.due(m, now)
+	assert.False(t, ok, "the plan is already being checked")
+
+	tracker.explain = func(ctx context.Context, sample planSample) (*QueryPlan, error) {
+		return ParsePostgresPlan([]byte(indexScanPlan))
+	}
+	require.NoError(t, tracker.check(context.Background(), m.Fingerprint, m.Query, sample))
+	_, ok = tracker.due(m, now.Add(time.Minute))
+	assert.False(t, ok, "the plan was checked recently")
+	_, ok = tracker.due(m, now.Add(time.Hour))
+	assert.True(t, ok)
+
+	_, ok = tracker.due(&QueryMetrics{Fingerprint: "b2", Sample: "INSERT INTO orders VALUES (1)", Count: 100}, now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,345 @@
+package dbtools
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pgAnalyzedPlan is EXPLAIN (FORMAT JSON, ANALYZE, BUFFERS) of a join whose
+// outer side filters a large table, sorted on disk
+const pgAnalyzedPlan = `[
+  {
+    "Plan": {
+      "Node Type": "Sort", "Total Cost": 52000.5, "Plan Rows": 50, "Actual Rows": 24000, "Actual Loops": 1,
+      "Actual Total Time": 812.4, "Sort Key": ["o.created_at"], "Sort Method": "external merge",
+      "Sort Space Used": 5120, "Sort Space Type": "Disk",
+      "Plans": [
+        {
+          "Node Type": "Nested Loop", "Parent Relationship": "Outer", "Total Cost": 51000, "Plan Rows": 50,
+          "Actual Rows": 24000, "Actual Loops": 1, "Actual Total Time": 790.1,
+          "Plans": [
+            {
+              "Node Type": "Seq Scan", "Parent Relationship": "Outer", "Relation Name": "orders", "Alias": "o",
+              "Total Cost": 2041, "Plan Rows": 50, "Actual Rows": 2000, "Actual Loops": 1, "Actual Total Time": 35.2,
+              "Filter": "(status = 'open'::text)", "Rows Removed by Filter": 98000
+            },
+            {
+              "Node Type": "Seq Scan", "Parent Relationship": "Inner", "Relation Name": "order_items", "Alias": "i",
+              "Total Cost": 980, "Plan Rows": 12, "Actual Rows": 12, "Actual Loops": 2000, "Actual Total Time": 0.37,
+              "Filter": "(order_id = o.id)", "Rows Removed by Filter": 40000
+            }
+          ]
+        }
+      ]
+    },
+    "Planning Time": 0.412,
+    "Execution Time": 815.9
+  }
+]`
+
+// mysqlPlan is EXPLAIN FORMAT=JSON of a sorted join reading both tables in full
+const mysqlPlan = `{
+  "query_block": {
+    "select_id": 1,
+    "cost_info": {"query_cost": "240510.25"},
+    "ordering_operation": {
+      "using_filesort": true,
+      "nested_loop": [
+        {
+          "table": {
+            "table_name": "orders", "access_type": "ALL", "possible_keys": ["idx_status"],
+            "rows_examined_per_scan": 100000, "rows_produced_per_join": 10000, "filtered": "10.00",
+            "cost_info": {"read_cost": "9000.00", "eval_cost": "1000.00", "prefix_cost": "10050.00"},
+            "attached_condition": "(` + "`shop`.`o`.`status` = 'open'" + `)"
+          }
+        },
+        {
+          "table": {
+            "table_name": "order_items", "access_type": "ALL",
+            "rows_examined_per_scan": 2000, "rows_produced_per_join": 20000, "filtered": "0.10",
+            "using_join_buffer": "hash join",
+            "cost_info": {"prefix_cost": "240510.25"},
+            "attached_condition": "(` + "`shop`.`i`.`order_id` = `shop`.`o`.`id`" + `)"
+          }
+        }
+      ]
+    }
+  }
+}`
+
+func TestParsePostgresPlan(t *testing.T) {
+	plan, err := ParsePostgresPlan([]byte(pgAnalyzedPlan))
+	require.NoError(t, err)
+	assert.True(t, plan.Analyzed)
+	assert.Equal(t, 52000.5, plan.TotalCost)
+	assert.Equal(t, 815.9, plan.ExecutionTime)
+
+	require.Len(t, plan.Root.Children, 1)
+	loop := plan.Root.Children[0]
+	assert.Equal(t, "Nested Loop", loop.Operation)
+	require.Len(t, loop.Children, 2)
+	orders := loop.Children[0]
+	assert.Equal(t, "orders", orders.Relation)
+	assert.Equal(t, 100000.0, orders.RowsExamined, "rows read are returned plus removed rows")
+	assert.Equal(t, 2000.0, loop.Children[1].Loops)
+
+	assert.Contains(t, plan.String(), "-> Sort (cost=52000.50 rows=50, actual rows=24000 loops=1 time=812.400ms)\n")
+	assert.Contains(t, plan.String(), "    -> Seq Scan on orders (cost=2041.00 rows=50")
+	assert.Contains(t, plan.String(), "Filter: (status = 'open'::text)")
+
+	_, err = ParsePostgresPlan([]byte(`{"query_block": {}}`))
+	assert.ErrorContains(t, err, "failed to parse PostgreSQL plan")
+}
+
+func TestPostgresPlanIssues(t *testing.T) {
+	plan, err := ParsePostgresPlan([]byte(pgAnalyzedPlan))
+	require.NoError(t, err)
+
+	kinds := make(map[string][]string)
+	for _, issue := range plan.Issues() {
+		kinds[issue.Kind] = append(kinds[issue.Kind], issue.Relation)
+	}
+	assert.Equal(t, []string{"orders", "order_items"}, kinds["missing-index"], "both filters keep few of the rows read")
+	assert.Equal(t, []string{"orders"}, kinds["row-estimate"], "estimated 50 rows, returned 2000")
+	assert.Len(t, kinds["disk-sort"], 1)
+	assert.Equal(t, []string{"order_items"}, kinds["nested-loop"])
+
+	// Planned only, the rows a filter removes are unknown
+	plan, err = ParsePostgresPlan([]byte(`[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "events",
+		"Total Cost": 1935, "Plan Rows": 120000}}]`))
+	require.NoError(t, err)
+	issues := plan.Issues()
+	require.Len(t, issues, 1)
+	assert.Equal(t, "seq-scan", issues[0].Kind)
+	assert.Equal(t, "Sequential scan of events reads 120000 rows", issues[0].Message)
+
+	// A small scan with an index is fine
+	plan, err = ParsePostgresPlan([]byte(`[{"Plan": {"Node Type": "Index Scan", "Relation Name": "users",
+		"Index Name": "users_pkey", "Total Cost": 8.29, "Plan Rows": 1}}]`))
+	require.NoError(t, err)
+	assert.False(t, plan.Analyzed)
+	assert.Empty(t, plan.Issues())
+}
+
+func TestParseMySQLPlan(t *testing.T) {
+	plan, err := ParseMySQLPlan([]byte(mysqlPlan))
+	require.NoError(t, err)
+	assert.False(t, plan.Analyzed)
+	assert.Equal(t, 240510.25, plan.TotalCost)
+
+	require.Len(t, plan.Root.Children, 1)
+	sort := plan.Root.Children[0]
+	assert.Equal(t, "Sort", sort.Operation)
+	assert.Equal(t, "filesort", sort.SortMethod)
+	assert.Equal(t, 20000.0, sort.EstimatedRows)
+
+	require.Len(t, sort.Children, 1)
+	loop := sort.Children[0]
+	require.Len(t, loop.Children, 2)
+	orders, items := loop.Children[0], loop.Children[1]
+	assert.Equal(t, "Full Table Scan", orders.Operation)
+	assert.Equal(t, []string{"idx_status"}, orders.PossibleKeys)
+	assert.Equal(t, 100000.0, orders.RowsExamined)
+	assert.Equal(t, 10000.0, items.Loops, "order_items is read once per order")
+	assert.Equal(t, 2.0, items.EstimatedRows)
+
+	issues := plan.Issues()
+	kinds := make(map[string][]string)
+	for _, issue := range issues {
+		kinds[issue.Kind] = append(kinds[issue.Kind], issue.Relation)
+	}
+	assert.Equal(t, []string{"orders"}, kinds["missing-index"])
+	assert.Len(t, kinds["disk-sort"], 1)
+	assert.Equal(t, []string{"order_items"}, kinds["nested-loop"])
+	assert.Empty(t, kinds["row-estimate"], "MySQL plans carry no actual rows")
+
+	_, err = ParseMySQLPlan([]byte(`[]`))
+	assert.ErrorContains(t, err, "failed to parse MySQL plan")
+}
+
+func TestPlanBytes(t *testing.T) {
+	data, err := planBytes([]interface{}{map[string]interface{}{"Plan": map[string]interface{}{"Node Type": "Result"}}})
+	require.NoError(t, err)
+	plan, err := ParsePostgresPlan(data)
+	require.NoError(t, err)
+	assert.Equal(t, "Result", plan.Root.Operation)
+
+	_, err = planBytes(nil)
+	assert.Error(t, err)
+}
+
+// explainDriver is a database/sql driver answering EXPLAIN and
+// current_setting queries, logging the statements and transaction ends
+type explainDriver struct {
+	mu       sync.Mutex
+	readOnly string
+	log      []string
+}
+
+func (d *explainDriver) Connect(ctx context.Context) (driver.Conn, error) { return explainConn{d}, nil }
+func (d *explainDriver) Driver() driver.Driver                            { return nil }
+
+func (d *explainDriver) record(entry string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.log = append(d.log, entry)
+}
+
+type explainConn struct{ d *explainDriver }
+
+func (c explainConn) Prepare(query string) (driver.Stmt, error) { return explainStmt{c.d, query}, nil }
+func (c explainConn) Close() error                              { return nil }
+func (c explainConn) Begin() (driver.Tx, error) {
+	c.d.record("BEGIN")
+	return explainTx{c.d}, nil
+}
+
+type explainTx struct{ d *explainDriver }
+
+func (tx explainTx) Commit() error   { tx.d.record("COMMIT"); return nil }
+func (tx explainTx) Rollback() error { tx.d.record("ROLLBACK"); return nil }
+
+type explainStmt struct {
+	d     *explainDriver
+	query string
+}
+
+func (s explainStmt) Close() error  { return nil }
+func (s explainStmt) NumInput() int { return -1 }
+func (s explainStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.d.record(s.query)
+	return driver.RowsAffected(0), nil
+}
+func (s explainStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.d.record(s.query)
+	switch {
+	case strings.Contains(s.query, "current_setting"):
+		return &explainRows{values: []string{s.d.readOnly}}, nil
+	case strings.HasPrefix(s.query, "EXPLAIN FORMAT=JSON"):
+		return &explainRows{values: []string{mysqlPlan}}, nil
+	case strings.Contains(s.query, "ANALYZE"):
+		return &explainRows{values: []string{pgAnalyzedPlan}}, nil
+	default:
+		return &explainRows{values: []string{`[{"Plan": {"Node Type": "Seq Scan", "Relation Name": "users", "Plan Rows": 10}}]`}}, nil
+	}
+}
+
+type explainRows struct {
+	values []string
+	next   int
+}
+
+func (r *explainRows) Columns() []string { return []string{"plan"} }
+func (r *explainRows) Close() error      { return nil }
+func (r *explainRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.values) {
+		return io.EOF
+	}
+	dest[0] = r.values[r.next]
+	r.next++
+	return nil
+}
+
+// explainDatabase serves a db.Database from an explainDriver
+type explainDatabase struct {
+	sqlDB  *sql.DB
+	driver string
+}
+
+func newExplainDatabase(driverName, readOnly string) (*explainDatabase, *explainDriver) {
+	d := &explainDriver{readOnly: readOnly}
+	return &explainDatabase{sqlDB: sql.OpenDB(d), driver: driverName}, d
+}
+
+func (e *explainDatabase) Query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return e.sqlDB.QueryContext(ctx, query, args...)
+}
+func (e *explainDatabase) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return e.sqlDB.QueryRowContext(ctx, query, args...)
+}
+func (e *explainDatabase) Exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return e.sqlDB.ExecContext(ctx, query, args...)
+}
+func (e *explainDatabase) BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error) {
+	return e.sqlDB.BeginTx(ctx, opts)
+}
+func (e *explainDatabase) Connect() error                 { return nil }
+func (e *explainDatabase) Close() error                   { return e.sqlDB.Close() }
+func (e *explainDatabase) Ping(ctx context.Context) error { return e.sqlDB.PingContext(ctx) }
+func (e *explainDatabase) DriverName() string             { return e.driver }
+func (e *explainDatabase) ConnectionString() string       { return "" }
+func (e *explainDatabase) QueryTimeout() int              { return 30 }
+func (e *explainDatabase) DB() *sql.DB                    { return e.sqlDB }
+
+func TestAnalyzePlan(t *testing.T) {
+	ctx := context.Background()
+
+	// Reads are executed directly
+	database, d := newExplainDatabase("postgres", "off")
+	plan, err := AnalyzePlan(ctx, database, "SELECT * FROM orders", false)
+	require.NoError(t, err)
+	assert.Equal(t, ExplainAnalyze, plan.Mode)
+	assert.Equal(t, []string{"EXPLAIN (FORMAT JSON, ANALYZE, BUFFERS) SELECT * FROM orders"}, d.log)
+
+	// Writes are executed in a transaction that is rolled back
+	database, d = newExplainDatabase("postgres", "off")
+	plan, err = AnalyzePlan(ctx, database, "DELETE FROM orders WHERE status = 'open'", false)
+	require.NoError(t, err)
+	assert.Equal(t, ExplainAnalyzeRollback, plan.Mode)
+	assert.True(t, plan.Analyzed)
+	assert.Equal(t, []string{
+		"SELECT current_setting('transaction_read_only')",
+		"BEGIN",
+		"EXPLAIN (FORMAT JSON, ANALYZE, BUFFERS) DELETE FROM orders WHERE status = 'open'",
+		"ROLLBACK",
+	}, d.log)
+
+	// On a read-only connection writes are refused, or planned with the fallback
+	database, d = newExplainDatabase("postgres", "on")
+	_, err = AnalyzePlan(ctx, database, "UPDATE users SET name = 'x'", false)
+	assert.ErrorContains(t, err, "read-only connection")
+	assert.Equal(t, []string{"SELECT current_setting('transaction_read_only')"}, d.log)
+
+	plan, err = AnalyzePlan(ctx, database, "UPDATE users SET name = 'x'", true)
+	require.NoError(t, err)
+	assert.Equal(t, ExplainOnly, plan.Mode)
+	assert.False(t, plan.Analyzed)
+	assert.Contains(t, plan.Note, "read-only")
+	assert.Contains(t, d.log, "EXPLAIN (FORMAT JSON) UPDATE users SET name = 'x'")
+	assert.NotContains(t, d.log, "BEGIN")
+
+	// Statements after the first are never run, in or out of the transaction
+	for _, query := range []string{
+		"DELETE FROM a; COMMIT; DELETE FROM b",
+		"SELECT 1; DROP TABLE t",
+		"SELECT '--'; DROP TABLE t",
+	} {
+		database, d = newExplainDatabase("postgres", "off")
+		_, err = AnalyzePlan(ctx, database, query, false)
+		assert.ErrorContains(t, err, "single statement", query)
+		assert.NotContains(t, d.log, "BEGIN", query)
+		for _, statement := range d.log {
+			assert.NotContains(t, statement, "EXPLAIN", query)
+		}
+	}
+	database, d = newExplainDatabase("postgres", "off")
+	_, err = AnalyzePlan(ctx, database, "SELECT ';' -- trailing; comment\n;", false)
+	require.NoError(t, err)
+	assert.Len(t, d.log, 1)
+
+	// MySQL plans are never executed
+	database, d = newExplainDatabase("mysql", "")
+	plan, err = AnalyzePlan(ctx, database, "DELETE FROM orders", false)
+	require.NoError(t, err)
+	assert.Equal(t, ExplainOnly, plan.Mode)
+	assert.NotEmpty(t, plan.Note)
+	assert.Equal(t, []string{"EXPLAIN FORMAT=JSON DELETE FROM orders"}, d.log)
+}
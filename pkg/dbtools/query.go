@@ -92,7 +92,7 @@ func handleQuery(ctx context.Context, params map[string]interface{}) (interface{
 	}
 
 	// Get the performance analyzer
-	analyzer := GetPerformanceAnalyzer()
+	analyzer := GetPerformanceAnalyzer(databaseID)
 
 	// Execute query with performance tracking
 	var result interface{}
@@ -78,7 +78,7 @@ func createQueryBuilderTool() *tools.Tool {
 						},
 						"from": map[string]interface{}{
 							"type":        "string",
-							"description": "Table to select from",
+							"description": "Table to select from, optionally qualified as schema.table and followed by an alias",
 						},
 						"joins": map[string]interface{}{
 							"type":        "array",
@@ -162,9 +162,17 @@ func createQueryBuilderTool() *tools.Tool {
 						},
 					},
 				},
+				"schema": map[string]interface{}{
+					"type":        "string",
+					"description": "Schema used to qualify unqualified from and join tables (defaults to the connection's default_schema)",
+				},
+				"explainFallback": map[string]interface{}{
+					"type":        "boolean",
+					"description": "For analyze: only plan a data-modifying statement when the connection is read-only, instead of failing (default: false)",
+				},
 				"timeout": map[string]interface{}{
 					"type":        "integer",
-					"description": "Execution timeout in milliseconds (default: 5000)",
+					"description": "Execution timeout in milliseconds (default: the connection's query_timeout)",
 				},
 				"database": map[string]interface{}{
 					"type":        "string",
@@ -235,6 +243,13 @@ func handleQueryBuilder(ctx context.Context, params map[string]interface{}) (int
 		if err := validateQueryComponents(&components); err != nil {
 			return nil, fmt.Errorf("invalid query components: %w", err)
 		}
+		schema, _ := getStringParam(params, "schema")
+		resolve := func(schema string) (string, error) {
+			return ResolveSchema(databaseID, schema)
+		}
+		if err := qualifyQueryTables(&components, db.DriverName(), schema, resolve); err != nil {
+			return nil, fmt.Errorf("invalid query components: %w", err)
+		}
 		builtQuery, err := buildQueryFromComponents(&components)
 		if err != nil {
 			return nil, fmt.Errorf("failed to build query: %w", err)
@@ -244,7 +259,8 @@ func handleQueryBuilder(ctx context.Context, params map[string]interface{}) (int
 		if query == "" {
 			return nil, fmt.Errorf("query parameter is required for analyze action")
 		}
-		return analyzeQueryPlan(timeoutCtx, db, query)
+		fallback, _ := params["explainFallback"].(bool)
+		return analyzeQueryPlan(timeoutCtx, db, query, fallback)
 	default:
 		return nil, fmt.Errorf("invalid action: %s", action)
 	}
@@ -394,6 +410,70 @@ func validateQueryComponents(components *QueryComponents) error {
 	return nil
 }
 
+// qualifyQueryTables qualifies and quotes the FROM and JOIN tables. resolve
+// applies the connection's schema settings; tables that resolve to no schema
+// are left as is.
+func qualifyQueryTables(components *QueryComponents, dbType, schema string, resolve func(string) (string, error)) error {
+	from, err := qualifyTableReference(components.From, dbType, schema, resolve)
+	if err != nil {
+		return err
+	}
+	components.From = from
+
+	for i := range components.Joins {
+		table, err := qualifyTableReference(components.Joins[i].Table, dbType, schema, resolve)
+		if err != nil {
+			return err
+		}
+		components.Joins[i].Table = table
+	}
+	return nil
+}
+
+// qualifyTableReference qualifies a table reference of the form
+// "[schema.]table [[AS] alias]"
+func qualifyTableReference(ref, dbType, schema string, resolve func(string) (string, error)) (string, error) {
+	ref = strings.TrimSpace(ref)
+
+	// Split off the alias at the first space outside a quoted identifier
+	name, alias := ref, ""
+	var quote rune
+	for i, r := range ref {
+		if quote != 0 {
+			if r == quote {
+				quote = 0
+			}
+			continue
+		}
+		if r == '"' || r == '`' {
+			quote = r
+		} else if r == ' ' || r == '\t' || r == '\n' {
+			name, alias = ref[:i], ref[i:]
+			break
+		}
+	}
+
+	tableSchema, table, err := db.ParseQualifiedName(name)
+	if err != nil {
+		return "", err
+	}
+	if tableSchema != "" && schema != "" && tableSchema != schema {
+		return "", fmt.Errorf("table %q is qualified with schema %q but schema parameter is %q", name, tableSchema, schema)
+	}
+	if tableSchema == "" {
+		tableSchema = schema
+	}
+
+	tableSchema, err = resolve(tableSchema)
+	if err != nil {
+		return "", err
+	}
+	if tableSchema == "" {
+		return ref, nil
+	}
+	return db.QuoteQualifiedName(dbType, tableSchema, table) + alias, nil
+}
+
 // buildQueryFromComponents builds a SQL query from components
 func buildQueryFromComponents(components *QueryComponents) (string, error) {
 	var query strings.Builder
@@ -489,31 +569,26 @@ func validateQuery(ctx context.Context, db db.Database, query string) (interface
 	}, nil
 }
 
-// analyzeQueryPlan analyzes a specific query for performance
-func analyzeQueryPlan(ctx context.Context, db db.Database, query string) (interface{}, error) {
-	explainQuery := "EXPLAIN (FORMAT JSON, ANALYZE, BUFFERS) " + query
-	rows, err := db.Query(ctx, explainQuery)
+// analyzeQueryPlan executes a query under EXPLAIN ANALYZE where the database
+// supports it, rolling back data-modifying statements, and returns its plan
+// tree with the issues found in it and the mode used
+func analyzeQueryPlan(ctx context.Context, db db.Database, query string, fallback bool) (interface{}, error) {
+	plan, err := AnalyzePlan(ctx, db, query, fallback)
 	if err != nil {
 		return nil, fmt.Errorf("failed to analyze query: %w", err)
 	}
-	defer func() {
-		if err := rows.Close(); err != nil {
-			logger.Error("error closing rows: %v", err)
-		}
-	}()
 
-	var plan []byte
-	if !rows.Next() {
-		return nil, fmt.Errorf("no explain plan returned")
+	result := map[string]interface{}{
+		"query":    query,
+		"mode":     plan.Mode,
+		"analyzed": plan.Analyzed,
+		"plan":     plan,
+		"issues":   plan.Issues(),
 	}
-	if err := rows.Scan(&plan); err != nil {
-		return nil, fmt.Errorf("failed to scan explain plan: %w", err)
+	if plan.Note != "" {
+		result["note"] = plan.Note
 	}
-
-	return map[string]interface{}{
-		"query": query,
-		"plan":  string(plan),
-	}, nil
+	return result, nil
 }
 
 // Helper function to calculate query complexity
@@ -193,3 +193,33 @@ func TestGetTableFromQuery(t *testing.T) {
 	// Test with no FROM clause
 	assert.Equal(t, "unknown_table", getTableFromQuery("SELECT 1 + 1"))
 }
+
+// TestQualifyQueryTables tests schema qualification of FROM and JOIN tables
+func TestQualifyQueryTables(t *testing.T) {
+	passthrough := func(schema string) (string, error) { return schema, nil }
+
+	components := QueryComponents{
+		From:  "orders o",
+		Joins: []JoinClause{{Type: "inner", Table: `"Audit".log AS l`, On: "l.order_id = o.id"}},
+	}
+	assert.NoError(t, qualifyQueryTables(&components, "postgres", "", func(schema string) (string, error) {
+		if schema == "" {
+			return "sales", nil
+		}
+		return schema, nil
+	}))
+	assert.Equal(t, `"sales"."orders" o`, components.From)
+	assert.Equal(t, `"Audit"."log" AS l`, components.Joins[0].Table)
+
+	// Unqualified tables without a schema are left untouched
+	ref, err := qualifyTableReference("Orders", "mysql", "", passthrough)
+	assert.NoError(t, err)
+	assert.Equal(t, "Orders", ref)
+
+	ref, err = qualifyTableReference("order-items", "mysql", "shop", passthrough)
+	assert.NoError(t, err)
+	assert.Equal(t, "`shop`.`order-items`", ref)
+
+	_, err = qualifyTableReference("hr.staff", "postgres", "sales", passthrough)
+	assert.Error(t, err)
+}
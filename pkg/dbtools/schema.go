@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/FreePeak/db-mcp-server/pkg/db"
@@ -13,53 +14,104 @@ import (
 
 // DatabaseStrategy defines the interface for database-specific query strategies
 type DatabaseStrategy interface {
+	GetSchemasQueries() []queryWithArgs
 	GetTablesQueries() []queryWithArgs
 	GetColumnsQueries(table string) []queryWithArgs
 	GetRelationshipsQueries(table string) []queryWithArgs
+	GetIndexesQueries(table string) []queryWithArgs
+	GetConstraintsQueries(table string) []queryWithArgs
+	GetViewsQueries() []queryWithArgs
+	GetSequencesQueries() []queryWithArgs
+	GetTriggersQueries(table string) []queryWithArgs
+	GetRoutinesQueries() []queryWithArgs
+	GetSchemaColumnsQueries() []queryWithArgs
+	GetRowEstimatesQueries() []queryWithArgs
+	GetUniqueKeysQueries() []queryWithArgs
 }
 
-// NewDatabaseStrategy creates the appropriate strategy for the given database type
-func NewDatabaseStrategy(driverName string) DatabaseStrategy {
+// NewDatabaseStrategy creates the appropriate strategy for the given database
+// type, scoped to a schema. An empty schema means the driver default: public
+// for PostgreSQL and the connected database for MySQL.
+func NewDatabaseStrategy(driverName, schema string) DatabaseStrategy {
 	switch driverName {
 	case "postgres":
-		return &PostgresStrategy{}
+		return &PostgresStrategy{Schema: schema}
 	case "mysql":
-		return &MySQLStrategy{}
+		return &MySQLStrategy{Schema: schema}
 	default:
 		logger.Warn("Unknown database driver: %s, will use generic strategy", driverName)
 		return &GenericStrategy{}
 	}
 }
 
-// PostgresStrategy implements DatabaseStrategy for PostgreSQL
-type PostgresStrategy struct{}
+// PostgresStrategy implements DatabaseStrategy for PostgreSQL. Queries bind
+// the schema as $1 and, where applicable, the table as $2.
+type PostgresStrategy struct {
+	Schema string
+}
 
-// GetTablesQueries returns queries for retrieving tables in PostgreSQL
+// schema returns the schema queries are scoped to
+func (s *PostgresStrategy) schema() string {
+	if s.Schema == "" {
+		return "public"
+	}
+	return s.Schema
+}
+
+// scoped builds a query that binds the schema as $1
+func (s *PostgresStrategy) scoped(query string) queryWithArgs {
+	return queryWithArgs{query: query, args: []interface{}{s.schema()}}
+}
+
+// GetSchemasQueries returns queries for listing schemas in PostgreSQL
+func (s *PostgresStrategy) GetSchemasQueries() []queryWithArgs {
+	return []queryWithArgs{
+		{query: `
+			SELECT nspname AS schema_name
+			FROM pg_catalog.pg_namespace
+			WHERE nspname NOT IN ('pg_catalog', 'information_schema')
+				AND nspname NOT LIKE 'pg\_toast%'
+				AND nspname NOT LIKE 'pg\_temp\_%'
+			ORDER BY nspname`},
+		{query: `
+			SELECT schema_name
+			FROM information_schema.schemata
+			WHERE schema_name NOT IN ('pg_catalog', 'information_schema')
+			ORDER BY schema_name`},
+	}
+}
+
+// GetTablesQueries returns queries for retrieving tables and their comments
+// in PostgreSQL
 func (s *PostgresStrategy) GetTablesQueries() []queryWithArgs {
+	args := []interface{}{s.schema()}
 	return []queryWithArgs{
 		// Primary: pg_catalog approach
-		{query: "SELECT tablename as table_name FROM pg_catalog.pg_tables WHERE schemaname = 'public'"},
+		{query: "SELECT tablename as table_name, obj_description(format('%I.%I', schemaname, tablename)::regclass, 'pg_class') as description FROM pg_catalog.pg_tables WHERE schemaname = $1", args: args},
 		// Secondary: information_schema approach
-		{query: "SELECT table_name FROM information_schema.tables WHERE table_schema = 'public'"},
+		{query: "SELECT table_name FROM information_schema.tables WHERE table_schema = $1", args: args},
 		// Tertiary: pg_class approach
-		{query: "SELECT relname as table_name FROM pg_catalog.pg_class WHERE relkind = 'r' AND relnamespace = (SELECT oid FROM pg_catalog.pg_namespace WHERE nspname = 'public')"},
+		{query: "SELECT relname as table_name, obj_description(oid, 'pg_class') as description FROM pg_catalog.pg_class WHERE relkind = 'r' AND relnamespace = (SELECT oid FROM pg_catalog.pg_namespace WHERE nspname = $1)", args: args},
 	}
 }
 
-// GetColumnsQueries returns queries for retrieving columns in PostgreSQL
+// GetColumnsQueries returns queries for retrieving columns and their comments
+// in PostgreSQL
 func (s *PostgresStrategy) GetColumnsQueries(table string) []queryWithArgs {
+	args := []interface{}{s.schema(), table}
 	return []queryWithArgs{
 		// Primary: information_schema approach for PostgreSQL
 		{
 			query: `
 				SELECT column_name, data_type, 
 				CASE WHEN is_nullable = 'YES' THEN 'YES' ELSE 'NO' END as is_nullable,
-				column_default
+				column_default,
+				col_description(format('%I.%I', table_schema, table_name)::regclass, ordinal_position) as description
 				FROM information_schema.columns 
-				WHERE table_name = $1 AND table_schema = 'public'
+				WHERE table_schema = $1 AND table_name = $2
 				ORDER BY ordinal_position
 			`,
-			args: []interface{}{table},
+			args: args,
 		},
 		// Secondary: pg_catalog approach for PostgreSQL
 		{
@@ -67,14 +119,15 @@ func (s *PostgresStrategy) GetColumnsQueries(table string) []queryWithArgs {
 				SELECT a.attname as column_name, 
 				pg_catalog.format_type(a.atttypid, a.atttypmod) as data_type,
 				CASE WHEN a.attnotnull THEN 'NO' ELSE 'YES' END as is_nullable,
-				pg_catalog.pg_get_expr(d.adbin, d.adrelid) as column_default
+				pg_catalog.pg_get_expr(d.adbin, d.adrelid) as column_default,
+				col_description(a.attrelid, a.attnum) as description
 				FROM pg_catalog.pg_attribute a
 				LEFT JOIN pg_catalog.pg_attrdef d ON (a.attrelid = d.adrelid AND a.attnum = d.adnum)
-				WHERE a.attrelid = (SELECT oid FROM pg_catalog.pg_class WHERE relname = $1 AND relnamespace = (SELECT oid FROM pg_catalog.pg_namespace WHERE nspname = 'public'))
+				WHERE a.attrelid = (SELECT oid FROM pg_catalog.pg_class WHERE relname = $2 AND relnamespace = (SELECT oid FROM pg_catalog.pg_namespace WHERE nspname = $1))
 				AND a.attnum > 0 AND NOT a.attisdropped
 				ORDER BY a.attnum
 			`,
-			args: []interface{}{table},
+			args: args,
 		},
 	}
 }
@@ -101,9 +154,9 @@ func (s *PostgresStrategy) GetRelationshipsQueries(table string) []queryWithArgs
 					ON ccu.constraint_name = tc.constraint_name
 					AND ccu.table_schema = tc.table_schema
 				WHERE tc.constraint_type = 'FOREIGN KEY'
-					AND tc.table_schema = 'public'
+					AND tc.table_schema = $1
 			`,
-			args: []interface{}{},
+			args: []interface{}{s.schema()},
 		},
 		// Alternate: Using pg_catalog for older PostgreSQL versions
 		{
@@ -124,9 +177,9 @@ func (s *PostgresStrategy) GetRelationshipsQueries(table string) []queryWithArgs
 				JOIN pg_attribute att2 ON att2.attrelid = cl2.oid AND att2.attnum = ANY(c.confkey)
 				JOIN pg_namespace ns2 ON ns2.oid = cl2.relnamespace
 				WHERE c.contype = 'f'
-				AND ns.nspname = 'public'
+				AND ns.nspname = $1
 			`,
-			args: []interface{}{},
+			args: []interface{}{s.schema()},
 		},
 	}
 
@@ -134,53 +187,78 @@ func (s *PostgresStrategy) GetRelationshipsQueries(table string) []queryWithArgs
 		return baseQueries
 	}
 
-	queries := make([]queryWithArgs, len(baseQueries))
-
 	// Add table filter
-	queries[0] = queryWithArgs{
-		query: baseQueries[0].query + " AND (tc.table_name = $1 OR ccu.table_name = $1)",
-		args:  []interface{}{table},
+	return []queryWithArgs{
+		withTableFilter(baseQueries[0], table, "(tc.table_name = $2 OR ccu.table_name = $2)", table),
+		withTableFilter(baseQueries[1], table, "(cl.relname = $2 OR cl2.relname = $2)", table),
 	}
+}
 
-	queries[1] = queryWithArgs{
-		query: baseQueries[1].query + " AND (cl.relname = $1 OR cl2.relname = $1)",
-		args:  []interface{}{table},
+// MySQLStrategy implements DatabaseStrategy for MySQL, where a schema is a
+// database
+type MySQLStrategy struct {
+	Schema string
+}
+
+// schemaFilter returns the expression and arguments selecting the schema
+func (s *MySQLStrategy) schemaFilter() (string, []interface{}) {
+	if s.Schema == "" {
+		return "DATABASE()", nil
 	}
+	return "?", []interface{}{s.Schema}
+}
 
-	return queries
+// scoped builds a query whose text contains a single {schema} placeholder
+func (s *MySQLStrategy) scoped(query string) queryWithArgs {
+	expr, args := s.schemaFilter()
+	return queryWithArgs{query: strings.Replace(query, "{schema}", expr, 1), args: args}
 }
 
-// MySQLStrategy implements DatabaseStrategy for MySQL
-type MySQLStrategy struct{}
+// GetSchemasQueries returns queries for listing schemas (databases) in MySQL
+func (s *MySQLStrategy) GetSchemasQueries() []queryWithArgs {
+	return []queryWithArgs{
+		{query: `
+			SELECT schema_name AS schema_name
+			FROM information_schema.schemata
+			WHERE schema_name NOT IN ('mysql', 'information_schema', 'performance_schema', 'sys')
+			ORDER BY schema_name`},
+		{query: "SHOW DATABASES"},
+	}
+}
 
-// GetTablesQueries returns queries for retrieving tables in MySQL
+// GetTablesQueries returns queries for retrieving tables and their comments
+// in MySQL
 func (s *MySQLStrategy) GetTablesQueries() []queryWithArgs {
+	show := "SHOW TABLES"
+	if s.Schema != "" {
+		show += " FROM " + db.QuoteIdentifier("mysql", s.Schema)
+	}
 	return []queryWithArgs{
 		// Primary: information_schema approach
-		{query: "SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE()"},
+		s.scoped("SELECT table_name AS table_name, NULLIF(table_comment, '') AS description FROM information_schema.tables WHERE table_schema = {schema}"),
 		// Secondary: SHOW TABLES approach
-		{query: "SHOW TABLES"},
+		{query: show},
 	}
 }
 
-// GetColumnsQueries returns queries for retrieving columns in MySQL
+// GetColumnsQueries returns queries for retrieving columns and their comments
+// in MySQL
 func (s *MySQLStrategy) GetColumnsQueries(table string) []queryWithArgs {
+	columns := s.scoped(`
+		SELECT column_name AS column_name, data_type AS data_type,
+			is_nullable AS is_nullable, column_default AS column_default,
+			NULLIF(column_comment, '') AS description
+		FROM information_schema.columns
+		WHERE table_schema = {schema} AND table_name = ?
+		ORDER BY ordinal_position
+	`)
+	columns.args = append(columns.args, table)
+
 	return []queryWithArgs{
 		// MySQL query for columns
-		{
-			query: `
-				SELECT column_name, data_type, is_nullable, column_default
-				FROM information_schema.columns
-				WHERE table_name = ? AND table_schema = DATABASE()
-				ORDER BY ordinal_position
-			`,
-			args: []interface{}{table},
-		},
+		columns,
 		// Fallback for older MySQL versions
-		{
-			query: `SHOW COLUMNS FROM ` + table,
-			args:  []interface{}{},
-		},
+		{query: "SHOW COLUMNS FROM " + db.QuoteQualifiedName("mysql", s.Schema, table)},
 	}
 }
 
@@ -188,13 +266,12 @@ func (s *MySQLStrategy) GetColumnsQueries(table string) []queryWithArgs {
 func (s *MySQLStrategy) GetRelationshipsQueries(table string) []queryWithArgs {
 	baseQueries := []queryWithArgs{
 		// Primary approach for MySQL
-		{
-			query: `
+		s.scoped(`
 				SELECT
-					tc.table_schema,
-					tc.constraint_name,
-					tc.table_name,
-					kcu.column_name,
+					tc.table_schema AS table_schema,
+					tc.constraint_name AS constraint_name,
+					tc.table_name AS table_name,
+					kcu.column_name AS column_name,
 					kcu.referenced_table_schema AS foreign_table_schema,
 					kcu.referenced_table_name AS foreign_table_name,
 					kcu.referenced_column_name AS foreign_column_name
@@ -203,52 +280,45 @@ func (s *MySQLStrategy) GetRelationshipsQueries(table string) []queryWithArgs {
 					ON tc.constraint_name = kcu.constraint_name
 					AND tc.table_schema = kcu.table_schema
 				WHERE tc.constraint_type = 'FOREIGN KEY'
-					AND tc.table_schema = DATABASE()
-			`,
-			args: []interface{}{},
-		},
+					AND tc.table_schema = {schema}
+			`),
 		// Fallback using simpler query for older MySQL versions
-		{
-			query: `
+		s.scoped(`
 				SELECT
 					kcu.constraint_schema AS table_schema,
-					kcu.constraint_name,
-					kcu.table_name,
-					kcu.column_name,
+					kcu.constraint_name AS constraint_name,
+					kcu.table_name AS table_name,
+					kcu.column_name AS column_name,
 					kcu.referenced_table_schema AS foreign_table_schema,
 					kcu.referenced_table_name AS foreign_table_name,
 					kcu.referenced_column_name AS foreign_column_name
 				FROM information_schema.key_column_usage kcu
 				WHERE kcu.referenced_table_name IS NOT NULL
-					AND kcu.constraint_schema = DATABASE()
-			`,
-			args: []interface{}{},
-		},
+					AND kcu.constraint_schema = {schema}
+			`),
 	}
 
 	if table == "" {
 		return baseQueries
 	}
 
-	queries := make([]queryWithArgs, len(baseQueries))
-
 	// Add table filter
-	queries[0] = queryWithArgs{
-		query: baseQueries[0].query + " AND (tc.table_name = ? OR kcu.referenced_table_name = ?)",
-		args:  []interface{}{table, table},
-	}
-
-	queries[1] = queryWithArgs{
-		query: baseQueries[1].query + " AND (kcu.table_name = ? OR kcu.referenced_table_name = ?)",
-		args:  []interface{}{table, table},
+	return []queryWithArgs{
+		withTableFilter(baseQueries[0], table, "(tc.table_name = ? OR kcu.referenced_table_name = ?)", table, table),
+		withTableFilter(baseQueries[1], table, "(kcu.table_name = ? OR kcu.referenced_table_name = ?)", table, table),
 	}
-
-	return queries
 }
 
 // GenericStrategy implements DatabaseStrategy for unknown database types
 type GenericStrategy struct{}
 
+// GetSchemasQueries returns generic queries for listing schemas
+func (s *GenericStrategy) GetSchemasQueries() []queryWithArgs {
+	return []queryWithArgs{
+		{query: "SELECT schema_name FROM information_schema.schemata ORDER BY schema_name"},
+	}
+}
+
 // GetTablesQueries returns generic queries for retrieving tables
 func (s *GenericStrategy) GetTablesQueries() []queryWithArgs {
 	return []queryWithArgs{
@@ -346,16 +416,20 @@ func createSchemaExplorerTool() *tools.Tool {
 			Properties: map[string]interface{}{
 				"component": map[string]interface{}{
 					"type":        "string",
-					"description": "Schema component to explore (tables, columns, relationships, or full)",
-					"enum":        []string{"tables", "columns", "relationships", "full"},
+					"description": "Schema component to explore (list_schemas, tables, columns, relationships, indexes, constraints, views, sequences, triggers, functions, or full)",
+					"enum":        SchemaComponents,
+				},
+				"schema": map[string]interface{}{
+					"type":        "string",
+					"description": "Schema (PostgreSQL) or database (MySQL) to explore (optional, defaults to the connection's default_schema)",
 				},
 				"table": map[string]interface{}{
 					"type":        "string",
-					"description": "Table name to explore (optional, leave empty for all tables)",
+					"description": "Table name to explore, optionally qualified as schema.table (optional, leave empty for all tables)",
 				},
 				"timeout": map[string]interface{}{
 					"type":        "integer",
-					"description": "Query timeout in milliseconds (default: 10000)",
+					"description": "Query timeout in milliseconds (default: the connection's query_timeout)",
 				},
 				"database": map[string]interface{}{
 					"type":        "string",
@@ -387,17 +461,18 @@ func handleSchemaExplorer(ctx context.Context, params map[string]interface{}) (i
 		return nil, fmt.Errorf("database parameter is required")
 	}
 
+	// Extract schema and table parameters (optional depending on component)
+	schema, _ := getStringParam(params, "schema")
+	table, _ := getStringParam(params, "table")
+
 	// Get database instance
-	db, err := dbManager.GetDatabase(databaseID)
+	database, err := dbManager.GetDatabase(databaseID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get database: %w", err)
 	}
 
-	// Extract table parameter (optional depending on component)
-	table, _ := getStringParam(params, "table")
-
-	// Extract timeout
-	timeout := 10000 // Default timeout: 10 seconds
+	// Extract timeout, defaulting to the database's query timeout
+	timeout := GetDatabaseQueryTimeout(database)
 	if timeoutParam, ok := getIntParam(params, "timeout"); ok {
 		timeout = timeoutParam
 	}
@@ -406,22 +481,11 @@ func handleSchemaExplorer(ctx context.Context, params map[string]interface{}) (i
 	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
 	defer cancel()
 
-	// Use actual database queries based on component type
-	switch component {
-	case "tables":
-		return getTables(timeoutCtx, db)
-	case "columns":
-		if table == "" {
-			return nil, fmt.Errorf("table parameter is required for columns component")
-		}
-		return getColumns(timeoutCtx, db, table)
-	case "relationships":
-		return getRelationships(timeoutCtx, db, table)
-	case "full":
-		return getFullSchema(timeoutCtx, db)
-	default:
-		return nil, fmt.Errorf("invalid component: %s", component)
+	result, err := DescribeSchema(timeoutCtx, databaseID, component, schema, table)
+	if err != nil {
+		return nil, err
 	}
+	return result, nil
 }
 
 // executeWithFallbacks executes a series of database queries with fallbacks
@@ -449,13 +513,13 @@ func executeWithFallbacks(ctx context.Context, db db.Database, queries []queryWi
 }
 
 // getTables retrieves the list of tables in the database
-func getTables(ctx context.Context, db db.Database) (interface{}, error) {
+func getTables(ctx context.Context, db db.Database, schema string) (interface{}, error) {
 	// Get database type from connected database
 	driverName := db.DriverName()
 	dbType := driverName
 
 	// Create the appropriate strategy
-	strategy := NewDatabaseStrategy(driverName)
+	strategy := NewDatabaseStrategy(driverName, schema)
 
 	// Get queries from strategy
 	queries := strategy.GetTablesQueries()
@@ -481,19 +545,20 @@ func getTables(ctx context.Context, db db.Database) (interface{}, error) {
 	}
 
 	return map[string]interface{}{
-		"tables": results,
+		"tables": withDescriptions(results),
 		"dbType": dbType,
+		"schema": schema,
 	}, nil
 }
 
 // getColumns retrieves the columns for a specific table
-func getColumns(ctx context.Context, db db.Database, table string) (interface{}, error) {
+func getColumns(ctx context.Context, db db.Database, schema, table string) (interface{}, error) {
 	// Get database type from connected database
 	driverName := db.DriverName()
 	dbType := driverName
 
 	// Create the appropriate strategy
-	strategy := NewDatabaseStrategy(driverName)
+	strategy := NewDatabaseStrategy(driverName, schema)
 
 	// Get queries from strategy
 	queries := strategy.GetColumnsQueries(table)
@@ -520,19 +585,20 @@ func getColumns(ctx context.Context, db db.Database, table string) (interface{},
 
 	return map[string]interface{}{
 		"table":   table,
-		"columns": results,
+		"columns": withDescriptions(results),
 		"dbType":  dbType,
+		"schema":  schema,
 	}, nil
 }
 
 // getRelationships retrieves the relationships for a table or all tables
-func getRelationships(ctx context.Context, db db.Database, table string) (interface{}, error) {
+func getRelationships(ctx context.Context, db db.Database, schema, table string) (interface{}, error) {
 	// Get database type from connected database
 	driverName := db.DriverName()
 	dbType := driverName
 
 	// Create the appropriate strategy
-	strategy := NewDatabaseStrategy(driverName)
+	strategy := NewDatabaseStrategy(driverName, schema)
 
 	// Get queries from strategy
 	queries := strategy.GetRelationshipsQueries(table)
@@ -561,6 +627,7 @@ func getRelationships(ctx context.Context, db db.Database, table string) (interf
 		"relationships": results,
 		"dbType":        dbType,
 		"table":         table,
+		"schema":        schema,
 	}, nil
 }
 
@@ -594,8 +661,8 @@ func safeGetString(m map[string]interface{}, key string) (string, error) {
 }
 
 // getFullSchema retrieves the complete database schema
-func getFullSchema(ctx context.Context, db db.Database) (interface{}, error) {
-	tablesResult, err := getTables(ctx, db)
+func getFullSchema(ctx context.Context, db db.Database, schema string) (interface{}, error) {
+	tablesResult, err := getTables(ctx, db, schema)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tables: %w", err)
 	}
@@ -618,7 +685,7 @@ func getFullSchema(ctx context.Context, db db.Database) (interface{}, error) {
 			return nil, fmt.Errorf("invalid table info: %w", err)
 		}
 
-		columnsResult, columnsErr := getColumns(ctx, db, tableName)
+		columnsResult, columnsErr := getColumns(ctx, db, schema, tableName)
 		if columnsErr != nil {
 			return nil, fmt.Errorf("failed to get columns for table %s: %w", tableName, columnsErr)
 		}
@@ -626,7 +693,7 @@ func getFullSchema(ctx context.Context, db db.Database) (interface{}, error) {
 	}
 
 	// Get all relationships
-	relationships, relErr := getRelationships(ctx, db, "")
+	relationships, relErr := getRelationships(ctx, db, schema, "")
 	if relErr != nil {
 		return nil, fmt.Errorf("failed to get relationships: %w", relErr)
 	}
@@ -639,6 +706,7 @@ func getFullSchema(ctx context.Context, db db.Database) (interface{}, error) {
 	return map[string]interface{}{
 		"tables":        tablesSlice,
 		"schema":        fullSchema,
+		"schemaName":    schema,
 		"relationships": relMap["relationships"],
 	}, nil
 }
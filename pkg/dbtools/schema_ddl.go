@@ -0,0 +1,597 @@
+package dbtools
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// getSchemaDDL exports a schema as executable DDL in the database's dialect:
+// tables with their columns, constraints and comments, foreign keys, views,
+// indexes and sequences, plus hypertables, compression, retention policies and
+// continuous aggregates on TimescaleDB
+func getSchemaDDL(ctx context.Context, database db.Database, schema string) (map[string]interface{}, error) {
+	var ddl string
+	var err error
+	switch database.DriverName() {
+	case "postgres":
+		ddl, err = exportPostgresDDL(ctx, database, &PostgresStrategy{Schema: schema})
+	case "mysql":
+		ddl, err = exportMySQLDDL(ctx, database, &MySQLStrategy{Schema: schema})
+	default:
+		return nil, fmt.Errorf("DDL export is not supported for %s databases", database.DriverName())
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"ddl":    ddl,
+		"dbType": database.DriverName(),
+		"schema": schema,
+	}, nil
+}
+
+//------------------------------------------------------------------------------
+// PostgreSQL
+//------------------------------------------------------------------------------
+
+// postgresSchemaObjects are the catalog rows a PostgreSQL export is rendered
+// from
+type postgresSchemaObjects struct {
+	schema      string
+	tables      []map[string]interface{}
+	columns     []map[string]interface{}
+	constraints []map[string]interface{}
+	indexes     []map[string]interface{}
+	sequences   []map[string]interface{}
+	views       []map[string]interface{}
+	timescale   *timescaleSchemaObjects // nil without TimescaleDB
+}
+
+// timescaleSchemaObjects are the TimescaleDB settings of a schema
+type timescaleSchemaObjects struct {
+	hypertables     []map[string]interface{}
+	spaceDimensions []map[string]interface{}
+	compressed      []map[string]interface{}
+	compression     []map[string]interface{}
+	policies        []map[string]interface{}
+	aggregates      []map[string]interface{}
+}
+
+// exportPostgresDDL reads the catalogs of a PostgreSQL schema and renders them
+// as DDL
+func exportPostgresDDL(ctx context.Context, database db.Database, s *PostgresStrategy) (string, error) {
+	objects := &postgresSchemaObjects{schema: s.schema()}
+	for _, part := range []struct {
+		rows      *[]map[string]interface{}
+		queries   []queryWithArgs
+		operation string
+	}{
+		{&objects.tables, s.ddlTablesQueries(), "get tables for DDL"},
+		{&objects.columns, s.ddlColumnsQueries(), "get columns for DDL"},
+		{&objects.constraints, s.ddlConstraintsQueries(), "get constraints for DDL"},
+		{&objects.indexes, s.ddlIndexesQueries(), "get indexes for DDL"},
+		{&objects.sequences, s.ddlSequencesQueries(), "get sequences for DDL"},
+		{&objects.views, s.ddlViewsQueries(), "get views for DDL"},
+	} {
+		rows, err := queryMaps(ctx, database, part.queries, part.operation)
+		if err != nil {
+			return "", err
+		}
+		*part.rows = rows
+	}
+
+	timescale, err := exportTimescaleObjects(ctx, database, s)
+	if err != nil {
+		return "", err
+	}
+	objects.timescale = timescale
+
+	return renderPostgresDDL(objects), nil
+}
+
+// exportTimescaleObjects reads hypertables and their settings, or returns nil
+// when TimescaleDB is not installed. Only the hypertables themselves are
+// required; the settings views of older TimescaleDB versions may be missing.
+func exportTimescaleObjects(ctx context.Context, database db.Database, s *PostgresStrategy) (*timescaleSchemaObjects, error) {
+	versions, err := queryMaps(ctx, database, []queryWithArgs{{query: timescaleVersionQuery}}, "get TimescaleDB version")
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, nil
+	}
+
+	objects := &timescaleSchemaObjects{}
+	objects.hypertables, err = queryMaps(ctx, database, hypertablesQueries(s.schema()), "get hypertables for DDL")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, part := range []struct {
+		rows      *[]map[string]interface{}
+		query     queryWithArgs
+		operation string
+	}{
+		{&objects.spaceDimensions, s.scoped(`
+			SELECT hypertable_name AS table_name, column_name, num_partitions
+			FROM timescaledb_information.dimensions
+			WHERE hypertable_schema = $1 AND dimension_type = 'Space'
+			ORDER BY hypertable_name, dimension_number`), "get space dimensions"},
+		{&objects.compressed, s.scoped(`
+			SELECT hypertable_name AS table_name
+			FROM timescaledb_information.hypertables
+			WHERE hypertable_schema = $1 AND compression_enabled
+			ORDER BY hypertable_name`), "get compressed hypertables"},
+		{&objects.compression, s.scoped(`
+			SELECT hypertable_name AS table_name, attname AS column_name,
+				segmentby_column_index, orderby_column_index, orderby_asc, orderby_nullsfirst
+			FROM timescaledb_information.compression_settings
+			WHERE hypertable_schema = $1
+			ORDER BY hypertable_name, segmentby_column_index, orderby_column_index`), "get compression settings"},
+		{&objects.policies, s.scoped(`
+			SELECT hypertable_name AS table_name, proc_name,
+				config->>'compress_after' AS compress_after,
+				config->>'drop_after' AS drop_after
+			FROM timescaledb_information.jobs
+			WHERE hypertable_schema = $1 AND proc_name IN ('policy_compression', 'policy_retention')
+			ORDER BY hypertable_name, proc_name`), "get TimescaleDB policies"},
+		{&objects.aggregates, s.scoped(`
+			SELECT view_name, view_definition AS definition, materialized_only
+			FROM timescaledb_information.continuous_aggregates
+			WHERE view_schema = $1
+			ORDER BY view_name`), "get continuous aggregates"},
+	} {
+		rows, err := queryMaps(ctx, database, []queryWithArgs{part.query}, part.operation)
+		if err != nil {
+			logger.Warn("DDL export without TimescaleDB settings: %v", err)
+			continue
+		}
+		*part.rows = rows
+	}
+	return objects, nil
+}
+
+// ddlTablesQueries returns queries listing the tables of a schema with their
+// comments
+func (s *PostgresStrategy) ddlTablesQueries() []queryWithArgs {
+	return []queryWithArgs{s.scoped(`
+		SELECT c.relname AS table_name, obj_description(c.oid, 'pg_class') AS description
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relkind IN ('r', 'p')
+		ORDER BY c.relname`)}
+}
+
+// ddlColumnsQueries returns queries listing every table column with its full
+// type, default, identity and generation expression
+func (s *PostgresStrategy) ddlColumnsQueries() []queryWithArgs {
+	build := func(identity, generated string) queryWithArgs {
+		return s.scoped(`
+			SELECT c.relname AS table_name, a.attname AS column_name,
+				pg_catalog.format_type(a.atttypid, a.atttypmod) AS column_type,
+				a.attnotnull AS not_null,
+				pg_catalog.pg_get_expr(d.adbin, d.adrelid) AS column_default,
+				` + identity + ` AS identity,
+				` + generated + ` AS generated,
+				pg_catalog.col_description(c.oid, a.attnum) AS description
+			FROM pg_catalog.pg_attribute a
+			JOIN pg_catalog.pg_class c ON c.oid = a.attrelid
+			JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+			LEFT JOIN pg_catalog.pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+			WHERE n.nspname = $1 AND c.relkind IN ('r', 'p') AND a.attnum > 0 AND NOT a.attisdropped
+			ORDER BY c.relname, a.attnum`)
+	}
+
+	// Identity columns exist from PostgreSQL 10, generated columns from 12
+	return []queryWithArgs{
+		build("a.attidentity::text", "a.attgenerated::text"),
+		build("a.attidentity::text", "''"),
+		build("''", "''"),
+	}
+}
+
+// ddlConstraintsQueries returns queries listing primary key, unique, check,
+// exclusion and foreign key constraints with their definitions
+func (s *PostgresStrategy) ddlConstraintsQueries() []queryWithArgs {
+	return []queryWithArgs{s.scoped(`
+		SELECT t.relname AS table_name, c.conname AS constraint_name,
+			c.contype::text AS constraint_type,
+			pg_catalog.pg_get_constraintdef(c.oid, true) AS definition
+		FROM pg_catalog.pg_constraint c
+		JOIN pg_catalog.pg_class t ON t.oid = c.conrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = $1 AND c.contype IN ('p', 'u', 'c', 'x', 'f')
+		ORDER BY t.relname, CASE c.contype WHEN 'p' THEN 0 ELSE 1 END, c.conname`)}
+}
+
+// ddlIndexesQueries returns queries listing the definitions of indexes that
+// do not back a constraint
+func (s *PostgresStrategy) ddlIndexesQueries() []queryWithArgs {
+	return []queryWithArgs{s.scoped(`
+		SELECT t.relname AS table_name, i.relname AS index_name,
+			pg_catalog.pg_get_indexdef(ix.indexrelid) AS definition
+		FROM pg_catalog.pg_index ix
+		JOIN pg_catalog.pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_catalog.pg_class t ON t.oid = ix.indrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = $1 AND t.relkind IN ('r', 'p', 'm')
+			AND NOT EXISTS (
+				SELECT 1 FROM pg_catalog.pg_constraint c
+				WHERE c.conrelid = ix.indrelid AND c.conindid = ix.indexrelid
+					AND c.contype IN ('p', 'u', 'x')
+			)
+		ORDER BY t.relname, i.relname`)}
+}
+
+// ddlSequencesQueries returns queries listing sequences, except those of
+// identity columns, together with the column owning them
+func (s *PostgresStrategy) ddlSequencesQueries() []queryWithArgs {
+	return []queryWithArgs{s.scoped(`
+		SELECT c.relname AS sequence_name, sq.data_type::text AS data_type,
+			sq.start_value, sq.min_value, sq.max_value, sq.increment_by, sq.cycle,
+			(
+				SELECT pg_catalog.format('%I.%I', t.relname, a.attname)
+				FROM pg_catalog.pg_depend d
+				JOIN pg_catalog.pg_class t ON t.oid = d.refobjid
+				JOIN pg_catalog.pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+				WHERE d.classid = 'pg_catalog.pg_class'::regclass AND d.objid = c.oid AND d.deptype = 'a'
+				LIMIT 1
+			) AS owned_by
+		FROM pg_catalog.pg_sequences sq
+		JOIN pg_catalog.pg_namespace n ON n.nspname = sq.schemaname
+		JOIN pg_catalog.pg_class c ON c.relnamespace = n.oid AND c.relname = sq.sequencename
+		WHERE sq.schemaname = $1
+			AND NOT EXISTS (
+				SELECT 1 FROM pg_catalog.pg_depend d
+				WHERE d.classid = 'pg_catalog.pg_class'::regclass AND d.objid = c.oid AND d.deptype = 'i'
+			)
+		ORDER BY c.relname`)}
+}
+
+// ddlViewsQueries returns queries listing views and materialized views in
+// creation order, so views come after the views they select from
+func (s *PostgresStrategy) ddlViewsQueries() []queryWithArgs {
+	return []queryWithArgs{s.scoped(`
+		SELECT c.relname AS view_name,
+			CASE c.relkind WHEN 'm' THEN 'MATERIALIZED VIEW' ELSE 'VIEW' END AS view_type,
+			pg_catalog.pg_get_viewdef(c.oid, true) AS definition
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relkind IN ('v', 'm')
+		ORDER BY c.oid`)}
+}
+
+// renderPostgresDDL renders a PostgreSQL schema as DDL. Foreign keys are added
+// once all tables exist, so tables can be created in name order.
+func renderPostgresDDL(objects *postgresSchemaObjects) string {
+	qualify := func(name string) string { return db.QuoteQualifiedName("postgres", objects.schema, name) }
+	var statements []string
+	add := func(format string, args ...interface{}) {
+		statements = append(statements, fmt.Sprintf(format, args...))
+	}
+
+	if objects.timescale != nil {
+		add("CREATE EXTENSION IF NOT EXISTS timescaledb;")
+	}
+	if objects.schema != "public" {
+		add("CREATE SCHEMA IF NOT EXISTS %s;", db.QuoteIdentifier("postgres", objects.schema))
+	}
+
+	for _, seq := range objects.sequences {
+		stmt := "CREATE SEQUENCE " + qualify(ddlString(seq["sequence_name"]))
+		if dataType := ddlString(seq["data_type"]); dataType != "" {
+			stmt += " AS " + dataType
+		}
+		stmt += fmt.Sprintf(" INCREMENT BY %s MINVALUE %s MAXVALUE %s START WITH %s",
+			ddlString(seq["increment_by"]), ddlString(seq["min_value"]), ddlString(seq["max_value"]), ddlString(seq["start_value"]))
+		if ddlBool(seq["cycle"]) {
+			stmt += " CYCLE"
+		}
+		add("%s;", stmt)
+	}
+
+	columns := groupByTable(objects.columns)
+	constraints := groupByTable(objects.constraints)
+	for _, table := range objects.tables {
+		name := ddlString(table["table_name"])
+		var lines []string
+		for _, column := range columns[name] {
+			lines = append(lines, "    "+postgresColumnDDL(column))
+		}
+		for _, constraint := range constraints[name] {
+			if ddlString(constraint["constraint_type"]) == "f" {
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("    CONSTRAINT %s %s",
+				db.QuoteIdentifier("postgres", ddlString(constraint["constraint_name"])), ddlString(constraint["definition"])))
+		}
+		add("CREATE TABLE %s (\n%s\n);", qualify(name), strings.Join(lines, ",\n"))
+
+		if description := ddlString(table["description"]); description != "" {
+			add("COMMENT ON TABLE %s IS %s;", qualify(name), db.QuoteLiteral(description))
+		}
+		for _, column := range columns[name] {
+			if description := ddlString(column["description"]); description != "" {
+				add("COMMENT ON COLUMN %s.%s IS %s;", qualify(name),
+					db.QuoteIdentifier("postgres", ddlString(column["column_name"])), db.QuoteLiteral(description))
+			}
+		}
+	}
+
+	aggregates := make(map[string]bool)
+	if objects.timescale != nil {
+		statements = append(statements, timescaleTableDDL(objects.timescale, qualify)...)
+		for _, aggregate := range objects.timescale.aggregates {
+			aggregates[ddlString(aggregate["view_name"])] = true
+		}
+	}
+
+	for _, table := range objects.tables {
+		name := ddlString(table["table_name"])
+		for _, constraint := range constraints[name] {
+			if ddlString(constraint["constraint_type"]) == "f" {
+				add("ALTER TABLE %s ADD CONSTRAINT %s %s;", qualify(name),
+					db.QuoteIdentifier("postgres", ddlString(constraint["constraint_name"])), ddlString(constraint["definition"]))
+			}
+		}
+	}
+
+	if objects.timescale != nil {
+		for _, aggregate := range objects.timescale.aggregates {
+			with := "timescaledb.continuous"
+			if ddlBool(aggregate["materialized_only"]) {
+				with += ", timescaledb.materialized_only = true"
+			}
+			add("CREATE MATERIALIZED VIEW %s WITH (%s) AS\n%s\nWITH NO DATA;",
+				qualify(ddlString(aggregate["view_name"])), with, viewBody(aggregate["definition"]))
+		}
+	}
+
+	for _, view := range objects.views {
+		name := ddlString(view["view_name"])
+		if aggregates[name] {
+			continue
+		}
+		if ddlString(view["view_type"]) == "MATERIALIZED VIEW" {
+			add("CREATE MATERIALIZED VIEW %s AS\n%s\nWITH NO DATA;", qualify(name), viewBody(view["definition"]))
+		} else {
+			add("CREATE VIEW %s AS\n%s;", qualify(name), viewBody(view["definition"]))
+		}
+	}
+
+	for _, index := range objects.indexes {
+		add("%s;", ddlString(index["definition"]))
+	}
+
+	for _, seq := range objects.sequences {
+		if owner := ddlString(seq["owned_by"]); owner != "" {
+			add("ALTER SEQUENCE %s OWNED BY %s.%s;", qualify(ddlString(seq["sequence_name"])),
+				db.QuoteIdentifier("postgres", objects.schema), owner)
+		}
+	}
+
+	return fmt.Sprintf("-- PostgreSQL schema %s\n\n%s\n", objects.schema, strings.Join(statements, "\n\n"))
+}
+
+// postgresColumnDDL renders a column definition of a CREATE TABLE statement
+func postgresColumnDDL(column map[string]interface{}) string {
+	def := db.QuoteIdentifier("postgres", ddlString(column["column_name"])) + " " + ddlString(column["column_type"])
+	switch {
+	case ddlString(column["identity"]) == "a":
+		def += " GENERATED ALWAYS AS IDENTITY"
+	case ddlString(column["identity"]) == "d":
+		def += " GENERATED BY DEFAULT AS IDENTITY"
+	case ddlString(column["generated"]) == "s":
+		def += " GENERATED ALWAYS AS (" + ddlString(column["column_default"]) + ") STORED"
+	case ddlString(column["column_default"]) != "":
+		def += " DEFAULT " + ddlString(column["column_default"])
+	}
+	if ddlBool(column["not_null"]) {
+		def += " NOT NULL"
+	}
+	return def
+}
+
+// timescaleTableDDL turns tables into hypertables and restores their extra
+// dimensions, compression settings and policies. Indexes are exported
+// separately, so create_hypertable must not add its default ones.
+func timescaleTableDDL(objects *timescaleSchemaObjects, qualify func(string) string) []string {
+	var statements []string
+	regclass := func(table string) string { return db.QuoteLiteral(qualify(table)) }
+
+	for _, hypertable := range objects.hypertables {
+		table := ddlString(hypertable["table_name"])
+		stmt := fmt.Sprintf("SELECT create_hypertable(%s, %s", regclass(table), db.QuoteLiteral(ddlString(hypertable["time_column"])))
+		if interval := ddlString(hypertable["chunk_interval"]); interval != "" {
+			stmt += ", chunk_time_interval => " + intervalLiteral(interval)
+		}
+		statements = append(statements, stmt+", create_default_indexes => false);")
+
+		for _, dimension := range objects.spaceDimensions {
+			if ddlString(dimension["table_name"]) != table {
+				continue
+			}
+			statements = append(statements, fmt.Sprintf("SELECT add_dimension(%s, %s, number_partitions => %s);",
+				regclass(table), db.QuoteLiteral(ddlString(dimension["column_name"])), ddlString(dimension["num_partitions"])))
+		}
+	}
+
+	for _, compressed := range objects.compressed {
+		table := ddlString(compressed["table_name"])
+		var segmentBy, orderBy []string
+		for _, setting := range objects.compression {
+			if ddlString(setting["table_name"]) != table {
+				continue
+			}
+			column := db.QuoteIdentifier("postgres", ddlString(setting["column_name"]))
+			if ddlString(setting["segmentby_column_index"]) != "" {
+				segmentBy = append(segmentBy, column)
+			}
+			if ddlString(setting["orderby_column_index"]) != "" {
+				asc, nullsFirst := ddlBool(setting["orderby_asc"]), ddlBool(setting["orderby_nullsfirst"])
+				switch {
+				case asc && nullsFirst:
+					column += " NULLS FIRST"
+				case !asc && !nullsFirst:
+					column += " DESC NULLS LAST"
+				case !asc:
+					column += " DESC"
+				}
+				orderBy = append(orderBy, column)
+			}
+		}
+
+		options := []string{"timescaledb.compress"}
+		if len(segmentBy) > 0 {
+			options = append(options, "timescaledb.compress_segmentby = "+db.QuoteLiteral(strings.Join(segmentBy, ", ")))
+		}
+		if len(orderBy) > 0 {
+			options = append(options, "timescaledb.compress_orderby = "+db.QuoteLiteral(strings.Join(orderBy, ", ")))
+		}
+		statements = append(statements, fmt.Sprintf("ALTER TABLE %s SET (%s);", qualify(table), strings.Join(options, ", ")))
+	}
+
+	for _, policy := range objects.policies {
+		table := ddlString(policy["table_name"])
+		switch ddlString(policy["proc_name"]) {
+		case "policy_compression":
+			if after := ddlString(policy["compress_after"]); after != "" {
+				statements = append(statements, fmt.Sprintf("SELECT add_compression_policy(%s, %s);", regclass(table), intervalLiteral(after)))
+			}
+		case "policy_retention":
+			if after := ddlString(policy["drop_after"]); after != "" {
+				statements = append(statements, fmt.Sprintf("SELECT add_retention_policy(%s, %s);", regclass(table), intervalLiteral(after)))
+			}
+		}
+	}
+	return statements
+}
+
+// intervalLiteral renders a TimescaleDB interval setting: integer time
+// columns use plain numbers, time columns an INTERVAL literal
+func intervalLiteral(value string) string {
+	if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return value
+	}
+	return "INTERVAL " + db.QuoteLiteral(value)
+}
+
+// viewBody trims the trailing semicolon pg_get_viewdef ends a definition with
+func viewBody(definition interface{}) string {
+	return strings.TrimRight(strings.TrimSpace(ddlString(definition)), ";")
+}
+
+//------------------------------------------------------------------------------
+// MySQL
+//------------------------------------------------------------------------------
+
+// mysqlEnvironmentClauses match the parts of SHOW CREATE output that describe
+// the server a schema was read from rather than the schema itself
+var mysqlEnvironmentClauses = []*regexp.Regexp{
+	regexp.MustCompile(` AUTO_INCREMENT=\d+`),
+	regexp.MustCompile(" DEFINER=`(?:[^`]|``)*`@`(?:[^`]|``)*`"),
+}
+
+// exportMySQLDDL renders a MySQL schema from SHOW CREATE TABLE and SHOW
+// CREATE VIEW, which already include columns, indexes and constraints
+func exportMySQLDDL(ctx context.Context, database db.Database, s *MySQLStrategy) (string, error) {
+	objects, err := queryMaps(ctx, database, s.ddlObjectsQueries(), "get tables for DDL")
+	if err != nil {
+		return "", err
+	}
+
+	var statements []string
+	for _, object := range objects {
+		name := ddlString(object["table_name"])
+		kind, column := "TABLE", "Create Table"
+		if ddlString(object["table_type"]) == "VIEW" {
+			kind, column = "VIEW", "Create View"
+		}
+
+		query := fmt.Sprintf("SHOW CREATE %s %s", kind, db.QuoteQualifiedName("mysql", s.Schema, name))
+		rows, err := queryMaps(ctx, database, []queryWithArgs{{query: query}}, "show create "+strings.ToLower(kind)+" "+name)
+		if err != nil {
+			return "", err
+		}
+		if len(rows) == 0 {
+			return "", fmt.Errorf("SHOW CREATE %s %s returned no rows", kind, name)
+		}
+		statements = append(statements, ddlString(rows[0][column]))
+	}
+
+	return renderMySQLDDL(s.Schema, statements), nil
+}
+
+// ddlObjectsQueries returns queries listing base tables, then views
+func (s *MySQLStrategy) ddlObjectsQueries() []queryWithArgs {
+	return []queryWithArgs{s.scoped(`
+		SELECT table_name AS table_name, table_type AS table_type
+		FROM information_schema.tables
+		WHERE table_schema = {schema} AND table_type IN ('BASE TABLE', 'VIEW')
+		ORDER BY table_type = 'VIEW', table_name`)}
+}
+
+// renderMySQLDDL joins SHOW CREATE statements into a script. Foreign key
+// checks are disabled while it runs, so tables can be created in name order.
+func renderMySQLDDL(schema string, statements []string) string {
+	header := "-- MySQL schema"
+	if schema != "" {
+		header += " " + schema
+	}
+
+	var out strings.Builder
+	out.WriteString(header + "\n\nSET FOREIGN_KEY_CHECKS = 0;\n")
+	for _, stmt := range statements {
+		for _, clause := range mysqlEnvironmentClauses {
+			stmt = clause.ReplaceAllString(stmt, "")
+		}
+		out.WriteString("\n" + stmt + ";\n")
+	}
+	out.WriteString("\nSET FOREIGN_KEY_CHECKS = 1;\n")
+	return out.String()
+}
+
+//------------------------------------------------------------------------------
+// Helpers
+//------------------------------------------------------------------------------
+
+// groupByTable groups catalog rows by their table_name, keeping their order
+func groupByTable(rows []map[string]interface{}) map[string][]map[string]interface{} {
+	grouped := make(map[string][]map[string]interface{})
+	for _, row := range rows {
+		name := ddlString(row["table_name"])
+		grouped[name] = append(grouped[name], row)
+	}
+	return grouped
+}
+
+// ddlString formats a catalog value, mapping NULL to ""
+func ddlString(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// ddlBool interprets a boolean catalog value
+func ddlBool(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	case string:
+		return v == "t" || v == "1" || strings.EqualFold(v, "true")
+	}
+	return false
+}
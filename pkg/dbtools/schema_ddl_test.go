@@ -0,0 +1,170 @@
+package dbtools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDDLQueriesBindSchema(t *testing.T) {
+	pg := &PostgresStrategy{Schema: "sales"}
+	all := map[string][]queryWithArgs{
+		"tables":      pg.ddlTablesQueries(),
+		"columns":     pg.ddlColumnsQueries(),
+		"constraints": pg.ddlConstraintsQueries(),
+		"indexes":     pg.ddlIndexesQueries(),
+		"sequences":   pg.ddlSequencesQueries(),
+		"views":       pg.ddlViewsQueries(),
+	}
+	for name, queries := range all {
+		require.NotEmpty(t, queries, name)
+		for _, q := range queries {
+			assert.Equal(t, []interface{}{"sales"}, q.args, name)
+		}
+	}
+
+	mysql := (&MySQLStrategy{Schema: "shop"}).ddlObjectsQueries()
+	require.Len(t, mysql, 1)
+	assert.Equal(t, []interface{}{"shop"}, mysql[0].args)
+	assert.NotContains(t, mysql[0].query, "{schema}")
+}
+
+func TestRenderPostgresDDL(t *testing.T) {
+	objects := &postgresSchemaObjects{
+		schema: "public",
+		tables: []map[string]interface{}{
+			{"table_name": "customers", "description": "People who buy things"},
+			{"table_name": "orders", "description": nil},
+		},
+		columns: []map[string]interface{}{
+			{"table_name": "customers", "column_name": "id", "column_type": "integer", "not_null": true, "identity": "d"},
+			{"table_name": "customers", "column_name": "email", "column_type": "text", "not_null": false, "description": "Login, it's unique"},
+			{"table_name": "orders", "column_name": "id", "column_type": "bigint", "not_null": true, "column_default": "nextval('orders_id_seq'::regclass)"},
+			{"table_name": "orders", "column_name": "customer_id", "column_type": "integer", "not_null": true},
+			{"table_name": "orders", "column_name": "total_cents", "column_type": "bigint", "column_default": "0"},
+			{"table_name": "orders", "column_name": "total", "column_type": "numeric", "generated": "s", "column_default": "(total_cents / 100.0)"},
+		},
+		constraints: []map[string]interface{}{
+			{"table_name": "customers", "constraint_name": "customers_pkey", "constraint_type": "p", "definition": "PRIMARY KEY (id)"},
+			{"table_name": "orders", "constraint_name": "orders_pkey", "constraint_type": "p", "definition": "PRIMARY KEY (id)"},
+			{"table_name": "orders", "constraint_name": "orders_customer_id_fkey", "constraint_type": "f", "definition": "FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE CASCADE"},
+		},
+		indexes: []map[string]interface{}{
+			{"table_name": "orders", "index_name": "orders_customer_idx", "definition": "CREATE INDEX orders_customer_idx ON public.orders USING btree (customer_id)"},
+		},
+		sequences: []map[string]interface{}{
+			{"sequence_name": "orders_id_seq", "data_type": "bigint", "start_value": int64(1), "min_value": int64(1),
+				"max_value": int64(9223372036854775807), "increment_by": int64(1), "cycle": false, "owned_by": "orders.id"},
+		},
+		views: []map[string]interface{}{
+			{"view_name": "big_orders", "view_type": "VIEW", "definition": " SELECT id\n   FROM orders\n  WHERE total > 100::numeric;"},
+		},
+	}
+
+	ddl := renderPostgresDDL(objects)
+	assert.NotContains(t, ddl, "timescaledb")
+	assert.NotContains(t, ddl, "CREATE SCHEMA")
+
+	expected := []string{
+		`CREATE SEQUENCE "public"."orders_id_seq" AS bigint INCREMENT BY 1 MINVALUE 1 MAXVALUE 9223372036854775807 START WITH 1;`,
+		"CREATE TABLE \"public\".\"customers\" (\n    \"id\" integer GENERATED BY DEFAULT AS IDENTITY NOT NULL,\n    \"email\" text,\n    CONSTRAINT \"customers_pkey\" PRIMARY KEY (id)\n);",
+		`COMMENT ON TABLE "public"."customers" IS 'People who buy things';`,
+		`COMMENT ON COLUMN "public"."customers"."email" IS 'Login, it''s unique';`,
+		`    "id" bigint DEFAULT nextval('orders_id_seq'::regclass) NOT NULL,`,
+		`    "total" numeric GENERATED ALWAYS AS ((total_cents / 100.0)) STORED`,
+		`ALTER TABLE "public"."orders" ADD CONSTRAINT "orders_customer_id_fkey" FOREIGN KEY (customer_id) REFERENCES customers(id) ON DELETE CASCADE;`,
+		"CREATE VIEW \"public\".\"big_orders\" AS\nSELECT id\n   FROM orders\n  WHERE total > 100::numeric;",
+		"CREATE INDEX orders_customer_idx ON public.orders USING btree (customer_id);",
+		`ALTER SEQUENCE "public"."orders_id_seq" OWNED BY "public".orders.id;`,
+	}
+	last := -1
+	for _, stmt := range expected {
+		pos := strings.Index(ddl, stmt)
+		require.GreaterOrEqual(t, pos, 0, "missing %q in\n%s", stmt, ddl)
+		assert.Greater(t, pos, last, "%q is out of order", stmt)
+		last = pos
+	}
+
+	// Foreign keys are only added once every table exists
+	assert.NotContains(t, ddl, `    CONSTRAINT "orders_customer_id_fkey"`)
+}
+
+func TestRenderPostgresDDLTimescale(t *testing.T) {
+	objects := &postgresSchemaObjects{
+		schema: "metrics",
+		tables: []map[string]interface{}{{"table_name": "readings"}},
+		columns: []map[string]interface{}{
+			{"table_name": "readings", "column_name": "time", "column_type": "timestamp with time zone", "not_null": true},
+			{"table_name": "readings", "column_name": "device_id", "column_type": "integer", "not_null": true},
+			{"table_name": "readings", "column_name": "value", "column_type": "double precision"},
+		},
+		views: []map[string]interface{}{
+			{"view_name": "readings_hourly", "view_type": "VIEW", "definition": "SELECT ..."},
+		},
+		timescale: &timescaleSchemaObjects{
+			hypertables: []map[string]interface{}{
+				{"schema_name": "metrics", "table_name": "readings", "time_column": "time", "chunk_interval": "7 days"},
+			},
+			spaceDimensions: []map[string]interface{}{
+				{"table_name": "readings", "column_name": "device_id", "num_partitions": int64(4)},
+			},
+			compressed: []map[string]interface{}{{"table_name": "readings"}},
+			compression: []map[string]interface{}{
+				{"table_name": "readings", "column_name": "device_id", "segmentby_column_index": int64(1)},
+				{"table_name": "readings", "column_name": "time", "orderby_column_index": int64(1), "orderby_asc": false, "orderby_nullsfirst": true},
+			},
+			policies: []map[string]interface{}{
+				{"table_name": "readings", "proc_name": "policy_compression", "compress_after": "7 days"},
+				{"table_name": "readings", "proc_name": "policy_retention", "drop_after": "90 days"},
+			},
+			aggregates: []map[string]interface{}{
+				{"view_name": "readings_hourly", "definition": " SELECT time_bucket('01:00:00'::interval, readings.\"time\") AS bucket\n   FROM metrics.readings\n  GROUP BY bucket;", "materialized_only": true},
+			},
+		},
+	}
+
+	ddl := renderPostgresDDL(objects)
+	expected := []string{
+		"CREATE EXTENSION IF NOT EXISTS timescaledb;",
+		`CREATE SCHEMA IF NOT EXISTS "metrics";`,
+		`CREATE TABLE "metrics"."readings" (`,
+		`SELECT create_hypertable('"metrics"."readings"', 'time', chunk_time_interval => INTERVAL '7 days', create_default_indexes => false);`,
+		`SELECT add_dimension('"metrics"."readings"', 'device_id', number_partitions => 4);`,
+		`ALTER TABLE "metrics"."readings" SET (timescaledb.compress, timescaledb.compress_segmentby = '"device_id"', timescaledb.compress_orderby = '"time" DESC');`,
+		`SELECT add_compression_policy('"metrics"."readings"', INTERVAL '7 days');`,
+		`SELECT add_retention_policy('"metrics"."readings"', INTERVAL '90 days');`,
+		"CREATE MATERIALIZED VIEW \"metrics\".\"readings_hourly\" WITH (timescaledb.continuous, timescaledb.materialized_only = true) AS\nSELECT time_bucket",
+		"GROUP BY bucket\nWITH NO DATA;",
+	}
+	last := -1
+	for _, stmt := range expected {
+		pos := strings.Index(ddl, stmt)
+		require.GreaterOrEqual(t, pos, 0, "missing %q in\n%s", stmt, ddl)
+		assert.Greater(t, pos, last, "%q is out of order", stmt)
+		last = pos
+	}
+
+	// The continuous aggregate is not repeated as a plain view
+	assert.NotContains(t, ddl, "CREATE VIEW")
+}
+
+func TestIntervalLiteral(t *testing.T) {
+	assert.Equal(t, "INTERVAL '1 day'", intervalLiteral("1 day"))
+	assert.Equal(t, "86400000000", intervalLiteral("86400000000"))
+}
+
+func TestRenderMySQLDDL(t *testing.T) {
+	ddl := renderMySQLDDL("shop", []string{
+		"CREATE TABLE `orders` (\n  `id` int NOT NULL AUTO_INCREMENT,\n  PRIMARY KEY (`id`)\n) ENGINE=InnoDB AUTO_INCREMENT=1042 DEFAULT CHARSET=utf8mb4",
+		"CREATE ALGORITHM=UNDEFINED DEFINER=`admin`@`%` SQL SECURITY DEFINER VIEW `recent_orders` AS select `orders`.`id` AS `id` from `orders`",
+	})
+
+	assert.True(t, strings.HasPrefix(ddl, "-- MySQL schema shop\n\nSET FOREIGN_KEY_CHECKS = 0;\n"))
+	assert.True(t, strings.HasSuffix(ddl, "SET FOREIGN_KEY_CHECKS = 1;\n"))
+	assert.Contains(t, ddl, "`id` int NOT NULL AUTO_INCREMENT,")
+	assert.Contains(t, ddl, ") ENGINE=InnoDB DEFAULT CHARSET=utf8mb4;")
+	assert.Contains(t, ddl, "CREATE ALGORITHM=UNDEFINED SQL SECURITY DEFINER VIEW `recent_orders`")
+	assert.NotContains(t, ddl, "admin")
+}
@@ -0,0 +1,64 @@
+package dbtools
+
+import (
+	"context"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// GetUniqueKeysQueries returns queries for the columns of every primary key
+// and unique index in PostgreSQL. Partial and expression indexes are left
+// out, as they do not make a column set unique.
+func (s *PostgresStrategy) GetUniqueKeysQueries() []queryWithArgs {
+	return []queryWithArgs{s.scoped(`
+		SELECT t.relname AS table_name, i.relname AS index_name, a.attname AS column_name
+		FROM pg_catalog.pg_index x
+		JOIN pg_catalog.pg_class t ON t.oid = x.indrelid
+		JOIN pg_catalog.pg_class i ON i.oid = x.indexrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_catalog.pg_attribute a ON a.attrelid = t.oid AND a.attnum = ANY(x.indkey)
+		WHERE n.nspname = $1 AND x.indisunique AND x.indpred IS NULL AND x.indexprs IS NULL
+		ORDER BY t.relname, i.relname, a.attnum`)}
+}
+
+// GetUniqueKeysQueries returns queries for the columns of every primary key
+// and unique index in MySQL
+func (s *MySQLStrategy) GetUniqueKeysQueries() []queryWithArgs {
+	return []queryWithArgs{s.scoped(`
+		SELECT table_name AS table_name, index_name AS index_name, column_name AS column_name
+		FROM information_schema.statistics
+		WHERE table_schema = {schema} AND non_unique = 0 AND column_name IS NOT NULL
+		ORDER BY table_name, index_name, seq_in_index`)}
+}
+
+// GetUniqueKeysQueries returns no queries; unique indexes are not portable
+func (s *GenericStrategy) GetUniqueKeysQueries() []queryWithArgs {
+	return nil
+}
+
+// getSchemaERD collects what an entity relationship diagram needs: the
+// summary model of tables, columns and foreign keys plus the unique keys that
+// decide cardinality. The table, when set, is the diagram's focus.
+func getSchemaERD(ctx context.Context, database db.Database, schema, table string) (map[string]interface{}, error) {
+	model, err := getSchemaSummary(ctx, database, schema)
+	if err != nil {
+		return nil, err
+	}
+
+	// Without unique keys every relationship is drawn as one-to-many
+	uniqueKeys := []map[string]interface{}{}
+	strategy := NewDatabaseStrategy(database.DriverName(), schema)
+	if queries := strategy.GetUniqueKeysQueries(); len(queries) > 0 {
+		rows, err := queryMaps(ctx, database, queries, "get unique keys")
+		if err != nil {
+			logger.Warn("ER diagram without unique keys: %v", err)
+		} else {
+			uniqueKeys = rows
+		}
+	}
+
+	model["unique_keys"] = uniqueKeys
+	model["table"] = table
+	return model, nil
+}
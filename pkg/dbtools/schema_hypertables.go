@@ -0,0 +1,94 @@
+package dbtools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// timescaleVersionQuery reports the installed TimescaleDB extension version
+const timescaleVersionQuery = "SELECT extversion FROM pg_extension WHERE extname = 'timescaledb'"
+
+// getHypertables reports the TimescaleDB extension version and the time
+// dimension of each hypertable. Databases without TimescaleDB return a nil
+// version and no hypertables.
+func getHypertables(ctx context.Context, database db.Database, schema string) (map[string]interface{}, error) {
+	out := map[string]interface{}{
+		"timescaledb_version": nil,
+		"hypertables":         []map[string]interface{}{},
+		"dbType":              database.DriverName(),
+		"schema":              schema,
+	}
+	if database.DriverName() != "postgres" {
+		return out, nil
+	}
+
+	versions, err := queryMaps(ctx, database, []queryWithArgs{{query: timescaleVersionQuery}}, "get TimescaleDB version")
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return out, nil
+	}
+	out["timescaledb_version"] = versions[0]["extversion"]
+
+	hypertables, err := queryMaps(ctx, database, hypertablesQueries(schema), "get hypertables")
+	if err != nil {
+		return nil, err
+	}
+	if hypertables != nil {
+		out["hypertables"] = hypertables
+	}
+	return out, nil
+}
+
+// hypertablesQueries returns queries listing hypertables and their time
+// dimension, optionally limited to one schema
+func hypertablesQueries(schema string) []queryWithArgs {
+	info := queryWithArgs{query: `
+		SELECT
+			hypertable_schema AS schema_name,
+			hypertable_name AS table_name,
+			column_name AS time_column,
+			COALESCE(time_interval::text, integer_interval::text) AS chunk_interval
+		FROM timescaledb_information.dimensions
+		WHERE dimension_type = 'Time'`}
+	info = withTableFilter(info, schema, "hypertable_schema = $1", schema)
+	info.query += " ORDER BY hypertable_schema, hypertable_name"
+
+	// TimescaleDB 1.x has no dimensions view
+	catalog := queryWithArgs{query: `
+		SELECT
+			h.schema_name AS schema_name,
+			h.table_name AS table_name,
+			d.column_name AS time_column,
+			d.interval_length::text AS chunk_interval
+		FROM _timescaledb_catalog.hypertable h
+		JOIN _timescaledb_catalog.dimension d ON h.id = d.hypertable_id
+		WHERE d.interval_length IS NOT NULL`}
+	catalog = withTableFilter(catalog, schema, "h.schema_name = $1", schema)
+	catalog.query += " ORDER BY h.schema_name, h.table_name"
+
+	return []queryWithArgs{info, catalog}
+}
+
+// queryMaps runs queries with fallbacks and returns the rows as maps
+func queryMaps(ctx context.Context, database db.Database, queries []queryWithArgs, operation string) ([]map[string]interface{}, error) {
+	rows, err := executeWithFallbacks(ctx, database, queries, operation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s: %w", operation, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Error("error closing rows: %v", err)
+		}
+	}()
+
+	results, err := rowsToMaps(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to %s: %w", operation, err)
+	}
+	return results, nil
+}
@@ -0,0 +1,652 @@
+package dbtools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// SchemaComponents lists the schema components that can be explored
+var SchemaComponents = []string{
+	"list_schemas", "tables", "columns", "relationships", "indexes", "constraints",
+	"views", "sequences", "triggers", "functions", "hypertables", "summary", "erd", "full", "ddl",
+}
+
+// GetSchemaComponent retrieves a single schema component from a database.
+// The schema scopes every component except list_schemas; empty means the
+// driver default. The table argument is required for columns and optional for
+// relationships, indexes, constraints and triggers; erd keeps it as the
+// diagram's focus and the other components ignore it.
+func GetSchemaComponent(ctx context.Context, database db.Database, component, schema, table string) (map[string]interface{}, error) {
+	var result interface{}
+	var err error
+
+	switch component {
+	case "tables":
+		result, err = getTables(ctx, database, schema)
+	case "columns":
+		if table == "" {
+			return nil, fmt.Errorf("table parameter is required for columns component")
+		}
+		result, err = getColumns(ctx, database, schema, table)
+	case "relationships":
+		result, err = getRelationships(ctx, database, schema, table)
+	case "full":
+		result, err = getFullSchema(ctx, database, schema)
+	case "list_schemas":
+		return getSchemaObjects(ctx, database, component, "", "")
+	case "indexes", "constraints", "triggers":
+		return getSchemaObjects(ctx, database, component, schema, table)
+	case "views", "sequences", "functions":
+		return getSchemaObjects(ctx, database, component, schema, "")
+	case "hypertables":
+		return getHypertables(ctx, database, schema)
+	case "summary":
+		return getSchemaSummary(ctx, database, schema)
+	case "erd":
+		return getSchemaERD(ctx, database, schema, table)
+	case "ddl":
+		return getSchemaDDL(ctx, database, schema)
+	default:
+		return nil, fmt.Errorf("invalid component: %s", component)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return safeGetMap(result)
+}
+
+// DescribeSchema retrieves a schema component for a configured database. The
+// table may be qualified as schema.table; the schema is resolved against the
+// connection's default_schema and schema_allowlist, which also filters the
+// list_schemas component. Descriptions from the connection's data dictionary
+// are merged into tables, columns and full results.
+func DescribeSchema(ctx context.Context, dbID, component, schema, table string) (map[string]interface{}, error) {
+	if dbManager == nil {
+		return nil, fmt.Errorf("database manager not initialized")
+	}
+
+	database, err := dbManager.GetDatabase(dbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database: %w", err)
+	}
+	cfg, err := dbManager.GetConnectionConfig(dbID)
+	if err != nil {
+		return nil, err
+	}
+
+	if component == "list_schemas" {
+		result, err := GetSchemaComponent(ctx, database, component, "", "")
+		if err != nil {
+			return nil, err
+		}
+		result["schemas"] = filterAllowedSchemas(cfg, result["schemas"])
+		return result, nil
+	}
+
+	schema, table, err = resolveTableName(cfg, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	result, err := GetSchemaComponent(ctx, database, component, schema, table)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.DataDictionary != "" {
+		dict, err := LoadDataDictionary(cfg.DataDictionary)
+		if err != nil {
+			return nil, err
+		}
+		dict.Apply(component, schema, table, result)
+	}
+	return result, nil
+}
+
+// ResolveSchema returns the schema to use for a configured database, applying
+// its default_schema and schema_allowlist
+func ResolveSchema(dbID, schema string) (string, error) {
+	if dbManager == nil {
+		return "", fmt.Errorf("database manager not initialized")
+	}
+	cfg, err := dbManager.GetConnectionConfig(dbID)
+	if err != nil {
+		return "", err
+	}
+	return cfg.ResolveSchema(schema)
+}
+
+// MigrationsDir returns the migrations directory configured for a database
+func MigrationsDir(dbID string) (string, error) {
+	if dbManager == nil {
+		return "", fmt.Errorf("database manager not initialized")
+	}
+	cfg, err := dbManager.GetConnectionConfig(dbID)
+	if err != nil {
+		return "", err
+	}
+	if cfg.MigrationsDir == "" {
+		return "", fmt.Errorf("no migrations_dir configured for database %s", dbID)
+	}
+	return cfg.MigrationsDir, nil
+}
+
+// resolveTableName splits a possibly qualified table name and resolves the
+// schema it belongs to
+func resolveTableName(cfg db.DatabaseConnectionConfig, schema, table string) (string, string, error) {
+	if table != "" {
+		tableSchema, name, err := db.ParseQualifiedName(table)
+		if err != nil {
+			return "", "", err
+		}
+		if tableSchema != "" {
+			if schema != "" && schema != tableSchema {
+				return "", "", fmt.Errorf("table %q is qualified with schema %q but schema parameter is %q", table, tableSchema, schema)
+			}
+			schema = tableSchema
+		}
+		table = name
+	}
+
+	resolved, err := cfg.ResolveSchema(schema)
+	if err != nil {
+		return "", "", err
+	}
+	return resolved, table, nil
+}
+
+// filterAllowedSchemas drops schemas outside the connection's allowlist
+func filterAllowedSchemas(cfg db.DatabaseConnectionConfig, schemas interface{}) interface{} {
+	rows, ok := schemas.([]map[string]interface{})
+	if !ok || len(cfg.SchemaAllowlist) == 0 {
+		return schemas
+	}
+
+	allowed := make([]map[string]interface{}, 0, len(rows))
+	for _, row := range rows {
+		if name, ok := row["schema_name"].(string); ok && cfg.SchemaAllowed(name) {
+			allowed = append(allowed, row)
+		}
+	}
+	return allowed
+}
+
+// getSchemaObjects retrieves schemas, indexes, constraints, views, sequences,
+// triggers or functions using the strategy for the database's driver
+func getSchemaObjects(ctx context.Context, database db.Database, component, schema, table string) (map[string]interface{}, error) {
+	strategy := NewDatabaseStrategy(database.DriverName(), schema)
+
+	var queries []queryWithArgs
+	switch component {
+	case "list_schemas":
+		queries = strategy.GetSchemasQueries()
+	case "indexes":
+		queries = strategy.GetIndexesQueries(table)
+	case "constraints":
+		queries = strategy.GetConstraintsQueries(table)
+	case "views":
+		queries = strategy.GetViewsQueries()
+	case "sequences":
+		queries = strategy.GetSequencesQueries()
+	case "triggers":
+		queries = strategy.GetTriggersQueries(table)
+	case "functions":
+		queries = strategy.GetRoutinesQueries()
+	default:
+		return nil, fmt.Errorf("invalid component: %s", component)
+	}
+
+	rows, err := executeWithFallbacks(ctx, database, queries, "get "+component)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", component, err)
+	}
+	defer func() {
+		if err := rows.Close(); err != nil {
+			logger.Error("error closing rows: %v", err)
+		}
+	}()
+
+	results, err := rowsToMaps(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to process %s: %w", component, err)
+	}
+	if results == nil {
+		results = []map[string]interface{}{}
+	}
+
+	key := component
+	if component == "list_schemas" {
+		key = "schemas"
+	}
+	out := map[string]interface{}{
+		key:      results,
+		"dbType": database.DriverName(),
+	}
+	if component != "list_schemas" {
+		out["schema"] = schema
+	}
+	if table != "" {
+		out["table"] = table
+	}
+	return out, nil
+}
+
+// withTableFilter appends a table filter to a query when a table is given
+func withTableFilter(q queryWithArgs, table, clause string, args ...interface{}) queryWithArgs {
+	if table == "" {
+		return q
+	}
+	q.query += " AND " + clause
+	q.args = append(q.args, args...)
+	return q
+}
+
+// withOrder appends an ORDER BY clause to each query
+func withOrder(queries []queryWithArgs, order string) []queryWithArgs {
+	for i := range queries {
+		queries[i].query += " ORDER BY " + order
+	}
+	return queries
+}
+
+//------------------------------------------------------------------------------
+// PostgreSQL
+//------------------------------------------------------------------------------
+
+// GetIndexesQueries returns queries for retrieving indexes in PostgreSQL
+func (s *PostgresStrategy) GetIndexesQueries(table string) []queryWithArgs {
+	catalog := s.scoped(`
+		SELECT
+			i.relname AS index_name,
+			t.relname AS table_name,
+			am.amname AS method,
+			ix.indisunique AS is_unique,
+			ix.indisprimary AS is_primary,
+			array_to_string(ARRAY(
+				SELECT pg_get_indexdef(ix.indexrelid, k + 1, true)
+				FROM generate_subscripts(ix.indkey, 1) AS k
+				ORDER BY k
+			), ', ') AS columns,
+			pg_get_expr(ix.indpred, ix.indrelid) AS predicate,
+			pg_get_indexdef(ix.indexrelid) AS definition
+		FROM pg_catalog.pg_index ix
+		JOIN pg_catalog.pg_class i ON i.oid = ix.indexrelid
+		JOIN pg_catalog.pg_class t ON t.oid = ix.indrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		JOIN pg_catalog.pg_am am ON am.oid = i.relam
+		WHERE n.nspname = $1`)
+
+	// pg_indexes only exposes the definition, but is available everywhere
+	fallback := s.scoped(`
+		SELECT indexname AS index_name, tablename AS table_name, indexdef AS definition
+		FROM pg_catalog.pg_indexes
+		WHERE schemaname = $1`)
+
+	return []queryWithArgs{
+		withTableFilter(catalog, table, "t.relname = $2", table),
+		withTableFilter(fallback, table, "tablename = $2", table),
+	}
+}
+
+// GetConstraintsQueries returns queries for retrieving primary key, unique and
+// check constraints in PostgreSQL
+func (s *PostgresStrategy) GetConstraintsQueries(table string) []queryWithArgs {
+	catalog := s.scoped(`
+		SELECT
+			c.conname AS constraint_name,
+			t.relname AS table_name,
+			CASE c.contype
+				WHEN 'p' THEN 'PRIMARY KEY'
+				WHEN 'u' THEN 'UNIQUE'
+				WHEN 'c' THEN 'CHECK'
+			END AS constraint_type,
+			array_to_string(ARRAY(
+				SELECT a.attname
+				FROM unnest(c.conkey) WITH ORDINALITY AS k(attnum, ord)
+				JOIN pg_catalog.pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = k.attnum
+				ORDER BY k.ord
+			), ', ') AS columns,
+			pg_get_constraintdef(c.oid, true) AS definition
+		FROM pg_catalog.pg_constraint c
+		JOIN pg_catalog.pg_class t ON t.oid = c.conrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+		WHERE n.nspname = $1 AND c.contype IN ('p', 'u', 'c')`)
+
+	fallback := s.scoped(`
+		SELECT
+			tc.constraint_name,
+			tc.table_name,
+			tc.constraint_type,
+			cc.check_clause AS definition
+		FROM information_schema.table_constraints tc
+		LEFT JOIN information_schema.check_constraints cc
+			ON cc.constraint_schema = tc.constraint_schema
+			AND cc.constraint_name = tc.constraint_name
+		WHERE tc.table_schema = $1
+			AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE', 'CHECK')`)
+
+	return []queryWithArgs{
+		withTableFilter(catalog, table, "t.relname = $2", table),
+		withTableFilter(fallback, table, "tc.table_name = $2", table),
+	}
+}
+
+// GetViewsQueries returns queries for retrieving views and materialized views
+// in PostgreSQL
+func (s *PostgresStrategy) GetViewsQueries() []queryWithArgs {
+	return []queryWithArgs{
+		s.scoped(`
+			SELECT viewname AS view_name, 'VIEW' AS view_type, definition
+			FROM pg_catalog.pg_views
+			WHERE schemaname = $1
+			UNION ALL
+			SELECT matviewname AS view_name, 'MATERIALIZED VIEW' AS view_type, definition
+			FROM pg_catalog.pg_matviews
+			WHERE schemaname = $1
+			ORDER BY view_name`),
+		s.scoped(`
+			SELECT table_name AS view_name, 'VIEW' AS view_type, view_definition AS definition
+			FROM information_schema.views
+			WHERE table_schema = $1
+			ORDER BY table_name`),
+	}
+}
+
+// GetSequencesQueries returns queries for retrieving sequences in PostgreSQL
+func (s *PostgresStrategy) GetSequencesQueries() []queryWithArgs {
+	return []queryWithArgs{
+		// pg_sequences is available from PostgreSQL 10
+		s.scoped(`
+			SELECT sequencename AS sequence_name, data_type, start_value, min_value,
+				max_value, increment_by, cycle, last_value
+			FROM pg_catalog.pg_sequences
+			WHERE schemaname = $1
+			ORDER BY sequencename`),
+		s.scoped(`
+			SELECT sequence_name, data_type, start_value, minimum_value AS min_value,
+				maximum_value AS max_value, increment AS increment_by, cycle_option AS cycle
+			FROM information_schema.sequences
+			WHERE sequence_schema = $1
+			ORDER BY sequence_name`),
+	}
+}
+
+// GetTriggersQueries returns queries for retrieving triggers in PostgreSQL
+func (s *PostgresStrategy) GetTriggersQueries(table string) []queryWithArgs {
+	catalog := s.scoped(`
+		SELECT
+			tg.tgname AS trigger_name,
+			c.relname AS table_name,
+			tg.tgenabled <> 'D' AS enabled,
+			pg_get_triggerdef(tg.oid, true) AS definition
+		FROM pg_catalog.pg_trigger tg
+		JOIN pg_catalog.pg_class c ON c.oid = tg.tgrelid
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND NOT tg.tgisinternal`)
+
+	fallback := s.scoped(`
+		SELECT
+			trigger_name,
+			event_object_table AS table_name,
+			action_timing,
+			event_manipulation,
+			action_statement AS definition
+		FROM information_schema.triggers
+		WHERE trigger_schema = $1`)
+
+	return withOrder([]queryWithArgs{
+		withTableFilter(catalog, table, "c.relname = $2", table),
+		withTableFilter(fallback, table, "event_object_table = $2", table),
+	}, "table_name, trigger_name")
+}
+
+// GetRoutinesQueries returns queries for retrieving stored functions and
+// procedures in PostgreSQL, excluding those installed by extensions
+func (s *PostgresStrategy) GetRoutinesQueries() []queryWithArgs {
+	return []queryWithArgs{
+		// prokind is available from PostgreSQL 11
+		s.scoped(`
+			SELECT
+				p.proname AS routine_name,
+				CASE p.prokind WHEN 'p' THEN 'PROCEDURE' ELSE 'FUNCTION' END AS routine_type,
+				pg_get_function_arguments(p.oid) AS arguments,
+				pg_get_function_result(p.oid) AS return_type,
+				l.lanname AS language,
+				pg_get_functiondef(p.oid) AS definition
+			FROM pg_catalog.pg_proc p
+			JOIN pg_catalog.pg_namespace n ON n.oid = p.pronamespace
+			JOIN pg_catalog.pg_language l ON l.oid = p.prolang
+			WHERE n.nspname = $1
+				AND p.prokind IN ('f', 'p')
+				AND NOT EXISTS (
+					SELECT 1 FROM pg_catalog.pg_depend d
+					WHERE d.classid = 'pg_catalog.pg_proc'::regclass
+						AND d.objid = p.oid AND d.deptype = 'e'
+				)
+			ORDER BY p.proname`),
+		s.scoped(`
+			SELECT
+				routine_name,
+				routine_type,
+				data_type AS return_type,
+				external_language AS language,
+				routine_definition AS definition
+			FROM information_schema.routines
+			WHERE routine_schema = $1
+			ORDER BY routine_name`),
+	}
+}
+
+//------------------------------------------------------------------------------
+// MySQL
+//------------------------------------------------------------------------------
+
+// GetIndexesQueries returns queries for retrieving indexes in MySQL. Columns
+// are aliased explicitly because MySQL 8 reports information_schema column
+// names in upper case.
+func (s *MySQLStrategy) GetIndexesQueries(table string) []queryWithArgs {
+	// Functional key parts report their expression instead of a column name
+	// from MySQL 8.0.13; the fallback covers older servers
+	build := func(column string) queryWithArgs {
+		q := withTableFilter(s.scoped(`
+			SELECT
+				index_name AS index_name,
+				table_name AS table_name,
+				index_type AS method,
+				CASE WHEN non_unique = 0 THEN 1 ELSE 0 END AS is_unique,
+				CASE WHEN index_name = 'PRIMARY' THEN 1 ELSE 0 END AS is_primary,
+				GROUP_CONCAT(`+column+` ORDER BY seq_in_index SEPARATOR ', ') AS columns,
+				NULL AS predicate
+			FROM information_schema.statistics
+			WHERE table_schema = {schema}`), table, "table_name = ?", table)
+		q.query += " GROUP BY table_name, index_name, index_type, non_unique ORDER BY table_name, index_name"
+		return q
+	}
+
+	return []queryWithArgs{
+		build("COALESCE(column_name, expression)"),
+		build("column_name"),
+	}
+}
+
+// GetConstraintsQueries returns queries for retrieving primary key, unique and
+// check constraints in MySQL
+func (s *MySQLStrategy) GetConstraintsQueries(table string) []queryWithArgs {
+	// information_schema.check_constraints exists from MySQL 8.0.16
+	build := func(withChecks bool) queryWithArgs {
+		definition, join := "NULL", ""
+		if withChecks {
+			definition = "cc.check_clause"
+			join = `
+			LEFT JOIN information_schema.check_constraints cc
+				ON cc.constraint_schema = tc.constraint_schema
+				AND cc.constraint_name = tc.constraint_name`
+		}
+
+		q := withTableFilter(s.scoped(`
+			SELECT
+				tc.constraint_name AS constraint_name,
+				tc.table_name AS table_name,
+				tc.constraint_type AS constraint_type,
+				GROUP_CONCAT(kcu.column_name ORDER BY kcu.ordinal_position SEPARATOR ', ') AS columns,
+				`+definition+` AS definition
+			FROM information_schema.table_constraints tc
+			LEFT JOIN information_schema.key_column_usage kcu
+				ON kcu.constraint_schema = tc.constraint_schema
+				AND kcu.constraint_name = tc.constraint_name
+				AND kcu.table_name = tc.table_name`+join+`
+			WHERE tc.table_schema = {schema}
+				AND tc.constraint_type IN ('PRIMARY KEY', 'UNIQUE', 'CHECK')`), table, "tc.table_name = ?", table)
+		q.query += " GROUP BY tc.constraint_name, tc.table_name, tc.constraint_type, definition ORDER BY tc.table_name, tc.constraint_name"
+		return q
+	}
+
+	return []queryWithArgs{build(true), build(false)}
+}
+
+// GetViewsQueries returns queries for retrieving views in MySQL, which has no
+// materialized views
+func (s *MySQLStrategy) GetViewsQueries() []queryWithArgs {
+	return []queryWithArgs{
+		s.scoped(`
+			SELECT table_name AS view_name, 'VIEW' AS view_type, view_definition AS definition, is_updatable AS is_updatable
+			FROM information_schema.views
+			WHERE table_schema = {schema}
+			ORDER BY table_name`),
+	}
+}
+
+// GetSequencesQueries returns AUTO_INCREMENT columns, MySQL's equivalent of
+// sequences
+func (s *MySQLStrategy) GetSequencesQueries() []queryWithArgs {
+	return []queryWithArgs{
+		s.scoped(`
+			SELECT
+				CONCAT(c.table_name, '.', c.column_name) AS sequence_name,
+				c.table_name AS table_name,
+				c.column_name AS column_name,
+				c.data_type AS data_type,
+				t.auto_increment AS next_value
+			FROM information_schema.columns c
+			JOIN information_schema.tables t
+				ON t.table_schema = c.table_schema AND t.table_name = c.table_name
+			WHERE c.table_schema = {schema} AND c.extra LIKE '%auto_increment%'
+			ORDER BY c.table_name`),
+	}
+}
+
+// GetTriggersQueries returns queries for retrieving triggers in MySQL
+func (s *MySQLStrategy) GetTriggersQueries(table string) []queryWithArgs {
+	q := withTableFilter(s.scoped(`
+		SELECT
+			trigger_name AS trigger_name,
+			event_object_table AS table_name,
+			action_timing AS action_timing,
+			event_manipulation AS event_manipulation,
+			action_statement AS definition
+		FROM information_schema.triggers
+		WHERE trigger_schema = {schema}`), table, "event_object_table = ?", table)
+
+	return withOrder([]queryWithArgs{q}, "event_object_table, trigger_name")
+}
+
+// GetRoutinesQueries returns queries for retrieving stored functions and
+// procedures in MySQL
+func (s *MySQLStrategy) GetRoutinesQueries() []queryWithArgs {
+	return []queryWithArgs{
+		s.scoped(`
+			SELECT
+				r.routine_name AS routine_name,
+				r.routine_type AS routine_type,
+				(
+					SELECT GROUP_CONCAT(
+						CONCAT_WS(' ', p.parameter_mode, p.parameter_name, p.dtd_identifier)
+						ORDER BY p.ordinal_position SEPARATOR ', ')
+					FROM information_schema.parameters p
+					WHERE p.specific_schema = r.routine_schema
+						AND p.specific_name = r.specific_name
+						AND p.ordinal_position > 0
+				) AS arguments,
+				r.dtd_identifier AS return_type,
+				r.routine_body AS language,
+				r.routine_definition AS definition
+			FROM information_schema.routines r
+			WHERE r.routine_schema = {schema}
+			ORDER BY r.routine_name`),
+		s.scoped(`
+			SELECT routine_name AS routine_name, routine_type AS routine_type, dtd_identifier AS return_type,
+				routine_body AS language, routine_definition AS definition
+			FROM information_schema.routines
+			WHERE routine_schema = {schema}
+			ORDER BY routine_name`),
+	}
+}
+
+//------------------------------------------------------------------------------
+// Generic
+//------------------------------------------------------------------------------
+
+// genericQueries builds PostgreSQL-style and MySQL-style variants of an
+// information_schema query with an optional table filter
+func genericQueries(query, tableColumn, table string) []queryWithArgs {
+	if table == "" {
+		return []queryWithArgs{{query: query}}
+	}
+	return []queryWithArgs{
+		withTableFilter(queryWithArgs{query: query}, table, tableColumn+" = $1", table),
+		withTableFilter(queryWithArgs{query: query}, table, tableColumn+" = ?", table),
+	}
+}
+
+// GetIndexesQueries returns generic queries for retrieving indexes
+func (s *GenericStrategy) GetIndexesQueries(table string) []queryWithArgs {
+	return []queryWithArgs{
+		withTableFilter(queryWithArgs{query: `
+			SELECT indexname AS index_name, tablename AS table_name, indexdef AS definition
+			FROM pg_indexes WHERE 1 = 1`}, table, "tablename = $1", table),
+		withTableFilter(queryWithArgs{query: `
+			SELECT index_name, table_name, column_name, non_unique
+			FROM information_schema.statistics WHERE 1 = 1`}, table, "table_name = ?", table),
+	}
+}
+
+// GetConstraintsQueries returns generic queries for retrieving constraints
+func (s *GenericStrategy) GetConstraintsQueries(table string) []queryWithArgs {
+	return genericQueries(`
+		SELECT constraint_name, table_name, constraint_type
+		FROM information_schema.table_constraints
+		WHERE constraint_type IN ('PRIMARY KEY', 'UNIQUE', 'CHECK')`, "table_name", table)
+}
+
+// GetViewsQueries returns generic queries for retrieving views
+func (s *GenericStrategy) GetViewsQueries() []queryWithArgs {
+	return genericQueries(`
+		SELECT table_name AS view_name, 'VIEW' AS view_type, view_definition AS definition
+		FROM information_schema.views WHERE 1 = 1`, "", "")
+}
+
+// GetSequencesQueries returns generic queries for retrieving sequences
+func (s *GenericStrategy) GetSequencesQueries() []queryWithArgs {
+	return genericQueries(`
+		SELECT sequence_name, data_type, start_value, increment
+		FROM information_schema.sequences WHERE 1 = 1`, "", "")
+}
+
+// GetTriggersQueries returns generic queries for retrieving triggers
+func (s *GenericStrategy) GetTriggersQueries(table string) []queryWithArgs {
+	return genericQueries(`
+		SELECT trigger_name, event_object_table AS table_name, action_timing,
+			event_manipulation, action_statement AS definition
+		FROM information_schema.triggers WHERE 1 = 1`, "event_object_table", table)
+}
+
+// GetRoutinesQueries returns generic queries for retrieving routines
+func (s *GenericStrategy) GetRoutinesQueries() []queryWithArgs {
+	return genericQueries(`
+		SELECT routine_name, routine_type, data_type AS return_type,
+			routine_definition AS definition
+		FROM information_schema.routines WHERE 1 = 1`, "", "")
+}
@@ -0,0 +1,193 @@
+package dbtools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+)
+
+func TestStrategyIntrospectionQueries(t *testing.T) {
+	tests := []struct {
+		name       string
+		strategy   DatabaseStrategy
+		schemaArgs []interface{}
+	}{
+		{"postgres", &PostgresStrategy{}, []interface{}{"public"}},
+		{"postgres schema", &PostgresStrategy{Schema: "sales"}, []interface{}{"sales"}},
+		{"mysql", &MySQLStrategy{}, nil},
+		{"mysql schema", &MySQLStrategy{Schema: "sales"}, []interface{}{"sales"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			all := map[string][]queryWithArgs{
+				"indexes":     tt.strategy.GetIndexesQueries(""),
+				"constraints": tt.strategy.GetConstraintsQueries(""),
+				"views":       tt.strategy.GetViewsQueries(),
+				"sequences":   tt.strategy.GetSequencesQueries(),
+				"triggers":    tt.strategy.GetTriggersQueries(""),
+				"functions":   tt.strategy.GetRoutinesQueries(),
+				"tables":      tt.strategy.GetTablesQueries()[:1],
+			}
+			for component, queries := range all {
+				require.NotEmpty(t, queries, component)
+				for _, q := range queries {
+					assert.Equal(t, tt.schemaArgs, q.args, "%s should only bind the schema", component)
+					assert.NotContains(t, q.query, "'public'", component)
+					assert.NotContains(t, q.query, "{schema}", component)
+				}
+			}
+
+			// Table filters bind the table after the schema
+			filtered := map[string][]queryWithArgs{
+				"indexes":     tt.strategy.GetIndexesQueries("users"),
+				"constraints": tt.strategy.GetConstraintsQueries("users"),
+				"triggers":    tt.strategy.GetTriggersQueries("users"),
+			}
+			for component, queries := range filtered {
+				for _, q := range queries {
+					assert.Equal(t, append(append([]interface{}{}, tt.schemaArgs...), "users"), q.args, component)
+					assert.NotContains(t, q.query, "'users'", "table must be bound, not inlined")
+				}
+			}
+		})
+	}
+}
+
+func TestGenericStrategyIntrospectionQueries(t *testing.T) {
+	strategy := &GenericStrategy{}
+	assert.NotEmpty(t, strategy.GetSchemasQueries())
+	for _, q := range strategy.GetIndexesQueries("users") {
+		assert.Equal(t, []interface{}{"users"}, q.args)
+	}
+	for _, q := range strategy.GetViewsQueries() {
+		assert.Empty(t, q.args)
+	}
+}
+
+func TestPostgresIndexQueryIncludesPredicateAndMethod(t *testing.T) {
+	q := (&PostgresStrategy{}).GetIndexesQueries("users")[0]
+	assert.Contains(t, q.query, "pg_get_expr(ix.indpred, ix.indrelid) AS predicate")
+	assert.Contains(t, q.query, "am.amname AS method")
+	assert.True(t, strings.HasSuffix(q.query, "t.relname = $2"))
+}
+
+func TestMySQLQueriesFilterBeforeGroupBy(t *testing.T) {
+	for _, q := range (&MySQLStrategy{}).GetIndexesQueries("users") {
+		assert.Less(t, strings.Index(q.query, "table_name = ?"), strings.Index(q.query, "GROUP BY"))
+	}
+	for _, q := range (&MySQLStrategy{}).GetConstraintsQueries("users") {
+		assert.Less(t, strings.Index(q.query, "tc.table_name = ?"), strings.Index(q.query, "GROUP BY"))
+	}
+}
+
+func TestMySQLShowFallbacksQuoteIdentifiers(t *testing.T) {
+	strategy := &MySQLStrategy{Schema: "sales-eu"}
+	assert.Equal(t, "SHOW TABLES FROM `sales-eu`", strategy.GetTablesQueries()[1].query)
+	assert.Equal(t, "SHOW COLUMNS FROM `sales-eu`.`order`", strategy.GetColumnsQueries("order")[1].query)
+}
+
+func TestResolveTableName(t *testing.T) {
+	cfg := db.DatabaseConnectionConfig{ID: "pg", Type: "postgres", SchemaAllowlist: []string{"public", "sales"}}
+
+	schema, table, err := resolveTableName(cfg, "", "sales.orders")
+	require.NoError(t, err)
+	assert.Equal(t, "sales", schema)
+	assert.Equal(t, "orders", table)
+
+	schema, table, err = resolveTableName(cfg, "", "orders")
+	require.NoError(t, err)
+	assert.Equal(t, "public", schema)
+	assert.Equal(t, "orders", table)
+
+	_, _, err = resolveTableName(cfg, "hr", "")
+	assert.Error(t, err)
+
+	_, _, err = resolveTableName(cfg, "public", "sales.orders")
+	assert.Error(t, err)
+}
+
+func TestFilterAllowedSchemas(t *testing.T) {
+	rows := []map[string]interface{}{{"schema_name": "public"}, {"schema_name": "hr"}, {"schema_name": "sales"}}
+
+	cfg := db.DatabaseConnectionConfig{SchemaAllowlist: []string{"sales"}}
+	assert.Equal(t, []map[string]interface{}{{"schema_name": "sales"}}, filterAllowedSchemas(cfg, rows))
+	assert.Equal(t, rows, filterAllowedSchemas(db.DatabaseConnectionConfig{}, rows))
+
+	// Other columns do not stand in for the schema name
+	withOwner := []map[string]interface{}{{"owner": "sales", "schema_name": "hr"}, {"owner": "hr", "schema_name": "sales"}}
+	assert.Equal(t, []map[string]interface{}{{"owner": "hr", "schema_name": "sales"}}, filterAllowedSchemas(cfg, withOwner))
+}
+
+func TestTableAndColumnQueriesIncludeComments(t *testing.T) {
+	pg := NewDatabaseStrategy("postgres", "public")
+	assert.Contains(t, pg.GetTablesQueries()[0].query, "obj_description")
+	assert.Contains(t, pg.GetColumnsQueries("orders")[0].query, "col_description")
+	assert.Contains(t, pg.GetColumnsQueries("orders")[1].query, "col_description")
+
+	my := NewDatabaseStrategy("mysql", "")
+	assert.Contains(t, my.GetTablesQueries()[0].query, "table_comment")
+	assert.Contains(t, my.GetColumnsQueries("orders")[0].query, "column_comment")
+}
+
+func TestSummaryQueries(t *testing.T) {
+	pg := NewDatabaseStrategy("postgres", "sales")
+	require.Len(t, pg.GetSchemaColumnsQueries(), 1)
+	assert.Contains(t, pg.GetSchemaColumnsQueries()[0].query, "is_primary_key")
+	assert.Equal(t, []interface{}{"sales"}, pg.GetSchemaColumnsQueries()[0].args)
+	assert.Contains(t, pg.GetRowEstimatesQueries()[0].query, "reltuples")
+
+	my := NewDatabaseStrategy("mysql", "")
+	assert.Contains(t, my.GetSchemaColumnsQueries()[0].query, "column_key = 'PRI'")
+	assert.Contains(t, my.GetRowEstimatesQueries()[0].query, "DATABASE()")
+	assert.Empty(t, my.GetRowEstimatesQueries()[0].args)
+
+	generic := &GenericStrategy{}
+	assert.NotEmpty(t, generic.GetSchemaColumnsQueries())
+	assert.Empty(t, generic.GetRowEstimatesQueries())
+}
+
+func TestUniqueKeysQueries(t *testing.T) {
+	pg := NewDatabaseStrategy("postgres", "sales").GetUniqueKeysQueries()
+	require.Len(t, pg, 1)
+	assert.Contains(t, pg[0].query, "x.indpred IS NULL")
+	assert.Equal(t, []interface{}{"sales"}, pg[0].args)
+
+	my := NewDatabaseStrategy("mysql", "shop").GetUniqueKeysQueries()
+	require.Len(t, my, 1)
+	assert.Contains(t, my[0].query, "non_unique = 0")
+	assert.Equal(t, []interface{}{"shop"}, my[0].args)
+
+	assert.Empty(t, (&GenericStrategy{}).GetUniqueKeysQueries())
+}
+
+func TestHypertablesQueriesFilterBySchema(t *testing.T) {
+	queries := hypertablesQueries("metrics")
+	require.Len(t, queries, 2)
+	for _, q := range queries {
+		assert.Contains(t, q.query, "= $1")
+		assert.Equal(t, []interface{}{"metrics"}, q.args)
+	}
+	assert.Contains(t, queries[0].query, "timescaledb_information.dimensions")
+	assert.Contains(t, queries[1].query, "_timescaledb_catalog.hypertable")
+
+	for _, q := range hypertablesQueries("") {
+		assert.NotContains(t, q.query, "$1")
+		assert.Empty(t, q.args)
+	}
+}
+
+func TestGetSchemaComponentValidation(t *testing.T) {
+	mockDB := new(MockDatabase)
+
+	_, err := GetSchemaComponent(context.Background(), mockDB, "bogus", "", "")
+	assert.EqualError(t, err, "invalid component: bogus")
+
+	_, err = GetSchemaComponent(context.Background(), mockDB, "columns", "", "")
+	assert.Error(t, err)
+}
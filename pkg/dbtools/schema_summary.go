@@ -0,0 +1,172 @@
+package dbtools
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+	"github.com/FreePeak/db-mcp-server/pkg/logger"
+)
+
+// GetSchemaColumnsQueries returns queries for retrieving every column in the
+// schema together with its full type and primary key membership in PostgreSQL
+func (s *PostgresStrategy) GetSchemaColumnsQueries() []queryWithArgs {
+	return []queryWithArgs{s.scoped(`
+		SELECT c.table_name, c.column_name, c.data_type, c.is_nullable, c.column_default,
+			(
+				SELECT pg_catalog.format_type(a.atttypid, a.atttypmod)
+				FROM pg_catalog.pg_attribute a
+				WHERE a.attrelid = format('%I.%I', c.table_schema, c.table_name)::regclass
+					AND a.attname = c.column_name
+			) AS column_type,
+			EXISTS (
+				SELECT 1
+				FROM information_schema.table_constraints tc
+				JOIN information_schema.key_column_usage kcu
+					ON kcu.constraint_name = tc.constraint_name
+					AND kcu.table_schema = tc.table_schema
+					AND kcu.table_name = tc.table_name
+				WHERE tc.constraint_type = 'PRIMARY KEY'
+					AND tc.table_schema = c.table_schema
+					AND tc.table_name = c.table_name
+					AND kcu.column_name = c.column_name
+			) AS is_primary_key
+		FROM information_schema.columns c
+		WHERE c.table_schema = $1
+		ORDER BY c.table_name, c.ordinal_position`)}
+}
+
+// GetRowEstimatesQueries returns queries for the planner's row estimate of
+// each table in PostgreSQL. Tables that were never analyzed report NULL.
+func (s *PostgresStrategy) GetRowEstimatesQueries() []queryWithArgs {
+	return []queryWithArgs{s.scoped(`
+		SELECT c.relname AS table_name,
+			CASE WHEN c.reltuples < 0 THEN NULL ELSE c.reltuples::bigint END AS row_estimate
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		WHERE n.nspname = $1 AND c.relkind IN ('r', 'p')`)}
+}
+
+// GetSchemaColumnsQueries returns queries for retrieving every column in the
+// schema together with its full type and primary key membership in MySQL
+func (s *MySQLStrategy) GetSchemaColumnsQueries() []queryWithArgs {
+	return []queryWithArgs{s.scoped(`
+		SELECT table_name AS table_name, column_name AS column_name,
+			data_type AS data_type, is_nullable AS is_nullable,
+			column_default AS column_default, column_type AS column_type,
+			column_key = 'PRI' AS is_primary_key
+		FROM information_schema.columns
+		WHERE table_schema = {schema}
+		ORDER BY table_name, ordinal_position`)}
+}
+
+// GetRowEstimatesQueries returns queries for InnoDB's row estimate of each
+// table in MySQL
+func (s *MySQLStrategy) GetRowEstimatesQueries() []queryWithArgs {
+	return []queryWithArgs{s.scoped(`
+		SELECT table_name AS table_name, table_rows AS row_estimate
+		FROM information_schema.tables
+		WHERE table_schema = {schema} AND table_type = 'BASE TABLE'`)}
+}
+
+// GetSchemaColumnsQueries returns generic queries for retrieving every column
+func (s *GenericStrategy) GetSchemaColumnsQueries() []queryWithArgs {
+	return []queryWithArgs{{query: `
+		SELECT table_name, column_name, data_type, is_nullable, column_default,
+			data_type AS column_type
+		FROM information_schema.columns
+		ORDER BY table_name, ordinal_position`}}
+}
+
+// GetRowEstimatesQueries returns no queries; row estimates are not portable
+func (s *GenericStrategy) GetRowEstimatesQueries() []queryWithArgs {
+	return nil
+}
+
+// getSchemaSummary collects what a compact schema summary needs in one pass:
+// tables with descriptions and row estimates, every column with primary key
+// membership, and all foreign keys
+func getSchemaSummary(ctx context.Context, database db.Database, schema string) (map[string]interface{}, error) {
+	strategy := NewDatabaseStrategy(database.DriverName(), schema)
+
+	tablesResult, err := getTables(ctx, database, schema)
+	if err != nil {
+		return nil, err
+	}
+	tablesMap, err := safeGetMap(tablesResult)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tables result: %w", err)
+	}
+	descriptions := make(map[string]interface{})
+	tableRows, _ := tablesMap["tables"].([]map[string]interface{})
+	for _, row := range tableRows {
+		if name, ok := row["table_name"].(string); ok {
+			descriptions[name] = row["description"]
+		}
+	}
+
+	columns, err := queryMaps(ctx, database, strategy.GetSchemaColumnsQueries(), "get schema columns")
+	if err != nil {
+		return nil, err
+	}
+
+	// Row estimates are a nicety; a failure only loses them
+	estimates := make(map[string]interface{})
+	if queries := strategy.GetRowEstimatesQueries(); len(queries) > 0 {
+		rows, err := queryMaps(ctx, database, queries, "get row estimates")
+		if err != nil {
+			logger.Warn("Schema summary without row estimates: %v", err)
+		}
+		for _, row := range rows {
+			if name, ok := row["table_name"].(string); ok {
+				estimates[name] = row["row_estimate"]
+			}
+		}
+	}
+
+	// Group columns by table, keeping the catalog order
+	var tables []map[string]interface{}
+	byName := make(map[string]map[string]interface{})
+	for _, column := range columns {
+		name, ok := column["table_name"].(string)
+		if !ok {
+			continue
+		}
+		table, ok := byName[name]
+		if !ok {
+			table = map[string]interface{}{
+				"table_name":   name,
+				"description":  descriptions[name],
+				"row_estimate": estimates[name],
+				"columns":      []map[string]interface{}{},
+			}
+			byName[name] = table
+			tables = append(tables, table)
+		}
+		delete(column, "table_name")
+		table["columns"] = append(table["columns"].([]map[string]interface{}), column)
+	}
+	if tables == nil {
+		tables = []map[string]interface{}{}
+	}
+
+	relationshipsResult, err := getRelationships(ctx, database, schema, "")
+	if err != nil {
+		return nil, err
+	}
+	relationshipsMap, err := safeGetMap(relationshipsResult)
+	if err != nil {
+		return nil, fmt.Errorf("invalid relationships result: %w", err)
+	}
+	relationships, _ := relationshipsMap["relationships"].([]map[string]interface{})
+	if relationships == nil {
+		relationships = []map[string]interface{}{}
+	}
+
+	return map[string]interface{}{
+		"tables":        tables,
+		"relationships": relationships,
+		"dbType":        database.DriverName(),
+		"schema":        schema,
+	}, nil
+}
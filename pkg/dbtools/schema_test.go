@@ -97,6 +97,11 @@ func (m *MockDatabase) ConnectionString() string {
 	return args.String(0)
 }
 
+func (m *MockDatabase) QueryTimeout() int {
+	args := m.Called()
+	return args.Int(0)
+}
+
 func (m *MockDatabase) DB() *sql.DB {
 	args := m.Called()
 	return args.Get(0).(*sql.DB)
@@ -0,0 +1,84 @@
+package dbtools
+
+import (
+	"regexp"
+	"strings"
+)
+
+// readStatements are the statements that only read data, unless they contain
+// a data-modifying clause
+var readStatements = map[string]bool{
+	"SELECT": true, "WITH": true, "VALUES": true, "TABLE": true,
+	"SHOW": true, "DESCRIBE": true, "DESC": true,
+}
+
+// Patterns IsDataModifying looks for outside literals and quoted identifiers
+var (
+	firstKeywordPattern = regexp.MustCompile(`^[\s(]*([A-Za-z]+)`)
+	lineCommentPattern  = regexp.MustCompile(`(?m)--.*$`)
+	modifyingPattern    = regexp.MustCompile(`(?i)\b(INSERT|UPDATE|DELETE|MERGE|REPLACE|UPSERT|TRUNCATE|INTO|CREATE|DROP|ALTER|GRANT|REVOKE|CALL|COPY|LOCK)\b|\bFOR\s+(KEY\s+)?SHARE\b`)
+	identifierPattern   = regexp.MustCompile("`[^`]*`")
+)
+
+// IsDataModifying reports whether executing a query may change data or
+// schema, or take row locks. Queries that only read start with SELECT, WITH,
+// VALUES, TABLE, SHOW or DESCRIBE and have no INSERT, UPDATE, DELETE, MERGE or
+// SELECT ... INTO clause, e.g. in a CTE, nor FOR UPDATE or FOR SHARE locking.
+// Functions called by a query are not inspected.
+func IsDataModifying(query string) bool {
+	// Comments may hold quotes and literals may hold comment markers, so
+	// either is removed first and the query only reads if both agree
+	return modifiesData(stripLiterals(stripAllComments(query))) ||
+		modifiesData(stripAllComments(stripLiterals(query)))
+}
+
+// modifiesData classifies each statement of a query without comments and
+// literals
+func modifiesData(query string) bool {
+	for _, statement := range splitStatements(query) {
+		match := firstKeywordPattern.FindStringSubmatch(statement)
+		if match == nil || !readStatements[strings.ToUpper(match[1])] {
+			return true
+		}
+		if modifyingPattern.MatchString(statement) {
+			return true
+		}
+	}
+	return false
+}
+
+// Statements splits a query into its non-empty statements, without comments
+// and with string literals and quoted identifiers emptied. As in
+// IsDataModifying both are stripped either way round, and the reading with
+// more statements is returned.
+func Statements(query string) []string {
+	statements := splitStatements(stripLiterals(stripAllComments(query)))
+	if other := splitStatements(stripAllComments(stripLiterals(query))); len(other) > len(statements) {
+		return other
+	}
+	return statements
+}
+
+// splitStatements splits a query without comments and literals at its
+// semicolons, dropping empty statements
+func splitStatements(query string) []string {
+	var statements []string
+	for _, statement := range strings.Split(query, ";") {
+		if statement = strings.TrimSpace(statement); statement != "" {
+			statements = append(statements, statement)
+		}
+	}
+	return statements
+}
+
+// stripAllComments removes block comments and the line comments of every line
+func stripAllComments(query string) string {
+	return lineCommentPattern.ReplaceAllString(StripComments(query), "")
+}
+
+// stripLiterals empties string literals and quoted identifiers
+func stripLiterals(query string) string {
+	query = stringPattern.ReplaceAllString(query, "''")
+	query = doubleQuotePattern.ReplaceAllString(query, `""`)
+	return identifierPattern.ReplaceAllString(query, "``")
+}
@@ -0,0 +1,53 @@
+package dbtools
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsDataModifying(t *testing.T) {
+	reads := []string{
+		"SELECT * FROM orders WHERE status = 'open'",
+		"  (SELECT id FROM users) UNION (SELECT id FROM admins)",
+		"WITH recent AS (SELECT * FROM orders WHERE created_at > now() - interval '1 day') SELECT count(*) FROM recent",
+		"select updated_at, deleted from audit",
+		"SELECT 'DELETE FROM users' AS example",
+		`SELECT "update" FROM t`,
+		"SELECT id FROM `insert`",
+		"-- DELETE everything\nSELECT 1",
+		"/* UPDATE */ SELECT 1;",
+		"VALUES (1), (2)",
+	}
+	for _, query := range reads {
+		assert.False(t, IsDataModifying(query), query)
+	}
+
+	writes := []string{
+		"UPDATE users SET name = 'x'",
+		"delete from orders",
+		"INSERT INTO orders (id) VALUES (1)",
+		"MERGE INTO t USING s ON t.id = s.id WHEN MATCHED THEN DELETE",
+		"WITH moved AS (DELETE FROM queue RETURNING *) SELECT * FROM moved",
+		"SELECT * INTO archive FROM orders",
+		"SELECT * FROM jobs FOR UPDATE SKIP LOCKED",
+		"SELECT * FROM jobs FOR KEY SHARE",
+		"SELECT 1; DROP TABLE users",
+		"CREATE TABLE t AS SELECT 1",
+		"TRUNCATE orders",
+		"SELECT 'a--b'; DELETE FROM users",
+		"-- don't\nDELETE FROM users",
+	}
+	for _, query := range writes {
+		assert.True(t, IsDataModifying(query), query)
+	}
+}
+
+func TestStatements(t *testing.T) {
+	assert.Equal(t, []string{"SELECT 1"}, Statements("SELECT 1;"))
+	assert.Equal(t, []string{"SELECT ''"}, Statements("/* a; b */ SELECT 'x;y' -- c; d"))
+	assert.Equal(t, []string{"DELETE FROM a", "COMMIT", "DELETE FROM b"}, Statements("DELETE FROM a; COMMIT; DELETE FROM b"))
+	assert.Len(t, Statements("SELECT 'a--b'; DROP TABLE t"), 2)
+	assert.Len(t, Statements("-- don't\nSELECT 1; DROP TABLE t"), 2)
+	assert.Empty(t, Statements(" ; -- nothing"))
+}
@@ -0,0 +1,441 @@
+package dbtools
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+	"github.com/FreePeak/db-mcp-server/pkg/db/timescale"
+)
+
+// defaultStorageLimit is the number of tables, indexes and chunks reported
+// when no limit is set
+const defaultStorageLimit = 20
+
+// Storage rankings
+const (
+	StorageBySize  = "size"  // total bytes on disk
+	StorageByBloat = "bloat" // estimated bloat, or free space on MySQL
+	StorageByDead  = "dead"  // dead tuples, PostgreSQL only
+)
+
+// Page layout used by the bloat estimates
+const (
+	pageHeaderBytes   = 24 // page header of heap and index pages
+	btreeSpecialBytes = 16 // btree page trailer
+	heapTupleHeader   = 24 // aligned heap tuple header
+	indexTupleHeader  = 8
+	itemPointerBytes  = 4
+	defaultFillfactor = 100
+	btreeFillfactor   = 90
+	defaultBlockSize  = 8192
+	maxAlignBytes     = 8
+)
+
+// StorageOptions tune the storage report
+type StorageOptions struct {
+	OrderBy string // size (default), bloat or dead
+	Limit   int    // tables, indexes and chunks to report, 20 by default
+}
+
+// StorageReport is the disk usage of a schema's tables and indexes, ranked
+// by the worst offenders
+type StorageReport struct {
+	Schema          string
+	OrderBy         string
+	Tables          int   // tables in the schema
+	TotalBytes      int64 // tables and indexes of the schema
+	DatabaseSize    string
+	HypertablesSize string
+	TopTables       []TableStorage
+	TopIndexes      []IndexStorage
+	Hypertables     []HypertableStorage
+	Notes           []string
+}
+
+// TableStorage is the disk usage of a table
+type TableStorage struct {
+	Table       string
+	TotalBytes  int64 // table, indexes and TOAST
+	TableBytes  int64
+	IndexBytes  int64
+	FreeBytes   int64 // MySQL: allocated but unused
+	BloatBytes  int64 // PostgreSQL: estimated from statistics, 0 without
+	RowEstimate float64
+	DeadTuples  int64
+	LastVacuum  string
+	LastAnalyze string
+}
+
+// IndexStorage is the disk usage of a PostgreSQL index
+type IndexStorage struct {
+	Table      string
+	Index      string
+	Method     string
+	Bytes      int64
+	BloatBytes int64 // estimated for btree indexes with statistics
+	Scans      int64
+}
+
+// HypertableStorage is the disk usage of a TimescaleDB hypertable over all
+// its chunks
+type HypertableStorage struct {
+	Table            string
+	ChunkCount       int
+	CompressedChunks int
+	TableBytes       int64
+	IndexBytes       int64
+	ToastBytes       int64
+	TotalBytes       int64
+	Chunks           []timescale.ChunkSize // most recent first, up to the limit
+}
+
+// pgTableStorageQuery reads the sizes, statistics and bloat estimate inputs
+// of the tables of a schema
+const pgTableStorageQuery = `
+	SELECT c.relname AS table_name,
+	       pg_catalog.pg_total_relation_size(c.oid) AS total_bytes,
+	       pg_catalog.pg_relation_size(c.oid) AS table_bytes,
+	       pg_catalog.pg_indexes_size(c.oid) AS index_bytes,
+	       c.reltuples AS row_estimate, c.relpages AS pages,
+	       COALESCE(s.n_dead_tup, 0) AS dead_tuples,
+	       GREATEST(s.last_vacuum, s.last_autovacuum)::text AS last_vacuum,
+	       GREATEST(s.last_analyze, s.last_autoanalyze)::text AS last_analyze,
+	       COALESCE((SELECT split_part(o, '=', 2)::int FROM unnest(c.reloptions) AS o WHERE o LIKE 'fillfactor=%'), 100) AS fillfactor,
+	       (SELECT SUM((1 - st.null_frac) * st.avg_width) FROM pg_catalog.pg_stats st
+	        WHERE st.schemaname = n.nspname AND st.tablename = c.relname) AS data_width,
+	       current_setting('block_size')::int AS block_size
+	FROM pg_catalog.pg_class c
+	JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+	LEFT JOIN pg_catalog.pg_stat_all_tables s ON s.relid = c.oid
+	WHERE n.nspname = $1 AND c.relkind IN ('r', 'm')`
+
+// pgIndexStorageQuery reads the sizes and bloat estimate inputs of the
+// indexes of a schema
+const pgIndexStorageQuery = `
+	SELECT t.relname AS table_name, ic.relname AS index_name, am.amname AS method,
+	       pg_catalog.pg_relation_size(ic.oid) AS index_bytes,
+	       ic.reltuples AS row_estimate, ic.relpages AS pages,
+	       COALESCE(su.idx_scan, 0) AS scans,
+	       COALESCE((SELECT split_part(o, '=', 2)::int FROM unnest(ic.reloptions) AS o WHERE o LIKE 'fillfactor=%'), 90) AS fillfactor,
+	       w.data_width, w.stats_columns, i.indnatts AS columns,
+	       current_setting('block_size')::int AS block_size
+	FROM pg_catalog.pg_index i
+	JOIN pg_catalog.pg_class ic ON ic.oid = i.indexrelid
+	JOIN pg_catalog.pg_class t ON t.oid = i.indrelid
+	JOIN pg_catalog.pg_namespace n ON n.oid = t.relnamespace
+	JOIN pg_catalog.pg_am am ON am.oid = ic.relam
+	LEFT JOIN pg_catalog.pg_stat_all_indexes su ON su.indexrelid = i.indexrelid
+	LEFT JOIN LATERAL (
+		SELECT SUM(st.avg_width) AS data_width, count(st.attname) AS stats_columns
+		FROM unnest(i.indkey) AS k(attnum)
+		JOIN pg_catalog.pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = k.attnum
+		JOIN pg_catalog.pg_stats st ON st.schemaname = n.nspname AND st.tablename = t.relname AND st.attname = a.attname
+	) w ON true
+	WHERE n.nspname = $1 AND t.relkind IN ('r', 'm')`
+
+// mysqlTableStorageQuery reads the sizes of the tables of a schema
+const mysqlTableStorageQuery = `
+	SELECT table_name AS table_name, data_length + index_length AS total_bytes,
+	       data_length AS table_bytes, index_length AS index_bytes, data_free AS free_bytes,
+	       table_rows AS row_estimate
+	FROM information_schema.tables
+	WHERE table_schema = {schema} AND table_type = 'BASE TABLE'`
+
+// GetStorageStats reports the disk usage of the tables and indexes of a
+// schema, ranked by size, bloat or dead tuples
+func GetStorageStats(ctx context.Context, dbID, schema string, opts StorageOptions) (*StorageReport, error) {
+	database, err := GetDatabase(dbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database: %w", err)
+	}
+	resolved, err := ResolveSchema(dbID, schema)
+	if err != nil {
+		return nil, err
+	}
+	return storageStats(ctx, database, resolved, opts)
+}
+
+// storageStats builds the storage report of a schema
+func storageStats(ctx context.Context, database db.Database, schema string, opts StorageOptions) (*StorageReport, error) {
+	driver := database.DriverName()
+	if driver != "postgres" && driver != "mysql" {
+		return nil, fmt.Errorf("storage statistics are not supported for %s databases", driver)
+	}
+	switch opts.OrderBy {
+	case "":
+		opts.OrderBy = StorageBySize
+	case StorageBySize, StorageByBloat:
+	case StorageByDead:
+		if driver != "postgres" {
+			return nil, fmt.Errorf("ranking by dead tuples is only supported on PostgreSQL")
+		}
+	default:
+		return nil, fmt.Errorf("invalid ranking %q: use size, bloat or dead", opts.OrderBy)
+	}
+	if opts.Limit <= 0 {
+		opts.Limit = defaultStorageLimit
+	}
+	if driver == "postgres" {
+		schema = (&PostgresStrategy{Schema: schema}).schema()
+	}
+
+	report := &StorageReport{Schema: schema, OrderBy: opts.OrderBy}
+	hypertables := make(map[string]bool)
+	if driver == "postgres" {
+		timescaleStorage(ctx, database, schema, opts.Limit, report)
+		for _, hypertable := range report.Hypertables {
+			hypertables[hypertable.Table] = true
+		}
+	}
+
+	tables, err := tableStorage(ctx, database, schema)
+	if err != nil {
+		return nil, err
+	}
+	var indexes []IndexStorage
+	if driver == "postgres" {
+		if indexes, err = indexStorage(ctx, database, schema); err != nil {
+			return nil, err
+		}
+	} else {
+		report.Notes = append(report.Notes, "free space is allocated but unused space; tables in a shared tablespace report the tablespace's free space")
+	}
+
+	// Hypertables are reported with their chunks
+	kept := tables[:0]
+	for _, table := range tables {
+		if !hypertables[table.Table] {
+			kept = append(kept, table)
+		}
+	}
+	tables = kept
+	keptIndexes := indexes[:0]
+	for _, index := range indexes {
+		if !hypertables[index.Table] {
+			keptIndexes = append(keptIndexes, index)
+		}
+	}
+	indexes = keptIndexes
+
+	report.Tables = len(tables)
+	withoutStats := 0
+	for _, table := range tables {
+		report.TotalBytes += table.TotalBytes
+		if driver == "postgres" && table.LastAnalyze == "" && table.TableBytes > 0 {
+			withoutStats++
+		}
+	}
+	if withoutStats > 0 && opts.OrderBy == StorageByBloat {
+		report.Notes = append(report.Notes, fmt.Sprintf("%d tables were never analyzed; run ANALYZE for their bloat estimates", withoutStats))
+	}
+
+	report.TopTables = rankTables(tables, opts.OrderBy, opts.Limit)
+	report.TopIndexes = rankIndexes(indexes, opts.OrderBy, opts.Limit)
+	return report, nil
+}
+
+// tableStorage reads the disk usage of the tables of a schema
+func tableStorage(ctx context.Context, database db.Database, schema string) ([]TableStorage, error) {
+	var q queryWithArgs
+	if database.DriverName() == "mysql" {
+		q = (&MySQLStrategy{Schema: schema}).scoped(mysqlTableStorageQuery)
+	} else {
+		q = (&PostgresStrategy{Schema: schema}).scoped(pgTableStorageQuery)
+	}
+	results, err := queryMaps(ctx, database, []queryWithArgs{q}, "get table storage")
+	if err != nil {
+		return nil, err
+	}
+
+	tables := make([]TableStorage, 0, len(results))
+	for _, row := range results {
+		table := TableStorage{
+			Table:       stringValue(row["table_name"], ""),
+			TotalBytes:  int64(numberValue(row["total_bytes"])),
+			TableBytes:  int64(numberValue(row["table_bytes"])),
+			IndexBytes:  int64(numberValue(row["index_bytes"])),
+			FreeBytes:   int64(numberValue(row["free_bytes"])),
+			RowEstimate: math.Max(numberValue(row["row_estimate"]), 0),
+			DeadTuples:  int64(numberValue(row["dead_tuples"])),
+			LastVacuum:  stringValue(row["last_vacuum"], ""),
+			LastAnalyze: stringValue(row["last_analyze"], ""),
+		}
+		if row["data_width"] != nil {
+			tupleBytes := alignStorage(heapTupleHeader+numberValue(row["data_width"])) + itemPointerBytes
+			table.BloatBytes = estimateBloat(numberValue(row["pages"]), numberValue(row["row_estimate"]), tupleBytes,
+				blockSize(row), pageHeaderBytes, fillfactor(row, defaultFillfactor))
+		}
+		tables = append(tables, table)
+	}
+	return tables, nil
+}
+
+// indexStorage reads the disk usage of the indexes of a PostgreSQL schema
+func indexStorage(ctx context.Context, database db.Database, schema string) ([]IndexStorage, error) {
+	results, err := queryMaps(ctx, database, []queryWithArgs{(&PostgresStrategy{Schema: schema}).scoped(pgIndexStorageQuery)}, "get index storage")
+	if err != nil {
+		return nil, err
+	}
+
+	indexes := make([]IndexStorage, 0, len(results))
+	for _, row := range results {
+		index := IndexStorage{
+			Table:  stringValue(row["table_name"], ""),
+			Index:  stringValue(row["index_name"], ""),
+			Method: stringValue(row["method"], ""),
+			Bytes:  int64(numberValue(row["index_bytes"])),
+			Scans:  int64(numberValue(row["scans"])),
+		}
+		// Expression columns have no statistics of their own
+		if index.Method == "btree" && numberValue(row["stats_columns"]) == numberValue(row["columns"]) {
+			tupleBytes := alignStorage(indexTupleHeader+numberValue(row["data_width"])) + itemPointerBytes
+			index.BloatBytes = estimateBloat(numberValue(row["pages"]), numberValue(row["row_estimate"]), tupleBytes,
+				blockSize(row), pageHeaderBytes+btreeSpecialBytes, fillfactor(row, btreeFillfactor))
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// timescaleStorage adds the hypertables of a schema with their chunks and
+// the database size to a report. Without TimescaleDB nothing is added; errors
+// become notes, as the tables are still worth reporting.
+func timescaleStorage(ctx context.Context, database db.Database, schema string, limit int, report *StorageReport) {
+	tsdb, err := timescale.NewTimescaleDBFromDatabase(ctx, database)
+	if err != nil {
+		report.Notes = append(report.Notes, fmt.Sprintf("TimescaleDB detection failed: %v", err))
+		return
+	}
+	if !tsdb.IsTimescaleDB() {
+		return
+	}
+
+	if size, err := tsdb.GetDatabaseSize(ctx); err == nil {
+		report.DatabaseSize = size["database_size"]
+		report.HypertablesSize = size["hypertables_size"]
+	} else {
+		report.Notes = append(report.Notes, fmt.Sprintf("database size unavailable: %v", err))
+	}
+
+	hypertables, err := tsdb.ListHypertables(ctx)
+	if err != nil {
+		report.Notes = append(report.Notes, fmt.Sprintf("hypertables unavailable: %v", err))
+		return
+	}
+	for _, hypertable := range hypertables {
+		if hypertable.SchemaName != schema {
+			continue
+		}
+		chunks, err := tsdb.ChunkSizes(ctx, schema, hypertable.TableName)
+		if err != nil {
+			report.Notes = append(report.Notes, fmt.Sprintf("chunk sizes of %s unavailable: %v", hypertable.TableName, err))
+			continue
+		}
+		report.Hypertables = append(report.Hypertables, summarizeChunks(hypertable.TableName, chunks, limit))
+	}
+	sort.Slice(report.Hypertables, func(i, j int) bool {
+		return report.Hypertables[i].TotalBytes > report.Hypertables[j].TotalBytes
+	})
+}
+
+// summarizeChunks totals the chunks of a hypertable, keeping the most recent
+func summarizeChunks(table string, chunks []timescale.ChunkSize, limit int) HypertableStorage {
+	storage := HypertableStorage{Table: table, ChunkCount: len(chunks)}
+	for _, chunk := range chunks {
+		storage.TableBytes += chunk.TableBytes
+		storage.IndexBytes += chunk.IndexBytes
+		storage.ToastBytes += chunk.ToastBytes
+		storage.TotalBytes += chunk.TotalBytes
+		if chunk.IsCompressed {
+			storage.CompressedChunks++
+		}
+	}
+	if len(chunks) > limit {
+		chunks = chunks[:limit]
+	}
+	storage.Chunks = chunks
+	return storage
+}
+
+// rankTables orders tables worst first and keeps the top ones
+func rankTables(tables []TableStorage, orderBy string, limit int) []TableStorage {
+	key := func(t TableStorage) float64 {
+		switch orderBy {
+		case StorageByBloat:
+			return float64(t.BloatBytes + t.FreeBytes)
+		case StorageByDead:
+			return float64(t.DeadTuples)
+		}
+		return float64(t.TotalBytes)
+	}
+	sort.SliceStable(tables, func(i, j int) bool {
+		if key(tables[i]) != key(tables[j]) {
+			return key(tables[i]) > key(tables[j])
+		}
+		return tables[i].Table < tables[j].Table
+	})
+	if len(tables) > limit {
+		tables = tables[:limit]
+	}
+	return tables
+}
+
+// rankIndexes orders indexes worst first and keeps the top ones; indexes
+// have no dead tuples and are ranked by size for that ranking
+func rankIndexes(indexes []IndexStorage, orderBy string, limit int) []IndexStorage {
+	key := func(index IndexStorage) int64 {
+		if orderBy == StorageByBloat {
+			return index.BloatBytes
+		}
+		return index.Bytes
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		if key(indexes[i]) != key(indexes[j]) {
+			return key(indexes[i]) > key(indexes[j])
+		}
+		return indexes[i].Index < indexes[j].Index
+	})
+	if len(indexes) > limit {
+		indexes = indexes[:limit]
+	}
+	return indexes
+}
+
+// estimateBloat estimates the bytes of a relation beyond what its rows need
+// when packed into pages at the fillfactor. Relations never analyzed have a
+// negative row count and no estimate.
+func estimateBloat(pages, rows, tupleBytes, blockSize, pageOverhead, fillfactor float64) int64 {
+	if pages <= 0 || rows < 0 || tupleBytes <= 0 {
+		return 0
+	}
+	usable := (blockSize - pageOverhead) * fillfactor / 100
+	expected := math.Max(math.Ceil(rows*tupleBytes/usable), 1)
+	if pages <= expected {
+		return 0
+	}
+	return int64((pages - expected) * blockSize)
+}
+
+// alignStorage rounds a tuple size up to the storage alignment
+func alignStorage(bytes float64) float64 {
+	return math.Ceil(bytes/maxAlignBytes) * maxAlignBytes
+}
+
+// blockSize reads the block size column of a storage row
+func blockSize(row map[string]interface{}) float64 {
+	if size := numberValue(row["block_size"]); size > 0 {
+		return size
+	}
+	return defaultBlockSize
+}
+
+// fillfactor reads the fillfactor column of a storage row
+func fillfactor(row map[string]interface{}, fallback float64) float64 {
+	if value := numberValue(row["fillfactor"]); value > 0 {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,70 @@
+package dbtools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db/timescale"
+)
+
+func TestEstimateBloat(t *testing.T) {
+	// 100000 rows of 36 bytes take 68 bytes each with their headers, 833 pages of 8 kB
+	tupleBytes := alignStorage(heapTupleHeader+36) + itemPointerBytes
+	assert.Equal(t, 68.0, tupleBytes)
+	assert.Equal(t, int64(0), estimateBloat(830, 100000, tupleBytes, 8192, pageHeaderBytes, 100))
+	assert.Equal(t, int64(1000*8192), estimateBloat(1833, 100000, tupleBytes, 8192, pageHeaderBytes, 100))
+
+	// A lower fillfactor leaves room on purpose
+	assert.Equal(t, int64(0), estimateBloat(1660, 100000, tupleBytes, 8192, pageHeaderBytes, 50))
+
+	// Never analyzed, or empty
+	assert.Equal(t, int64(0), estimateBloat(1833, -1, tupleBytes, 8192, pageHeaderBytes, 100))
+	assert.Equal(t, int64(0), estimateBloat(0, 0, tupleBytes, 8192, pageHeaderBytes, 100))
+}
+
+func TestRankTables(t *testing.T) {
+	tables := []TableStorage{
+		{Table: "events", TotalBytes: 900, BloatBytes: 10, DeadTuples: 5},
+		{Table: "orders", TotalBytes: 500, BloatBytes: 300, DeadTuples: 9000},
+		{Table: "users", TotalBytes: 100, FreeBytes: 200},
+	}
+	names := func(tables []TableStorage) []string {
+		var result []string
+		for _, table := range tables {
+			result = append(result, table.Table)
+		}
+		return result
+	}
+	assert.Equal(t, []string{"events", "orders"}, names(rankTables(tables, StorageBySize, 2)))
+	assert.Equal(t, []string{"orders", "users", "events"}, names(rankTables(tables, StorageByBloat, 20)))
+	assert.Equal(t, []string{"orders", "events", "users"}, names(rankTables(tables, StorageByDead, 20)))
+}
+
+func TestSummarizeChunks(t *testing.T) {
+	storage := summarizeChunks("metrics", []timescale.ChunkSize{
+		{ChunkName: "_hyper_1_3_chunk", TableBytes: 100, IndexBytes: 50, TotalBytes: 150},
+		{ChunkName: "_hyper_1_2_chunk", TableBytes: 10, IndexBytes: 5, ToastBytes: 1, TotalBytes: 16, IsCompressed: true},
+		{ChunkName: "_hyper_1_1_chunk", TableBytes: 10, IndexBytes: 5, TotalBytes: 15, IsCompressed: true},
+	}, 2)
+	assert.Equal(t, 3, storage.ChunkCount)
+	assert.Equal(t, 2, storage.CompressedChunks)
+	assert.Equal(t, int64(181), storage.TotalBytes)
+	assert.Equal(t, int64(60), storage.IndexBytes)
+	require.Len(t, storage.Chunks, 2)
+	assert.Equal(t, "_hyper_1_3_chunk", storage.Chunks[0].ChunkName)
+}
+
+func TestStorageStatsOptions(t *testing.T) {
+	database, _ := newExplainDatabase("mysql", "")
+	_, err := storageStats(context.Background(), database, "shop", StorageOptions{OrderBy: StorageByDead})
+	assert.ErrorContains(t, err, "only supported on PostgreSQL")
+	_, err = storageStats(context.Background(), database, "shop", StorageOptions{OrderBy: "rows"})
+	assert.ErrorContains(t, err, "invalid ranking")
+
+	database, _ = newExplainDatabase("sqlite", "")
+	_, err = storageStats(context.Background(), database, "main", StorageOptions{})
+	assert.ErrorContains(t, err, "not supported")
+}
@@ -0,0 +1,278 @@
+package dbtools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FreePeak/db-mcp-server/pkg/db"
+)
+
+// WorkloadStats are the statement statistics a database keeps itself, so
+// they cover queries from every client, not only those run through the
+// server. Queries are normalized into fingerprint metrics.
+type WorkloadStats struct {
+	Source    string // pg_stat_statements or performance_schema
+	Available bool
+	Reason    string // why the statistics are not available
+	Queries   []*QueryMetrics
+}
+
+// WorkloadOrders are the rankings of workload statistics
+var WorkloadOrders = []string{"total_time", "mean_time", "calls", "rows", "io"}
+
+// pgWorkloadOrders and mysqlWorkloadOrders map rankings to ORDER BY
+// expressions; io ranks by blocks read from disk on PostgreSQL and rows
+// examined on MySQL
+var (
+	pgWorkloadOrders = map[string]string{
+		"total_time": "total_time",
+		"mean_time":  "mean_time",
+		"calls":      "calls",
+		"rows":       "row_count",
+		"io":         "shared_blks_read",
+	}
+	mysqlWorkloadOrders = map[string]string{
+		"total_time": "SUM_TIMER_WAIT",
+		"mean_time":  "AVG_TIMER_WAIT",
+		"calls":      "COUNT_STAR",
+		"rows":       "row_count",
+		"io":         "SUM_ROWS_EXAMINED",
+	}
+)
+
+// GetWorkloadStatistics reads up to limit statements from the statistics of
+// a database, pg_stat_statements on PostgreSQL and the performance_schema
+// statement digests on MySQL, ranked by orderBy
+func GetWorkloadStatistics(ctx context.Context, dbID, orderBy string, limit int) (*WorkloadStats, error) {
+	if dbManager == nil {
+		return nil, fmt.Errorf("database manager not initialized")
+	}
+	database, err := dbManager.GetDatabase(dbID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get database: %w", err)
+	}
+	return workloadStatistics(ctx, database, orderBy, limit)
+}
+
+// workloadStatistics reads the statement statistics of a database
+func workloadStatistics(ctx context.Context, database db.Database, orderBy string, limit int) (*WorkloadStats, error) {
+	if orderBy == "" {
+		orderBy = "total_time"
+	}
+	if limit <= 0 {
+		limit = 10
+	}
+
+	switch database.DriverName() {
+	case "postgres":
+		return pgWorkloadStatistics(ctx, database, orderBy, limit)
+	case "mysql":
+		return mysqlWorkloadStatistics(ctx, database, orderBy, limit)
+	default:
+		return &WorkloadStats{Reason: fmt.Sprintf("workload statistics are not supported for %s databases", database.DriverName())}, nil
+	}
+}
+
+// pgWorkloadStatistics reads pg_stat_statements for the current database
+func pgWorkloadStatistics(ctx context.Context, database db.Database, orderBy string, limit int) (*WorkloadStats, error) {
+	stats := &WorkloadStats{Source: "pg_stat_statements"}
+
+	var installed bool
+	err := database.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pg_stat_statements')").Scan(&installed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for pg_stat_statements: %w", err)
+	}
+	if !installed {
+		stats.Reason = "the pg_stat_statements extension is not installed in this database; " +
+			"add it to shared_preload_libraries and run CREATE EXTENSION pg_stat_statements"
+		return stats, nil
+	}
+
+	var version int
+	if err := database.QueryRow(ctx, "SELECT current_setting('server_version_num')::int").Scan(&version); err != nil {
+		return nil, fmt.Errorf("failed to read the server version: %w", err)
+	}
+	query, err := pgWorkloadQuery(version, orderBy)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := database.Query(ctx, query, limit)
+	if err != nil {
+		if strings.Contains(err.Error(), "shared_preload_libraries") {
+			stats.Reason = "pg_stat_statements is installed but not loaded; add it to shared_preload_libraries and restart the server"
+			return stats, nil
+		}
+		return nil, fmt.Errorf("failed to read pg_stat_statements: %w", err)
+	}
+	defer cleanupRows(rows)
+	results, err := rowsToMaps(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pg_stat_statements: %w", err)
+	}
+
+	stats.Available = true
+	stats.Queries = workloadMetrics(results)
+	return stats, nil
+}
+
+// pgWorkloadQuery builds the pg_stat_statements query for a server version:
+// the timing columns gained an exec_ prefix in 13 and the block I/O timings a
+// shared_ prefix in 17
+func pgWorkloadQuery(version int, orderBy string) (string, error) {
+	order, ok := pgWorkloadOrders[orderBy]
+	if !ok {
+		return "", invalidWorkloadOrder(orderBy)
+	}
+
+	timing := "time"
+	if version >= 130000 {
+		timing = "exec_time"
+	}
+	ioTime := "blk_read_time + blk_write_time"
+	if version >= 170000 {
+		ioTime = "shared_blk_read_time + shared_blk_write_time"
+	}
+
+	return fmt.Sprintf(`SELECT COALESCE(queryid::text, '') AS fingerprint, query, calls, rows AS row_count,
+       total_%[1]s AS total_time, mean_%[1]s AS mean_time, min_%[1]s AS min_time, max_%[1]s AS max_time,
+       shared_blks_hit, shared_blks_read, %[2]s AS io_time
+FROM pg_stat_statements
+WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+ORDER BY %[3]s DESC
+LIMIT $1`, timing, ioTime, order), nil
+}
+
+// mysqlWorkloadStatistics reads the statement digests of the current
+// database from performance_schema
+func mysqlWorkloadStatistics(ctx context.Context, database db.Database, orderBy string, limit int) (*WorkloadStats, error) {
+	stats := &WorkloadStats{Source: "performance_schema"}
+
+	var enabled int
+	if err := database.QueryRow(ctx, "SELECT @@performance_schema").Scan(&enabled); err != nil {
+		return nil, fmt.Errorf("failed to check for performance_schema: %w", err)
+	}
+	if enabled == 0 {
+		stats.Reason = "performance_schema is disabled; start the server with performance_schema=ON"
+		return stats, nil
+	}
+
+	query, err := mysqlWorkloadQuery(orderBy, true)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := database.Query(ctx, query, limit)
+	if err != nil {
+		// Latency quantiles and query samples are only kept from MySQL 8.0
+		query, _ = mysqlWorkloadQuery(orderBy, false)
+		rows, err = database.Query(ctx, query, limit)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read performance_schema statement digests: %w", err)
+	}
+	defer cleanupRows(rows)
+	results, err := rowsToMaps(rows)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read performance_schema statement digests: %w", err)
+	}
+
+	stats.Available = true
+	stats.Queries = workloadMetrics(results)
+	return stats, nil
+}
+
+// mysqlWorkloadQuery builds the statement digest query. Timers are in
+// picoseconds and converted to milliseconds.
+func mysqlWorkloadQuery(orderBy string, quantiles bool) (string, error) {
+	order, ok := mysqlWorkloadOrders[orderBy]
+	if !ok {
+		return "", invalidWorkloadOrder(orderBy)
+	}
+
+	percentiles := ""
+	if quantiles {
+		percentiles = "\n       QUANTILE_95 / 1000000000 AS p95_time, QUANTILE_99 / 1000000000 AS p99_time, QUERY_SAMPLE_TEXT AS sample,"
+	}
+	return fmt.Sprintf(`SELECT COALESCE(DIGEST, '') AS fingerprint, COALESCE(DIGEST_TEXT, '') AS query, COUNT_STAR AS calls,
+       SUM_ROWS_SENT + SUM_ROWS_AFFECTED AS row_count, SUM_ROWS_EXAMINED AS rows_examined, SUM_ERRORS AS errors,
+       SUM_TIMER_WAIT / 1000000000 AS total_time, AVG_TIMER_WAIT / 1000000000 AS mean_time,
+       MIN_TIMER_WAIT / 1000000000 AS min_time, MAX_TIMER_WAIT / 1000000000 AS max_time,%s
+       LAST_SEEN AS last_seen
+FROM performance_schema.events_statements_summary_by_digest
+WHERE SCHEMA_NAME = DATABASE()
+ORDER BY %s DESC
+LIMIT ?`, percentiles, order), nil
+}
+
+// workloadMetrics normalizes statistics rows into fingerprint metrics.
+// Times are in milliseconds; missing columns stay zero.
+func workloadMetrics(results []map[string]interface{}) []*QueryMetrics {
+	metrics := make([]*QueryMetrics, 0, len(results))
+	for _, row := range results {
+		m := &QueryMetrics{
+			Fingerprint:   stringValue(row["fingerprint"], ""),
+			Query:         stringValue(row["query"], ""),
+			Count:         int(numberValue(row["calls"])),
+			Errors:        int(numberValue(row["errors"])),
+			Rows:          int64(numberValue(row["row_count"])),
+			RowsExamined:  int64(numberValue(row["rows_examined"])),
+			BlocksHit:     int64(numberValue(row["shared_blks_hit"])),
+			BlocksRead:    int64(numberValue(row["shared_blks_read"])),
+			TotalDuration: millisecondsDuration(row["total_time"]),
+			AvgDuration:   millisecondsDuration(row["mean_time"]),
+			MinDuration:   millisecondsDuration(row["min_time"]),
+			MaxDuration:   millisecondsDuration(row["max_time"]),
+			P95Duration:   millisecondsDuration(row["p95_time"]),
+			P99Duration:   millisecondsDuration(row["p99_time"]),
+			IOTime:        millisecondsDuration(row["io_time"]),
+			Sample:        stringValue(row["sample"], ""),
+		}
+		switch lastSeen := row["last_seen"].(type) {
+		case time.Time:
+			m.LastExecuted = lastSeen
+		case string:
+			if parsed, err := time.Parse("2006-01-02 15:04:05.999999", lastSeen); err == nil {
+				m.LastExecuted = parsed
+			}
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics
+}
+
+// invalidWorkloadOrder reports an unknown ranking
+func invalidWorkloadOrder(orderBy string) error {
+	return fmt.Errorf("invalid order %q: use %s", orderBy, strings.Join(WorkloadOrders, ", "))
+}
+
+// numberValue converts a numeric column value, which drivers return as
+// integers, floats or decimal text, to float64
+func numberValue(value interface{}) float64 {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case int:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case float64:
+		return v
+	case float32:
+		return float64(v)
+	case []byte:
+		n, _ := strconv.ParseFloat(string(v), 64)
+		return n
+	case string:
+		n, _ := strconv.ParseFloat(v, 64)
+		return n
+	}
+	return 0
+}
+
+// millisecondsDuration converts a column value in milliseconds
+func millisecondsDuration(value interface{}) time.Duration {
+	return time.Duration(numberValue(value) * float64(time.Millisecond))
+}
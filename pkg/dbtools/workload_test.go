@@ -0,0 +1,88 @@
+package dbtools
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPgWorkloadQuery(t *testing.T) {
+	query, err := pgWorkloadQuery(120005, "total_time")
+	require.NoError(t, err)
+	assert.Contains(t, query, "total_time AS total_time, mean_time AS mean_time")
+	assert.Contains(t, query, "blk_read_time + blk_write_time AS io_time")
+	assert.Contains(t, query, "ORDER BY total_time DESC\nLIMIT $1")
+
+	query, err = pgWorkloadQuery(160002, "io")
+	require.NoError(t, err)
+	assert.Contains(t, query, "total_exec_time AS total_time, mean_exec_time AS mean_time")
+	assert.Contains(t, query, " blk_read_time + blk_write_time AS io_time")
+	assert.Contains(t, query, "ORDER BY shared_blks_read DESC")
+
+	query, err = pgWorkloadQuery(170000, "calls")
+	require.NoError(t, err)
+	assert.Contains(t, query, "shared_blk_read_time + shared_blk_write_time AS io_time")
+
+	_, err = pgWorkloadQuery(170000, "p95_time")
+	assert.ErrorContains(t, err, `invalid order "p95_time"`)
+}
+
+func TestMySQLWorkloadQuery(t *testing.T) {
+	query, err := mysqlWorkloadQuery("mean_time", true)
+	require.NoError(t, err)
+	assert.Contains(t, query, "QUANTILE_95 / 1000000000 AS p95_time")
+	assert.Contains(t, query, "WHERE SCHEMA_NAME = DATABASE()\nORDER BY AVG_TIMER_WAIT DESC\nLIMIT ?")
+
+	query, err = mysqlWorkloadQuery("io", false)
+	require.NoError(t, err)
+	assert.NotContains(t, query, "QUANTILE")
+	assert.Contains(t, query, "ORDER BY SUM_ROWS_EXAMINED DESC")
+}
+
+func TestWorkloadMetrics(t *testing.T) {
+	metrics := workloadMetrics([]map[string]interface{}{
+		{
+			"fingerprint": "-4617514393217834925", "query": "SELECT * FROM orders WHERE id = $1",
+			"calls": int64(120), "row_count": int64(120), "total_time": 360.5, "mean_time": 3.004,
+			"min_time": 0.5, "max_time": 41.25, "shared_blks_hit": int64(900), "shared_blks_read": int64(12), "io_time": 2.5,
+		},
+		{
+			// MySQL returns the picosecond divisions as decimal text
+			"fingerprint": "8c3d", "query": "SELECT * FROM `orders` WHERE `id` = ?",
+			"calls": "40", "row_count": "40", "rows_examined": "4000", "errors": "2",
+			"total_time": "80.000000", "mean_time": "2.0000", "p95_time": "5.7544", "p99_time": "9.1201",
+			"last_seen": "2024-03-01 10:15:00.123456",
+		},
+	})
+	require.Len(t, metrics, 2)
+
+	pg := metrics[0]
+	assert.Equal(t, "-4617514393217834925", pg.Fingerprint)
+	assert.Equal(t, 120, pg.Count)
+	assert.Equal(t, 360500*time.Microsecond, pg.TotalDuration)
+	assert.Equal(t, 41250*time.Microsecond, pg.MaxDuration)
+	assert.Equal(t, int64(12), pg.BlocksRead)
+	assert.Equal(t, 2500*time.Microsecond, pg.IOTime)
+	assert.Zero(t, pg.P95Duration)
+
+	mysql := metrics[1]
+	assert.Equal(t, 40, mysql.Count)
+	assert.Equal(t, 2, mysql.Errors)
+	assert.Equal(t, int64(4000), mysql.RowsExamined)
+	assert.Equal(t, 80*time.Millisecond, mysql.TotalDuration)
+	assert.Equal(t, 9120100*time.Nanosecond, mysql.P99Duration)
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 15, 0, 123456000, time.UTC), mysql.LastExecuted)
+}
+
+func TestWorkloadStatisticsUnsupported(t *testing.T) {
+	database := new(MockDatabase)
+	database.On("DriverName").Return("sqlite3")
+
+	stats, err := workloadStatistics(context.Background(), database, "", 0)
+	require.NoError(t, err)
+	assert.False(t, stats.Available)
+	assert.Equal(t, "workload statistics are not supported for sqlite3 databases", stats.Reason)
+}
@@ -14,14 +14,23 @@ var (
 	initialized bool = false
 	level       string
 	logFile     *os.File
+	stdioMode   bool
 )
 
-// Initialize sets up the logger with the specified level
+// Initialize sets up the logger with the specified level, reading the
+// transport mode from the TRANSPORT_MODE environment variable
 func Initialize(logLevel string) {
+	InitializeWithTransport(logLevel, os.Getenv("TRANSPORT_MODE"))
+}
+
+// InitializeWithTransport sets up the logger with the specified level for the
+// given transport mode
+func InitializeWithTransport(logLevel, transportMode string) {
 	level = logLevel
+	stdioMode = transportMode == "stdio"
 
 	// If in stdio mode, redirect logs to a file
-	if os.Getenv("TRANSPORT_MODE") == "stdio" {
+	if stdioMode {
 		// Create logs directory if it doesn't exist
 		logsDir := "logs"
 		if _, err := os.Stat(logsDir); os.IsNotExist(err) {
@@ -113,7 +122,7 @@ func logMessage(level string, format string, v ...interface{}) {
 	message := fmt.Sprintf(format, v...)
 
 	// If we're in stdio mode, avoid stdout completely
-	if os.Getenv("TRANSPORT_MODE") == "stdio" {
+	if stdioMode {
 		if logFile != nil {
 			// Format the message with timestamp
 			timestamp := time.Now().Format("2006-01-02 15:04:05")